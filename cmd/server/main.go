@@ -13,6 +13,7 @@ import (
 	"bgame/internal/config"
 	"bgame/internal/model"
 	"bgame/internal/router"
+	"bgame/internal/service"
 	"bgame/internal/util"
 	"bgame/pkg/mysql"
 	"bgame/pkg/redis"
@@ -38,6 +39,11 @@ import (
 // @name Authorization
 // @description Type "Bearer" followed by a space and JWT token.
 
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @description 服务端接口凭证，见配置 server_api.keys
+
 func main() {
 	// 加载配置
 	configPath := "config.yaml"
@@ -55,6 +61,12 @@ func main() {
 	}
 	util.Info("日志系统初始化成功")
 
+	// 加载JWT签名密钥
+	if err := util.InitJWTKeys(); err != nil {
+		util.LogError("加载JWT密钥失败: %v", err)
+		log.Fatalf("加载JWT密钥失败: %v", err)
+	}
+
 	// 初始化MySQL
 	if err := mysql.Init(); err != nil {
 		util.LogError("初始化MySQL失败: %v", err)
@@ -78,6 +90,32 @@ func main() {
 	}
 	util.Info("数据库表迁移完成")
 
+	// 初始化内置角色和默认权限
+	if err := service.NewRoleService().SeedDefaults(); err != nil {
+		util.LogError("初始化内置角色失败: %v", err)
+		log.Fatalf("初始化内置角色失败: %v", err)
+	}
+
+	// 尚无管理员时输出一次性初始化token，用于创建第一个超级管理员
+	setupToken, err := service.NewBootstrapService().PrepareSetupToken()
+	if err != nil {
+		util.LogError("生成初始化token失败: %v", err)
+	} else if setupToken != "" {
+		// 只输出到控制台，不写入日志文件
+		log.Printf("系统尚未创建管理员，请在24小时内使用初始化token调用 POST /api/admin/setup 创建超级管理员（或运行 go run scripts/create_admin.go）")
+		log.Printf("初始化token: %s", setupToken)
+	}
+
+	// 启动排行榜归档任务
+	archiverCtx, stopArchiver := context.WithCancel(context.Background())
+	defer stopArchiver()
+	service.NewLeaderboardService().StartArchiver(archiverCtx)
+
+	// 启动超时充值订单关闭任务
+	expirerCtx, stopExpirer := context.WithCancel(context.Background())
+	defer stopExpirer()
+	service.NewPaymentService().StartExpirer(expirerCtx)
+
 	// 设置路由
 	r := router.SetupRouter()
 
@@ -125,7 +163,32 @@ func main() {
 func autoMigrate() error {
 	if err := mysql.DB.AutoMigrate(
 		&model.User{},
+		&model.UserProfile{},
 		&model.Admin{},
+		&model.AdminTwoFactor{},
+		&model.AdminRecoveryCode{},
+		&model.Role{},
+		&model.RolePermission{},
+		&model.WalletLedgerEntry{},
+		&model.AdminAuditLog{},
+		&model.ExperienceLog{},
+		&model.LeaderboardArchive{},
+		&model.UserCheckin{},
+		&model.RedeemBatch{},
+		&model.RedeemCode{},
+		&model.RedeemRecord{},
+		&model.Item{},
+		&model.UserItem{},
+		&model.ItemLog{},
+		&model.ShopSku{},
+		&model.ShopPurchase{},
+		&model.PaymentOrder{},
+		&model.PaymentCallbackLog{},
+		&model.Mail{},
+		&model.UserMail{},
+		&model.FriendRequest{},
+		&model.Friendship{},
+		&model.UserBlock{},
 	); err != nil {
 		return fmt.Errorf("数据库迁移失败: %w", err)
 	}
@@ -11,12 +11,24 @@ import (
 var Cfg *Config
 
 type Config struct {
-	Server    ServerConfig    `yaml:"server"`
-	MySQL     MySQLConfig     `yaml:"mysql"`
-	Redis     RedisConfig     `yaml:"redis"`
-	JWT       JWTConfig       `yaml:"jwt"`
-	RateLimit RateLimitConfig `yaml:"rate_limit"`
-	Log       LogConfig       `yaml:"log"`
+	Server      ServerConfig      `yaml:"server"`
+	MySQL       MySQLConfig       `yaml:"mysql"`
+	Redis       RedisConfig       `yaml:"redis"`
+	JWT         JWTConfig         `yaml:"jwt"`
+	User        UserConfig        `yaml:"user"`
+	RateLimit   RateLimitConfig   `yaml:"rate_limit"`
+	Idempotency IdempotencyConfig `yaml:"idempotency"`
+	LoginGuard  LoginGuardConfig  `yaml:"login_guard"`
+	TwoFactor   TwoFactorConfig   `yaml:"two_factor"`
+	ServerAPI   ServerAPIConfig   `yaml:"server_api"`
+	Progression ProgressionConfig `yaml:"progression"`
+	Leaderboard LeaderboardConfig `yaml:"leaderboard"`
+	Checkin     CheckinConfig     `yaml:"checkin"`
+	Redeem      RedeemConfig      `yaml:"redeem"`
+	Payment     PaymentConfig     `yaml:"payment"`
+	Mail        MailConfig        `yaml:"mail"`
+	Friend      FriendConfig      `yaml:"friend"`
+	Log         LogConfig         `yaml:"log"`
 }
 
 type ServerConfig struct {
@@ -49,15 +61,173 @@ type RedisConfig struct {
 }
 
 type JWTConfig struct {
-	Secret     string `yaml:"secret"`
-	UserExpire int    `yaml:"user_expire"`
-	AdminExpire int   `yaml:"admin_expire"`
+	Secret        string `yaml:"secret"`
+	UserExpire    int    `yaml:"user_expire"`
+	AdminExpire   int    `yaml:"admin_expire"`
+	RefreshExpire int    `yaml:"refresh_expire"` // refresh token 有效期（秒）
+
+	// 检查 access token 吊销状态时 Redis 不可用：open 放行（默认），closed 拒绝
+	RevocationFailMode string `yaml:"revocation_fail_mode"`
+
+	// 非对称签名：active_kid 为空时使用 secret 进行 HS256 签名
+	ActiveKID string         `yaml:"active_kid"`
+	Keys      []JWTKeyConfig `yaml:"keys"`
+}
+
+type JWTKeyConfig struct {
+	KID            string `yaml:"kid"`
+	Algorithm      string `yaml:"algorithm"`        // RS256, EdDSA
+	PrivateKeyFile string `yaml:"private_key_file"` // PEM 私钥，仅签名密钥需要
+	PublicKeyFile  string `yaml:"public_key_file"`  // PEM 公钥，未配置时从私钥推导
+	VerifyUntil    string `yaml:"verify_until"`     // 验签截止时间（RFC3339），为空表示长期有效
+}
+
+type UserConfig struct {
+	AutoRegister bool `yaml:"auto_register"` // regAndLogin 接口遇到新用户名时是否自动注册
 }
 
 type RateLimitConfig struct {
+	Enabled  bool   `yaml:"enabled"`
+	RPS      int    `yaml:"rps"`       // 每秒补充的令牌数
+	Burst    int    `yaml:"burst"`     // 令牌桶容量（允许的突发请求数）
+	FailMode string `yaml:"fail_mode"` // Redis 不可用时：open 放行（默认），closed 拒绝
+	KeyBy    string `yaml:"key_by"`    // 默认策略的限流维度：ip（默认）, user, api_key
+
+	Exclude   []string          `yaml:"exclude"`   // 不限流的路由，支持 /prefix/* 前缀匹配
+	Allowlist []string          `yaml:"allowlist"` // 不限流的IP或CIDR
+	Policies  []RateLimitPolicy `yaml:"policies"`  // 按路由覆盖的策略，最长匹配优先
+}
+
+type RateLimitPolicy struct {
+	Name  string   `yaml:"name"`
+	Paths []string `yaml:"paths"` // 路由（gin 注册的路径），支持 /prefix/* 前缀匹配
+	RPS   int      `yaml:"rps"`   // 为0时该路由不限流
+	Burst int      `yaml:"burst"`
+	KeyBy string   `yaml:"key_by"` // ip, user, api_key
+}
+
+type IdempotencyConfig struct {
 	Enabled bool `yaml:"enabled"`
-	RPS     int  `yaml:"rps"`
-	Burst   int  `yaml:"burst"`
+	TTL     int  `yaml:"ttl"`      // 响应缓存时间（秒）
+	LockTTL int  `yaml:"lock_ttl"` // 处理中状态的最长保留时间（秒）
+}
+
+type LoginGuardConfig struct {
+	Enabled            bool `yaml:"enabled"`
+	MaxFailures        int  `yaml:"max_failures"`         // 同一用户名连续失败N次后锁定
+	IPMaxFailures      int  `yaml:"ip_max_failures"`      // 同一IP失败N次后锁定该IP
+	FailureWindow      int  `yaml:"failure_window"`       // 失败计数窗口（秒）
+	BackoffBase        int  `yaml:"backoff_base"`         // 每次失败后的等待时间基数（秒），按失败次数指数增长
+	LockoutDuration    int  `yaml:"lockout_duration"`     // 首次锁定时长（秒），24小时内再次锁定时翻倍
+	MaxLockoutDuration int  `yaml:"max_lockout_duration"` // 锁定时长上限（秒）
+}
+
+type TwoFactorConfig struct {
+	Issuer            string `yaml:"issuer"`              // 认证器 App 中显示的签发方
+	RequireSuperAdmin bool   `yaml:"require_super_admin"` // 超级管理员必须启用两步验证，未绑定时登录后需先完成绑定
+	ChallengeTTL      int    `yaml:"challenge_ttl"`       // 登录挑战有效期（秒）
+	MaxAttempts       int    `yaml:"max_attempts"`        // 每个登录挑战允许的验证失败次数
+	Skew              int    `yaml:"skew"`                // 允许前后偏差的时间步数（每步30秒）
+	RecoveryCodes     int    `yaml:"recovery_codes"`      // 每次生成的恢复码数量
+}
+
+type ServerAPIConfig struct {
+	Keys []ServerAPIKeyConfig `yaml:"keys"` // 服务端调用凭证，通过 X-API-Key 请求头传递
+}
+
+type ServerAPIKeyConfig struct {
+	Name string `yaml:"name"` // 调用方名称，记录在日志和流水中
+	Key  string `yaml:"key"`
+}
+
+type ProgressionConfig struct {
+	MaxLevel int                 `yaml:"max_level"` // 等级上限，为0时使用曲线表长度+1，公式模式默认100
+	Levels   []int               `yaml:"levels"`    // 曲线表：第 i 项为从 i+1 级升到 i+2 级所需经验，配置后优先于公式
+	Base     int                 `yaml:"base"`      // 公式：从 n 级升到 n+1 级所需经验 = base * n^exponent
+	Exponent float64             `yaml:"exponent"`
+	MaxGrant int                 `yaml:"max_grant"` // 单次发放经验上限，为0时不限制
+	Rewards  []LevelRewardConfig `yaml:"rewards"`   // 升级奖励
+}
+
+type LevelRewardConfig struct {
+	Level           int    `yaml:"level"`            // 达到该等级时发放，为0时每次升级都发放
+	ActivityBalance string `yaml:"activity_balance"` // 发放的活动余额，如 "5.00"
+}
+
+type LeaderboardConfig struct {
+	SeasonStart     string             `yaml:"season_start"`     // 第1赛季开始时间（RFC3339）
+	SeasonDays      int                `yaml:"season_days"`      // 每个赛季的天数
+	ArchiveSize     int                `yaml:"archive_size"`     // 周期结束时归档到 MySQL 的名次数
+	ArchiveInterval int                `yaml:"archive_interval"` // 归档检查间隔（秒）
+	Retention       int                `yaml:"retention"`        // 周期结束后榜单在 Redis 中的保留时间（秒）
+	Boards          []LeaderboardBoard `yaml:"boards"`
+}
+
+type LeaderboardBoard struct {
+	Name   string `yaml:"name"`   // 榜单标识，用于接口路径
+	Title  string `yaml:"title"`  // 榜单名称
+	Mode   string `yaml:"mode"`   // 计分方式：best 取最好成绩，sum 累加，latest 取最新
+	Period string `yaml:"period"` // 周期：all 永久，daily 每日，weekly 每周，season 赛季
+	Order  string `yaml:"order"`  // 排序：desc 分数高者在前（默认），asc 分数低者在前
+	Source string `yaml:"source"` // 数据来源：level、experience 由等级系统自动提交；为空时通过服务端接口提交
+}
+
+type CheckinConfig struct {
+	Timezone     string                `yaml:"timezone"`      // 按该时区划分自然日，如 Asia/Shanghai，为空时使用服务器时区
+	CycleDays    int                   `yaml:"cycle_days"`    // 奖励日历周期，连续签到超过周期后从第1天奖励重新开始，为0时等于奖励天数
+	MakeupDays   int                   `yaml:"makeup_days"`   // 可补签最近N天内漏签的日期，为0时不允许补签
+	MakeupLimit  int                   `yaml:"makeup_limit"`  // 每个自然月的补签次数上限，为0时不限制
+	MakeupCost   string                `yaml:"makeup_cost"`   // 每次补签扣除的余额，如 "1.00"，为空时免费
+	MakeupReward bool                  `yaml:"makeup_reward"` // 补签是否发放当天奖励
+	Rewards      []CheckinRewardConfig `yaml:"rewards"`       // 奖励日历：第 i 项为连续签到第 i+1 天的奖励
+}
+
+type CheckinRewardConfig struct {
+	Experience      int                `yaml:"experience"`       // 经验
+	ActivityBalance string             `yaml:"activity_balance"` // 活动余额，如 "0.50"
+	Items           []RewardItemConfig `yaml:"items"`            // 道具
+}
+
+type RewardItemConfig struct {
+	ItemID   uint `yaml:"item_id"`
+	Quantity int  `yaml:"quantity"`
+}
+
+type RedeemConfig struct {
+	CodeLength    int `yaml:"code_length"`    // 随机生成的兑换码长度，默认12
+	MaxBatchSize  int `yaml:"max_batch_size"` // 单批次最多生成的兑换码数量
+	MaxFailures   int `yaml:"max_failures"`   // 同一玩家在窗口内输入无效兑换码的次数上限，达到后暂停兑换，为0时不限制
+	FailureWindow int `yaml:"failure_window"` // 无效兑换码计数窗口（秒）
+	CacheTTL      int `yaml:"cache_ttl"`      // 已兑换、已用完标记在 Redis 中的缓存时间（秒）
+}
+
+type PaymentConfig struct {
+	OrderExpire       int                     `yaml:"order_expire"`       // 订单支付超时（秒），超时未支付的订单关闭为失败，默认1800
+	MinAmount         string                  `yaml:"min_amount"`         // 单笔充值下限，如 "1.00"，为空时不限
+	MaxAmount         string                  `yaml:"max_amount"`         // 单笔充值上限，为空时不限
+	CallbackTolerance int                     `yaml:"callback_tolerance"` // 回调签名时间戳与服务器时间允许的偏差（秒），默认300
+	SweepInterval     int                     `yaml:"sweep_interval"`     // 关闭超时订单的检查间隔（秒），默认60
+	Providers         []PaymentProviderConfig `yaml:"providers"`          // 启用的支付渠道
+}
+
+type PaymentProviderConfig struct {
+	Name    string `yaml:"name"`    // 渠道标识，需与代码中注册的 PaymentProvider 对应，内置 mock
+	Enabled bool   `yaml:"enabled"` // 是否启用
+	Secret  string `yaml:"secret"`  // 回调签名密钥（HMAC-SHA256）
+}
+
+type MailConfig struct {
+	DefaultExpireDays int    `yaml:"default_expire_days"` // 未指定过期时间的邮件有效天数，默认30
+	MaxRecipients     int    `yaml:"max_recipients"`      // 按玩家列表发送时单次最多收件人数，默认1000
+	DefaultSender     string `yaml:"default_sender"`      // 默认发件人显示名称
+	UnreadCacheTTL    int    `yaml:"unread_cache_ttl"`    // 未读数在 Redis 中的缓存时间（秒），默认600
+}
+
+type FriendConfig struct {
+	MaxFriends         int `yaml:"max_friends"`          // 好友数量上限，默认100
+	MaxPendingRequests int `yaml:"max_pending_requests"` // 同时待处理的已发出申请上限，默认50
+	MaxBlocks          int `yaml:"max_blocks"`           // 屏蔽人数上限，默认200
+	HeartbeatTTL       int `yaml:"heartbeat_ttl"`        // 在线心跳有效期（秒），超过未心跳视为离线，默认60
 }
 
 type LogConfig struct {
@@ -106,3 +276,11 @@ func (c *Config) GetWriteTimeout() time.Duration {
 	return time.Duration(c.Server.WriteTimeout) * time.Second
 }
 
+// GetRefreshExpire refresh token 有效期，未配置时默认30天
+func (c *Config) GetRefreshExpire() time.Duration {
+	if c.JWT.RefreshExpire <= 0 {
+		return 30 * 24 * time.Hour
+	}
+	return time.Duration(c.JWT.RefreshExpire) * time.Second
+}
+
@@ -14,8 +14,19 @@ import (
 const (
 	adminCachePrefix = "admin:" // 管理员缓存前缀
 	adminCacheTTL    = 1800 * time.Second // 管理员缓存时间
+
+	adminSetupTokenPrefix = "admin:setup_token:" // 首个管理员初始化 token（key 为 token 摘要）
 )
 
+// AdminFilter 管理员查询条件，零值字段不参与过滤
+type AdminFilter struct {
+	Username  string // 模糊匹配
+	Role      model.AdminRole
+	Status    *int
+	StartTime time.Time // 创建时间
+	EndTime   time.Time
+}
+
 type AdminDAO struct{}
 
 func NewAdminDAO() *AdminDAO {
@@ -79,3 +90,85 @@ func (d *AdminDAO) DeleteCache(adminID uint) {
 	redis.Client.Del(context.Background(), cacheKey)
 }
 
+// FindByID 根据ID获取管理员（不过滤状态、不走缓存，用于管理操作）
+func (d *AdminDAO) FindByID(id uint) (*model.Admin, error) {
+	var admin model.Admin
+	if err := mysql.DB.First(&admin, id).Error; err != nil {
+		return nil, err
+	}
+	return &admin, nil
+}
+
+// List 分页查询管理员（按ID倒序）
+func (d *AdminDAO) List(filter *AdminFilter, offset, limit int) ([]model.Admin, int64, error) {
+	query := mysql.DB.Model(&model.Admin{})
+	if filter.Username != "" {
+		query = query.Where("username LIKE ?", "%"+filter.Username+"%")
+	}
+	if filter.Role > 0 {
+		query = query.Where("role = ?", filter.Role)
+	}
+	if filter.Status != nil {
+		query = query.Where("status = ?", *filter.Status)
+	}
+	if !filter.StartTime.IsZero() {
+		query = query.Where("created_at >= ?", filter.StartTime)
+	}
+	if !filter.EndTime.IsZero() {
+		query = query.Where("created_at < ?", filter.EndTime)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var admins []model.Admin
+	if err := query.Order("id DESC").Offset(offset).Limit(limit).Find(&admins).Error; err != nil {
+		return nil, 0, err
+	}
+	return admins, total, nil
+}
+
+// UpdateFields 更新管理员指定字段并清除缓存
+func (d *AdminDAO) UpdateFields(id uint, fields map[string]interface{}) error {
+	err := mysql.DB.Model(&model.Admin{}).Where("id = ?", id).Updates(fields).Error
+	if err == nil {
+		d.DeleteCache(id)
+	}
+	return err
+}
+
+// Delete 软删除管理员并清除缓存
+func (d *AdminDAO) Delete(id uint) error {
+	err := mysql.DB.Delete(&model.Admin{}, id).Error
+	if err == nil {
+		d.DeleteCache(id)
+	}
+	return err
+}
+
+// CountActiveByRole 指定角色下状态正常的管理员数量
+func (d *AdminDAO) CountActiveByRole(role model.AdminRole) (int64, error) {
+	var count int64
+	err := mysql.DB.Model(&model.Admin{}).Where("role = ? AND status = 1", role).Count(&count).Error
+	return count, err
+}
+
+// Count 管理员总数（不含已删除）
+func (d *AdminDAO) Count() (int64, error) {
+	var count int64
+	err := mysql.DB.Model(&model.Admin{}).Count(&count).Error
+	return count, err
+}
+
+// SaveSetupToken 保存初始化 token
+func (d *AdminDAO) SaveSetupToken(tokenHash string, ttl time.Duration) error {
+	return redis.Client.Set(context.Background(), adminSetupTokenPrefix+tokenHash, 1, ttl).Err()
+}
+
+// ConsumeSetupToken 核销初始化 token，返回 false 表示 token 不存在或已被使用
+func (d *AdminDAO) ConsumeSetupToken(tokenHash string) (bool, error) {
+	n, err := redis.Client.Del(context.Background(), adminSetupTokenPrefix+tokenHash).Result()
+	return n == 1, err
+}
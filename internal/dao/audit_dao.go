@@ -0,0 +1,67 @@
+package dao
+
+import (
+	"time"
+
+	"bgame/internal/model"
+	"bgame/pkg/mysql"
+)
+
+// AuditLogFilter 审计日志查询条件，零值字段不参与过滤
+type AuditLogFilter struct {
+	AdminID    uint
+	Action     string
+	TargetType string
+	TargetID   string
+	RequestID  string
+	StartTime  time.Time
+	EndTime    time.Time
+}
+
+type AuditLogDAO struct{}
+
+func NewAuditLogDAO() *AuditLogDAO {
+	return &AuditLogDAO{}
+}
+
+// Create 写入审计日志
+func (d *AuditLogDAO) Create(log *model.AdminAuditLog) error {
+	return mysql.DB.Create(log).Error
+}
+
+// List 分页查询审计日志（按时间倒序）
+func (d *AuditLogDAO) List(filter *AuditLogFilter, offset, limit int) ([]model.AdminAuditLog, int64, error) {
+	query := mysql.DB.Model(&model.AdminAuditLog{})
+	if filter.AdminID > 0 {
+		query = query.Where("admin_id = ?", filter.AdminID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID != "" {
+		query = query.Where("target_id = ?", filter.TargetID)
+	}
+	if filter.RequestID != "" {
+		query = query.Where("request_id = ?", filter.RequestID)
+	}
+	if !filter.StartTime.IsZero() {
+		query = query.Where("created_at >= ?", filter.StartTime)
+	}
+	if !filter.EndTime.IsZero() {
+		query = query.Where("created_at < ?", filter.EndTime)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var logs []model.AdminAuditLog
+	if err := query.Order("id DESC").Offset(offset).Limit(limit).Find(&logs).Error; err != nil {
+		return nil, 0, err
+	}
+	return logs, total, nil
+}
@@ -0,0 +1,102 @@
+package dao
+
+import (
+	"errors"
+	"time"
+
+	"bgame/internal/model"
+	"bgame/pkg/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CheckinDAO struct{}
+
+func NewCheckinDAO() *CheckinDAO {
+	return &CheckinDAO{}
+}
+
+// LockUser 在事务内锁定用户资料行，同一用户的签到和补签串行执行
+func (d *CheckinDAO) LockUser(tx *gorm.DB, userID uint) error {
+	var profile model.UserProfile
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").Where("user_id = ?", userID).First(&profile).Error
+}
+
+// GetByDate 在事务内获取用户某天的签到记录，不存在时返回 nil
+func (d *CheckinDAO) GetByDate(tx *gorm.DB, userID uint, date string) (*model.UserCheckin, error) {
+	var checkin model.UserCheckin
+	err := tx.Where("user_id = ? AND checkin_date = ?", userID, date).First(&checkin).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &checkin, nil
+}
+
+// GetLatest 获取用户最近一次签到记录，不存在时返回 nil
+func (d *CheckinDAO) GetLatest(userID uint) (*model.UserCheckin, error) {
+	var checkin model.UserCheckin
+	err := mysql.DB.Where("user_id = ?", userID).Order("checkin_date DESC").First(&checkin).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &checkin, nil
+}
+
+// ListSince 在事务内获取用户某天（含）之后的签到记录（按日期升序）
+func (d *CheckinDAO) ListSince(tx *gorm.DB, userID uint, date string) ([]model.UserCheckin, error) {
+	var checkins []model.UserCheckin
+	err := tx.Where("user_id = ? AND checkin_date >= ?", userID, date).Order("checkin_date ASC").Find(&checkins).Error
+	return checkins, err
+}
+
+// CountMakeupSince 统计用户某时间之后的补签次数，仅用于展示
+func (d *CheckinDAO) CountMakeupSince(userID uint, since time.Time) (int64, error) {
+	return d.CountMakeupSinceTx(mysql.DB, userID, since)
+}
+
+// CountMakeupSinceTx 在事务内统计用户某时间之后的补签次数，调用方需先持有用户锁
+func (d *CheckinDAO) CountMakeupSinceTx(tx *gorm.DB, userID uint, since time.Time) (int64, error) {
+	var count int64
+	err := tx.Model(&model.UserCheckin{}).
+		Where("user_id = ? AND makeup = ? AND created_at >= ?", userID, true, since).Count(&count).Error
+	return count, err
+}
+
+// Create 写入签到记录
+func (d *CheckinDAO) Create(tx *gorm.DB, checkin *model.UserCheckin) error {
+	return tx.Create(checkin).Error
+}
+
+// UpdateStreak 更新签到记录的连续天数（补签后重算）
+func (d *CheckinDAO) UpdateStreak(tx *gorm.DB, id uint, streak int) error {
+	return tx.Model(&model.UserCheckin{}).Where("id = ?", id).Update("streak", streak).Error
+}
+
+// List 分页查询用户签到记录（按日期倒序），startDate/endDate 为空时不过滤
+func (d *CheckinDAO) List(userID uint, startDate, endDate string, offset, limit int) ([]model.UserCheckin, int64, error) {
+	query := mysql.DB.Model(&model.UserCheckin{}).Where("user_id = ?", userID)
+	if startDate != "" {
+		query = query.Where("checkin_date >= ?", startDate)
+	}
+	if endDate != "" {
+		query = query.Where("checkin_date <= ?", endDate)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var checkins []model.UserCheckin
+	if err := query.Order("checkin_date DESC").Offset(offset).Limit(limit).Find(&checkins).Error; err != nil {
+		return nil, 0, err
+	}
+	return checkins, total, nil
+}
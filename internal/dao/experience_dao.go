@@ -0,0 +1,96 @@
+package dao
+
+import (
+	"errors"
+	"time"
+
+	"bgame/internal/model"
+	"bgame/pkg/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ExperienceLogFilter 经验流水查询条件，零值字段不参与过滤
+type ExperienceLogFilter struct {
+	UserID      uint
+	Source      string
+	ReasonCode  string
+	ReferenceID string
+	StartTime   time.Time
+	EndTime     time.Time
+}
+
+type ExperienceDAO struct{}
+
+func NewExperienceDAO() *ExperienceDAO {
+	return &ExperienceDAO{}
+}
+
+// LockProfile 在事务内锁定用户资料行，同一用户的经验发放串行执行
+func (d *ExperienceDAO) LockProfile(tx *gorm.DB, userID uint) (*model.UserProfile, error) {
+	var profile model.UserProfile
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ?", userID).First(&profile).Error; err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// FindByReference 查询同一用户、原因码和关联业务ID的发放记录，不存在时返回 nil
+func (d *ExperienceDAO) FindByReference(tx *gorm.DB, userID uint, reasonCode, referenceID string) (*model.ExperienceLog, error) {
+	var log model.ExperienceLog
+	err := tx.Where("user_id = ? AND reason_code = ? AND reference_id = ?", userID, reasonCode, referenceID).
+		First(&log).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &log, nil
+}
+
+// UpdateProgress 更新用户等级和累计经验
+func (d *ExperienceDAO) UpdateProgress(tx *gorm.DB, profileID uint, level, experience int) error {
+	return tx.Model(&model.UserProfile{}).Where("id = ?", profileID).
+		Updates(map[string]interface{}{"level": level, "experience": experience}).Error
+}
+
+// CreateLog 写入经验流水
+func (d *ExperienceDAO) CreateLog(tx *gorm.DB, log *model.ExperienceLog) error {
+	return tx.Create(log).Error
+}
+
+// ListLogs 分页查询经验流水（按时间倒序）
+func (d *ExperienceDAO) ListLogs(filter *ExperienceLogFilter, offset, limit int) ([]model.ExperienceLog, int64, error) {
+	query := mysql.DB.Model(&model.ExperienceLog{})
+	if filter.UserID > 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.Source != "" {
+		query = query.Where("source = ?", filter.Source)
+	}
+	if filter.ReasonCode != "" {
+		query = query.Where("reason_code = ?", filter.ReasonCode)
+	}
+	if filter.ReferenceID != "" {
+		query = query.Where("reference_id = ?", filter.ReferenceID)
+	}
+	if !filter.StartTime.IsZero() {
+		query = query.Where("created_at >= ?", filter.StartTime)
+	}
+	if !filter.EndTime.IsZero() {
+		query = query.Where("created_at < ?", filter.EndTime)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var logs []model.ExperienceLog
+	if err := query.Order("id DESC").Offset(offset).Limit(limit).Find(&logs).Error; err != nil {
+		return nil, 0, err
+	}
+	return logs, total, nil
+}
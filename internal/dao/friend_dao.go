@@ -0,0 +1,265 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"bgame/internal/model"
+	"bgame/pkg/mysql"
+	"bgame/pkg/redis"
+	goredis "github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const presencePrefix = "presence:"
+
+type FriendDAO struct{}
+
+func NewFriendDAO() *FriendDAO {
+	return &FriendDAO{}
+}
+
+// LockUsers 在事务内按ID顺序锁定用户行，返回存在的用户ID
+// 涉及同一对玩家的好友、申请和屏蔽变更都先锁定双方，保证好友数上限和申请状态在并发下准确
+func (d *FriendDAO) LockUsers(tx *gorm.DB, ids ...uint) ([]uint, error) {
+	var existing []uint
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Model(&model.User{}).
+		Where("id IN ?", ids).
+		Order("id ASC").
+		Pluck("id", &existing).Error
+	return existing, err
+}
+
+// CreateRequest 在事务内创建好友申请
+func (d *FriendDAO) CreateRequest(tx *gorm.DB, request *model.FriendRequest) error {
+	return tx.Create(request).Error
+}
+
+// GetRequest 根据ID获取好友申请
+func (d *FriendDAO) GetRequest(id uint) (*model.FriendRequest, error) {
+	var request model.FriendRequest
+	if err := mysql.DB.First(&request, id).Error; err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+// LockRequest 在事务内锁定好友申请
+func (d *FriendDAO) LockRequest(tx *gorm.DB, id uint) (*model.FriendRequest, error) {
+	var request model.FriendRequest
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&request, id).Error; err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+// FindPendingRequest 查询 from 发给 to 的待处理申请，不存在时返回 nil
+func (d *FriendDAO) FindPendingRequest(tx *gorm.DB, fromUserID, toUserID uint) (*model.FriendRequest, error) {
+	var request model.FriendRequest
+	err := tx.Where("from_user_id = ? AND to_user_id = ? AND status = ?", fromUserID, toUserID, model.FriendRequestPending).
+		First(&request).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+// UpdateRequestStatus 在事务内变更待处理申请的状态
+func (d *FriendDAO) UpdateRequestStatus(tx *gorm.DB, id uint, status string, now time.Time) error {
+	return tx.Model(&model.FriendRequest{}).
+		Where("id = ? AND status = ?", id, model.FriendRequestPending).
+		Updates(map[string]interface{}{"status": status, "handled_at": now}).Error
+}
+
+// ClosePendingBetween 在事务内关闭两名玩家之间所有方向的待处理申请
+func (d *FriendDAO) ClosePendingBetween(tx *gorm.DB, userID, otherID uint, status string, now time.Time) error {
+	return tx.Model(&model.FriendRequest{}).
+		Where("((from_user_id = ? AND to_user_id = ?) OR (from_user_id = ? AND to_user_id = ?)) AND status = ?",
+			userID, otherID, otherID, userID, model.FriendRequestPending).
+		Updates(map[string]interface{}{"status": status, "handled_at": now}).Error
+}
+
+// CountPendingSent 在事务内统计玩家发出的待处理申请数
+func (d *FriendDAO) CountPendingSent(tx *gorm.DB, userID uint) (int64, error) {
+	var count int64
+	err := tx.Model(&model.FriendRequest{}).
+		Where("from_user_id = ? AND status = ?", userID, model.FriendRequestPending).
+		Count(&count).Error
+	return count, err
+}
+
+// ListPendingRequests 分页查询玩家收到（incoming）或发出（outgoing）的待处理申请（按ID倒序）
+func (d *FriendDAO) ListPendingRequests(userID uint, outgoing bool, offset, limit int) ([]model.FriendRequest, int64, error) {
+	column := "to_user_id"
+	if outgoing {
+		column = "from_user_id"
+	}
+	query := mysql.DB.Model(&model.FriendRequest{}).
+		Where(column+" = ? AND status = ?", userID, model.FriendRequestPending)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var requests []model.FriendRequest
+	if err := query.Order("id DESC").Offset(offset).Limit(limit).Find(&requests).Error; err != nil {
+		return nil, 0, err
+	}
+	return requests, total, nil
+}
+
+// CreateFriendship 在事务内写入双向好友关系，已存在时忽略
+func (d *FriendDAO) CreateFriendship(tx *gorm.DB, userID, friendID uint) error {
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create([]model.Friendship{
+		{UserID: userID, FriendID: friendID},
+		{UserID: friendID, FriendID: userID},
+	}).Error
+}
+
+// DeleteFriendship 在事务内删除双向好友关系，返回是否存在
+func (d *FriendDAO) DeleteFriendship(tx *gorm.DB, userID, friendID uint) (bool, error) {
+	result := tx.Where("(user_id = ? AND friend_id = ?) OR (user_id = ? AND friend_id = ?)",
+		userID, friendID, friendID, userID).
+		Delete(&model.Friendship{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// IsFriend 在事务内查询两名玩家是否为好友
+func (d *FriendDAO) IsFriend(tx *gorm.DB, userID, friendID uint) (bool, error) {
+	var count int64
+	err := tx.Model(&model.Friendship{}).Where("user_id = ? AND friend_id = ?", userID, friendID).Count(&count).Error
+	return count > 0, err
+}
+
+// CountFriends 在事务内统计玩家的好友数
+func (d *FriendDAO) CountFriends(tx *gorm.DB, userID uint) (int64, error) {
+	var count int64
+	err := tx.Model(&model.Friendship{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+// ListFriends 分页查询玩家的好友（按成为好友的时间倒序）
+func (d *FriendDAO) ListFriends(userID uint, offset, limit int) ([]model.Friendship, int64, error) {
+	query := mysql.DB.Model(&model.Friendship{}).Where("user_id = ?", userID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var friendships []model.Friendship
+	if err := query.Order("id DESC").Offset(offset).Limit(limit).Find(&friendships).Error; err != nil {
+		return nil, 0, err
+	}
+	return friendships, total, nil
+}
+
+// CreateBlock 在事务内写入屏蔽关系，已存在时忽略
+func (d *FriendDAO) CreateBlock(tx *gorm.DB, userID, blockedUserID uint) error {
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&model.UserBlock{
+		UserID:        userID,
+		BlockedUserID: blockedUserID,
+	}).Error
+}
+
+// DeleteBlock 解除屏蔽，返回是否存在
+func (d *FriendDAO) DeleteBlock(userID, blockedUserID uint) (bool, error) {
+	result := mysql.DB.Where("user_id = ? AND blocked_user_id = ?", userID, blockedUserID).Delete(&model.UserBlock{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// GetBlockDirections 查询两名玩家之间的屏蔽关系：userID 是否屏蔽了 otherID，otherID 是否屏蔽了 userID
+func (d *FriendDAO) GetBlockDirections(tx *gorm.DB, userID, otherID uint) (blocked, blockedBy bool, err error) {
+	var blocks []model.UserBlock
+	err = tx.Where("(user_id = ? AND blocked_user_id = ?) OR (user_id = ? AND blocked_user_id = ?)",
+		userID, otherID, otherID, userID).
+		Find(&blocks).Error
+	if err != nil {
+		return false, false, err
+	}
+	for _, b := range blocks {
+		if b.UserID == userID {
+			blocked = true
+		} else {
+			blockedBy = true
+		}
+	}
+	return blocked, blockedBy, nil
+}
+
+// HasBlock userID 是否屏蔽了 blockedUserID
+func (d *FriendDAO) HasBlock(userID, blockedUserID uint) (bool, error) {
+	var count int64
+	err := mysql.DB.Model(&model.UserBlock{}).
+		Where("user_id = ? AND blocked_user_id = ?", userID, blockedUserID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// CountBlocks 在事务内统计玩家屏蔽的人数
+func (d *FriendDAO) CountBlocks(tx *gorm.DB, userID uint) (int64, error) {
+	var count int64
+	err := tx.Model(&model.UserBlock{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+// ListBlocks 分页查询玩家屏蔽的人（按ID倒序）
+func (d *FriendDAO) ListBlocks(userID uint, offset, limit int) ([]model.UserBlock, int64, error) {
+	query := mysql.DB.Model(&model.UserBlock{}).Where("user_id = ?", userID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var blocks []model.UserBlock
+	if err := query.Order("id DESC").Offset(offset).Limit(limit).Find(&blocks).Error; err != nil {
+		return nil, 0, err
+	}
+	return blocks, total, nil
+}
+
+// Heartbeat 写入在线心跳，键在 ttl 后过期即视为离线
+func (d *FriendDAO) Heartbeat(userID uint, now time.Time, ttl time.Duration) error {
+	return redis.Client.Set(context.Background(), presenceKey(userID), now.Unix(), ttl).Err()
+}
+
+// GetPresence 批量获取玩家最近一次心跳时间，不在线的玩家不在结果中
+func (d *FriendDAO) GetPresence(userIDs []uint) (map[uint]time.Time, error) {
+	presence := make(map[uint]time.Time, len(userIDs))
+	if len(userIDs) == 0 {
+		return presence, nil
+	}
+	keys := make([]string, 0, len(userIDs))
+	for _, id := range userIDs {
+		keys = append(keys, presenceKey(id))
+	}
+	values, err := redis.Client.MGet(context.Background(), keys...).Result()
+	if err != nil && err != goredis.Nil {
+		return nil, err
+	}
+	for i, v := range values {
+		s, ok := v.(string)
+		if !ok {
+			continue
+		}
+		ts, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			continue
+		}
+		presence[userIDs[i]] = time.Unix(ts, 0)
+	}
+	return presence, nil
+}
+
+func presenceKey(userID uint) string {
+	return presencePrefix + strconv.FormatUint(uint64(userID), 10)
+}
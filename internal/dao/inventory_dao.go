@@ -0,0 +1,199 @@
+package dao
+
+import (
+	"errors"
+	"time"
+
+	"bgame/internal/model"
+	"bgame/pkg/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ItemLogFilter 道具流水查询条件，零值字段不参与过滤
+type ItemLogFilter struct {
+	UserID      uint
+	ItemID      uint
+	Source      string
+	ReasonCode  string
+	ReferenceID string
+	StartTime   time.Time
+	EndTime     time.Time
+}
+
+// ErrInsufficientItems 扣减数量时该组道具的剩余数量不足
+var ErrInsufficientItems = errors.New("道具数量不足")
+
+type InventoryDAO struct{}
+
+func NewInventoryDAO() *InventoryDAO {
+	return &InventoryDAO{}
+}
+
+// LockUser 在事务内锁定用户资料行，同一用户的背包变动串行执行
+func (d *InventoryDAO) LockUser(tx *gorm.DB, userID uint) error {
+	var profile model.UserProfile
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").Where("user_id = ?", userID).First(&profile).Error
+}
+
+// FindStack 在事务内查找并锁定过期时间相同的一组道具，用于可堆叠道具合并，不存在时返回 nil
+func (d *InventoryDAO) FindStack(tx *gorm.DB, userID, itemID uint, expireAt *time.Time) (*model.UserItem, error) {
+	query := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ? AND item_id = ?", userID, itemID)
+	if expireAt == nil {
+		query = query.Where("expire_at IS NULL")
+	} else {
+		query = query.Where("expire_at = ?", *expireAt)
+	}
+
+	var stack model.UserItem
+	err := query.First(&stack).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &stack, nil
+}
+
+// CreateStacks 写入背包道具
+func (d *InventoryDAO) CreateStacks(tx *gorm.DB, stacks []model.UserItem) error {
+	return tx.Create(&stacks).Error
+}
+
+// AddQuantity 按增量修改一组道具的数量（delta 为负数时扣减），在数据库中原子计算，扣减后不足0时返回 ErrInsufficientItems
+func (d *InventoryDAO) AddQuantity(tx *gorm.DB, id uint, delta int) error {
+	query := tx.Model(&model.UserItem{}).Where("id = ?", id)
+	if delta < 0 {
+		query = query.Where("quantity >= ?", -delta)
+	}
+	result := query.Update("quantity", gorm.Expr("quantity + ?", delta))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInsufficientItems
+	}
+	return nil
+}
+
+// DeleteStack 删除数量耗尽的一组道具
+func (d *InventoryDAO) DeleteStack(tx *gorm.DB, id uint) error {
+	return tx.Delete(&model.UserItem{}, id).Error
+}
+
+// ListAvailable 在事务内获取并锁定未过期的道具，按过期时间从早到晚排列（永久的排在最后）
+func (d *InventoryDAO) ListAvailable(tx *gorm.DB, userID, itemID uint, now time.Time) ([]model.UserItem, error) {
+	var stacks []model.UserItem
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ? AND item_id = ? AND (expire_at IS NULL OR expire_at > ?)", userID, itemID, now).
+		Order("expire_at IS NULL, expire_at ASC, id ASC").Find(&stacks).Error
+	return stacks, err
+}
+
+// SumAvailable 在事务内以加锁读统计未过期的道具数量
+// 加锁读总是读取最新提交的数据，不受事务快照影响（如调用方在锁定用户前已做过普通查询）
+func (d *InventoryDAO) SumAvailable(tx *gorm.DB, userID, itemID uint, now time.Time) (int, error) {
+	var total int
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Model(&model.UserItem{}).
+		Where("user_id = ? AND item_id = ? AND (expire_at IS NULL OR expire_at > ?)", userID, itemID, now).
+		Select("COALESCE(SUM(quantity), 0)").Scan(&total).Error
+	return total, err
+}
+
+// FindLogByReference 在事务内以加锁读查询同一用户、道具、原因码和关联业务ID的流水，不存在时返回 nil
+func (d *InventoryDAO) FindLogByReference(tx *gorm.DB, userID, itemID uint, reasonCode, referenceID string) (*model.ItemLog, error) {
+	var log model.ItemLog
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND item_id = ? AND reason_code = ? AND reference_id = ?", userID, itemID, reasonCode, referenceID).
+		First(&log).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &log, nil
+}
+
+// CreateLog 写入道具流水
+func (d *InventoryDAO) CreateLog(tx *gorm.DB, log *model.ItemLog) error {
+	return tx.Create(log).Error
+}
+
+// ListUserItems 分页查询玩家背包中未过期的道具（附带道具信息），可按分类过滤
+func (d *InventoryDAO) ListUserItems(userID uint, category string, now time.Time, offset, limit int) ([]model.UserItem, int64, error) {
+	query := mysql.DB.Model(&model.UserItem{}).
+		Where("user_items.user_id = ? AND (user_items.expire_at IS NULL OR user_items.expire_at > ?)", userID, now)
+	if category != "" {
+		query = query.Joins("JOIN items ON items.id = user_items.item_id").Where("items.category = ?", category)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var stacks []model.UserItem
+	if err := query.Preload("Item").Order("user_items.item_id ASC, user_items.id ASC").
+		Offset(offset).Limit(limit).Find(&stacks).Error; err != nil {
+		return nil, 0, err
+	}
+	return stacks, total, nil
+}
+
+// SumUserItems 统计玩家每种道具未过期的持有数量，返回以道具ID为键的映射
+func (d *InventoryDAO) SumUserItems(userID uint, now time.Time) (map[uint]int, error) {
+	var rows []struct {
+		ItemID   uint
+		Quantity int
+	}
+	if err := mysql.DB.Model(&model.UserItem{}).
+		Select("item_id, SUM(quantity) AS quantity").
+		Where("user_id = ? AND (expire_at IS NULL OR expire_at > ?)", userID, now).
+		Group("item_id").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	totals := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		totals[row.ItemID] = row.Quantity
+	}
+	return totals, nil
+}
+
+// ListLogs 分页查询道具流水（按时间倒序）
+func (d *InventoryDAO) ListLogs(filter *ItemLogFilter, offset, limit int) ([]model.ItemLog, int64, error) {
+	query := mysql.DB.Model(&model.ItemLog{})
+	if filter.UserID > 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.ItemID > 0 {
+		query = query.Where("item_id = ?", filter.ItemID)
+	}
+	if filter.Source != "" {
+		query = query.Where("source = ?", filter.Source)
+	}
+	if filter.ReasonCode != "" {
+		query = query.Where("reason_code = ?", filter.ReasonCode)
+	}
+	if filter.ReferenceID != "" {
+		query = query.Where("reference_id = ?", filter.ReferenceID)
+	}
+	if !filter.StartTime.IsZero() {
+		query = query.Where("created_at >= ?", filter.StartTime)
+	}
+	if !filter.EndTime.IsZero() {
+		query = query.Where("created_at < ?", filter.EndTime)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var logs []model.ItemLog
+	if err := query.Order("id DESC").Offset(offset).Limit(limit).Find(&logs).Error; err != nil {
+		return nil, 0, err
+	}
+	return logs, total, nil
+}
@@ -0,0 +1,88 @@
+package dao
+
+import (
+	"bgame/internal/model"
+	"bgame/pkg/mysql"
+)
+
+// ItemFilter 道具目录查询条件，零值字段不参与过滤
+type ItemFilter struct {
+	Keyword  string // 按标识或名称模糊匹配
+	Category string
+	Status   *int
+}
+
+type ItemDAO struct{}
+
+func NewItemDAO() *ItemDAO {
+	return &ItemDAO{}
+}
+
+// Create 创建道具
+func (d *ItemDAO) Create(item *model.Item) error {
+	return mysql.DB.Create(item).Error
+}
+
+// GetByID 根据ID获取道具
+func (d *ItemDAO) GetByID(id uint) (*model.Item, error) {
+	var item model.Item
+	if err := mysql.DB.First(&item, id).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// GetByCode 根据标识获取道具
+func (d *ItemDAO) GetByCode(code string) (*model.Item, error) {
+	var item model.Item
+	if err := mysql.DB.Where("code = ?", code).First(&item).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// GetByIDs 批量获取道具，返回以ID为键的映射
+func (d *ItemDAO) GetByIDs(ids []uint) (map[uint]*model.Item, error) {
+	items := make(map[uint]*model.Item, len(ids))
+	if len(ids) == 0 {
+		return items, nil
+	}
+	var list []*model.Item
+	if err := mysql.DB.Where("id IN ?", ids).Find(&list).Error; err != nil {
+		return nil, err
+	}
+	for _, item := range list {
+		items[item.ID] = item
+	}
+	return items, nil
+}
+
+// List 分页查询道具目录
+func (d *ItemDAO) List(filter *ItemFilter, offset, limit int) ([]model.Item, int64, error) {
+	query := mysql.DB.Model(&model.Item{})
+	if filter.Keyword != "" {
+		query = query.Where("code LIKE ? OR name LIKE ?", "%"+filter.Keyword+"%", "%"+filter.Keyword+"%")
+	}
+	if filter.Category != "" {
+		query = query.Where("category = ?", filter.Category)
+	}
+	if filter.Status != nil {
+		query = query.Where("status = ?", *filter.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var items []model.Item
+	if err := query.Order("id ASC").Offset(offset).Limit(limit).Find(&items).Error; err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+// UpdateFields 更新道具字段
+func (d *ItemDAO) UpdateFields(id uint, fields map[string]interface{}) error {
+	return mysql.DB.Model(&model.Item{}).Where("id = ?", id).Updates(fields).Error
+}
@@ -0,0 +1,218 @@
+package dao
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"bgame/internal/model"
+	"bgame/pkg/mysql"
+	"bgame/pkg/redis"
+	goredis "github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	leaderboardPrefix         = "leaderboard:"          // 榜单有序集合 leaderboard:<榜单>:<周期>
+	leaderboardArchivedPrefix = "leaderboard:archived:" // 已归档标记
+	leaderboardLockPrefix     = "leaderboard:lock:"     // 归档锁，防止多实例重复归档
+)
+
+// leaderboardBestScript best 模式：新分数更好时才写入
+// KEYS[1] 榜单key；ARGV[1] 用户ID；ARGV[2] 分数；ARGV[3] 1 表示分数越低越好
+var leaderboardBestScript = goredis.NewScript(`
+local current = redis.call('ZSCORE', KEYS[1], ARGV[1])
+local score = tonumber(ARGV[2])
+if current then
+	current = tonumber(current)
+	if (ARGV[3] == '1' and score >= current) or (ARGV[3] ~= '1' and score <= current) then
+		return tostring(current)
+	end
+end
+redis.call('ZADD', KEYS[1], score, ARGV[1])
+return tostring(score)
+`)
+
+// LeaderboardEntry 榜单中的一条记录，Rank 从1开始
+type LeaderboardEntry struct {
+	Rank   int64
+	UserID uint
+	Score  int64
+}
+
+type LeaderboardDAO struct{}
+
+func NewLeaderboardDAO() *LeaderboardDAO {
+	return &LeaderboardDAO{}
+}
+
+func leaderboardKey(board, period string) string {
+	return leaderboardPrefix + board + ":" + period
+}
+
+// Submit 按计分方式写入分数，返回写入后的分数；expireAt 不为零时设置榜单过期时间
+func (d *LeaderboardDAO) Submit(board, period, mode string, asc bool, userID uint, score int64, expireAt time.Time) (int64, error) {
+	ctx := context.Background()
+	key := leaderboardKey(board, period)
+	member := strconv.FormatUint(uint64(userID), 10)
+
+	var result float64
+	switch mode {
+	case model.LeaderboardModeSum:
+		v, err := redis.Client.ZIncrBy(ctx, key, float64(score), member).Result()
+		if err != nil {
+			return 0, err
+		}
+		result = v
+	case model.LeaderboardModeBest:
+		ascArg := "0"
+		if asc {
+			ascArg = "1"
+		}
+		v, err := leaderboardBestScript.Run(ctx, redis.Client, []string{key}, member, score, ascArg).Text()
+		if err != nil {
+			return 0, err
+		}
+		result, _ = strconv.ParseFloat(v, 64)
+	default:
+		if err := redis.Client.ZAdd(ctx, key, &goredis.Z{Score: float64(score), Member: member}).Err(); err != nil {
+			return 0, err
+		}
+		result = float64(score)
+	}
+
+	if !expireAt.IsZero() {
+		redis.Client.ExpireAt(ctx, key, expireAt)
+	}
+	return int64(result), nil
+}
+
+// Range 按名次区间查询（start、stop 从0开始，包含两端）
+func (d *LeaderboardDAO) Range(board, period string, asc bool, start, stop int64) ([]LeaderboardEntry, error) {
+	ctx := context.Background()
+	key := leaderboardKey(board, period)
+
+	var (
+		items []goredis.Z
+		err   error
+	)
+	if asc {
+		items, err = redis.Client.ZRangeWithScores(ctx, key, start, stop).Result()
+	} else {
+		items, err = redis.Client.ZRevRangeWithScores(ctx, key, start, stop).Result()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]LeaderboardEntry, 0, len(items))
+	for i, item := range items {
+		member, _ := item.Member.(string)
+		userID, _ := strconv.ParseUint(member, 10, 64)
+		entries = append(entries, LeaderboardEntry{
+			Rank:   start + int64(i) + 1,
+			UserID: uint(userID),
+			Score:  int64(item.Score),
+		})
+	}
+	return entries, nil
+}
+
+// Rank 查询用户名次（从0开始），未上榜时返回 -1
+func (d *LeaderboardDAO) Rank(board, period string, asc bool, userID uint) (int64, error) {
+	ctx := context.Background()
+	key := leaderboardKey(board, period)
+	member := strconv.FormatUint(uint64(userID), 10)
+
+	var (
+		rank int64
+		err  error
+	)
+	if asc {
+		rank, err = redis.Client.ZRank(ctx, key, member).Result()
+	} else {
+		rank, err = redis.Client.ZRevRank(ctx, key, member).Result()
+	}
+	if err == goredis.Nil {
+		return -1, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return rank, nil
+}
+
+// Count 榜单人数
+func (d *LeaderboardDAO) Count(board, period string) (int64, error) {
+	return redis.Client.ZCard(context.Background(), leaderboardKey(board, period)).Result()
+}
+
+// IsArchived 周期是否已归档
+func (d *LeaderboardDAO) IsArchived(board, period string) (bool, error) {
+	n, err := redis.Client.Exists(context.Background(), leaderboardArchivedPrefix+board+":"+period).Result()
+	if err != nil {
+		return false, err
+	}
+	if n > 0 {
+		return true, nil
+	}
+
+	// Redis 标记过期后以数据库为准
+	var count int64
+	if err := mysql.DB.Model(&model.LeaderboardArchive{}).
+		Where("board = ? AND period = ?", board, period).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// MarkArchived 标记周期已归档
+func (d *LeaderboardDAO) MarkArchived(board, period string, ttl time.Duration) error {
+	return redis.Client.Set(context.Background(), leaderboardArchivedPrefix+board+":"+period, 1, ttl).Err()
+}
+
+// AcquireArchiveLock 获取归档锁
+func (d *LeaderboardDAO) AcquireArchiveLock(board, period string, ttl time.Duration) (bool, error) {
+	return redis.Client.SetNX(context.Background(), leaderboardLockPrefix+board+":"+period, 1, ttl).Result()
+}
+
+// ReleaseArchiveLock 释放归档锁
+func (d *LeaderboardDAO) ReleaseArchiveLock(board, period string) {
+	redis.Client.Del(context.Background(), leaderboardLockPrefix+board+":"+period)
+}
+
+// SaveArchive 在一个事务内批量写入归档名次，已存在的名次忽略，重复归档同一周期不会失败
+func (d *LeaderboardDAO) SaveArchive(archives []model.LeaderboardArchive) error {
+	if len(archives) == 0 {
+		return nil
+	}
+	return mysql.DB.Transaction(func(tx *gorm.DB) error {
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(archives, 500).Error
+	})
+}
+
+// ListArchive 分页查询某一周期的归档名次
+func (d *LeaderboardDAO) ListArchive(board, period string, offset, limit int) ([]model.LeaderboardArchive, int64, error) {
+	query := mysql.DB.Model(&model.LeaderboardArchive{}).Where("board = ? AND period = ?", board, period)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var archives []model.LeaderboardArchive
+	if err := query.Order("`rank` ASC").Offset(offset).Limit(limit).Find(&archives).Error; err != nil {
+		return nil, 0, err
+	}
+	return archives, total, nil
+}
+
+// ListArchivedPeriods 榜单已归档的周期（按周期结束时间倒序）
+func (d *LeaderboardDAO) ListArchivedPeriods(board string, limit int) ([]string, error) {
+	var periods []string
+	err := mysql.DB.Model(&model.LeaderboardArchive{}).
+		Where("board = ? AND `rank` = 1", board).
+		Order("period_end DESC").Limit(limit).Pluck("period", &periods).Error
+	return periods, err
+}
@@ -0,0 +1,98 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"bgame/pkg/redis"
+)
+
+const (
+	loginFailPrefix      = "login:fail:"       // 失败计数
+	loginBackoffPrefix   = "login:backoff:"    // 退避等待
+	loginLockPrefix      = "login:lock:"       // 临时锁定
+	loginLockCountPrefix = "login:lock_count:" // 24小时内的锁定次数
+)
+
+// LoginAttemptDAO 登录失败计数与锁定状态（Redis）
+// scope 区分用户/管理员登录，subject 为 "user:<用户名>" 或 "ip:<IP>"
+type LoginAttemptDAO struct{}
+
+func NewLoginAttemptDAO() *LoginAttemptDAO {
+	return &LoginAttemptDAO{}
+}
+
+// IncrFailure 失败计数加一，计数窗口从第一次失败开始计算
+func (d *LoginAttemptDAO) IncrFailure(scope, subject string, window time.Duration) (int64, error) {
+	return incrWithTTL(loginKey(loginFailPrefix, scope, subject), window)
+}
+
+// IncrLockCount 锁定次数加一
+func (d *LoginAttemptDAO) IncrLockCount(scope, subject string, window time.Duration) (int64, error) {
+	return incrWithTTL(loginKey(loginLockCountPrefix, scope, subject), window)
+}
+
+// SetBackoff 设置退避等待
+func (d *LoginAttemptDAO) SetBackoff(scope, subject string, ttl time.Duration) error {
+	return redis.Client.Set(context.Background(), loginKey(loginBackoffPrefix, scope, subject), 1, ttl).Err()
+}
+
+// Lock 临时锁定
+func (d *LoginAttemptDAO) Lock(scope, subject string, ttl time.Duration) error {
+	return redis.Client.Set(context.Background(), loginKey(loginLockPrefix, scope, subject), 1, ttl).Err()
+}
+
+// GetBlockedTTL 返回锁定或退避的剩余时间，未被阻止时返回0
+func (d *LoginAttemptDAO) GetBlockedTTL(scope, subject string) (lock time.Duration, backoff time.Duration, err error) {
+	ctx := context.Background()
+	pipe := redis.Client.Pipeline()
+	lockTTL := pipe.PTTL(ctx, loginKey(loginLockPrefix, scope, subject))
+	backoffTTL := pipe.PTTL(ctx, loginKey(loginBackoffPrefix, scope, subject))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, 0, err
+	}
+	return positive(lockTTL.Val()), positive(backoffTTL.Val()), nil
+}
+
+// ClearFailures 清除失败计数和退避等待
+func (d *LoginAttemptDAO) ClearFailures(scope, subject string) error {
+	return redis.Client.Del(context.Background(),
+		loginKey(loginFailPrefix, scope, subject),
+		loginKey(loginBackoffPrefix, scope, subject),
+	).Err()
+}
+
+// Unlock 解除锁定并清除全部失败记录
+func (d *LoginAttemptDAO) Unlock(scope, subject string) error {
+	return redis.Client.Del(context.Background(),
+		loginKey(loginFailPrefix, scope, subject),
+		loginKey(loginBackoffPrefix, scope, subject),
+		loginKey(loginLockPrefix, scope, subject),
+		loginKey(loginLockCountPrefix, scope, subject),
+	).Err()
+}
+
+func incrWithTTL(key string, ttl time.Duration) (int64, error) {
+	ctx := context.Background()
+	n, err := redis.Client.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if n == 1 {
+		redis.Client.Expire(ctx, key, ttl)
+	}
+	return n, nil
+}
+
+func loginKey(prefix, scope, subject string) string {
+	return fmt.Sprintf("%s%s:%s", prefix, scope, subject)
+}
+
+// positive PTTL 对不存在的 key 返回负值，统一按0处理
+func positive(d time.Duration) time.Duration {
+	if d < 0 {
+		return 0
+	}
+	return d
+}
@@ -0,0 +1,256 @@
+package dao
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"bgame/internal/model"
+	"bgame/pkg/mysql"
+	"bgame/pkg/redis"
+	goredis "github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	mailUnreadPrefix        = "mail:unread:"
+	mailBroadcastVersionKey = "mail:broadcast:version"
+)
+
+// MailFilter 邮件查询条件，零值字段不参与过滤
+type MailFilter struct {
+	Title      string // 模糊匹配
+	TargetType string
+	StartTime  time.Time
+	EndTime    time.Time
+}
+
+// MailStats 邮件投递统计，全服和分群邮件只统计已投递（玩家已打开邮箱）的部分，不含不满足分群条件而跳过的玩家
+type MailStats struct {
+	Delivered int64 `json:"delivered"`
+	Read      int64 `json:"read"`
+	Claimed   int64 `json:"claimed"`
+	Deleted   int64 `json:"deleted"`
+}
+
+type MailDAO struct{}
+
+func NewMailDAO() *MailDAO {
+	return &MailDAO{}
+}
+
+// CreateMail 在事务内创建邮件
+func (d *MailDAO) CreateMail(tx *gorm.DB, mail *model.Mail) error {
+	return tx.Create(mail).Error
+}
+
+// CreateUserMails 批量创建投递记录，已投递过的忽略
+func (d *MailDAO) CreateUserMails(tx *gorm.DB, userMails []model.UserMail) error {
+	if len(userMails) == 0 {
+		return nil
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(userMails, 500).Error
+}
+
+// GetMail 根据ID获取邮件
+func (d *MailDAO) GetMail(id uint) (*model.Mail, error) {
+	var mail model.Mail
+	if err := mysql.DB.First(&mail, id).Error; err != nil {
+		return nil, err
+	}
+	return &mail, nil
+}
+
+// ListMails 分页查询邮件（按ID倒序）
+func (d *MailDAO) ListMails(filter *MailFilter, offset, limit int) ([]model.Mail, int64, error) {
+	query := mysql.DB.Model(&model.Mail{})
+	if filter.Title != "" {
+		query = query.Where("title LIKE ?", "%"+filter.Title+"%")
+	}
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+	if !filter.StartTime.IsZero() {
+		query = query.Where("created_at >= ?", filter.StartTime)
+	}
+	if !filter.EndTime.IsZero() {
+		query = query.Where("created_at < ?", filter.EndTime)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var mails []model.Mail
+	if err := query.Order("id DESC").Offset(offset).Limit(limit).Find(&mails).Error; err != nil {
+		return nil, 0, err
+	}
+	return mails, total, nil
+}
+
+// GetMailStats 统计邮件的投递、阅读、领取和删除人数
+func (d *MailDAO) GetMailStats(mailID uint) (*MailStats, error) {
+	var stats MailStats
+	err := mysql.DB.Model(&model.UserMail{}).
+		Select("COUNT(*) AS delivered, COUNT(read_at) AS `read`, COUNT(claimed_at) AS claimed, "+
+			"COALESCE(SUM(deleted), 0) AS deleted").
+		Where("mail_id = ? AND skipped = ?", mailID, false).
+		Scan(&stats).Error
+	if err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// ListPendingBroadcasts 查询尚未投递给该玩家（包括已跳过的分群邮件）、未过期且在玩家注册之后发送的全服和分群邮件
+func (d *MailDAO) ListPendingBroadcasts(userID uint, registerTime, now time.Time) ([]model.Mail, error) {
+	var mails []model.Mail
+	err := mysql.DB.
+		Where("target_type IN ? AND expire_at > ? AND created_at >= ?",
+			[]string{model.MailTargetAll, model.MailTargetSegment}, now, registerTime).
+		Where("NOT EXISTS (SELECT 1 FROM user_mails WHERE user_mails.mail_id = mails.id AND user_mails.user_id = ?)", userID).
+		Order("id ASC").
+		Find(&mails).Error
+	return mails, err
+}
+
+// ExistingUserIDs 返回 ids 中存在的用户ID
+func (d *MailDAO) ExistingUserIDs(ids []uint) ([]uint, error) {
+	var existing []uint
+	if len(ids) == 0 {
+		return existing, nil
+	}
+	err := mysql.DB.Model(&model.User{}).Where("id IN ?", ids).Pluck("id", &existing).Error
+	return existing, err
+}
+
+// ListUserMails 分页查询玩家未删除、未过期的邮件（按ID倒序）
+func (d *MailDAO) ListUserMails(userID uint, now time.Time, offset, limit int) ([]model.UserMail, int64, error) {
+	query := mysql.DB.Model(&model.UserMail{}).
+		Where("user_id = ? AND deleted = ? AND expire_at > ?", userID, false, now)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var userMails []model.UserMail
+	if err := query.Preload("Mail").Order("id DESC").Offset(offset).Limit(limit).Find(&userMails).Error; err != nil {
+		return nil, 0, err
+	}
+	return userMails, total, nil
+}
+
+// GetUserMail 获取玩家的一封邮件
+func (d *MailDAO) GetUserMail(userID, id uint) (*model.UserMail, error) {
+	var userMail model.UserMail
+	if err := mysql.DB.Preload("Mail").Where("id = ? AND user_id = ?", id, userID).First(&userMail).Error; err != nil {
+		return nil, err
+	}
+	return &userMail, nil
+}
+
+// LockUserMail 在事务内锁定玩家的一封邮件，领取附件串行执行
+func (d *MailDAO) LockUserMail(tx *gorm.DB, userID, id uint) (*model.UserMail, error) {
+	var userMail model.UserMail
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("Mail").
+		Where("id = ? AND user_id = ?", id, userID).
+		First(&userMail).Error; err != nil {
+		return nil, err
+	}
+	return &userMail, nil
+}
+
+// MarkRead 标记邮件已读，已读过时返回 false
+func (d *MailDAO) MarkRead(id uint, now time.Time) (bool, error) {
+	result := mysql.DB.Model(&model.UserMail{}).
+		Where("id = ? AND read_at IS NULL", id).
+		Update("read_at", now)
+	return result.RowsAffected > 0, result.Error
+}
+
+// MarkClaimed 在事务内标记附件已领取（同时标记已读），已领取过时返回 false
+func (d *MailDAO) MarkClaimed(tx *gorm.DB, id uint, now time.Time) (bool, error) {
+	result := tx.Model(&model.UserMail{}).
+		Where("id = ? AND claimed_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"claimed_at": now,
+			"read_at":    gorm.Expr("COALESCE(read_at, ?)", now),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// MarkDeleted 标记邮件已删除
+func (d *MailDAO) MarkDeleted(id uint) error {
+	return mysql.DB.Model(&model.UserMail{}).Where("id = ?", id).Update("deleted", true).Error
+}
+
+// ListClaimableIDs 查询玩家所有可领取附件的邮件ID（按ID正序）
+func (d *MailDAO) ListClaimableIDs(userID uint, now time.Time) ([]uint, error) {
+	var ids []uint
+	err := mysql.DB.Model(&model.UserMail{}).
+		Where("user_id = ? AND deleted = ? AND expire_at > ? AND has_attachments = ? AND claimed_at IS NULL",
+			userID, false, now, true).
+		Order("id ASC").
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// CountUnread 统计玩家未删除、未过期的未读邮件数
+func (d *MailDAO) CountUnread(userID uint, now time.Time) (int64, error) {
+	var count int64
+	err := mysql.DB.Model(&model.UserMail{}).
+		Where("user_id = ? AND deleted = ? AND expire_at > ? AND read_at IS NULL", userID, false, now).
+		Count(&count).Error
+	return count, err
+}
+
+// GetBroadcastVersion 获取全服邮件版本号，每次发送全服或分群邮件时递增，使所有玩家的未读数缓存失效
+func (d *MailDAO) GetBroadcastVersion() (int64, error) {
+	v, err := redis.Client.Get(context.Background(), mailBroadcastVersionKey).Int64()
+	if err == goredis.Nil {
+		return 0, nil
+	}
+	return v, err
+}
+
+// IncrBroadcastVersion 递增全服邮件版本号
+func (d *MailDAO) IncrBroadcastVersion() error {
+	return redis.Client.Incr(context.Background(), mailBroadcastVersionKey).Err()
+}
+
+// GetUnreadCache 获取玩家在指定全服邮件版本下缓存的未读数
+func (d *MailDAO) GetUnreadCache(userID uint, version int64) (int64, bool, error) {
+	n, err := redis.Client.Get(context.Background(), mailUnreadKey(userID, version)).Int64()
+	if err == goredis.Nil {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return n, true, nil
+}
+
+// SetUnreadCache 缓存玩家的未读数
+func (d *MailDAO) SetUnreadCache(userID uint, version, count int64, ttl time.Duration) error {
+	return redis.Client.Set(context.Background(), mailUnreadKey(userID, version), count, ttl).Err()
+}
+
+// DeleteUnreadCache 删除玩家在指定全服邮件版本下的未读数缓存
+func (d *MailDAO) DeleteUnreadCache(version int64, userIDs ...uint) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(userIDs))
+	for _, id := range userIDs {
+		keys = append(keys, mailUnreadKey(id, version))
+	}
+	return redis.Client.Del(context.Background(), keys...).Err()
+}
+
+func mailUnreadKey(userID uint, version int64) string {
+	return mailUnreadPrefix + strconv.FormatUint(uint64(userID), 10) + ":" + strconv.FormatInt(version, 10)
+}
@@ -0,0 +1,141 @@
+package dao
+
+import (
+	"time"
+
+	"bgame/internal/model"
+	"bgame/pkg/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PaymentOrderFilter 订单查询条件，零值字段不参与过滤
+type PaymentOrderFilter struct {
+	UserID    uint
+	OrderNo   string
+	TradeNo   string
+	Provider  string
+	Status    string
+	StartTime time.Time
+	EndTime   time.Time
+}
+
+type PaymentDAO struct{}
+
+func NewPaymentDAO() *PaymentDAO {
+	return &PaymentDAO{}
+}
+
+// CreateOrder 创建订单
+func (d *PaymentDAO) CreateOrder(order *model.PaymentOrder) error {
+	return mysql.DB.Create(order).Error
+}
+
+// GetOrder 根据ID获取订单
+func (d *PaymentDAO) GetOrder(id uint) (*model.PaymentOrder, error) {
+	var order model.PaymentOrder
+	if err := mysql.DB.First(&order, id).Error; err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// GetOrderByNo 根据订单号获取订单
+func (d *PaymentDAO) GetOrderByNo(orderNo string) (*model.PaymentOrder, error) {
+	var order model.PaymentOrder
+	if err := mysql.DB.Where("order_no = ?", orderNo).First(&order).Error; err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// LockOrder 在事务内锁定订单行，同一订单的回调和退款串行执行
+func (d *PaymentDAO) LockOrder(tx *gorm.DB, id uint) (*model.PaymentOrder, error) {
+	var order model.PaymentOrder
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, id).Error; err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// LockOrderByNo 在事务内按订单号锁定订单行
+func (d *PaymentDAO) LockOrderByNo(tx *gorm.DB, orderNo string) (*model.PaymentOrder, error) {
+	var order model.PaymentOrder
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("order_no = ?", orderNo).First(&order).Error; err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// UpdateOrderStatus 仅当订单仍处于 from 状态时更新，返回是否更新成功
+func (d *PaymentDAO) UpdateOrderStatus(tx *gorm.DB, id uint, from string, fields map[string]interface{}) (bool, error) {
+	result := tx.Model(&model.PaymentOrder{}).
+		Where("id = ? AND status = ?", id, from).Updates(fields)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// ExpirePending 关闭已过支付截止时间的待支付订单，返回关闭数量
+func (d *PaymentDAO) ExpirePending(now time.Time, reason string) (int64, error) {
+	result := mysql.DB.Model(&model.PaymentOrder{}).
+		Where("status = ? AND expire_at < ?", model.PaymentStatusPending, now).
+		Updates(map[string]interface{}{
+			"status":      model.PaymentStatusFailed,
+			"fail_reason": reason,
+		})
+	return result.RowsAffected, result.Error
+}
+
+// ListOrders 分页查询订单（按时间倒序）
+func (d *PaymentDAO) ListOrders(filter *PaymentOrderFilter, offset, limit int) ([]model.PaymentOrder, int64, error) {
+	query := mysql.DB.Model(&model.PaymentOrder{})
+	if filter.UserID > 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.OrderNo != "" {
+		query = query.Where("order_no = ?", filter.OrderNo)
+	}
+	if filter.TradeNo != "" {
+		query = query.Where("trade_no = ?", filter.TradeNo)
+	}
+	if filter.Provider != "" {
+		query = query.Where("provider = ?", filter.Provider)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if !filter.StartTime.IsZero() {
+		query = query.Where("created_at >= ?", filter.StartTime)
+	}
+	if !filter.EndTime.IsZero() {
+		query = query.Where("created_at < ?", filter.EndTime)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var orders []model.PaymentOrder
+	if err := query.Order("id DESC").Offset(offset).Limit(limit).Find(&orders).Error; err != nil {
+		return nil, 0, err
+	}
+	return orders, total, nil
+}
+
+// CreateCallbackLog 写入回调记录
+func (d *PaymentDAO) CreateCallbackLog(log *model.PaymentCallbackLog) error {
+	return mysql.DB.Create(log).Error
+}
+
+// ListCallbackLogs 查询订单的全部回调记录（按时间正序）
+func (d *PaymentDAO) ListCallbackLogs(orderNo string) ([]model.PaymentCallbackLog, error) {
+	var logs []model.PaymentCallbackLog
+	if err := mysql.DB.Where("order_no = ?", orderNo).Order("id ASC").Find(&logs).Error; err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
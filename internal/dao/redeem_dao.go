@@ -0,0 +1,280 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"bgame/internal/model"
+	"bgame/pkg/mysql"
+	"bgame/pkg/redis"
+	goredis "github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	redeemUsedPrefix      = "redeem:used:"      // 玩家已兑换某个码 redeem:used:<码ID>:<用户ID>
+	redeemExhaustedPrefix = "redeem:exhausted:" // 兑换码次数已用完 redeem:exhausted:<码>
+	redeemFailPrefix      = "redeem:fail:"      // 玩家输入无效兑换码次数 redeem:fail:<用户ID>
+)
+
+// RedeemRecordFilter 兑换记录查询条件，零值字段不参与过滤
+type RedeemRecordFilter struct {
+	BatchID   uint
+	UserID    uint
+	Code      string
+	StartTime time.Time
+	EndTime   time.Time
+}
+
+// RedeemBatchStats 批次兑换统计
+type RedeemBatchStats struct {
+	UsedCodes int64 // 至少被兑换过一次的码数量
+	Users     int64 // 兑换过的玩家数
+}
+
+type RedeemDAO struct{}
+
+func NewRedeemDAO() *RedeemDAO {
+	return &RedeemDAO{}
+}
+
+// CreateBatch 创建批次并写入兑换码
+func (d *RedeemDAO) CreateBatch(tx *gorm.DB, batch *model.RedeemBatch, codes []model.RedeemCode) error {
+	if err := tx.Create(batch).Error; err != nil {
+		return err
+	}
+	for i := range codes {
+		codes[i].BatchID = batch.ID
+	}
+	return tx.CreateInBatches(codes, 500).Error
+}
+
+// GetBatch 根据ID获取批次
+func (d *RedeemDAO) GetBatch(id uint) (*model.RedeemBatch, error) {
+	var batch model.RedeemBatch
+	if err := mysql.DB.First(&batch, id).Error; err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+// GetBatchTx 在事务内获取批次
+func (d *RedeemDAO) GetBatchTx(tx *gorm.DB, id uint) (*model.RedeemBatch, error) {
+	var batch model.RedeemBatch
+	if err := tx.First(&batch, id).Error; err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+// ListBatches 分页查询批次（按创建时间倒序）
+func (d *RedeemDAO) ListBatches(keyword, batchType string, status *int, offset, limit int) ([]model.RedeemBatch, int64, error) {
+	query := mysql.DB.Model(&model.RedeemBatch{})
+	if keyword != "" {
+		query = query.Where("name LIKE ?", "%"+keyword+"%")
+	}
+	if batchType != "" {
+		query = query.Where("type = ?", batchType)
+	}
+	if status != nil {
+		query = query.Where("status = ?", *status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var batches []model.RedeemBatch
+	if err := query.Order("id DESC").Offset(offset).Limit(limit).Find(&batches).Error; err != nil {
+		return nil, 0, err
+	}
+	return batches, total, nil
+}
+
+// UpdateBatchStatus 更新批次状态
+func (d *RedeemDAO) UpdateBatchStatus(id uint, status int) error {
+	return mysql.DB.Model(&model.RedeemBatch{}).Where("id = ?", id).Update("status", status).Error
+}
+
+// GetBatchStats 统计批次的已使用码数量和兑换玩家数
+func (d *RedeemDAO) GetBatchStats(batchID uint) (*RedeemBatchStats, error) {
+	stats := &RedeemBatchStats{}
+	if err := mysql.DB.Model(&model.RedeemCode{}).
+		Where("batch_id = ? AND used_count > 0", batchID).Count(&stats.UsedCodes).Error; err != nil {
+		return nil, err
+	}
+	if err := mysql.DB.Model(&model.RedeemRecord{}).
+		Where("batch_id = ?", batchID).Distinct("user_id").Count(&stats.Users).Error; err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// FindCode 根据兑换码查询，不存在时返回 nil
+func (d *RedeemDAO) FindCode(code string) (*model.RedeemCode, error) {
+	var redeemCode model.RedeemCode
+	err := mysql.DB.Where("code = ?", code).First(&redeemCode).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &redeemCode, nil
+}
+
+// LockUser 在事务内锁定用户资料行，同一用户的兑换串行执行，保证每人兑换次数上限在并发下准确
+func (d *RedeemDAO) LockUser(tx *gorm.DB, userID uint) error {
+	var profile model.UserProfile
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").Where("user_id = ?", userID).First(&profile).Error
+}
+
+// LockCode 在事务内锁定兑换码行，同一个码的兑换串行执行
+func (d *RedeemDAO) LockCode(tx *gorm.DB, id uint) (*model.RedeemCode, error) {
+	var redeemCode model.RedeemCode
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&redeemCode, id).Error; err != nil {
+		return nil, err
+	}
+	return &redeemCode, nil
+}
+
+// ListCodes 分页查询批次内的兑换码，used 不为空时按是否已使用过滤
+func (d *RedeemDAO) ListCodes(batchID uint, used *bool, offset, limit int) ([]model.RedeemCode, int64, error) {
+	query := mysql.DB.Model(&model.RedeemCode{}).Where("batch_id = ?", batchID)
+	if used != nil {
+		if *used {
+			query = query.Where("used_count > 0")
+		} else {
+			query = query.Where("used_count = 0")
+		}
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var codes []model.RedeemCode
+	if err := query.Order("id ASC").Offset(offset).Limit(limit).Find(&codes).Error; err != nil {
+		return nil, 0, err
+	}
+	return codes, total, nil
+}
+
+// HasRedeemed 玩家是否已兑换过该码
+func (d *RedeemDAO) HasRedeemed(tx *gorm.DB, codeID, userID uint) (bool, error) {
+	var count int64
+	if err := tx.Model(&model.RedeemRecord{}).
+		Where("code_id = ? AND user_id = ?", codeID, userID).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// CountUserRedeems 统计玩家在批次内的兑换次数
+func (d *RedeemDAO) CountUserRedeems(tx *gorm.DB, batchID, userID uint) (int64, error) {
+	var count int64
+	if err := tx.Model(&model.RedeemRecord{}).
+		Where("batch_id = ? AND user_id = ?", batchID, userID).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// CreateRecord 写入兑换记录并累加兑换码和批次的兑换次数
+func (d *RedeemDAO) CreateRecord(tx *gorm.DB, record *model.RedeemRecord) error {
+	if err := tx.Create(record).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&model.RedeemCode{}).Where("id = ?", record.CodeID).
+		Update("used_count", gorm.Expr("used_count + 1")).Error; err != nil {
+		return err
+	}
+	return tx.Model(&model.RedeemBatch{}).Where("id = ?", record.BatchID).
+		Update("redeem_count", gorm.Expr("redeem_count + 1")).Error
+}
+
+// ListRecords 分页查询兑换记录（按时间倒序）
+func (d *RedeemDAO) ListRecords(filter *RedeemRecordFilter, offset, limit int) ([]model.RedeemRecord, int64, error) {
+	query := mysql.DB.Model(&model.RedeemRecord{})
+	if filter.BatchID > 0 {
+		query = query.Where("batch_id = ?", filter.BatchID)
+	}
+	if filter.UserID > 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.Code != "" {
+		query = query.Where("code = ?", filter.Code)
+	}
+	if !filter.StartTime.IsZero() {
+		query = query.Where("created_at >= ?", filter.StartTime)
+	}
+	if !filter.EndTime.IsZero() {
+		query = query.Where("created_at < ?", filter.EndTime)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var records []model.RedeemRecord
+	if err := query.Order("id DESC").Offset(offset).Limit(limit).Find(&records).Error; err != nil {
+		return nil, 0, err
+	}
+	return records, total, nil
+}
+
+// IsUsedCached Redis 中是否有玩家已兑换该码的标记
+func (d *RedeemDAO) IsUsedCached(codeID, userID uint) (bool, error) {
+	n, err := redis.Client.Exists(context.Background(), redeemUsedKey(codeID, userID)).Result()
+	return n > 0, err
+}
+
+// MarkUsed 标记玩家已兑换该码
+func (d *RedeemDAO) MarkUsed(codeID, userID uint, ttl time.Duration) error {
+	return redis.Client.Set(context.Background(), redeemUsedKey(codeID, userID), 1, ttl).Err()
+}
+
+// IsExhaustedCached Redis 中是否有兑换码次数已用完的标记
+func (d *RedeemDAO) IsExhaustedCached(code string) (bool, error) {
+	n, err := redis.Client.Exists(context.Background(), redeemExhaustedPrefix+code).Result()
+	return n > 0, err
+}
+
+// MarkExhausted 标记兑换码次数已用完
+func (d *RedeemDAO) MarkExhausted(code string, ttl time.Duration) error {
+	return redis.Client.Set(context.Background(), redeemExhaustedPrefix+code, 1, ttl).Err()
+}
+
+// GetFailures 获取玩家在窗口内输入无效兑换码的次数
+func (d *RedeemDAO) GetFailures(userID uint) (int64, error) {
+	n, err := redis.Client.Get(context.Background(), redeemFailPrefix+strconv.FormatUint(uint64(userID), 10)).Int64()
+	if err == goredis.Nil {
+		return 0, nil
+	}
+	return n, err
+}
+
+// IncrFailures 累加无效兑换码次数，首次计数时设置窗口过期时间
+func (d *RedeemDAO) IncrFailures(userID uint, window time.Duration) (int64, error) {
+	ctx := context.Background()
+	key := redeemFailPrefix + strconv.FormatUint(uint64(userID), 10)
+	n, err := redis.Client.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if n == 1 {
+		redis.Client.Expire(ctx, key, window)
+	}
+	return n, nil
+}
+
+func redeemUsedKey(codeID, userID uint) string {
+	return redeemUsedPrefix + strconv.FormatUint(uint64(codeID), 10) + ":" + strconv.FormatUint(uint64(userID), 10)
+}
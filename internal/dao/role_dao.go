@@ -0,0 +1,187 @@
+package dao
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"bgame/internal/model"
+	"bgame/pkg/mysql"
+	"bgame/pkg/redis"
+	"gorm.io/gorm"
+)
+
+const (
+	rolePermCachePrefix = "role:perms:"     // 角色权限缓存前缀
+	rolePermCacheTTL    = 600 * time.Second // 角色权限缓存时间
+)
+
+type RoleDAO struct{}
+
+func NewRoleDAO() *RoleDAO {
+	return &RoleDAO{}
+}
+
+// List 获取全部角色（含权限）
+func (d *RoleDAO) List() ([]*model.Role, error) {
+	var roles []*model.Role
+	if err := mysql.DB.Order("id ASC").Find(&roles).Error; err != nil {
+		return nil, err
+	}
+
+	var perms []*model.RolePermission
+	if err := mysql.DB.Order("id ASC").Find(&perms).Error; err != nil {
+		return nil, err
+	}
+	byRole := make(map[uint][]string)
+	for _, p := range perms {
+		byRole[p.RoleID] = append(byRole[p.RoleID], p.Permission)
+	}
+	for _, role := range roles {
+		role.Permissions = byRole[role.ID]
+		if role.Permissions == nil {
+			role.Permissions = []string{}
+		}
+	}
+	return roles, nil
+}
+
+// GetByID 获取角色（含权限）
+func (d *RoleDAO) GetByID(id uint) (*model.Role, error) {
+	var role model.Role
+	if err := mysql.DB.First(&role, id).Error; err != nil {
+		return nil, err
+	}
+	perms, err := d.loadPermissions(mysql.DB, id)
+	if err != nil {
+		return nil, err
+	}
+	role.Permissions = perms
+	return &role, nil
+}
+
+// GetByName 根据名称获取角色
+func (d *RoleDAO) GetByName(name string) (*model.Role, error) {
+	var role model.Role
+	if err := mysql.DB.Where("name = ?", name).First(&role).Error; err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+// Create 创建角色及其权限
+func (d *RoleDAO) Create(role *model.Role) error {
+	return mysql.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(role).Error; err != nil {
+			return err
+		}
+		return d.replacePermissions(tx, role.ID, role.Permissions)
+	})
+}
+
+// Update 更新角色信息，permissions 不为 nil 时整体替换权限
+func (d *RoleDAO) Update(id uint, fields map[string]interface{}, permissions []string) error {
+	err := mysql.DB.Transaction(func(tx *gorm.DB) error {
+		if len(fields) > 0 {
+			if err := tx.Model(&model.Role{}).Where("id = ?", id).Updates(fields).Error; err != nil {
+				return err
+			}
+		}
+		if permissions == nil {
+			return nil
+		}
+		return d.replacePermissions(tx, id, permissions)
+	})
+	if err == nil {
+		d.DeleteCache(id)
+	}
+	return err
+}
+
+// Delete 删除角色及其权限
+func (d *RoleDAO) Delete(id uint) error {
+	err := mysql.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("role_id = ?", id).Delete(&model.RolePermission{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Role{}, id).Error
+	})
+	if err == nil {
+		d.DeleteCache(id)
+	}
+	return err
+}
+
+// CountAdmins 使用该角色的管理员数量
+func (d *RoleDAO) CountAdmins(id uint) (int64, error) {
+	var count int64
+	err := mysql.DB.Model(&model.Admin{}).Where("role = ?", id).Count(&count).Error
+	return count, err
+}
+
+// GetPermissions 获取角色权限（带缓存）
+func (d *RoleDAO) GetPermissions(roleID uint) ([]string, error) {
+	cacheKey := fmt.Sprintf("%s%d", rolePermCachePrefix, roleID)
+	cached, err := redis.Client.Get(context.Background(), cacheKey).Result()
+	if err == nil {
+		var perms []string
+		if json.Unmarshal([]byte(cached), &perms) == nil {
+			return perms, nil
+		}
+	}
+
+	perms, err := d.loadPermissions(mysql.DB, roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	if data, err := json.Marshal(perms); err == nil {
+		redis.Client.Set(context.Background(), cacheKey, data, rolePermCacheTTL)
+	}
+	return perms, nil
+}
+
+// DeleteCache 删除角色权限缓存
+func (d *RoleDAO) DeleteCache(roleID uint) {
+	cacheKey := fmt.Sprintf("%s%d", rolePermCachePrefix, roleID)
+	redis.Client.Del(context.Background(), cacheKey)
+}
+
+// Seed 内置角色不存在时创建并写入默认权限，已存在的角色保持不变
+func (d *RoleDAO) Seed(role *model.Role) (bool, error) {
+	created := false
+	err := mysql.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ?", role.ID).Attrs(role).FirstOrCreate(&model.Role{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		created = true
+		return d.replacePermissions(tx, role.ID, role.Permissions)
+	})
+	return created, err
+}
+
+func (d *RoleDAO) loadPermissions(db *gorm.DB, roleID uint) ([]string, error) {
+	perms := []string{}
+	err := db.Model(&model.RolePermission{}).Where("role_id = ?", roleID).
+		Order("id ASC").Pluck("permission", &perms).Error
+	return perms, err
+}
+
+func (d *RoleDAO) replacePermissions(tx *gorm.DB, roleID uint, permissions []string) error {
+	if err := tx.Where("role_id = ?", roleID).Delete(&model.RolePermission{}).Error; err != nil {
+		return err
+	}
+	rows := make([]*model.RolePermission, 0, len(permissions))
+	for _, p := range permissions {
+		rows = append(rows, &model.RolePermission{RoleID: roleID, Permission: p})
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	return tx.Create(&rows).Error
+}
@@ -0,0 +1,177 @@
+package dao
+
+import (
+	"time"
+
+	"bgame/internal/model"
+	"bgame/pkg/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ShopSkuFilter 商品查询条件，零值字段不参与过滤
+type ShopSkuFilter struct {
+	Keyword string // 按名称模糊匹配
+	Status  *int
+}
+
+// ShopPurchaseFilter 购买记录查询条件，零值字段不参与过滤
+type ShopPurchaseFilter struct {
+	UserID    uint
+	SkuID     uint
+	ReceiptNo string
+	Currency  model.WalletCurrency
+	StartTime time.Time
+	EndTime   time.Time
+}
+
+type ShopDAO struct{}
+
+func NewShopDAO() *ShopDAO {
+	return &ShopDAO{}
+}
+
+// CreateSku 创建商品
+func (d *ShopDAO) CreateSku(sku *model.ShopSku) error {
+	return mysql.DB.Create(sku).Error
+}
+
+// GetSku 根据ID获取商品
+func (d *ShopDAO) GetSku(id uint) (*model.ShopSku, error) {
+	var sku model.ShopSku
+	if err := mysql.DB.First(&sku, id).Error; err != nil {
+		return nil, err
+	}
+	return &sku, nil
+}
+
+// LockSku 在事务内锁定商品行，同一商品的购买串行执行，保证库存和限购准确
+func (d *ShopDAO) LockSku(tx *gorm.DB, id uint) (*model.ShopSku, error) {
+	var sku model.ShopSku
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&sku, id).Error; err != nil {
+		return nil, err
+	}
+	return &sku, nil
+}
+
+// ListSkus 分页查询商品（管理端，按ID倒序）
+func (d *ShopDAO) ListSkus(filter *ShopSkuFilter, offset, limit int) ([]model.ShopSku, int64, error) {
+	query := mysql.DB.Model(&model.ShopSku{})
+	if filter.Keyword != "" {
+		query = query.Where("name LIKE ?", "%"+filter.Keyword+"%")
+	}
+	if filter.Status != nil {
+		query = query.Where("status = ?", *filter.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var skus []model.ShopSku
+	if err := query.Order("id DESC").Offset(offset).Limit(limit).Find(&skus).Error; err != nil {
+		return nil, 0, err
+	}
+	return skus, total, nil
+}
+
+// ListOnSale 分页查询当前在售商品（按排序值倒序），已售罄的商品仍会返回
+func (d *ShopDAO) ListOnSale(now time.Time, offset, limit int) ([]model.ShopSku, int64, error) {
+	query := mysql.DB.Model(&model.ShopSku{}).
+		Where("status = ?", model.ShopSkuOnSale).
+		Where("sale_start_at IS NULL OR sale_start_at <= ?", now).
+		Where("sale_end_at IS NULL OR sale_end_at > ?", now)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var skus []model.ShopSku
+	if err := query.Order("sort_order DESC, id DESC").Offset(offset).Limit(limit).Find(&skus).Error; err != nil {
+		return nil, 0, err
+	}
+	return skus, total, nil
+}
+
+// UpdateSkuFields 更新商品字段
+func (d *ShopDAO) UpdateSkuFields(id uint, fields map[string]interface{}) error {
+	return mysql.DB.Model(&model.ShopSku{}).Where("id = ?", id).Updates(fields).Error
+}
+
+// SumUserQuantity 统计玩家已购买某商品的份数
+func (d *ShopDAO) SumUserQuantity(tx *gorm.DB, skuID, userID uint) (int, error) {
+	var total int
+	if err := tx.Model(&model.ShopPurchase{}).
+		Select("COALESCE(SUM(quantity), 0)").
+		Where("sku_id = ? AND user_id = ?", skuID, userID).Scan(&total).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// SumUserQuantities 批量统计玩家已购买的份数，返回以商品ID为键的映射
+func (d *ShopDAO) SumUserQuantities(userID uint, skuIDs []uint) (map[uint]int, error) {
+	totals := make(map[uint]int, len(skuIDs))
+	if len(skuIDs) == 0 {
+		return totals, nil
+	}
+	var rows []struct {
+		SkuID    uint
+		Quantity int
+	}
+	if err := mysql.DB.Model(&model.ShopPurchase{}).
+		Select("sku_id, SUM(quantity) AS quantity").
+		Where("user_id = ? AND sku_id IN ?", userID, skuIDs).
+		Group("sku_id").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		totals[row.SkuID] = row.Quantity
+	}
+	return totals, nil
+}
+
+// CreatePurchase 写入购买记录并累加商品已售数量
+func (d *ShopDAO) CreatePurchase(tx *gorm.DB, purchase *model.ShopPurchase) error {
+	if err := tx.Create(purchase).Error; err != nil {
+		return err
+	}
+	return tx.Model(&model.ShopSku{}).Where("id = ?", purchase.SkuID).
+		Update("sold_count", gorm.Expr("sold_count + ?", purchase.Quantity)).Error
+}
+
+// ListPurchases 分页查询购买记录（按时间倒序）
+func (d *ShopDAO) ListPurchases(filter *ShopPurchaseFilter, offset, limit int) ([]model.ShopPurchase, int64, error) {
+	query := mysql.DB.Model(&model.ShopPurchase{})
+	if filter.UserID > 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.SkuID > 0 {
+		query = query.Where("sku_id = ?", filter.SkuID)
+	}
+	if filter.ReceiptNo != "" {
+		query = query.Where("receipt_no = ?", filter.ReceiptNo)
+	}
+	if filter.Currency != "" {
+		query = query.Where("currency = ?", filter.Currency)
+	}
+	if !filter.StartTime.IsZero() {
+		query = query.Where("created_at >= ?", filter.StartTime)
+	}
+	if !filter.EndTime.IsZero() {
+		query = query.Where("created_at < ?", filter.EndTime)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var purchases []model.ShopPurchase
+	if err := query.Order("id DESC").Offset(offset).Limit(limit).Find(&purchases).Error; err != nil {
+		return nil, 0, err
+	}
+	return purchases, total, nil
+}
@@ -0,0 +1,167 @@
+package dao
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"bgame/pkg/redis"
+	goredis "github.com/go-redis/redis/v8"
+)
+
+const (
+	refreshTokenPrefix   = "refresh:token:"   // refresh token 记录（key 为 token 摘要）
+	refreshRotatedPrefix = "refresh:rotated:" // 已轮换标记（key 为 token 摘要）
+	refreshFamilyPrefix  = "refresh:family:"  // token 家族成员集合
+	refreshDevicePrefix  = "refresh:device:"  // 设备当前使用的 token 家族
+	refreshSubjectPrefix = "refresh:subject:" // 用户/管理员名下的全部 token 家族
+
+	tokenDenyPrefix         = "token:deny:"          // 已吊销的 access token（key 为 jti）
+	tokenRevokeBeforePrefix = "token:revoke_before:" // 早于该时间签发的 access token 全部失效
+)
+
+// RefreshTokenRecord Redis 中保存的 refresh token 信息
+type RefreshTokenRecord struct {
+	Type      string `json:"type"` // "user" or "admin"
+	SubjectID uint   `json:"subject_id"`
+	DeviceID  string `json:"device_id"`
+	FamilyID  string `json:"family_id"`
+}
+
+type RefreshTokenDAO struct{}
+type TokenRevocationDAO struct{}
+
+func NewRefreshTokenDAO() *RefreshTokenDAO {
+	return &RefreshTokenDAO{}
+}
+
+func NewTokenRevocationDAO() *TokenRevocationDAO {
+	return &TokenRevocationDAO{}
+}
+
+// Save 保存 refresh token 并加入所属家族
+func (d *RefreshTokenDAO) Save(tokenHash string, record *RefreshTokenRecord, ttl time.Duration) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	familyKey := refreshFamilyPrefix + record.FamilyID
+	pipe := redis.Client.TxPipeline()
+	pipe.Set(ctx, refreshTokenPrefix+tokenHash, data, ttl)
+	pipe.SAdd(ctx, familyKey, tokenHash)
+	pipe.Expire(ctx, familyKey, ttl)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// Get 获取 refresh token 记录
+func (d *RefreshTokenDAO) Get(tokenHash string) (*RefreshTokenRecord, error) {
+	data, err := redis.Client.Get(context.Background(), refreshTokenPrefix+tokenHash).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var record RefreshTokenRecord
+	if err := json.Unmarshal([]byte(data), &record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// MarkRotated 标记 token 已轮换，返回 false 表示该 token 此前已被使用过
+func (d *RefreshTokenDAO) MarkRotated(tokenHash string, ttl time.Duration) (bool, error) {
+	return redis.Client.SetNX(context.Background(), refreshRotatedPrefix+tokenHash, 1, ttl).Result()
+}
+
+// RevokeFamily 吊销整个 token 家族
+func (d *RefreshTokenDAO) RevokeFamily(familyID string) error {
+	ctx := context.Background()
+	familyKey := refreshFamilyPrefix + familyID
+	hashes, err := redis.Client.SMembers(ctx, familyKey).Result()
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(hashes)*2+1)
+	for _, h := range hashes {
+		keys = append(keys, refreshTokenPrefix+h, refreshRotatedPrefix+h)
+	}
+	keys = append(keys, familyKey)
+	return redis.Client.Del(ctx, keys...).Err()
+}
+
+// GetDeviceFamily 获取设备当前的 token 家族ID
+func (d *RefreshTokenDAO) GetDeviceFamily(subjectType string, subjectID uint, deviceID string) (string, error) {
+	return redis.Client.Get(context.Background(), deviceKey(subjectType, subjectID, deviceID)).Result()
+}
+
+// SetDeviceFamily 设置设备当前的 token 家族ID，并登记到用户/管理员名下
+func (d *RefreshTokenDAO) SetDeviceFamily(subjectType string, subjectID uint, deviceID, familyID string, ttl time.Duration) error {
+	ctx := context.Background()
+	sKey := subjectKey(refreshSubjectPrefix, subjectType, subjectID)
+	pipe := redis.Client.TxPipeline()
+	pipe.Set(ctx, deviceKey(subjectType, subjectID, deviceID), familyID, ttl)
+	pipe.SAdd(ctx, sKey, familyID)
+	pipe.Expire(ctx, sKey, ttl)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// RevokeSubject 吊销用户/管理员名下的全部 token 家族
+func (d *RefreshTokenDAO) RevokeSubject(subjectType string, subjectID uint) error {
+	ctx := context.Background()
+	sKey := subjectKey(refreshSubjectPrefix, subjectType, subjectID)
+	families, err := redis.Client.SMembers(ctx, sKey).Result()
+	if err != nil {
+		return err
+	}
+
+	for _, familyID := range families {
+		if err := d.RevokeFamily(familyID); err != nil {
+			return err
+		}
+	}
+	return redis.Client.Del(ctx, sKey).Err()
+}
+
+// Deny 将 access token 加入黑名单，ttl 为其剩余有效期
+func (d *TokenRevocationDAO) Deny(jti string, ttl time.Duration) error {
+	return redis.Client.Set(context.Background(), tokenDenyPrefix+jti, 1, ttl).Err()
+}
+
+// SetRevokeBefore 使用户/管理员在 before 之前签发的 access token 全部失效
+func (d *TokenRevocationDAO) SetRevokeBefore(subjectType string, subjectID uint, before time.Time, ttl time.Duration) error {
+	key := subjectKey(tokenRevokeBeforePrefix, subjectType, subjectID)
+	return redis.Client.Set(context.Background(), key, before.Unix(), ttl).Err()
+}
+
+// IsRevoked 检查 access token 是否在黑名单中或不晚于吊销时间签发（一次往返）
+// iat 只精确到秒，与吊销时刻同一秒签发的 token 也视为已吊销
+func (d *TokenRevocationDAO) IsRevoked(jti, subjectType string, subjectID uint, issuedAt time.Time) (bool, error) {
+	ctx := context.Background()
+	pipe := redis.Client.Pipeline()
+	denied := pipe.Exists(ctx, tokenDenyPrefix+jti)
+	before := pipe.Get(ctx, subjectKey(tokenRevokeBeforePrefix, subjectType, subjectID))
+	if _, err := pipe.Exec(ctx); err != nil && err != goredis.Nil {
+		return false, err
+	}
+
+	if denied.Val() > 0 {
+		return true, nil
+	}
+	if ts, err := before.Int64(); err == nil && issuedAt.Unix() <= ts {
+		return true, nil
+	}
+	return false, nil
+}
+
+func deviceKey(subjectType string, subjectID uint, deviceID string) string {
+	return fmt.Sprintf("%s%s:%d:%s", refreshDevicePrefix, subjectType, subjectID, deviceID)
+}
+
+func subjectKey(prefix, subjectType string, subjectID uint) string {
+	return fmt.Sprintf("%s%s:%d", prefix, subjectType, subjectID)
+}
@@ -0,0 +1,176 @@
+package dao
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"bgame/internal/model"
+	"bgame/pkg/mysql"
+	"bgame/pkg/redis"
+	"gorm.io/gorm"
+)
+
+const (
+	twoFactorChallengePrefix = "2fa:challenge:" // 登录挑战（key 为挑战 token 摘要）
+	twoFactorAttemptsPrefix  = "2fa:attempts:"  // 登录挑战的验证失败次数
+	twoFactorUsedCodePrefix  = "2fa:used:"      // 已使用的 TOTP 时间步，防止验证码重放
+)
+
+var ErrTwoFactorEnabled = errors.New("两步验证已启用")
+
+// TwoFactorChallenge 密码验证通过、等待两步验证的登录挑战
+type TwoFactorChallenge struct {
+	AdminID      uint   `json:"admin_id"`
+	DeviceID     string `json:"device_id"`
+	ClientIP     string `json:"client_ip"`
+	SetupPending bool   `json:"setup_pending"` // 强制启用两步验证但尚未绑定
+}
+
+type TwoFactorDAO struct{}
+
+func NewTwoFactorDAO() *TwoFactorDAO {
+	return &TwoFactorDAO{}
+}
+
+// Get 获取管理员两步验证配置
+func (d *TwoFactorDAO) Get(adminID uint) (*model.AdminTwoFactor, error) {
+	var tf model.AdminTwoFactor
+	if err := mysql.DB.Where("admin_id = ?", adminID).First(&tf).Error; err != nil {
+		return nil, err
+	}
+	return &tf, nil
+}
+
+// IsEnabled 管理员是否已启用两步验证
+func (d *TwoFactorDAO) IsEnabled(adminID uint) (bool, error) {
+	tf, err := d.Get(adminID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return tf.Enabled, nil
+}
+
+// SavePending 保存待验证的新密钥，已启用的配置不会被覆盖
+func (d *TwoFactorDAO) SavePending(adminID uint, secret string) error {
+	return mysql.DB.Transaction(func(tx *gorm.DB) error {
+		var tf model.AdminTwoFactor
+		err := tx.Where("admin_id = ?", adminID).First(&tf).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return tx.Create(&model.AdminTwoFactor{AdminID: adminID, Secret: secret}).Error
+		}
+		if err != nil {
+			return err
+		}
+		if tf.Enabled {
+			return ErrTwoFactorEnabled
+		}
+		return tx.Model(&tf).Update("secret", secret).Error
+	})
+}
+
+// Enable 启用两步验证并替换全部恢复码
+func (d *TwoFactorDAO) Enable(adminID uint, codeHashes []string) error {
+	return mysql.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Model(&model.AdminTwoFactor{}).Where("admin_id = ?", adminID).
+			Updates(map[string]interface{}{"enabled": true, "enabled_at": &now}).Error; err != nil {
+			return err
+		}
+		return d.replaceRecoveryCodes(tx, adminID, codeHashes)
+	})
+}
+
+// Disable 关闭两步验证，删除密钥和恢复码
+func (d *TwoFactorDAO) Disable(adminID uint) error {
+	return mysql.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("admin_id = ?", adminID).Delete(&model.AdminTwoFactor{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("admin_id = ?", adminID).Delete(&model.AdminRecoveryCode{}).Error
+	})
+}
+
+// ReplaceRecoveryCodes 重新生成恢复码，旧恢复码全部作废
+func (d *TwoFactorDAO) ReplaceRecoveryCodes(adminID uint, codeHashes []string) error {
+	return mysql.DB.Transaction(func(tx *gorm.DB) error {
+		return d.replaceRecoveryCodes(tx, adminID, codeHashes)
+	})
+}
+
+func (d *TwoFactorDAO) replaceRecoveryCodes(tx *gorm.DB, adminID uint, codeHashes []string) error {
+	if err := tx.Where("admin_id = ?", adminID).Delete(&model.AdminRecoveryCode{}).Error; err != nil {
+		return err
+	}
+	codes := make([]*model.AdminRecoveryCode, 0, len(codeHashes))
+	for _, hash := range codeHashes {
+		codes = append(codes, &model.AdminRecoveryCode{AdminID: adminID, CodeHash: hash})
+	}
+	if len(codes) == 0 {
+		return nil
+	}
+	return tx.Create(&codes).Error
+}
+
+// UseRecoveryCode 核销恢复码，返回是否核销成功（条件更新保证并发下只能使用一次）
+func (d *TwoFactorDAO) UseRecoveryCode(adminID uint, codeHash string) (bool, error) {
+	result := mysql.DB.Model(&model.AdminRecoveryCode{}).
+		Where("admin_id = ? AND code_hash = ? AND used_at IS NULL", adminID, codeHash).
+		Update("used_at", time.Now())
+	return result.RowsAffected == 1, result.Error
+}
+
+// CountRecoveryCodes 剩余可用的恢复码数量
+func (d *TwoFactorDAO) CountRecoveryCodes(adminID uint) (int64, error) {
+	var count int64
+	err := mysql.DB.Model(&model.AdminRecoveryCode{}).
+		Where("admin_id = ? AND used_at IS NULL", adminID).Count(&count).Error
+	return count, err
+}
+
+// SaveChallenge 保存登录挑战
+func (d *TwoFactorDAO) SaveChallenge(tokenHash string, challenge *TwoFactorChallenge, ttl time.Duration) error {
+	data, err := json.Marshal(challenge)
+	if err != nil {
+		return err
+	}
+	return redis.Client.Set(context.Background(), twoFactorChallengePrefix+tokenHash, data, ttl).Err()
+}
+
+// GetChallenge 获取登录挑战
+func (d *TwoFactorDAO) GetChallenge(tokenHash string) (*TwoFactorChallenge, error) {
+	data, err := redis.Client.Get(context.Background(), twoFactorChallengePrefix+tokenHash).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var challenge TwoFactorChallenge
+	if err := json.Unmarshal([]byte(data), &challenge); err != nil {
+		return nil, err
+	}
+	return &challenge, nil
+}
+
+// IncrChallengeAttempts 挑战验证失败次数加一
+func (d *TwoFactorDAO) IncrChallengeAttempts(tokenHash string, ttl time.Duration) (int64, error) {
+	return incrWithTTL(twoFactorAttemptsPrefix+tokenHash, ttl)
+}
+
+// DeleteChallenge 删除登录挑战，返回是否删除成功（用于保证挑战只能完成一次）
+func (d *TwoFactorDAO) DeleteChallenge(tokenHash string) (bool, error) {
+	ctx := context.Background()
+	n, err := redis.Client.Del(ctx, twoFactorChallengePrefix+tokenHash).Result()
+	redis.Client.Del(ctx, twoFactorAttemptsPrefix+tokenHash)
+	return n == 1, err
+}
+
+// MarkCodeUsed 记录已使用的 TOTP 时间步，返回 false 表示该验证码已被使用过
+func (d *TwoFactorDAO) MarkCodeUsed(adminID uint, step int64, ttl time.Duration) (bool, error) {
+	key := fmt.Sprintf("%s%d:%d", twoFactorUsedCodePrefix, adminID, step)
+	return redis.Client.SetNX(context.Background(), key, 1, ttl).Result()
+}
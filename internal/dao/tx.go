@@ -0,0 +1,11 @@
+package dao
+
+import (
+	"bgame/pkg/mysql"
+	"gorm.io/gorm"
+)
+
+// Transaction 开启数据库事务，供需要跨多个 DAO 保证原子性的业务使用
+func Transaction(fn func(tx *gorm.DB) error) error {
+	return mysql.DB.Transaction(fn)
+}
@@ -9,6 +9,7 @@ import (
 	"bgame/internal/model"
 	"bgame/pkg/mysql"
 	"bgame/pkg/redis"
+	"gorm.io/gorm"
 )
 
 const (
@@ -16,6 +17,20 @@ const (
 	userCacheTTL    = 3600 * time.Second
 )
 
+// userPublicColumns 不含密码的用户字段
+const userPublicColumns = "id, username, email, nickname, status, ban_reason, banned_until, created_at, updated_at"
+
+// UserFilter 用户查询条件，零值字段不参与过滤
+type UserFilter struct {
+	ID        uint
+	Username  string // 模糊匹配
+	Email     string // 模糊匹配
+	Nickname  string // 模糊匹配
+	Status    int
+	StartTime time.Time // 注册时间
+	EndTime   time.Time
+}
+
 type UserDAO struct{}
 type UserProfileDAO struct{}
 
@@ -32,6 +47,17 @@ func (d *UserDAO) Create(user *model.User) error {
 	return mysql.DB.Create(user).Error
 }
 
+// CreateWithProfile 在同一事务中创建用户及其资料
+func (d *UserDAO) CreateWithProfile(user *model.User, userProfile *model.UserProfile) error {
+	return mysql.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		userProfile.UserID = user.ID
+		return tx.Create(userProfile).Error
+	})
+}
+
 // GetByID 根据ID获取用户（带缓存）
 func (d *UserDAO) GetByID(id uint) (*model.User, error) {
 	// 先查缓存
@@ -46,9 +72,15 @@ func (d *UserDAO) GetByID(id uint) (*model.User, error) {
 
 	// 查数据库排除软删除和password字段
 	var user model.User
-	if err := mysql.DB.Where("id = ? AND status = 1").Select("id, username, email, nickname, status, created_at, updated_at").First(&user).Error; err != nil {
+	if err := mysql.DB.Where("id = ? AND status = 1", id).Select("id, username, email, nickname, status, created_at, updated_at").First(&user).Error; err != nil {
 		return nil, err
 	}
+
+	// 写入缓存
+	if userData, err := json.Marshal(user); err == nil {
+		redis.Client.Set(context.Background(), cacheKey, userData, userCacheTTL)
+	}
+
 	return &user, nil
 }
 
@@ -87,6 +119,77 @@ func (d *UserDAO) DeleteCache(userID uint) {
 	redis.Client.Del(context.Background(), cacheKey)
 }
 
+// FindByID 根据ID获取用户（不过滤状态、不走缓存、不含密码，用于管理操作）
+func (d *UserDAO) FindByID(id uint) (*model.User, error) {
+	var user model.User
+	if err := mysql.DB.Select(userPublicColumns).First(&user, id).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetNicknames 批量获取用户昵称，用于排行榜等展示场景
+func (d *UserDAO) GetNicknames(ids []uint) (map[uint]string, error) {
+	nicknames := make(map[uint]string, len(ids))
+	if len(ids) == 0 {
+		return nicknames, nil
+	}
+	var users []model.User
+	if err := mysql.DB.Select("id, nickname").Where("id IN ?", ids).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	for _, user := range users {
+		nicknames[user.ID] = user.Nickname
+	}
+	return nicknames, nil
+}
+
+// List 分页查询用户（按ID倒序，不含密码）
+func (d *UserDAO) List(filter *UserFilter, offset, limit int) ([]model.User, int64, error) {
+	query := mysql.DB.Model(&model.User{})
+	if filter.ID > 0 {
+		query = query.Where("id = ?", filter.ID)
+	}
+	if filter.Username != "" {
+		query = query.Where("username LIKE ?", "%"+filter.Username+"%")
+	}
+	if filter.Email != "" {
+		query = query.Where("email LIKE ?", "%"+filter.Email+"%")
+	}
+	if filter.Nickname != "" {
+		query = query.Where("nickname LIKE ?", "%"+filter.Nickname+"%")
+	}
+	if filter.Status > 0 {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if !filter.StartTime.IsZero() {
+		query = query.Where("created_at >= ?", filter.StartTime)
+	}
+	if !filter.EndTime.IsZero() {
+		query = query.Where("created_at < ?", filter.EndTime)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var users []model.User
+	if err := query.Select(userPublicColumns).Order("id DESC").Offset(offset).Limit(limit).Find(&users).Error; err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+// UpdateFields 更新用户指定字段并清除缓存
+func (d *UserDAO) UpdateFields(id uint, fields map[string]interface{}) error {
+	err := mysql.DB.Model(&model.User{}).Where("id = ?", id).Updates(fields).Error
+	if err == nil {
+		d.DeleteCache(id)
+	}
+	return err
+}
+
 // CreateUserProfile 创建用户资料
 func (d *UserProfileDAO) CreateUserProfile(userProfile *model.UserProfile) error {
 	return mysql.DB.Create(userProfile).Error
@@ -101,7 +204,8 @@ func (d *UserProfileDAO) GetUserProfileByUserID(userID uint) (*model.UserProfile
 	return &userProfile, nil
 }
 
-// UpdateUserProfileByUserID 根据用户ID更新用户资料
+// UpdateUserProfileByUserID 根据用户ID更新用户资料（余额只能通过钱包流水变更，此处忽略）
 func (d *UserProfileDAO) UpdateUserProfileByUserID(userID uint, userProfile *model.UserProfile) error {
-	return mysql.DB.Model(&model.UserProfile{}).Where("user_id = ?", userID).Updates(userProfile).Error
+	return mysql.DB.Model(&model.UserProfile{}).Where("user_id = ?", userID).
+		Omit("balance", "activity_balance").Updates(userProfile).Error
 }
@@ -0,0 +1,154 @@
+package dao
+
+import (
+	"errors"
+	"time"
+
+	"bgame/internal/model"
+	"bgame/internal/util"
+	"bgame/pkg/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInsufficientBalance = errors.New("余额不足")
+	ErrBalanceOverflow     = errors.New("余额超出上限")
+)
+
+// WalletChange 一笔钱包变动
+type WalletChange struct {
+	UserID      uint
+	Currency    model.WalletCurrency
+	Direction   model.WalletDirection
+	Amount      model.Money
+	ReasonCode  string
+	ReferenceID string
+	OperatorID  uint
+	Remark      string
+}
+
+// WalletLedgerFilter 流水查询条件，零值字段不参与过滤
+type WalletLedgerFilter struct {
+	UserID      uint
+	Currency    model.WalletCurrency
+	Direction   model.WalletDirection
+	ReasonCode  string
+	ReferenceID string
+	StartTime   time.Time
+	EndTime     time.Time
+}
+
+type WalletDAO struct{}
+
+func NewWalletDAO() *WalletDAO {
+	return &WalletDAO{}
+}
+
+// Apply 在事务内锁定用户资料行、变更余额并写入复式流水，返回用户账户分录
+func (d *WalletDAO) Apply(tx *gorm.DB, change *WalletChange) (*model.WalletLedgerEntry, error) {
+	var profile model.UserProfile
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ?", change.UserID).First(&profile).Error; err != nil {
+		return nil, err
+	}
+
+	column := string(change.Currency)
+	current := profile.Balance
+	if change.Currency == model.CurrencyActivityBalance {
+		current = profile.ActivityBalance
+	}
+
+	amount := change.Amount
+	counterDirection := model.WalletDebit
+	var balanceAfter model.Money
+	var err error
+	if change.Direction == model.WalletDebit {
+		balanceAfter, err = current.Sub(amount)
+		counterDirection = model.WalletCredit
+	} else {
+		balanceAfter, err = current.Add(amount)
+	}
+	if err != nil || !balanceAfter.InRange() {
+		return nil, ErrBalanceOverflow
+	}
+	if balanceAfter.IsNegative() {
+		return nil, ErrInsufficientBalance
+	}
+
+	if err := tx.Model(&model.UserProfile{}).Where("id = ?", profile.ID).
+		Update(column, balanceAfter).Error; err != nil {
+		return nil, err
+	}
+
+	txNo := util.NewSerialNo("W")
+	entries := []*model.WalletLedgerEntry{
+		{
+			TxNo:         txNo,
+			AccountType:  model.WalletAccountUser,
+			AccountID:    change.UserID,
+			Currency:     change.Currency,
+			Direction:    change.Direction,
+			Amount:       amount,
+			BalanceAfter: balanceAfter,
+			ReasonCode:   change.ReasonCode,
+			ReferenceID:  change.ReferenceID,
+			OperatorID:   change.OperatorID,
+			Remark:       change.Remark,
+		},
+		{
+			// 系统对手账户不维护余额
+			TxNo:        txNo,
+			AccountType: model.WalletAccountSystem,
+			AccountID:   0,
+			Currency:    change.Currency,
+			Direction:   counterDirection,
+			Amount:      amount,
+			ReasonCode:  change.ReasonCode,
+			ReferenceID: change.ReferenceID,
+			OperatorID:  change.OperatorID,
+			Remark:      change.Remark,
+		},
+	}
+	if err := tx.Create(entries).Error; err != nil {
+		return nil, err
+	}
+	return entries[0], nil
+}
+
+// ListUserEntries 分页查询用户账户流水（按时间倒序）
+func (d *WalletDAO) ListUserEntries(filter *WalletLedgerFilter, offset, limit int) ([]model.WalletLedgerEntry, int64, error) {
+	query := mysql.DB.Model(&model.WalletLedgerEntry{}).Where("account_type = ?", model.WalletAccountUser)
+	if filter.UserID > 0 {
+		query = query.Where("account_id = ?", filter.UserID)
+	}
+	if filter.Currency != "" {
+		query = query.Where("currency = ?", filter.Currency)
+	}
+	if filter.Direction != "" {
+		query = query.Where("direction = ?", filter.Direction)
+	}
+	if filter.ReasonCode != "" {
+		query = query.Where("reason_code = ?", filter.ReasonCode)
+	}
+	if filter.ReferenceID != "" {
+		query = query.Where("reference_id = ?", filter.ReferenceID)
+	}
+	if !filter.StartTime.IsZero() {
+		query = query.Where("created_at >= ?", filter.StartTime)
+	}
+	if !filter.EndTime.IsZero() {
+		query = query.Where("created_at < ?", filter.EndTime)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var entries []model.WalletLedgerEntry
+	if err := query.Order("id DESC").Offset(offset).Limit(limit).Find(&entries).Error; err != nil {
+		return nil, 0, err
+	}
+	return entries, total, nil
+}
@@ -0,0 +1,239 @@
+package admin
+
+import (
+	"strconv"
+
+	"bgame/internal/model"
+	"bgame/internal/service"
+	"bgame/internal/util"
+	"github.com/gin-gonic/gin"
+)
+
+// ListAdmins 查询管理员列表
+// @Summary      查询管理员列表
+// @Description  分页查询管理员，支持按用户名、角色、状态和创建时间过滤
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page        query     int     false  "页码"
+// @Param        page_size   query     int     false  "每页数量"
+// @Param        username    query     string  false  "用户名（模糊匹配）"
+// @Param        role        query     int     false  "角色ID"
+// @Param        status      query     int     false  "状态：1正常 0禁用"
+// @Param        start_time  query     string  false  "创建时间起（2006-01-02 15:04:05）"
+// @Param        end_time    query     string  false  "创建时间止（2006-01-02 15:04:05）"
+// @Success      200  {object}  util.Response{data=util.PageResult{list=[]model.Admin}}
+// @Failure      400  {object}  util.Response
+// @Failure      403  {object}  util.Response
+// @Router       /api/admin/admins [get]
+func (h *AdminHandler) ListAdmins(c *gin.Context) {
+	var req service.AdminListQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+
+	resp, err := h.adminService.ListAdmins(&req)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.Success(c, resp)
+}
+
+// GetAdmin 获取管理员详情
+// @Summary      获取管理员详情
+// @Description  获取指定管理员的信息（包括已禁用的管理员）
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "管理员ID"
+// @Success      200  {object}  util.Response{data=model.Admin}
+// @Failure      400  {object}  util.Response
+// @Failure      403  {object}  util.Response
+// @Router       /api/admin/admins/{id} [get]
+func (h *AdminHandler) GetAdmin(c *gin.Context) {
+	adminID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.Error(c, "参数错误: 无效的管理员ID")
+		return
+	}
+
+	admin, err := h.adminService.GetAdmin(uint(adminID))
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.Success(c, admin)
+}
+
+// UpdateAdminRole 修改管理员角色
+// @Summary      修改管理员角色
+// @Description  修改指定管理员的角色，并吊销其全部会话使新角色立即生效；只有超级管理员可以管理或授予超级管理员，目标管理员的当前角色和新角色都不能拥有操作者自己没有的权限
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      int                             true  "管理员ID"
+// @Param        request  body      service.UpdateAdminRoleRequest  true  "修改角色请求"
+// @Success      200  {object}  util.Response
+// @Failure      400  {object}  util.Response
+// @Failure      403  {object}  util.Response
+// @Router       /api/admin/admins/{id}/role [put]
+func (h *AdminHandler) UpdateAdminRole(c *gin.Context) {
+	operatorID, operatorRole, ok := currentOperator(c)
+	if !ok {
+		return
+	}
+
+	adminID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.Error(c, "参数错误: 无效的管理员ID")
+		return
+	}
+
+	var req service.UpdateAdminRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+
+	if err := h.adminService.UpdateAdminRole(operatorID, operatorRole, uint(adminID), &req); err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.SuccessWithMessage(c, "修改角色成功", nil)
+}
+
+// UpdateAdminStatus 禁用或启用管理员
+// @Summary      禁用或启用管理员
+// @Description  修改指定管理员的状态，禁用时吊销其全部会话；不能管理权限超出自己的管理员
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      int                               true  "管理员ID"
+// @Param        request  body      service.UpdateAdminStatusRequest  true  "修改状态请求"
+// @Success      200  {object}  util.Response
+// @Failure      400  {object}  util.Response
+// @Failure      403  {object}  util.Response
+// @Router       /api/admin/admins/{id}/status [put]
+func (h *AdminHandler) UpdateAdminStatus(c *gin.Context) {
+	operatorID, operatorRole, ok := currentOperator(c)
+	if !ok {
+		return
+	}
+
+	adminID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.Error(c, "参数错误: 无效的管理员ID")
+		return
+	}
+
+	var req service.UpdateAdminStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+
+	if err := h.adminService.UpdateAdminStatus(operatorID, operatorRole, uint(adminID), &req); err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.SuccessWithMessage(c, "修改状态成功", nil)
+}
+
+// ResetAdminPassword 重置管理员密码
+// @Summary      重置管理员密码
+// @Description  重置指定管理员的密码并吊销其全部会话；未指定新密码时生成随机临时密码并在响应中返回；不能管理权限超出自己的管理员
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      int                                true   "管理员ID"
+// @Param        request  body      service.ResetAdminPasswordRequest  false  "重置密码请求"
+// @Success      200  {object}  util.Response{data=service.ResetAdminPasswordResponse}
+// @Failure      400  {object}  util.Response
+// @Failure      403  {object}  util.Response
+// @Router       /api/admin/admins/{id}/password/reset [post]
+func (h *AdminHandler) ResetAdminPassword(c *gin.Context) {
+	operatorID, operatorRole, ok := currentOperator(c)
+	if !ok {
+		return
+	}
+
+	adminID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.Error(c, "参数错误: 无效的管理员ID")
+		return
+	}
+
+	var req service.ResetAdminPasswordRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			util.Error(c, "参数错误: "+err.Error())
+			return
+		}
+	}
+
+	resp, err := h.adminService.ResetAdminPassword(operatorID, operatorRole, uint(adminID), &req)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.SuccessWithMessage(c, "重置密码成功", resp)
+}
+
+// DeleteAdmin 删除管理员
+// @Summary      删除管理员
+// @Description  软删除指定管理员并吊销其全部会话；不能管理权限超出自己的管理员
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "管理员ID"
+// @Success      200  {object}  util.Response
+// @Failure      400  {object}  util.Response
+// @Failure      403  {object}  util.Response
+// @Router       /api/admin/admins/{id} [delete]
+func (h *AdminHandler) DeleteAdmin(c *gin.Context) {
+	operatorID, operatorRole, ok := currentOperator(c)
+	if !ok {
+		return
+	}
+
+	adminID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.Error(c, "参数错误: 无效的管理员ID")
+		return
+	}
+
+	if err := h.adminService.DeleteAdmin(operatorID, operatorRole, uint(adminID)); err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.SuccessWithMessage(c, "删除管理员成功", nil)
+}
+
+// currentOperator 从上下文获取当前管理员ID和角色，获取失败时已写入响应
+func currentOperator(c *gin.Context) (uint, model.AdminRole, bool) {
+	adminID, exists := c.Get("admin_id")
+	if !exists {
+		util.Unauthorized(c, "未获取到管理员信息")
+		return 0, 0, false
+	}
+	role, exists := c.Get("role")
+	if !exists {
+		util.Unauthorized(c, "未获取到角色信息")
+		return 0, 0, false
+	}
+	return adminID.(uint), model.AdminRole(role.(int)), true
+}
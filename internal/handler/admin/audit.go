@@ -0,0 +1,53 @@
+package admin
+
+import (
+	"bgame/internal/service"
+	"bgame/internal/util"
+	"github.com/gin-gonic/gin"
+)
+
+type AuditHandler struct {
+	auditService *service.AuditService
+}
+
+func NewAuditHandler() *AuditHandler {
+	return &AuditHandler{
+		auditService: service.NewAuditService(),
+	}
+}
+
+// ListAuditLogs 查询管理员操作审计日志
+// @Summary      查询审计日志
+// @Description  分页查询管理员写操作的审计日志，可按操作人、动作、目标、请求ID和时间筛选；仅超级管理员可访问
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page        query int    false "页码"
+// @Param        page_size   query int    false "每页数量"
+// @Param        admin_id    query int    false "操作管理员ID"
+// @Param        action      query string false "动作，如 user.ban"
+// @Param        target_type query string false "目标类型：admin/user/role/wallet"
+// @Param        target_id   query string false "目标ID"
+// @Param        request_id  query string false "请求ID"
+// @Param        start_time  query string false "开始时间（2006-01-02 15:04:05）"
+// @Param        end_time    query string false "结束时间（2006-01-02 15:04:05）"
+// @Success      200  {object}  util.Response{data=util.PageResult{list=[]model.AdminAuditLog}}
+// @Failure      400  {object}  util.Response
+// @Failure      403  {object}  util.Response
+// @Router       /api/admin/audit-logs [get]
+func (h *AuditHandler) ListAuditLogs(c *gin.Context) {
+	var req service.AuditLogQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+
+	result, err := h.auditService.List(&req)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.Success(c, result)
+}
@@ -0,0 +1,290 @@
+package admin
+
+import (
+	"strconv"
+
+	"bgame/internal/service"
+	"bgame/internal/util"
+	"github.com/gin-gonic/gin"
+)
+
+type ItemHandler struct {
+	itemService      *service.ItemService
+	inventoryService *service.InventoryService
+}
+
+func NewItemHandler() *ItemHandler {
+	return &ItemHandler{
+		itemService:      service.NewItemService(),
+		inventoryService: service.NewInventoryService(),
+	}
+}
+
+// CreateItem 创建道具
+// @Summary      创建道具
+// @Description  在道具目录中新增道具。标识和是否可堆叠创建后不可修改；持有上限为单个玩家未过期道具的总数量，有效期从玩家获得时开始计算
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      service.CreateItemRequest  true  "道具信息"
+// @Success      200  {object}  util.Response{data=model.Item}
+// @Failure      400  {object}  util.Response
+// @Failure      403  {object}  util.Response
+// @Router       /api/admin/items [post]
+func (h *ItemHandler) CreateItem(c *gin.Context) {
+	adminID, exists := c.Get("admin_id")
+	if !exists {
+		util.Unauthorized(c, "未获取到管理员信息")
+		return
+	}
+
+	var req service.CreateItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+
+	item, err := h.itemService.CreateItem(adminID.(uint), &req)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.SuccessWithMessage(c, "创建成功", item)
+}
+
+// ListItems 查询道具目录
+// @Summary      查询道具目录
+// @Description  分页查询道具目录，可按标识或名称、分类、状态筛选
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page       query     int     false  "页码"
+// @Param        page_size  query     int     false  "每页数量"
+// @Param        keyword    query     string  false  "标识或名称（模糊匹配）"
+// @Param        category   query     string  false  "分类：consumable, equipment, material, cosmetic, gift"
+// @Param        status     query     int     false  "状态：1启用 0停用"
+// @Success      200  {object}  util.Response{data=util.PageResult{list=[]model.Item}}
+// @Failure      400  {object}  util.Response
+// @Failure      403  {object}  util.Response
+// @Router       /api/admin/items [get]
+func (h *ItemHandler) ListItems(c *gin.Context) {
+	var req service.ItemQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+
+	resp, err := h.itemService.ListItems(&req)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.Success(c, resp)
+}
+
+// GetItem 获取道具详情
+// @Summary      获取道具详情
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "道具ID"
+// @Success      200  {object}  util.Response{data=model.Item}
+// @Failure      400  {object}  util.Response
+// @Failure      403  {object}  util.Response
+// @Router       /api/admin/items/{id} [get]
+func (h *ItemHandler) GetItem(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.Error(c, "参数错误: 无效的道具ID")
+		return
+	}
+
+	item, err := h.itemService.GetItem(uint(id))
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.Success(c, item)
+}
+
+// UpdateItem 修改道具
+// @Summary      修改道具
+// @Description  修改道具名称、描述、图标、分类、持有上限、有效期和状态，只传需要修改的字段。停用后不能再发放，已持有的道具仍可消耗；修改有效期只影响之后获得的道具
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      int                        true  "道具ID"
+// @Param        request  body      service.UpdateItemRequest  true  "修改内容"
+// @Success      200  {object}  util.Response{data=model.Item}
+// @Failure      400  {object}  util.Response
+// @Failure      403  {object}  util.Response
+// @Router       /api/admin/items/{id} [put]
+func (h *ItemHandler) UpdateItem(c *gin.Context) {
+	adminID, exists := c.Get("admin_id")
+	if !exists {
+		util.Unauthorized(c, "未获取到管理员信息")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.Error(c, "参数错误: 无效的道具ID")
+		return
+	}
+
+	var req service.UpdateItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+
+	item, err := h.itemService.UpdateItem(adminID.(uint), uint(id), &req)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.SuccessWithMessage(c, "修改成功", item)
+}
+
+// GetUserInventory 查看玩家背包
+// @Summary      查看玩家背包
+// @Description  分页查询指定玩家背包中未过期的道具
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id         path      int     true   "用户ID"
+// @Param        page       query     int     false  "页码"
+// @Param        page_size  query     int     false  "每页数量"
+// @Param        category   query     string  false  "道具分类"
+// @Success      200  {object}  util.Response{data=util.PageResult{list=[]model.UserItem}}
+// @Failure      400  {object}  util.Response
+// @Failure      403  {object}  util.Response
+// @Router       /api/admin/users/{id}/inventory [get]
+func (h *ItemHandler) GetUserInventory(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.Error(c, "参数错误: 无效的用户ID")
+		return
+	}
+
+	var req service.InventoryQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+
+	resp, err := h.inventoryService.ListUserItems(uint(userID), &req)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.Success(c, resp)
+}
+
+// GrantItem 发放玩家道具
+// @Summary      发放玩家道具
+// @Description  管理员为玩家发放道具，必须填写备注，写入道具流水（原因码 admin_grant）
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      int                             true  "用户ID"
+// @Param        request  body      service.AdminItemChangeRequest  true  "发放请求"
+// @Success      200  {object}  util.Response{data=service.ItemChangeResponse}
+// @Failure      400  {object}  util.Response
+// @Failure      403  {object}  util.Response
+// @Router       /api/admin/users/{id}/items/grant [post]
+func (h *ItemHandler) GrantItem(c *gin.Context) {
+	h.changeItem(c, h.inventoryService.AdminGrant, "发放成功")
+}
+
+// ConsumeItem 扣除玩家道具
+// @Summary      扣除玩家道具
+// @Description  管理员扣除玩家道具，数量不足时失败，必须填写备注，写入道具流水（原因码 admin_consume）
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      int                             true  "用户ID"
+// @Param        request  body      service.AdminItemChangeRequest  true  "扣除请求"
+// @Success      200  {object}  util.Response{data=service.ItemChangeResponse}
+// @Failure      400  {object}  util.Response
+// @Failure      403  {object}  util.Response
+// @Router       /api/admin/users/{id}/items/consume [post]
+func (h *ItemHandler) ConsumeItem(c *gin.Context) {
+	h.changeItem(c, h.inventoryService.AdminConsume, "扣除成功")
+}
+
+func (h *ItemHandler) changeItem(c *gin.Context, change func(uint, string, uint, *service.AdminItemChangeRequest) (*service.ItemChangeResponse, error), message string) {
+	adminID, exists := c.Get("admin_id")
+	if !exists {
+		util.Unauthorized(c, "未获取到管理员信息")
+		return
+	}
+
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.Error(c, "参数错误: 无效的用户ID")
+		return
+	}
+
+	var req service.AdminItemChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+
+	resp, err := change(adminID.(uint), c.GetString("username"), uint(userID), &req)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.SuccessWithMessage(c, message, resp)
+}
+
+// ListItemLogs 查询道具流水
+// @Summary      查询道具流水
+// @Description  分页查询玩家道具流水，可按用户、道具、来源、原因码、关联业务ID和时间筛选
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page          query     int     false  "页码"
+// @Param        page_size     query     int     false  "每页数量"
+// @Param        user_id       query     int     false  "用户ID"
+// @Param        item_id       query     int     false  "道具ID"
+// @Param        source        query     string  false  "来源：server, admin, system"
+// @Param        reason_code   query     string  false  "原因码"
+// @Param        reference_id  query     string  false  "关联业务ID"
+// @Param        start_time    query     string  false  "开始时间，格式 2006-01-02 15:04:05"
+// @Param        end_time      query     string  false  "结束时间，格式 2006-01-02 15:04:05"
+// @Success      200  {object}  util.Response{data=util.PageResult{list=[]model.ItemLog}}
+// @Failure      400  {object}  util.Response
+// @Failure      403  {object}  util.Response
+// @Router       /api/admin/inventory/logs [get]
+func (h *ItemHandler) ListItemLogs(c *gin.Context) {
+	var req service.AdminItemLogQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+
+	resp, err := h.inventoryService.ListLogs(&req)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.Success(c, resp)
+}
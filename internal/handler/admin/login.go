@@ -7,18 +7,26 @@ import (
 )
 
 type AdminHandler struct {
-	adminService *service.AdminService
+	adminService     *service.AdminService
+	tokenService     *service.TokenService
+	twoFactorService *service.TwoFactorService
+	roleService      *service.RoleService
+	bootstrapService *service.BootstrapService
 }
 
 func NewAdminHandler() *AdminHandler {
 	return &AdminHandler{
-		adminService: service.NewAdminService(),
+		adminService:     service.NewAdminService(),
+		tokenService:     service.NewTokenService(),
+		twoFactorService: service.NewTwoFactorService(),
+		roleService:      service.NewRoleService(),
+		bootstrapService: service.NewBootstrapService(),
 	}
 }
 
 // Login 管理员登录
 // @Summary      管理员登录
-// @Description  管理员登录接口，返回JWT token和管理员信息
+// @Description  管理员登录接口，返回JWT token和管理员信息；已启用两步验证时返回 challenge_token，需调用 /api/admin/login/2fa 完成登录
 // @Tags         管理员接口
 // @Accept       json
 // @Produce      json
@@ -32,6 +40,7 @@ func (h *AdminHandler) Login(c *gin.Context) {
 		util.Error(c, "参数错误: "+err.Error())
 		return
 	}
+	req.ClientIP = c.ClientIP()
 
 	resp, err := h.adminService.Login(&req)
 	if err != nil {
@@ -0,0 +1,113 @@
+package admin
+
+import (
+	"strconv"
+
+	"bgame/internal/service"
+	"bgame/internal/util"
+	"github.com/gin-gonic/gin"
+)
+
+type MailHandler struct {
+	mailService *service.MailService
+}
+
+func NewMailHandler() *MailHandler {
+	return &MailHandler{
+		mailService: service.NewMailService(),
+	}
+}
+
+// SendMail 发送邮件
+// @Summary      发送邮件
+// @Description  向指定玩家（users，单次上限见配置）、分群（segment，按等级和注册时间筛选）或全服（all）发送邮件，可附带余额、活动余额、经验和道具作为附件，不带附件即为公告。指定玩家的邮件立即投递；分群和全服邮件在玩家打开邮箱时投递，只投递给发送时已注册的玩家，分群条件按投递时（玩家打开邮箱时）的等级和注册时间判断，而不是发送时：发送后升级超出范围的玩家收不到，之后才进入范围的玩家可以收到；不满足条件的玩家只判断一次，之后不再投递。未指定过期时间时按配置的默认有效期
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      service.SendMailRequest  true  "邮件内容"
+// @Success      200  {object}  util.Response{data=model.Mail}
+// @Failure      400  {object}  util.Response
+// @Failure      403  {object}  util.Response
+// @Router       /api/admin/mail [post]
+func (h *MailHandler) SendMail(c *gin.Context) {
+	adminID, exists := c.Get("admin_id")
+	if !exists {
+		util.Unauthorized(c, "未获取到管理员信息")
+		return
+	}
+
+	var req service.SendMailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+
+	mail, err := h.mailService.Send(adminID.(uint), &req)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.SuccessWithMessage(c, "发送成功", mail)
+}
+
+// ListMails 查询已发送的邮件
+// @Summary      查询已发送的邮件
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page         query     int     false  "页码"
+// @Param        page_size    query     int     false  "每页数量"
+// @Param        title        query     string  false  "标题（模糊匹配）"
+// @Param        target_type  query     string  false  "发送对象：users/segment/all"
+// @Param        start_time   query     string  false  "发送时间起（2006-01-02 15:04:05）"
+// @Param        end_time     query     string  false  "发送时间止（2006-01-02 15:04:05）"
+// @Success      200  {object}  util.Response{data=util.PageResult{list=[]model.Mail}}
+// @Failure      400  {object}  util.Response
+// @Failure      403  {object}  util.Response
+// @Router       /api/admin/mail [get]
+func (h *MailHandler) ListMails(c *gin.Context) {
+	var req service.MailQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+
+	resp, err := h.mailService.ListMails(&req)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.Success(c, resp)
+}
+
+// GetMail 获取邮件详情
+// @Summary      获取邮件详情
+// @Description  返回邮件内容及投递、阅读、领取、删除人数；分群和全服邮件只统计已投递给玩家的部分
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "邮件ID"
+// @Success      200  {object}  util.Response{data=service.MailDetail}
+// @Failure      400  {object}  util.Response
+// @Failure      403  {object}  util.Response
+// @Router       /api/admin/mail/{id} [get]
+func (h *MailHandler) GetMail(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.Error(c, "参数错误: 无效的邮件ID")
+		return
+	}
+
+	detail, err := h.mailService.GetMail(uint(id))
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.Success(c, detail)
+}
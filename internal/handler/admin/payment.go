@@ -0,0 +1,124 @@
+package admin
+
+import (
+	"strconv"
+
+	"bgame/internal/service"
+	"bgame/internal/util"
+	"github.com/gin-gonic/gin"
+)
+
+type PaymentHandler struct {
+	paymentService *service.PaymentService
+}
+
+func NewPaymentHandler() *PaymentHandler {
+	return &PaymentHandler{
+		paymentService: service.NewPaymentService(),
+	}
+}
+
+// ListOrders 查询充值订单
+// @Summary      查询充值订单
+// @Description  分页查询充值订单，可按用户、订单号、渠道交易号、渠道、状态和时间筛选
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page        query     int     false  "页码"
+// @Param        page_size   query     int     false  "每页数量"
+// @Param        user_id     query     int     false  "用户ID"
+// @Param        order_no    query     string  false  "订单号"
+// @Param        trade_no    query     string  false  "渠道交易号"
+// @Param        provider    query     string  false  "支付渠道"
+// @Param        status      query     string  false  "状态：pending, paid, failed, refunding, refunded"
+// @Param        start_time  query     string  false  "开始时间，格式 2006-01-02 15:04:05"
+// @Param        end_time    query     string  false  "结束时间，格式 2006-01-02 15:04:05"
+// @Success      200  {object}  util.Response{data=util.PageResult{list=[]model.PaymentOrder}}
+// @Failure      400  {object}  util.Response
+// @Failure      403  {object}  util.Response
+// @Router       /api/admin/payment/orders [get]
+func (h *PaymentHandler) ListOrders(c *gin.Context) {
+	var req service.AdminPaymentOrderQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+
+	resp, err := h.paymentService.ListOrders(&req)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.Success(c, resp)
+}
+
+// GetOrder 获取充值订单详情
+// @Summary      获取充值订单详情
+// @Description  获取订单信息及全部回调记录（含验签失败和重复的回调）
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "订单ID"
+// @Success      200  {object}  util.Response{data=service.PaymentOrderDetail}
+// @Failure      400  {object}  util.Response
+// @Failure      403  {object}  util.Response
+// @Router       /api/admin/payment/orders/{id} [get]
+func (h *PaymentHandler) GetOrder(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.Error(c, "参数错误: 无效的订单ID")
+		return
+	}
+
+	detail, err := h.paymentService.GetOrderDetail(uint(id))
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.Success(c, detail)
+}
+
+// Refund 充值订单退款
+// @Summary      充值订单退款
+// @Description  只有已支付的订单可以退款：先在事务内扣回玩家余额（余额不足时失败）并标记退款中，提交后调用渠道退款；渠道成功时标记已退款，失败时退还余额并恢复为已支付
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      int                           true  "订单ID"
+// @Param        request  body      service.RefundPaymentRequest  true  "退款原因"
+// @Success      200  {object}  util.Response{data=model.PaymentOrder}
+// @Failure      400  {object}  util.Response
+// @Failure      403  {object}  util.Response
+// @Router       /api/admin/payment/orders/{id}/refund [post]
+func (h *PaymentHandler) Refund(c *gin.Context) {
+	adminID, exists := c.Get("admin_id")
+	if !exists {
+		util.Unauthorized(c, "未获取到管理员信息")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.Error(c, "参数错误: 无效的订单ID")
+		return
+	}
+
+	var req service.RefundPaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+
+	order, err := h.paymentService.Refund(adminID.(uint), uint(id), &req)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.SuccessWithMessage(c, "退款成功", order)
+}
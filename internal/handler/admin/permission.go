@@ -0,0 +1,188 @@
+package admin
+
+import (
+	"strconv"
+
+	"bgame/internal/model"
+	"bgame/internal/service"
+	"bgame/internal/util"
+	"github.com/gin-gonic/gin"
+)
+
+type RoleHandler struct {
+	roleService *service.RoleService
+}
+
+func NewRoleHandler() *RoleHandler {
+	return &RoleHandler{
+		roleService: service.NewRoleService(),
+	}
+}
+
+// ListPermissions 获取权限列表
+// @Summary      获取权限列表
+// @Description  获取系统支持的全部权限标识；超级管理员分配权限时还可使用 "*"（全部权限）和 "资源:*"（该资源的全部操作）
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  util.Response{data=[]model.PermissionDef}
+// @Failure      403  {object}  util.Response
+// @Router       /api/admin/permissions [get]
+func (h *RoleHandler) ListPermissions(c *gin.Context) {
+	util.Success(c, model.Permissions)
+}
+
+// ListRoles 获取角色及权限列表
+// @Summary      获取角色及权限列表
+// @Description  获取全部角色及每个角色拥有的权限
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  util.Response{data=[]model.Role}
+// @Failure      403  {object}  util.Response
+// @Router       /api/admin/rbac/roles [get]
+func (h *RoleHandler) ListRoles(c *gin.Context) {
+	roles, err := h.roleService.List()
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.Success(c, roles)
+}
+
+// GetRole 获取角色详情
+// @Summary      获取角色详情
+// @Description  获取角色信息及其权限
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "角色ID"
+// @Success      200  {object}  util.Response{data=model.Role}
+// @Failure      400  {object}  util.Response
+// @Failure      403  {object}  util.Response
+// @Router       /api/admin/rbac/roles/{id} [get]
+func (h *RoleHandler) GetRole(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.Error(c, "参数错误: 无效的角色ID")
+		return
+	}
+
+	role, err := h.roleService.Get(uint(id))
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.Success(c, role)
+}
+
+// CreateRole 创建角色
+// @Summary      创建角色
+// @Description  创建自定义角色并分配权限，只能分配自己拥有的权限，通配权限只有超级管理员可以分配
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body service.CreateRoleRequest true "创建角色请求"
+// @Success      200  {object}  util.Response{data=model.Role}
+// @Failure      400  {object}  util.Response
+// @Failure      403  {object}  util.Response
+// @Router       /api/admin/rbac/roles [post]
+func (h *RoleHandler) CreateRole(c *gin.Context) {
+	operatorID, operatorRole, ok := currentOperator(c)
+	if !ok {
+		return
+	}
+
+	var req service.CreateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+
+	role, err := h.roleService.Create(operatorID, operatorRole, &req)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.SuccessWithMessage(c, "创建角色成功", role)
+}
+
+// UpdateRole 更新角色
+// @Summary      更新角色
+// @Description  更新角色名称、描述或权限（传入permissions时整体替换）；超级管理员角色的权限不可修改，非超级管理员不能修改权限超出自己的角色，也不能分配自己没有的权限
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      int                        true  "角色ID"
+// @Param        request  body      service.UpdateRoleRequest  true  "更新角色请求"
+// @Success      200  {object}  util.Response{data=model.Role}
+// @Failure      400  {object}  util.Response
+// @Failure      403  {object}  util.Response
+// @Router       /api/admin/rbac/roles/{id} [put]
+func (h *RoleHandler) UpdateRole(c *gin.Context) {
+	operatorID, operatorRole, ok := currentOperator(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.Error(c, "参数错误: 无效的角色ID")
+		return
+	}
+
+	var req service.UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+
+	role, err := h.roleService.Update(operatorID, operatorRole, uint(id), &req)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.SuccessWithMessage(c, "更新角色成功", role)
+}
+
+// DeleteRole 删除角色
+// @Summary      删除角色
+// @Description  删除自定义角色；内置角色和仍被管理员使用的角色不可删除
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "角色ID"
+// @Success      200  {object}  util.Response
+// @Failure      400  {object}  util.Response
+// @Failure      403  {object}  util.Response
+// @Router       /api/admin/rbac/roles/{id} [delete]
+func (h *RoleHandler) DeleteRole(c *gin.Context) {
+	adminID, exists := c.Get("admin_id")
+	if !exists {
+		util.Unauthorized(c, "未获取到管理员信息")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.Error(c, "参数错误: 无效的角色ID")
+		return
+	}
+
+	if err := h.roleService.Delete(adminID.(uint), uint(id)); err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.SuccessWithMessage(c, "删除角色成功", nil)
+}
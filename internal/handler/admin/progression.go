@@ -0,0 +1,95 @@
+package admin
+
+import (
+	"strconv"
+
+	"bgame/internal/service"
+	"bgame/internal/util"
+	"github.com/gin-gonic/gin"
+)
+
+type ProgressionHandler struct {
+	progressionService *service.ProgressionService
+}
+
+func NewProgressionHandler() *ProgressionHandler {
+	return &ProgressionHandler{
+		progressionService: service.NewProgressionService(),
+	}
+}
+
+// GrantExperience 发放经验
+// @Summary      发放经验
+// @Description  为玩家发放经验，可连升多级并按配置发放升级奖励
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      int                                  true  "用户ID"
+// @Param        request  body      service.AdminGrantExperienceRequest  true  "发放请求"
+// @Success      200  {object}  util.Response{data=service.GrantExperienceResponse}
+// @Failure      400  {object}  util.Response
+// @Failure      403  {object}  util.Response
+// @Router       /api/admin/users/{id}/experience [post]
+func (h *ProgressionHandler) GrantExperience(c *gin.Context) {
+	adminID, exists := c.Get("admin_id")
+	if !exists {
+		util.Unauthorized(c, "未获取到管理员信息")
+		return
+	}
+
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.Error(c, "参数错误: 无效的用户ID")
+		return
+	}
+
+	var req service.AdminGrantExperienceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+
+	resp, err := h.progressionService.AdminGrant(adminID.(uint), c.GetString("username"), uint(userID), &req)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.SuccessWithMessage(c, "发放成功", resp)
+}
+
+// ListExperienceLogs 查询经验流水
+// @Summary      查询经验流水
+// @Description  分页查询玩家经验流水，可按用户、来源、原因码、关联业务ID和时间筛选
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page          query     int     false  "页码"
+// @Param        page_size     query     int     false  "每页数量"
+// @Param        user_id       query     int     false  "用户ID"
+// @Param        source        query     string  false  "来源：server, admin"
+// @Param        reason_code   query     string  false  "原因码"
+// @Param        reference_id  query     string  false  "关联业务ID"
+// @Param        start_time    query     string  false  "开始时间（2006-01-02 15:04:05）"
+// @Param        end_time      query     string  false  "结束时间（2006-01-02 15:04:05）"
+// @Success      200  {object}  util.Response{data=util.PageResult{list=[]model.ExperienceLog}}
+// @Failure      400  {object}  util.Response
+// @Failure      403  {object}  util.Response
+// @Router       /api/admin/experience/logs [get]
+func (h *ProgressionHandler) ListExperienceLogs(c *gin.Context) {
+	var req service.AdminExperienceLogQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+
+	resp, err := h.progressionService.ListLogs(&req)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.Success(c, resp)
+}
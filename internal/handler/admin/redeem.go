@@ -0,0 +1,224 @@
+package admin
+
+import (
+	"strconv"
+
+	"bgame/internal/service"
+	"bgame/internal/util"
+	"github.com/gin-gonic/gin"
+)
+
+type RedeemHandler struct {
+	redeemService *service.RedeemService
+}
+
+func NewRedeemHandler() *RedeemHandler {
+	return &RedeemHandler{
+		redeemService: service.NewRedeemService(),
+	}
+}
+
+// CreateBatch 创建兑换码批次
+// @Summary      创建兑换码批次
+// @Description  一码一用（single）按数量批量生成随机码，每个码只能兑换一次，可限制每个玩家在本批次最多兑换几个码；通用码（multi）生成一个可被多人兑换的码，可自定义码并限制总兑换次数。同一批次共用奖励和有效期
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      service.CreateRedeemBatchRequest  true  "批次信息"
+// @Success      200  {object}  util.Response{data=model.RedeemBatch}
+// @Failure      400  {object}  util.Response
+// @Failure      403  {object}  util.Response
+// @Router       /api/admin/redeem/batches [post]
+func (h *RedeemHandler) CreateBatch(c *gin.Context) {
+	adminID, exists := c.Get("admin_id")
+	if !exists {
+		util.Unauthorized(c, "未获取到管理员信息")
+		return
+	}
+
+	var req service.CreateRedeemBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+
+	batch, err := h.redeemService.CreateBatch(adminID.(uint), &req)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.SuccessWithMessage(c, "创建成功", batch)
+}
+
+// ListBatches 查询兑换码批次
+// @Summary      查询兑换码批次
+// @Description  分页查询兑换码批次，包含已兑换次数
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page       query     int     false  "页码"
+// @Param        page_size  query     int     false  "每页数量"
+// @Param        name       query     string  false  "批次名称（模糊匹配）"
+// @Param        type       query     string  false  "类型：single, multi"
+// @Param        status     query     int     false  "状态：1启用 0停用"
+// @Success      200  {object}  util.Response{data=util.PageResult{list=[]model.RedeemBatch}}
+// @Failure      400  {object}  util.Response
+// @Failure      403  {object}  util.Response
+// @Router       /api/admin/redeem/batches [get]
+func (h *RedeemHandler) ListBatches(c *gin.Context) {
+	var req service.RedeemBatchQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+
+	resp, err := h.redeemService.ListBatches(&req)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.Success(c, resp)
+}
+
+// GetBatch 获取兑换码批次详情
+// @Summary      获取兑换码批次详情
+// @Description  获取批次信息及兑换统计（已兑换次数、已使用码数量、兑换玩家数）
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "批次ID"
+// @Success      200  {object}  util.Response{data=service.RedeemBatchDetail}
+// @Failure      400  {object}  util.Response
+// @Failure      403  {object}  util.Response
+// @Router       /api/admin/redeem/batches/{id} [get]
+func (h *RedeemHandler) GetBatch(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.Error(c, "参数错误: 无效的批次ID")
+		return
+	}
+
+	detail, err := h.redeemService.GetBatch(uint(id))
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.Success(c, detail)
+}
+
+// UpdateBatchStatus 启用/停用兑换码批次
+// @Summary      启用/停用兑换码批次
+// @Description  停用后该批次所有兑换码都不能再兑换，已发放的奖励不受影响
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      int                                     true  "批次ID"
+// @Param        request  body      service.UpdateRedeemBatchStatusRequest  true  "状态"
+// @Success      200  {object}  util.Response
+// @Failure      400  {object}  util.Response
+// @Failure      403  {object}  util.Response
+// @Router       /api/admin/redeem/batches/{id}/status [put]
+func (h *RedeemHandler) UpdateBatchStatus(c *gin.Context) {
+	adminID, exists := c.Get("admin_id")
+	if !exists {
+		util.Unauthorized(c, "未获取到管理员信息")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.Error(c, "参数错误: 无效的批次ID")
+		return
+	}
+
+	var req service.UpdateRedeemBatchStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+
+	if err := h.redeemService.UpdateBatchStatus(adminID.(uint), uint(id), &req); err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.SuccessWithMessage(c, "修改成功", nil)
+}
+
+// ListCodes 查询批次内的兑换码
+// @Summary      查询批次内的兑换码
+// @Description  分页查询批次内的兑换码及已兑换次数，用于导出发放
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id         path      int   true   "批次ID"
+// @Param        page       query     int   false  "页码"
+// @Param        page_size  query     int   false  "每页数量"
+// @Param        used       query     bool  false  "是否已使用"
+// @Success      200  {object}  util.Response{data=util.PageResult{list=[]model.RedeemCode}}
+// @Failure      400  {object}  util.Response
+// @Failure      403  {object}  util.Response
+// @Router       /api/admin/redeem/batches/{id}/codes [get]
+func (h *RedeemHandler) ListCodes(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.Error(c, "参数错误: 无效的批次ID")
+		return
+	}
+
+	var req service.RedeemCodeQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+
+	resp, err := h.redeemService.ListCodes(uint(id), &req)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.Success(c, resp)
+}
+
+// ListRecords 查询兑换记录
+// @Summary      查询兑换记录
+// @Description  分页查询兑换记录，可按批次、用户、兑换码和时间筛选
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page        query     int     false  "页码"
+// @Param        page_size   query     int     false  "每页数量"
+// @Param        batch_id    query     int     false  "批次ID"
+// @Param        user_id     query     int     false  "用户ID"
+// @Param        code        query     string  false  "兑换码"
+// @Param        start_time  query     string  false  "开始时间（2006-01-02 15:04:05）"
+// @Param        end_time    query     string  false  "结束时间（2006-01-02 15:04:05）"
+// @Success      200  {object}  util.Response{data=util.PageResult{list=[]model.RedeemRecord}}
+// @Failure      400  {object}  util.Response
+// @Failure      403  {object}  util.Response
+// @Router       /api/admin/redeem/records [get]
+func (h *RedeemHandler) ListRecords(c *gin.Context) {
+	var req service.RedeemRecordQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+
+	resp, err := h.redeemService.ListRecords(&req)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.Success(c, resp)
+}
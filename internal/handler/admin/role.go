@@ -1,37 +1,42 @@
 package admin
 
 import (
-	"bgame/internal/model"
 	"bgame/internal/service"
 	"bgame/internal/util"
 	"github.com/gin-gonic/gin"
 )
 
-// CreateAdmin 创建管理员（需要超级管理员权限）
+// CreateAdmin 创建管理员（需要 admin:create 权限）
 // @Summary      创建管理员
-// @Description  创建新管理员账户，需要超级管理员权限
+// @Description  创建新管理员账户，需要 admin:create 权限；只有超级管理员可以创建超级管理员，分配的角色不能拥有操作者自己没有的权限
 // @Tags         管理员接口
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
 // @Param        request body service.CreateAdminRequest true "创建管理员请求"
-// @Success      200  {object}  util.Response
+// @Success      200  {object}  util.Response{data=model.Admin}
 // @Failure      400  {object}  util.Response
 // @Failure      403  {object}  util.Response
 // @Router       /api/admin/create [post]
 func (h *AdminHandler) CreateAdmin(c *gin.Context) {
+	operatorID, operatorRole, ok := currentOperator(c)
+	if !ok {
+		return
+	}
+
 	var req service.CreateAdminRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		util.Error(c, "参数错误: "+err.Error())
 		return
 	}
 
-	if err := h.adminService.CreateAdmin(&req); err != nil {
+	admin, err := h.adminService.CreateAdmin(operatorID, operatorRole, &req)
+	if err != nil {
 		util.Error(c, err.Error())
 		return
 	}
 
-	util.SuccessWithMessage(c, "创建管理员成功", nil)
+	util.SuccessWithMessage(c, "创建管理员成功", admin)
 }
 
 // GetAdminInfo 获取管理员信息
@@ -62,19 +67,44 @@ func (h *AdminHandler) GetAdminInfo(c *gin.Context) {
 
 // GetRoles 获取所有角色列表
 // @Summary      获取角色列表
-// @Description  获取所有可用的管理员角色列表
+// @Description  获取所有可用的管理员角色列表（内置角色和自定义角色）
 // @Tags         管理员接口
 // @Accept       json
 // @Produce      json
-// @Success      200  {object}  util.Response
+// @Success      200  {object}  util.Response{data=[]service.RoleOption}
 // @Router       /api/admin/roles [get]
 func (h *AdminHandler) GetRoles(c *gin.Context) {
-	roles := []map[string]interface{}{
-		{"value": int(model.RoleSuperAdmin), "label": model.RoleSuperAdmin.String()},
-		{"value": int(model.RoleAdmin), "label": model.RoleAdmin.String()},
-		{"value": int(model.RoleOperator), "label": model.RoleOperator.String()},
+	roles, err := h.roleService.ListOptions()
+	if err != nil {
+		util.Error(c, err.Error())
+		return
 	}
 
 	util.Success(c, roles)
 }
 
+// Setup 初始化第一个超级管理员
+// @Summary      初始化超级管理员
+// @Description  系统中没有任何管理员时，使用服务启动日志中输出的一次性初始化token创建第一个超级管理员；初始化完成后该接口不再可用
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Param        request body service.SetupAdminRequest true "初始化请求"
+// @Success      200  {object}  util.Response
+// @Failure      400  {object}  util.Response
+// @Router       /api/admin/setup [post]
+func (h *AdminHandler) Setup(c *gin.Context) {
+	var req service.SetupAdminRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+
+	if err := h.bootstrapService.Setup(&req, c.ClientIP()); err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.SuccessWithMessage(c, "初始化成功，请使用该账号登录", nil)
+}
+
@@ -0,0 +1,84 @@
+package admin
+
+import (
+	"bgame/internal/service"
+	"bgame/internal/util"
+	"github.com/gin-gonic/gin"
+)
+
+type SecurityHandler struct {
+	loginGuardService *service.LoginGuardService
+	adminService      *service.AdminService
+}
+
+func NewSecurityHandler() *SecurityHandler {
+	return &SecurityHandler{
+		loginGuardService: service.NewLoginGuardService(),
+		adminService:      service.NewAdminService(),
+	}
+}
+
+// UnlockUser 解除玩家登录锁定
+// @Summary      解除玩家登录锁定
+// @Description  清除玩家用户名或IP的登录失败记录和临时锁定
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body service.UnlockLoginRequest true "解除锁定请求"
+// @Success      200  {object}  util.Response
+// @Failure      400  {object}  util.Response
+// @Failure      403  {object}  util.Response
+// @Router       /api/admin/security/users/unlock [post]
+func (h *SecurityHandler) UnlockUser(c *gin.Context) {
+	adminID, exists := c.Get("admin_id")
+	if !exists {
+		util.Unauthorized(c, "未获取到管理员信息")
+		return
+	}
+
+	var req service.UnlockLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+
+	if err := h.loginGuardService.UnlockUser(adminID.(uint), &req); err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.SuccessWithMessage(c, "解除锁定成功", nil)
+}
+
+// UnlockAdmin 解除管理员登录锁定（需要 admin:manage 权限）
+// @Summary      解除管理员登录锁定
+// @Description  清除管理员用户名或IP的登录失败记录和临时锁定；指定用户名时管理员必须存在，只有超级管理员可以解锁超级管理员，不能解锁权限超出自己的管理员
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body service.UnlockLoginRequest true "解除锁定请求"
+// @Success      200  {object}  util.Response
+// @Failure      400  {object}  util.Response
+// @Failure      403  {object}  util.Response
+// @Router       /api/admin/security/admins/unlock [post]
+func (h *SecurityHandler) UnlockAdmin(c *gin.Context) {
+	operatorID, operatorRole, ok := currentOperator(c)
+	if !ok {
+		return
+	}
+
+	var req service.UnlockLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+
+	if err := h.adminService.UnlockAdmin(operatorID, operatorRole, &req); err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.SuccessWithMessage(c, "解除锁定成功", nil)
+}
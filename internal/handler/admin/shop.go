@@ -0,0 +1,187 @@
+package admin
+
+import (
+	"strconv"
+
+	"bgame/internal/service"
+	"bgame/internal/util"
+	"github.com/gin-gonic/gin"
+)
+
+type ShopHandler struct {
+	shopService *service.ShopService
+}
+
+func NewShopHandler() *ShopHandler {
+	return &ShopHandler{
+		shopService: service.NewShopService(),
+	}
+}
+
+// CreateSku 创建商品
+// @Summary      创建商品
+// @Description  创建商城商品，每份包含一组道具（道具必须存在且已启用）。余额价格和活动余额价格至少设置一个，折扣同时作用于两种价格；可设置总库存、每人限购、销售时间段和折扣时间段
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      service.CreateShopSkuRequest  true  "商品信息"
+// @Success      200  {object}  util.Response{data=model.ShopSku}
+// @Failure      400  {object}  util.Response
+// @Failure      403  {object}  util.Response
+// @Router       /api/admin/shop/skus [post]
+func (h *ShopHandler) CreateSku(c *gin.Context) {
+	adminID, exists := c.Get("admin_id")
+	if !exists {
+		util.Unauthorized(c, "未获取到管理员信息")
+		return
+	}
+
+	var req service.CreateShopSkuRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+
+	sku, err := h.shopService.CreateSku(adminID.(uint), &req)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.SuccessWithMessage(c, "创建成功", sku)
+}
+
+// ListSkus 查询商品
+// @Summary      查询商品
+// @Description  分页查询全部商品（含已下架和不在销售时间内的商品）
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page       query     int     false  "页码"
+// @Param        page_size  query     int     false  "每页数量"
+// @Param        keyword    query     string  false  "商品名称（模糊匹配）"
+// @Param        status     query     int     false  "状态：1上架 0下架"
+// @Success      200  {object}  util.Response{data=util.PageResult{list=[]model.ShopSku}}
+// @Failure      400  {object}  util.Response
+// @Failure      403  {object}  util.Response
+// @Router       /api/admin/shop/skus [get]
+func (h *ShopHandler) ListSkus(c *gin.Context) {
+	var req service.ShopSkuQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+
+	resp, err := h.shopService.ListSkus(&req)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.Success(c, resp)
+}
+
+// GetSku 获取商品详情
+// @Summary      获取商品详情
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "商品ID"
+// @Success      200  {object}  util.Response{data=model.ShopSku}
+// @Failure      400  {object}  util.Response
+// @Failure      403  {object}  util.Response
+// @Router       /api/admin/shop/skus/{id} [get]
+func (h *ShopHandler) GetSku(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.Error(c, "参数错误: 无效的商品ID")
+		return
+	}
+
+	sku, err := h.shopService.GetSku(uint(id))
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.Success(c, sku)
+}
+
+// UpdateSku 修改商品
+// @Summary      修改商品
+// @Description  只传需要修改的字段，{"status": 0} 下架。clear_discount、clear_sale_window 分别清空折扣时间段和销售时间段；库存不能小于已售数量。已完成的购买不受影响
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      int                           true  "商品ID"
+// @Param        request  body      service.UpdateShopSkuRequest  true  "修改内容"
+// @Success      200  {object}  util.Response{data=model.ShopSku}
+// @Failure      400  {object}  util.Response
+// @Failure      403  {object}  util.Response
+// @Router       /api/admin/shop/skus/{id} [put]
+func (h *ShopHandler) UpdateSku(c *gin.Context) {
+	adminID, exists := c.Get("admin_id")
+	if !exists {
+		util.Unauthorized(c, "未获取到管理员信息")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.Error(c, "参数错误: 无效的商品ID")
+		return
+	}
+
+	var req service.UpdateShopSkuRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+
+	sku, err := h.shopService.UpdateSku(adminID.(uint), uint(id), &req)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.SuccessWithMessage(c, "修改成功", sku)
+}
+
+// ListPurchases 查询购买记录
+// @Summary      查询购买记录
+// @Description  分页查询商城购买记录，可按用户、商品、收据号、币种和时间筛选
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page        query     int     false  "页码"
+// @Param        page_size   query     int     false  "每页数量"
+// @Param        user_id     query     int     false  "用户ID"
+// @Param        sku_id      query     int     false  "商品ID"
+// @Param        receipt_no  query     string  false  "收据号"
+// @Param        currency    query     string  false  "币种：balance, activity_balance"
+// @Param        start_time  query     string  false  "开始时间，格式 2006-01-02 15:04:05"
+// @Param        end_time    query     string  false  "结束时间，格式 2006-01-02 15:04:05"
+// @Success      200  {object}  util.Response{data=util.PageResult{list=[]model.ShopPurchase}}
+// @Failure      400  {object}  util.Response
+// @Failure      403  {object}  util.Response
+// @Router       /api/admin/shop/purchases [get]
+func (h *ShopHandler) ListPurchases(c *gin.Context) {
+	var req service.AdminShopPurchaseQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+
+	resp, err := h.shopService.ListPurchases(&req)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.Success(c, resp)
+}
@@ -0,0 +1,128 @@
+package admin
+
+import (
+	"strconv"
+
+	"bgame/internal/service"
+	"bgame/internal/util"
+	"github.com/gin-gonic/gin"
+)
+
+// RefreshToken 刷新管理员token
+// @Summary      刷新管理员token
+// @Description  使用refresh token换取新的access token，旧的refresh token随即失效；重放已使用的refresh token会吊销该设备的全部会话
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Param        request body service.RefreshTokenRequest true "刷新token请求"
+// @Success      200  {object}  util.Response{data=service.TokenPair}
+// @Failure      400  {object}  util.Response
+// @Router       /api/admin/token/refresh [post]
+func (h *AdminHandler) RefreshToken(c *gin.Context) {
+	var req service.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+
+	resp, err := h.tokenService.RefreshAdmin(req.RefreshToken)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.Success(c, resp)
+}
+
+// Logout 管理员退出登录
+// @Summary      管理员退出登录
+// @Description  吊销当前access token；传入refresh_token时同时吊销该设备的refresh token
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body service.LogoutRequest false "退出登录请求"
+// @Success      200  {object}  util.Response
+// @Failure      401  {object}  util.Response
+// @Router       /api/admin/logout [post]
+func (h *AdminHandler) Logout(c *gin.Context) {
+	claims, exists := c.Get("claims")
+	if !exists {
+		util.Unauthorized(c, "未获取到管理员信息")
+		return
+	}
+
+	var req service.LogoutRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			util.Error(c, "参数错误: "+err.Error())
+			return
+		}
+	}
+
+	if err := h.tokenService.Logout(claims.(*util.Claims), req.RefreshToken); err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.SuccessWithMessage(c, "退出登录成功", nil)
+}
+
+// RevokeUserSessions 吊销指定用户的全部会话
+// @Summary      吊销用户会话
+// @Description  使指定用户已签发的access token和refresh token全部失效，用于封禁等场景
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "用户ID"
+// @Success      200  {object}  util.Response
+// @Failure      400  {object}  util.Response
+// @Failure      403  {object}  util.Response
+// @Router       /api/admin/users/{id}/sessions/revoke [post]
+func (h *AdminHandler) RevokeUserSessions(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.Error(c, "参数错误: 无效的用户ID")
+		return
+	}
+
+	if err := h.tokenService.RevokeUserSessions(uint(userID)); err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.SuccessWithMessage(c, "已吊销用户全部会话", nil)
+}
+
+// RevokeAdminSessions 吊销指定管理员的全部会话（需要 admin:manage 权限）
+// @Summary      吊销管理员会话
+// @Description  使指定管理员已签发的access token和refresh token全部失效；只有超级管理员可以吊销超级管理员的会话，不能吊销权限超出自己的管理员的会话
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "管理员ID"
+// @Success      200  {object}  util.Response
+// @Failure      400  {object}  util.Response
+// @Failure      403  {object}  util.Response
+// @Router       /api/admin/admins/{id}/sessions/revoke [post]
+func (h *AdminHandler) RevokeAdminSessions(c *gin.Context) {
+	operatorID, operatorRole, ok := currentOperator(c)
+	if !ok {
+		return
+	}
+
+	adminID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.Error(c, "参数错误: 无效的管理员ID")
+		return
+	}
+
+	if err := h.adminService.RevokeAdminSessions(operatorID, operatorRole, uint(adminID)); err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.SuccessWithMessage(c, "已吊销管理员全部会话", nil)
+}
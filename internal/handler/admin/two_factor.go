@@ -0,0 +1,243 @@
+package admin
+
+import (
+	"strconv"
+
+	"bgame/internal/service"
+	"bgame/internal/util"
+	"github.com/gin-gonic/gin"
+)
+
+// LoginTwoFactor 管理员登录两步验证
+// @Summary      管理员登录两步验证
+// @Description  使用登录返回的challenge_token和动态验证码（或恢复码）完成登录；首次绑定时同时返回恢复码
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Param        request body service.TwoFactorLoginRequest true "两步验证请求"
+// @Success      200  {object}  util.Response{data=service.AdminLoginResponse}
+// @Failure      400  {object}  util.Response
+// @Router       /api/admin/login/2fa [post]
+func (h *AdminHandler) LoginTwoFactor(c *gin.Context) {
+	var req service.TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+
+	resp, err := h.adminService.LoginTwoFactor(&req)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.Success(c, resp)
+}
+
+// LoginTwoFactorSetup 登录时绑定认证器
+// @Summary      登录时绑定认证器
+// @Description  强制启用两步验证但尚未绑定时，使用challenge_token生成TOTP密钥，再调用 /api/admin/login/2fa 提交验证码完成绑定和登录
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Param        request body service.TwoFactorChallengeRequest true "登录挑战"
+// @Success      200  {object}  util.Response{data=service.TwoFactorSetupResponse}
+// @Failure      400  {object}  util.Response
+// @Router       /api/admin/login/2fa/setup [post]
+func (h *AdminHandler) LoginTwoFactorSetup(c *gin.Context) {
+	var req service.TwoFactorChallengeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+
+	resp, err := h.twoFactorService.SetupChallenge(req.ChallengeToken)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.Success(c, resp)
+}
+
+// GetTwoFactorStatus 获取两步验证状态
+// @Summary      获取两步验证状态
+// @Description  获取当前管理员的两步验证启用状态和剩余恢复码数量
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  util.Response{data=service.TwoFactorStatusResponse}
+// @Failure      401  {object}  util.Response
+// @Router       /api/admin/2fa [get]
+func (h *AdminHandler) GetTwoFactorStatus(c *gin.Context) {
+	adminID, exists := c.Get("admin_id")
+	if !exists {
+		util.Unauthorized(c, "未获取到管理员信息")
+		return
+	}
+
+	resp, err := h.twoFactorService.Status(adminID.(uint))
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.Success(c, resp)
+}
+
+// SetupTwoFactor 生成两步验证密钥
+// @Summary      生成两步验证密钥
+// @Description  生成新的TOTP密钥和otpauth URI，需调用 /api/admin/2fa/enable 验证后才会启用
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  util.Response{data=service.TwoFactorSetupResponse}
+// @Failure      400  {object}  util.Response
+// @Router       /api/admin/2fa/setup [post]
+func (h *AdminHandler) SetupTwoFactor(c *gin.Context) {
+	adminID, exists := c.Get("admin_id")
+	if !exists {
+		util.Unauthorized(c, "未获取到管理员信息")
+		return
+	}
+
+	resp, err := h.twoFactorService.Setup(adminID.(uint))
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.Success(c, resp)
+}
+
+// EnableTwoFactor 启用两步验证
+// @Summary      启用两步验证
+// @Description  提交认证器中的动态验证码启用两步验证，返回一次性恢复码（仅展示一次）
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body service.TwoFactorCodeRequest true "动态验证码"
+// @Success      200  {object}  util.Response{data=service.RecoveryCodesResponse}
+// @Failure      400  {object}  util.Response
+// @Router       /api/admin/2fa/enable [post]
+func (h *AdminHandler) EnableTwoFactor(c *gin.Context) {
+	adminID, exists := c.Get("admin_id")
+	if !exists {
+		util.Unauthorized(c, "未获取到管理员信息")
+		return
+	}
+
+	var req service.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+
+	resp, err := h.twoFactorService.Enable(adminID.(uint), req.Code)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.Success(c, resp)
+}
+
+// DisableTwoFactor 关闭两步验证
+// @Summary      关闭两步验证
+// @Description  验证密码和动态验证码（或恢复码）后关闭两步验证；强制启用的角色不能关闭
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body service.TwoFactorDisableRequest true "关闭请求"
+// @Success      200  {object}  util.Response
+// @Failure      400  {object}  util.Response
+// @Router       /api/admin/2fa/disable [post]
+func (h *AdminHandler) DisableTwoFactor(c *gin.Context) {
+	adminID, exists := c.Get("admin_id")
+	if !exists {
+		util.Unauthorized(c, "未获取到管理员信息")
+		return
+	}
+
+	var req service.TwoFactorDisableRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+
+	if err := h.twoFactorService.Disable(adminID.(uint), &req); err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.SuccessWithMessage(c, "两步验证已关闭", nil)
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码
+// @Summary      重新生成恢复码
+// @Description  提交动态验证码后重新生成恢复码，旧恢复码全部作废
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body service.TwoFactorCodeRequest true "动态验证码"
+// @Success      200  {object}  util.Response{data=service.RecoveryCodesResponse}
+// @Failure      400  {object}  util.Response
+// @Router       /api/admin/2fa/recovery-codes [post]
+func (h *AdminHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	adminID, exists := c.Get("admin_id")
+	if !exists {
+		util.Unauthorized(c, "未获取到管理员信息")
+		return
+	}
+
+	var req service.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+
+	resp, err := h.twoFactorService.RegenerateRecoveryCodes(adminID.(uint), req.Code)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.Success(c, resp)
+}
+
+// ResetTwoFactor 重置管理员两步验证（需要 admin:manage 权限）
+// @Summary      重置管理员两步验证
+// @Description  管理员丢失认证器时重置，删除其密钥和恢复码，并吊销该管理员的全部会话；只有超级管理员可以重置超级管理员，不能重置权限超出自己的管理员
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "管理员ID"
+// @Success      200  {object}  util.Response
+// @Failure      400  {object}  util.Response
+// @Failure      403  {object}  util.Response
+// @Router       /api/admin/admins/{id}/2fa/reset [post]
+func (h *AdminHandler) ResetTwoFactor(c *gin.Context) {
+	operatorID, operatorRole, ok := currentOperator(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.Error(c, "参数错误: 无效的管理员ID")
+		return
+	}
+
+	if err := h.adminService.ResetTwoFactor(operatorID, operatorRole, uint(id)); err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.SuccessWithMessage(c, "两步验证已重置", nil)
+}
@@ -0,0 +1,197 @@
+package admin
+
+import (
+	"strconv"
+
+	"bgame/internal/service"
+	"bgame/internal/util"
+	"github.com/gin-gonic/gin"
+)
+
+type UserHandler struct {
+	userService *service.UserService
+}
+
+func NewUserHandler() *UserHandler {
+	return &UserHandler{
+		userService: service.NewUserService(),
+	}
+}
+
+// SearchUsers 查询用户列表
+// @Summary      查询用户列表
+// @Description  分页查询玩家，支持按ID、用户名、邮箱、昵称、状态和注册时间过滤
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page        query     int     false  "页码"
+// @Param        page_size   query     int     false  "每页数量"
+// @Param        id          query     int     false  "用户ID"
+// @Param        username    query     string  false  "用户名（模糊匹配）"
+// @Param        email       query     string  false  "邮箱（模糊匹配）"
+// @Param        nickname    query     string  false  "昵称（模糊匹配）"
+// @Param        status      query     int     false  "状态：1正常 2禁用"
+// @Param        start_time  query     string  false  "注册时间起（2006-01-02 15:04:05）"
+// @Param        end_time    query     string  false  "注册时间止（2006-01-02 15:04:05）"
+// @Success      200  {object}  util.Response{data=util.PageResult{list=[]model.User}}
+// @Failure      400  {object}  util.Response
+// @Failure      403  {object}  util.Response
+// @Router       /api/admin/users [get]
+func (h *UserHandler) SearchUsers(c *gin.Context) {
+	var req service.AdminUserQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+
+	resp, err := h.userService.SearchUsers(&req)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.Success(c, resp)
+}
+
+// GetUser 获取用户详情
+// @Summary      获取用户详情
+// @Description  获取玩家信息及其用户资料（余额、等级等）
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "用户ID"
+// @Success      200  {object}  util.Response{data=service.UserDetailResponse}
+// @Failure      400  {object}  util.Response
+// @Failure      403  {object}  util.Response
+// @Router       /api/admin/users/{id} [get]
+func (h *UserHandler) GetUser(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.Error(c, "参数错误: 无效的用户ID")
+		return
+	}
+
+	resp, err := h.userService.GetUserDetail(uint(userID))
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.Success(c, resp)
+}
+
+// UpdateUser 修改用户资料
+// @Summary      修改用户资料
+// @Description  修改玩家昵称或邮箱
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      int                        true  "用户ID"
+// @Param        request  body      service.UpdateUserRequest  true  "修改请求"
+// @Success      200  {object}  util.Response{data=model.User}
+// @Failure      400  {object}  util.Response
+// @Failure      403  {object}  util.Response
+// @Router       /api/admin/users/{id} [put]
+func (h *UserHandler) UpdateUser(c *gin.Context) {
+	adminID, exists := c.Get("admin_id")
+	if !exists {
+		util.Unauthorized(c, "未获取到管理员信息")
+		return
+	}
+
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.Error(c, "参数错误: 无效的用户ID")
+		return
+	}
+
+	var req service.UpdateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+
+	user, err := h.userService.UpdateUser(adminID.(uint), uint(userID), &req)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.SuccessWithMessage(c, "修改成功", user)
+}
+
+// BanUser 封禁用户
+// @Summary      封禁用户
+// @Description  封禁玩家并吊销其全部会话；可指定到期时间，到期后玩家再次登录时自动解封
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      int                     true  "用户ID"
+// @Param        request  body      service.BanUserRequest  true  "封禁请求"
+// @Success      200  {object}  util.Response
+// @Failure      400  {object}  util.Response
+// @Failure      403  {object}  util.Response
+// @Router       /api/admin/users/{id}/ban [post]
+func (h *UserHandler) BanUser(c *gin.Context) {
+	adminID, exists := c.Get("admin_id")
+	if !exists {
+		util.Unauthorized(c, "未获取到管理员信息")
+		return
+	}
+
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.Error(c, "参数错误: 无效的用户ID")
+		return
+	}
+
+	var req service.BanUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+
+	if err := h.userService.BanUser(adminID.(uint), uint(userID), &req); err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.SuccessWithMessage(c, "封禁成功", nil)
+}
+
+// UnbanUser 解除用户封禁
+// @Summary      解除用户封禁
+// @Description  解除玩家封禁，恢复正常状态
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "用户ID"
+// @Success      200  {object}  util.Response
+// @Failure      400  {object}  util.Response
+// @Failure      403  {object}  util.Response
+// @Router       /api/admin/users/{id}/unban [post]
+func (h *UserHandler) UnbanUser(c *gin.Context) {
+	adminID, exists := c.Get("admin_id")
+	if !exists {
+		util.Unauthorized(c, "未获取到管理员信息")
+		return
+	}
+
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.Error(c, "参数错误: 无效的用户ID")
+		return
+	}
+
+	if err := h.userService.UnbanUser(adminID.(uint), uint(userID)); err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.SuccessWithMessage(c, "解除封禁成功", nil)
+}
@@ -0,0 +1,86 @@
+package admin
+
+import (
+	"bgame/internal/service"
+	"bgame/internal/util"
+	"github.com/gin-gonic/gin"
+)
+
+type WalletHandler struct {
+	walletService *service.WalletService
+}
+
+func NewWalletHandler() *WalletHandler {
+	return &WalletHandler{
+		walletService: service.NewWalletService(),
+	}
+}
+
+// ListTransactions 查询钱包流水
+// @Summary      查询钱包流水
+// @Description  分页查询全部用户的钱包流水，支持按用户、币种、原因码、关联业务ID和时间范围过滤
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page          query     int     false  "页码"
+// @Param        page_size     query     int     false  "每页数量"
+// @Param        user_id       query     int     false  "用户ID"
+// @Param        currency      query     string  false  "币种：balance, activity_balance"
+// @Param        direction     query     string  false  "方向：credit, debit"
+// @Param        reason_code   query     string  false  "原因码"
+// @Param        reference_id  query     string  false  "关联业务ID"
+// @Param        start_time    query     string  false  "开始时间（2006-01-02 15:04:05）"
+// @Param        end_time      query     string  false  "结束时间（2006-01-02 15:04:05）"
+// @Success      200  {object}  util.Response{data=util.PageResult{list=[]model.WalletLedgerEntry}}
+// @Failure      400  {object}  util.Response
+// @Router       /api/admin/wallet/transactions [get]
+func (h *WalletHandler) ListTransactions(c *gin.Context) {
+	var req service.AdminWalletTransactionQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+
+	resp, err := h.walletService.ListTransactions(&req)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.Success(c, resp)
+}
+
+// Adjust 调整用户余额
+// @Summary      调整用户余额
+// @Description  管理员为用户入账或扣款，写入流水并记录操作人
+// @Tags         管理员接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body service.AdjustWalletRequest true "调整余额请求"
+// @Success      200  {object}  util.Response{data=model.WalletLedgerEntry}
+// @Failure      400  {object}  util.Response
+// @Failure      403  {object}  util.Response
+// @Router       /api/admin/wallet/adjust [post]
+func (h *WalletHandler) Adjust(c *gin.Context) {
+	adminID, exists := c.Get("admin_id")
+	if !exists {
+		util.Unauthorized(c, "未获取到管理员信息")
+		return
+	}
+
+	var req service.AdjustWalletRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+
+	entry, err := h.walletService.Adjust(adminID.(uint), &req)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.SuccessWithMessage(c, "调整余额成功", entry)
+}
@@ -0,0 +1,73 @@
+package payment
+
+import (
+	"io"
+
+	"bgame/internal/service"
+	"bgame/internal/util"
+	"github.com/gin-gonic/gin"
+)
+
+// callbackBodyMaxSize 回调报文上限
+const callbackBodyMaxSize = 64 << 10
+
+type PaymentHandler struct {
+	paymentService *service.PaymentService
+}
+
+func NewPaymentHandler() *PaymentHandler {
+	return &PaymentHandler{
+		paymentService: service.NewPaymentService(),
+	}
+}
+
+// Callback 支付渠道回调
+// @Summary      支付渠道回调
+// @Description  由支付渠道调用，按渠道规则校验签名（内置渠道使用 X-Payment-Timestamp、X-Payment-Signature 请求头，签名为 hex(HMAC-SHA256(secret, timestamp + "." + body))），在一个事务内变更订单状态并入账。同一订单只会入账一次，重复通知直接返回成功；验签失败返回错误
+// @Tags         支付接口
+// @Accept       json
+// @Produce      json
+// @Param        provider  path      string  true  "支付渠道"
+// @Success      200  {object}  util.Response
+// @Failure      400  {object}  util.Response
+// @Router       /api/payment/callback/{provider} [post]
+func (h *PaymentHandler) Callback(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, callbackBodyMaxSize))
+	if err != nil {
+		util.Error(c, "参数错误: 读取回调内容失败")
+		return
+	}
+
+	if err := h.paymentService.HandleCallback(c.Param("provider"), c.Request.Header, body, c.ClientIP()); err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.Success(c, nil)
+}
+
+// MockPay 模拟支付
+// @Summary      模拟支付
+// @Description  仅在启用 mock 渠道时可用：为 mock 渠道的订单生成签名回调，并按真实回调流程验签、变更订单状态和入账，用于本地联调和测试
+// @Tags         支付接口
+// @Accept       json
+// @Produce      json
+// @Param        request  body      service.MockPayRequest  true  "模拟支付请求"
+// @Success      200  {object}  util.Response{data=model.PaymentOrder}
+// @Failure      400  {object}  util.Response
+// @Router       /api/payment/mock/pay [post]
+func (h *PaymentHandler) MockPay(c *gin.Context) {
+	var req service.MockPayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+
+	order, err := h.paymentService.MockPay(&req, c.ClientIP())
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.Success(c, order)
+}
@@ -0,0 +1,112 @@
+package server
+
+import (
+	"strconv"
+
+	"bgame/internal/service"
+	"bgame/internal/util"
+	"github.com/gin-gonic/gin"
+)
+
+type InventoryHandler struct {
+	inventoryService *service.InventoryService
+}
+
+func NewInventoryHandler() *InventoryHandler {
+	return &InventoryHandler{
+		inventoryService: service.NewInventoryService(),
+	}
+}
+
+// GrantItem 发放道具
+// @Summary      发放道具
+// @Description  游戏服等内部服务为玩家发放道具（item_id 和 item_code 二选一），校验道具状态和持有上限；传入 reference_id 时同一用户、道具、原因码下只发放一次，重复调用返回 duplicate=true
+// @Tags         服务端接口
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        request  body      service.ServerItemChangeRequest  true  "发放请求"
+// @Success      200  {object}  util.Response{data=service.ItemChangeResponse}
+// @Failure      400  {object}  util.Response
+// @Failure      401  {object}  util.Response
+// @Router       /api/server/items/grant [post]
+func (h *InventoryHandler) GrantItem(c *gin.Context) {
+	var req service.ServerItemChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+
+	resp, err := h.inventoryService.ServerGrant(c.GetString("api_client"), &req)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.Success(c, resp)
+}
+
+// ConsumeItem 消耗道具
+// @Summary      消耗道具
+// @Description  游戏服等内部服务消耗玩家道具（item_id 和 item_code 二选一），数量不足时整体失败，优先消耗最早过期的道具；reference_id 规则同发放
+// @Tags         服务端接口
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        request  body      service.ServerItemChangeRequest  true  "消耗请求"
+// @Success      200  {object}  util.Response{data=service.ItemChangeResponse}
+// @Failure      400  {object}  util.Response
+// @Failure      401  {object}  util.Response
+// @Router       /api/server/items/consume [post]
+func (h *InventoryHandler) ConsumeItem(c *gin.Context) {
+	var req service.ServerItemChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+
+	resp, err := h.inventoryService.ServerConsume(c.GetString("api_client"), &req)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.Success(c, resp)
+}
+
+// GetInventory 获取玩家背包
+// @Summary      获取玩家背包
+// @Description  分页查询指定玩家背包中未过期的道具
+// @Tags         服务端接口
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id         path      int     true   "用户ID"
+// @Param        page       query     int     false  "页码"
+// @Param        page_size  query     int     false  "每页数量"
+// @Param        category   query     string  false  "道具分类"
+// @Success      200  {object}  util.Response{data=util.PageResult{list=[]model.UserItem}}
+// @Failure      400  {object}  util.Response
+// @Failure      401  {object}  util.Response
+// @Router       /api/server/users/{id}/inventory [get]
+func (h *InventoryHandler) GetInventory(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.Error(c, "参数错误: 无效的用户ID")
+		return
+	}
+
+	var req service.InventoryQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+
+	resp, err := h.inventoryService.ListUserItems(uint(userID), &req)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.Success(c, resp)
+}
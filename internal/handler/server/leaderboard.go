@@ -0,0 +1,46 @@
+package server
+
+import (
+	"bgame/internal/service"
+	"bgame/internal/util"
+	"github.com/gin-gonic/gin"
+)
+
+type LeaderboardHandler struct {
+	leaderboardService *service.LeaderboardService
+}
+
+func NewLeaderboardHandler() *LeaderboardHandler {
+	return &LeaderboardHandler{
+		leaderboardService: service.NewLeaderboardService(),
+	}
+}
+
+// SubmitScore 提交排行榜分数
+// @Summary      提交排行榜分数
+// @Description  按榜单的计分方式（best/sum/latest）写入当前周期，返回玩家当前名次；由等级系统驱动的榜单不能提交
+// @Tags         服务端接口
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        name     path      string                      true  "榜单标识"
+// @Param        request  body      service.SubmitScoreRequest  true  "提交请求"
+// @Success      200  {object}  util.Response{data=service.LeaderboardEntryResponse}
+// @Failure      400  {object}  util.Response
+// @Failure      401  {object}  util.Response
+// @Router       /api/server/leaderboard/{name}/scores [post]
+func (h *LeaderboardHandler) SubmitScore(c *gin.Context) {
+	var req service.SubmitScoreRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+
+	resp, err := h.leaderboardService.Submit(c.Param("name"), &req)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.Success(c, resp)
+}
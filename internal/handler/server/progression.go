@@ -0,0 +1,75 @@
+package server
+
+import (
+	"strconv"
+
+	"bgame/internal/service"
+	"bgame/internal/util"
+	"github.com/gin-gonic/gin"
+)
+
+type ProgressionHandler struct {
+	progressionService *service.ProgressionService
+}
+
+func NewProgressionHandler() *ProgressionHandler {
+	return &ProgressionHandler{
+		progressionService: service.NewProgressionService(),
+	}
+}
+
+// GrantExperience 发放经验
+// @Summary      发放经验
+// @Description  游戏服等内部服务为玩家发放经验；传入 reference_id 时同一用户、原因码下只发放一次，重复调用返回 duplicate=true
+// @Tags         服务端接口
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        request  body      service.GrantExperienceRequest  true  "发放请求"
+// @Success      200  {object}  util.Response{data=service.GrantExperienceResponse}
+// @Failure      400  {object}  util.Response
+// @Failure      401  {object}  util.Response
+// @Router       /api/server/experience/grant [post]
+func (h *ProgressionHandler) GrantExperience(c *gin.Context) {
+	var req service.GrantExperienceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+
+	resp, err := h.progressionService.ServerGrant(c.GetString("api_client"), &req)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.Success(c, resp)
+}
+
+// GetLevel 获取玩家等级进度
+// @Summary      获取玩家等级进度
+// @Description  获取指定玩家的等级和累计经验
+// @Tags         服务端接口
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id   path      int  true  "用户ID"
+// @Success      200  {object}  util.Response{data=service.LevelProgressResponse}
+// @Failure      400  {object}  util.Response
+// @Failure      401  {object}  util.Response
+// @Router       /api/server/users/{id}/level [get]
+func (h *ProgressionHandler) GetLevel(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.Error(c, "参数错误: 无效的用户ID")
+		return
+	}
+
+	resp, err := h.progressionService.GetProgress(uint(userID))
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+
+	util.Success(c, resp)
+}
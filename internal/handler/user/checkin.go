@@ -0,0 +1,127 @@
+package user
+
+import (
+	"bgame/internal/service"
+	"bgame/internal/util"
+	"github.com/gin-gonic/gin"
+)
+
+type CheckinHandler struct {
+	checkinService *service.CheckinService
+}
+
+func NewCheckinHandler() *CheckinHandler {
+	return &CheckinHandler{
+		checkinService: service.NewCheckinService(),
+	}
+}
+
+// GetStatus 获取签到状态
+// @Summary      获取签到状态
+// @Description  获取今天是否已签到、连续签到天数、奖励日历以及可补签的日期
+// @Tags         用户接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  util.Response{data=service.CheckinStatusResponse}
+// @Failure      400  {object}  util.Response
+// @Router       /api/user/checkin [get]
+func (h *CheckinHandler) GetStatus(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		util.Unauthorized(c, "未获取到用户信息")
+		return
+	}
+	resp, err := h.checkinService.Status(userID.(uint))
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+	util.Success(c, resp)
+}
+
+// Checkin 每日签到
+// @Summary      每日签到
+// @Description  签到今天（按配置时区划分自然日），每天一次，按连续签到天数发放奖励日历中的经验和活动余额
+// @Tags         用户接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  util.Response{data=service.CheckinResponse}
+// @Failure      400  {object}  util.Response
+// @Router       /api/user/checkin [post]
+func (h *CheckinHandler) Checkin(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		util.Unauthorized(c, "未获取到用户信息")
+		return
+	}
+	resp, err := h.checkinService.Checkin(userID.(uint))
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+	util.SuccessWithMessage(c, "签到成功", resp)
+}
+
+// Makeup 补签
+// @Summary      补签
+// @Description  补签最近若干天内漏签的日期，扣除配置的补签费用（余额），补签后之后连续的签到天数顺延
+// @Tags         用户接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      service.MakeupCheckinRequest  true  "补签请求"
+// @Success      200  {object}  util.Response{data=service.CheckinResponse}
+// @Failure      400  {object}  util.Response
+// @Router       /api/user/checkin/makeup [post]
+func (h *CheckinHandler) Makeup(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		util.Unauthorized(c, "未获取到用户信息")
+		return
+	}
+	var req service.MakeupCheckinRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+	resp, err := h.checkinService.Makeup(userID.(uint), &req)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+	util.SuccessWithMessage(c, "补签成功", resp)
+}
+
+// ListHistory 查询签到记录
+// @Summary      查询签到记录
+// @Description  分页查询当前用户的签到记录，按日期倒序
+// @Tags         用户接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page       query     int     false  "页码"
+// @Param        page_size  query     int     false  "每页数量"
+// @Param        month      query     string  false  "月份 YYYY-MM"
+// @Success      200  {object}  util.Response{data=util.PageResult{list=[]model.UserCheckin}}
+// @Failure      400  {object}  util.Response
+// @Router       /api/user/checkin/history [get]
+func (h *CheckinHandler) ListHistory(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		util.Unauthorized(c, "未获取到用户信息")
+		return
+	}
+	var req service.CheckinHistoryQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+	resp, err := h.checkinService.History(userID.(uint), &req)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+	util.Success(c, resp)
+}
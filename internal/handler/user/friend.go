@@ -0,0 +1,320 @@
+package user
+
+import (
+	"strconv"
+
+	"bgame/internal/model"
+	"bgame/internal/service"
+	"bgame/internal/util"
+	"github.com/gin-gonic/gin"
+)
+
+type FriendHandler struct {
+	friendService *service.FriendService
+}
+
+func NewFriendHandler() *FriendHandler {
+	return &FriendHandler{
+		friendService: service.NewFriendService(),
+	}
+}
+
+// ListFriends 查询好友列表
+// @Summary      查询好友列表
+// @Description  分页查询好友，按成为好友的时间倒序，返回昵称和在线状态（根据 Redis 中的心跳判断）
+// @Tags         用户接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page       query     int  false  "页码"
+// @Param        page_size  query     int  false  "每页数量"
+// @Success      200  {object}  util.Response{data=util.PageResult{list=[]service.FriendResponse}}
+// @Failure      400  {object}  util.Response
+// @Router       /api/user/friends [get]
+func (h *FriendHandler) ListFriends(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		util.Unauthorized(c, "未获取到用户信息")
+		return
+	}
+	var req util.PageQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+	resp, err := h.friendService.ListFriends(userID.(uint), &req)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+	util.Success(c, resp)
+}
+
+// RemoveFriend 删除好友
+// @Summary      删除好友
+// @Description  双方的好友关系同时解除
+// @Tags         用户接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        user_id  path      int  true  "好友的用户ID"
+// @Success      200  {object}  util.Response
+// @Failure      400  {object}  util.Response
+// @Router       /api/user/friends/{user_id} [delete]
+func (h *FriendHandler) RemoveFriend(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		util.Unauthorized(c, "未获取到用户信息")
+		return
+	}
+	friendID, err := strconv.ParseUint(c.Param("user_id"), 10, 64)
+	if err != nil {
+		util.Error(c, "参数错误: 无效的用户ID")
+		return
+	}
+	if err := h.friendService.RemoveFriend(userID.(uint), uint(friendID)); err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+	util.SuccessWithMessage(c, "删除成功", nil)
+}
+
+// SendRequest 发送好友申请
+// @Summary      发送好友申请
+// @Description  向其他玩家发送好友申请。屏蔽了对方或被对方屏蔽时不能发送；对方已向自己发出待处理的申请时直接成为好友（accepted 为 true）。发出的待处理申请数量和双方好友数量受配置限制
+// @Tags         用户接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      service.SendFriendRequestRequest  true  "申请内容"
+// @Success      200  {object}  util.Response{data=service.SendFriendRequestResponse}
+// @Failure      400  {object}  util.Response
+// @Router       /api/user/friends/requests [post]
+func (h *FriendHandler) SendRequest(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		util.Unauthorized(c, "未获取到用户信息")
+		return
+	}
+	var req service.SendFriendRequestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+	resp, err := h.friendService.SendRequest(userID.(uint), &req)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+	if resp.Accepted {
+		util.SuccessWithMessage(c, "已成为好友", resp)
+		return
+	}
+	util.SuccessWithMessage(c, "申请已发送", resp)
+}
+
+// ListRequests 查询好友申请
+// @Summary      查询好友申请
+// @Description  分页查询待处理的好友申请，nickname 为对方昵称
+// @Tags         用户接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        direction  query     string  false  "incoming 收到的（默认），outgoing 发出的"
+// @Param        page       query     int     false  "页码"
+// @Param        page_size  query     int     false  "每页数量"
+// @Success      200  {object}  util.Response{data=util.PageResult{list=[]service.FriendRequestResponse}}
+// @Failure      400  {object}  util.Response
+// @Router       /api/user/friends/requests [get]
+func (h *FriendHandler) ListRequests(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		util.Unauthorized(c, "未获取到用户信息")
+		return
+	}
+	var req service.FriendRequestQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+	resp, err := h.friendService.ListRequests(userID.(uint), &req)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+	util.Success(c, resp)
+}
+
+// AcceptRequest 同意好友申请
+// @Summary      同意好友申请
+// @Description  只能处理发给自己的待处理申请，双方好友数量都未达上限时成为好友
+// @Tags         用户接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "申请ID"
+// @Success      200  {object}  util.Response{data=model.FriendRequest}
+// @Failure      400  {object}  util.Response
+// @Router       /api/user/friends/requests/{id}/accept [post]
+func (h *FriendHandler) AcceptRequest(c *gin.Context) {
+	h.handleRequest(c, h.friendService.AcceptRequest, "已成为好友")
+}
+
+// DeclineRequest 拒绝好友申请
+// @Summary      拒绝好友申请
+// @Tags         用户接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "申请ID"
+// @Success      200  {object}  util.Response{data=model.FriendRequest}
+// @Failure      400  {object}  util.Response
+// @Router       /api/user/friends/requests/{id}/decline [post]
+func (h *FriendHandler) DeclineRequest(c *gin.Context) {
+	h.handleRequest(c, h.friendService.DeclineRequest, "已拒绝")
+}
+
+// CancelRequest 撤回好友申请
+// @Summary      撤回好友申请
+// @Tags         用户接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "申请ID"
+// @Success      200  {object}  util.Response{data=model.FriendRequest}
+// @Failure      400  {object}  util.Response
+// @Router       /api/user/friends/requests/{id}/cancel [post]
+func (h *FriendHandler) CancelRequest(c *gin.Context) {
+	h.handleRequest(c, h.friendService.CancelRequest, "已撤回")
+}
+
+// ListBlocks 查询屏蔽列表
+// @Summary      查询屏蔽列表
+// @Tags         用户接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page       query     int  false  "页码"
+// @Param        page_size  query     int  false  "每页数量"
+// @Success      200  {object}  util.Response{data=util.PageResult{list=[]service.BlockResponse}}
+// @Failure      400  {object}  util.Response
+// @Router       /api/user/friends/blocks [get]
+func (h *FriendHandler) ListBlocks(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		util.Unauthorized(c, "未获取到用户信息")
+		return
+	}
+	var req util.PageQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+	resp, err := h.friendService.ListBlocks(userID.(uint), &req)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+	util.Success(c, resp)
+}
+
+// Block 屏蔽玩家
+// @Summary      屏蔽玩家
+// @Description  屏蔽后解除双方的好友关系并关闭双方之间待处理的申请，对方不能再向自己发送好友申请和消息；解除屏蔽不会恢复好友关系
+// @Tags         用户接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      service.BlockUserRequest  true  "屏蔽的玩家"
+// @Success      200  {object}  util.Response
+// @Failure      400  {object}  util.Response
+// @Router       /api/user/friends/blocks [post]
+func (h *FriendHandler) Block(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		util.Unauthorized(c, "未获取到用户信息")
+		return
+	}
+	var req service.BlockUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+	if err := h.friendService.Block(userID.(uint), &req); err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+	util.SuccessWithMessage(c, "屏蔽成功", nil)
+}
+
+// Unblock 解除屏蔽
+// @Summary      解除屏蔽
+// @Tags         用户接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        user_id  path      int  true  "被屏蔽的用户ID"
+// @Success      200  {object}  util.Response
+// @Failure      400  {object}  util.Response
+// @Router       /api/user/friends/blocks/{user_id} [delete]
+func (h *FriendHandler) Unblock(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		util.Unauthorized(c, "未获取到用户信息")
+		return
+	}
+	targetID, err := strconv.ParseUint(c.Param("user_id"), 10, 64)
+	if err != nil {
+		util.Error(c, "参数错误: 无效的用户ID")
+		return
+	}
+	if err := h.friendService.Unblock(userID.(uint), uint(targetID)); err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+	util.SuccessWithMessage(c, "已解除屏蔽", nil)
+}
+
+// Heartbeat 在线心跳
+// @Summary      在线心跳
+// @Description  客户端在前台时按返回的 interval（秒）定期调用，超过 friend.heartbeat_ttl 未心跳的玩家在好友列表中显示为离线
+// @Tags         用户接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  util.Response{data=service.HeartbeatResponse}
+// @Failure      400  {object}  util.Response
+// @Router       /api/user/friends/heartbeat [post]
+func (h *FriendHandler) Heartbeat(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		util.Unauthorized(c, "未获取到用户信息")
+		return
+	}
+	resp, err := h.friendService.Heartbeat(userID.(uint))
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+	util.Success(c, resp)
+}
+
+// handleRequest 处理好友申请（同意、拒绝、撤回）的公共流程
+func (h *FriendHandler) handleRequest(c *gin.Context, fn func(userID, requestID uint) (*model.FriendRequest, error), message string) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		util.Unauthorized(c, "未获取到用户信息")
+		return
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.Error(c, "参数错误: 无效的申请ID")
+		return
+	}
+	request, err := fn(userID.(uint), uint(id))
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+	util.SuccessWithMessage(c, message, request)
+}
@@ -0,0 +1,82 @@
+package user
+
+import (
+	"bgame/internal/service"
+	"bgame/internal/util"
+	"github.com/gin-gonic/gin"
+)
+
+type InventoryHandler struct {
+	inventoryService *service.InventoryService
+}
+
+func NewInventoryHandler() *InventoryHandler {
+	return &InventoryHandler{
+		inventoryService: service.NewInventoryService(),
+	}
+}
+
+// GetInventory 获取背包
+// @Summary      获取背包
+// @Description  分页查询当前用户背包中未过期的道具（附带道具信息），可堆叠道具按过期时间分组
+// @Tags         用户接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page       query     int     false  "页码"
+// @Param        page_size  query     int     false  "每页数量"
+// @Param        category   query     string  false  "道具分类：consumable, equipment, material, cosmetic, gift"
+// @Success      200  {object}  util.Response{data=util.PageResult{list=[]model.UserItem}}
+// @Failure      400  {object}  util.Response
+// @Router       /api/user/inventory [get]
+func (h *InventoryHandler) GetInventory(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		util.Unauthorized(c, "未获取到用户信息")
+		return
+	}
+	var req service.InventoryQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+	resp, err := h.inventoryService.ListUserItems(userID.(uint), &req)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+	util.Success(c, resp)
+}
+
+// ListItemLogs 查询道具流水
+// @Summary      查询道具流水
+// @Description  分页查询当前用户的道具获得和消耗记录，按时间倒序
+// @Tags         用户接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page         query     int     false  "页码"
+// @Param        page_size    query     int     false  "每页数量"
+// @Param        item_id      query     int     false  "道具ID"
+// @Param        reason_code  query     string  false  "原因码"
+// @Success      200  {object}  util.Response{data=util.PageResult{list=[]model.ItemLog}}
+// @Failure      400  {object}  util.Response
+// @Router       /api/user/inventory/logs [get]
+func (h *InventoryHandler) ListItemLogs(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		util.Unauthorized(c, "未获取到用户信息")
+		return
+	}
+	var req service.ItemLogQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+	resp, err := h.inventoryService.ListUserLogs(userID.(uint), &req)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+	util.Success(c, resp)
+}
@@ -0,0 +1,120 @@
+package user
+
+import (
+	"bgame/internal/service"
+	"bgame/internal/util"
+	"github.com/gin-gonic/gin"
+)
+
+type LeaderboardHandler struct {
+	leaderboardService *service.LeaderboardService
+}
+
+func NewLeaderboardHandler() *LeaderboardHandler {
+	return &LeaderboardHandler{
+		leaderboardService: service.NewLeaderboardService(),
+	}
+}
+
+// ListBoards 排行榜列表
+// @Summary      排行榜列表
+// @Description  获取全部排行榜及其当前周期
+// @Tags         用户接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  util.Response{data=[]service.LeaderboardInfo}
+// @Router       /api/user/leaderboard [get]
+func (h *LeaderboardHandler) ListBoards(c *gin.Context) {
+	util.Success(c, h.leaderboardService.Boards())
+}
+
+// GetTop 排行榜前N名
+// @Summary      排行榜前N名
+// @Description  获取排行榜当前周期的前N名，并附带当前用户的名次
+// @Tags         用户接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        name   path      string  true   "榜单标识"
+// @Param        limit  query     int     false  "数量，默认10，最大100"
+// @Success      200  {object}  util.Response{data=service.LeaderboardResponse}
+// @Failure      400  {object}  util.Response
+// @Router       /api/user/leaderboard/{name} [get]
+func (h *LeaderboardHandler) GetTop(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		util.Unauthorized(c, "未获取到用户信息")
+		return
+	}
+	var req service.LeaderboardTopQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+	resp, err := h.leaderboardService.Top(c.Param("name"), userID.(uint), &req)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+	util.Success(c, resp)
+}
+
+// GetAroundMe 我附近的名次
+// @Summary      我附近的名次
+// @Description  获取排行榜当前周期中当前用户前后各N名；未上榜时列表为空
+// @Tags         用户接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        name   path      string  true   "榜单标识"
+// @Param        range  query     int     false  "前后各取的人数，默认5，最大50"
+// @Success      200  {object}  util.Response{data=service.LeaderboardResponse}
+// @Failure      400  {object}  util.Response
+// @Router       /api/user/leaderboard/{name}/around [get]
+func (h *LeaderboardHandler) GetAroundMe(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		util.Unauthorized(c, "未获取到用户信息")
+		return
+	}
+	var req service.LeaderboardAroundQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+	resp, err := h.leaderboardService.AroundMe(c.Param("name"), userID.(uint), &req)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+	util.Success(c, resp)
+}
+
+// GetArchive 历史周期排名
+// @Summary      历史周期排名
+// @Description  分页查询已结束周期的最终排名，不传 period 时返回最近一次归档
+// @Tags         用户接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        name       path      string  true   "榜单标识"
+// @Param        period     query     string  false  "周期，如 20261017、2026W41、S3"
+// @Param        page       query     int     false  "页码"
+// @Param        page_size  query     int     false  "每页数量"
+// @Success      200  {object}  util.Response{data=util.PageResult{list=[]model.LeaderboardArchive}}
+// @Failure      400  {object}  util.Response
+// @Router       /api/user/leaderboard/{name}/archive [get]
+func (h *LeaderboardHandler) GetArchive(c *gin.Context) {
+	var req service.LeaderboardArchiveQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+	resp, err := h.leaderboardService.Archive(c.Param("name"), &req)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+	util.Success(c, resp)
+}
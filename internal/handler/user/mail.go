@@ -0,0 +1,187 @@
+package user
+
+import (
+	"strconv"
+
+	"bgame/internal/service"
+	"bgame/internal/util"
+	"github.com/gin-gonic/gin"
+)
+
+type MailHandler struct {
+	mailService *service.MailService
+}
+
+func NewMailHandler() *MailHandler {
+	return &MailHandler{
+		mailService: service.NewMailService(),
+	}
+}
+
+// ListMails 查询邮箱
+// @Summary      查询邮箱
+// @Description  分页查询未删除、未过期的邮件，按时间倒序；查询时会投递玩家尚未收到的全服和分群邮件
+// @Tags         用户接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page       query     int  false  "页码"
+// @Param        page_size  query     int  false  "每页数量"
+// @Success      200  {object}  util.Response{data=util.PageResult{list=[]service.UserMailResponse}}
+// @Failure      400  {object}  util.Response
+// @Router       /api/user/mail [get]
+func (h *MailHandler) ListMails(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		util.Unauthorized(c, "未获取到用户信息")
+		return
+	}
+	var req util.PageQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+	resp, err := h.mailService.ListUserMails(userID.(uint), &req)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+	util.Success(c, resp)
+}
+
+// GetUnread 查询未读邮件数
+// @Summary      查询未读邮件数
+// @Description  用于客户端红点提示，结果缓存在 Redis
+// @Tags         用户接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  util.Response{data=service.MailUnreadResponse}
+// @Failure      400  {object}  util.Response
+// @Router       /api/user/mail/unread [get]
+func (h *MailHandler) GetUnread(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		util.Unauthorized(c, "未获取到用户信息")
+		return
+	}
+	resp, err := h.mailService.UnreadCount(userID.(uint))
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+	util.Success(c, resp)
+}
+
+// ReadMail 阅读邮件
+// @Summary      阅读邮件
+// @Description  返回邮件内容并标记已读
+// @Tags         用户接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "邮件ID"
+// @Success      200  {object}  util.Response{data=service.UserMailResponse}
+// @Failure      400  {object}  util.Response
+// @Router       /api/user/mail/{id} [get]
+func (h *MailHandler) ReadMail(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		util.Unauthorized(c, "未获取到用户信息")
+		return
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.Error(c, "参数错误: 无效的邮件ID")
+		return
+	}
+	resp, err := h.mailService.ReadMail(userID.(uint), uint(id))
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+	util.Success(c, resp)
+}
+
+// DeleteMail 删除邮件
+// @Summary      删除邮件
+// @Description  有未领取的附件时需先领取
+// @Tags         用户接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "邮件ID"
+// @Success      200  {object}  util.Response
+// @Failure      400  {object}  util.Response
+// @Router       /api/user/mail/{id} [delete]
+func (h *MailHandler) DeleteMail(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		util.Unauthorized(c, "未获取到用户信息")
+		return
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.Error(c, "参数错误: 无效的邮件ID")
+		return
+	}
+	if err := h.mailService.DeleteMail(userID.(uint), uint(id)); err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+	util.SuccessWithMessage(c, "删除成功", nil)
+}
+
+// ClaimMail 领取邮件附件
+// @Summary      领取邮件附件
+// @Description  在一个事务内标记已领取并发放附件（余额、活动余额、经验、道具），每封邮件只能领取一次，过期后不能领取
+// @Tags         用户接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "邮件ID"
+// @Success      200  {object}  util.Response{data=service.ClaimMailResponse}
+// @Failure      400  {object}  util.Response
+// @Router       /api/user/mail/{id}/claim [post]
+func (h *MailHandler) ClaimMail(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		util.Unauthorized(c, "未获取到用户信息")
+		return
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		util.Error(c, "参数错误: 无效的邮件ID")
+		return
+	}
+	resp, err := h.mailService.ClaimAttachments(userID.(uint), uint(id))
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+	util.SuccessWithMessage(c, "领取成功", resp)
+}
+
+// ClaimAll 一键领取邮件附件
+// @Summary      一键领取邮件附件
+// @Description  领取所有未过期邮件的附件，每封邮件单独发放，部分失败时返回失败数量
+// @Tags         用户接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  util.Response{data=service.ClaimAllMailResponse}
+// @Failure      400  {object}  util.Response
+// @Router       /api/user/mail/claim [post]
+func (h *MailHandler) ClaimAll(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		util.Unauthorized(c, "未获取到用户信息")
+		return
+	}
+	resp, err := h.mailService.ClaimAll(userID.(uint))
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+	util.SuccessWithMessage(c, "领取成功", resp)
+}
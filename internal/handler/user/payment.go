@@ -0,0 +1,105 @@
+package user
+
+import (
+	"bgame/internal/service"
+	"bgame/internal/util"
+	"github.com/gin-gonic/gin"
+)
+
+type PaymentHandler struct {
+	paymentService *service.PaymentService
+}
+
+func NewPaymentHandler() *PaymentHandler {
+	return &PaymentHandler{
+		paymentService: service.NewPaymentService(),
+	}
+}
+
+// CreateOrder 创建充值订单
+// @Summary      创建充值订单
+// @Description  创建待支付的充值订单并向支付渠道下单，返回客户端拉起支付所需的参数。支付成功后由渠道回调为余额入账，超时未支付的订单自动关闭
+// @Tags         用户接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      service.CreatePaymentOrderRequest  true  "下单请求"
+// @Success      200  {object}  util.Response{data=service.CreatePaymentOrderResponse}
+// @Failure      400  {object}  util.Response
+// @Router       /api/user/payment/orders [post]
+func (h *PaymentHandler) CreateOrder(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		util.Unauthorized(c, "未获取到用户信息")
+		return
+	}
+	var req service.CreatePaymentOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+	req.ClientIP = c.ClientIP()
+	resp, err := h.paymentService.CreateOrder(userID.(uint), &req)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+	util.SuccessWithMessage(c, "下单成功", resp)
+}
+
+// ListOrders 查询充值订单
+// @Summary      查询充值订单
+// @Description  分页查询当前用户的充值订单，按时间倒序
+// @Tags         用户接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page       query     int     false  "页码"
+// @Param        page_size  query     int     false  "每页数量"
+// @Param        status     query     string  false  "状态：pending, paid, failed, refunding, refunded"
+// @Success      200  {object}  util.Response{data=util.PageResult{list=[]model.PaymentOrder}}
+// @Failure      400  {object}  util.Response
+// @Router       /api/user/payment/orders [get]
+func (h *PaymentHandler) ListOrders(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		util.Unauthorized(c, "未获取到用户信息")
+		return
+	}
+	var req service.PaymentOrderQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+	resp, err := h.paymentService.ListUserOrders(userID.(uint), &req)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+	util.Success(c, resp)
+}
+
+// GetOrder 查询充值订单详情
+// @Summary      查询充值订单详情
+// @Description  按订单号查询当前用户的订单，客户端支付完成后可轮询订单状态
+// @Tags         用户接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        order_no  path      string  true  "订单号"
+// @Success      200  {object}  util.Response{data=model.PaymentOrder}
+// @Failure      400  {object}  util.Response
+// @Router       /api/user/payment/orders/{order_no} [get]
+func (h *PaymentHandler) GetOrder(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		util.Unauthorized(c, "未获取到用户信息")
+		return
+	}
+	order, err := h.paymentService.GetUserOrder(userID.(uint), c.Param("order_no"))
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+	util.Success(c, order)
+}
@@ -0,0 +1,74 @@
+package user
+
+import (
+	"bgame/internal/service"
+	"bgame/internal/util"
+	"github.com/gin-gonic/gin"
+)
+
+type ProgressionHandler struct {
+	progressionService *service.ProgressionService
+}
+
+func NewProgressionHandler() *ProgressionHandler {
+	return &ProgressionHandler{
+		progressionService: service.NewProgressionService(),
+	}
+}
+
+// GetLevel 获取等级进度
+// @Summary      获取等级进度
+// @Description  获取当前用户的等级、累计经验以及当前/下一级所需的累计经验
+// @Tags         用户接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  util.Response{data=service.LevelProgressResponse}
+// @Failure      400  {object}  util.Response
+// @Router       /api/user/level [get]
+func (h *ProgressionHandler) GetLevel(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		util.Unauthorized(c, "未获取到用户信息")
+		return
+	}
+	resp, err := h.progressionService.GetProgress(userID.(uint))
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+	util.Success(c, resp)
+}
+
+// ListExperienceLogs 查询经验流水
+// @Summary      查询经验流水
+// @Description  分页查询当前用户的经验流水，按时间倒序
+// @Tags         用户接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page         query     int     false  "页码"
+// @Param        page_size    query     int     false  "每页数量"
+// @Param        source       query     string  false  "来源：server, admin"
+// @Param        reason_code  query     string  false  "原因码"
+// @Success      200  {object}  util.Response{data=util.PageResult{list=[]model.ExperienceLog}}
+// @Failure      400  {object}  util.Response
+// @Router       /api/user/experience/logs [get]
+func (h *ProgressionHandler) ListExperienceLogs(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		util.Unauthorized(c, "未获取到用户信息")
+		return
+	}
+	var req service.ExperienceLogQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+	resp, err := h.progressionService.ListUserLogs(userID.(uint), &req)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+	util.Success(c, resp)
+}
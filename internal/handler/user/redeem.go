@@ -0,0 +1,48 @@
+package user
+
+import (
+	"bgame/internal/service"
+	"bgame/internal/util"
+	"github.com/gin-gonic/gin"
+)
+
+type RedeemHandler struct {
+	redeemService *service.RedeemService
+}
+
+func NewRedeemHandler() *RedeemHandler {
+	return &RedeemHandler{
+		redeemService: service.NewRedeemService(),
+	}
+}
+
+// Redeem 使用兑换码
+// @Summary      使用兑换码
+// @Description  校验兑换码的状态、有效期、剩余次数和每人兑换上限，在一个事务内写入兑换记录并发放奖励（余额、活动余额、经验）。兑换码不区分大小写，忽略空格和连字符
+// @Tags         用户接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      service.RedeemRequest  true  "兑换请求"
+// @Success      200  {object}  util.Response{data=service.RedeemResponse}
+// @Failure      400  {object}  util.Response
+// @Router       /api/user/redeem [post]
+func (h *RedeemHandler) Redeem(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		util.Unauthorized(c, "未获取到用户信息")
+		return
+	}
+	var req service.RedeemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+	req.ClientIP = c.ClientIP()
+	resp, err := h.redeemService.Redeem(userID.(uint), &req)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+	util.SuccessWithMessage(c, "兑换成功", resp)
+}
@@ -7,23 +7,74 @@ import (
 )
 
 type UserHandler struct {
-	userService *service.UserService
+	userService  *service.UserService
+	tokenService *service.TokenService
 }
 
 func NewUserHandler() *UserHandler {
 	return &UserHandler{
-		userService: service.NewUserService(),
+		userService:  service.NewUserService(),
+		tokenService: service.NewTokenService(),
 	}
 }
 
+// Register 用户注册
+// @Summary      用户注册
+// @Description  用户注册接口，注册成功后直接返回JWT token
+// @Tags         用户接口
+// @Accept       json
+// @Produce      json
+// @Param        request body service.RegisterRequest true "注册请求"
+// @Success      200  {object}  util.Response{data=service.UserLoginResponse}
+// @Failure      400  {object}  util.Response
+// @Router       /api/user/register [post]
+func (h *UserHandler) Register(c *gin.Context) {
+	var req service.RegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+	resp, err := h.userService.Register(&req)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+	util.Success(c, resp)
+}
+
+// Login 用户登录
+// @Summary      用户登录
+// @Description  用户登录接口，返回JWT token、用户信息和用户资料
+// @Tags         用户接口
+// @Accept       json
+// @Produce      json
+// @Param        request body service.LoginRequest true "登录请求"
+// @Success      200  {object}  util.Response{data=service.UserLoginResponse}
+// @Failure      400  {object}  util.Response
+// @Router       /api/user/login [post]
+func (h *UserHandler) Login(c *gin.Context) {
+	var req service.LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+	req.ClientIP = c.ClientIP()
+	resp, err := h.userService.Login(&req)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+	util.Success(c, resp)
+}
+
 // RegAndLogin 用户注册和登录合并
 // @Summary      用户注册和登录合并
-// @Description  用户注册和登录合并接口
+// @Description  已存在的用户校验密码后登录；开启 user.auto_register 时新用户名自动注册
 // @Tags         用户接口
 // @Accept       json
 // @Produce      json
 // @Param        request body service.RegAndLoginRequest true "注册和登录请求"
-// @Success      200  {object}  util.Response{data=service.RegAndLoginResponse}
+// @Success      200  {object}  util.Response{data=service.UserLoginResponse}
 // @Failure      400  {object}  util.Response
 // @Router       /api/user/regAndLogin [post]
 func (h *UserHandler) RegAndLogin(c *gin.Context) {
@@ -32,6 +83,7 @@ func (h *UserHandler) RegAndLogin(c *gin.Context) {
 		util.Error(c, "参数错误: "+err.Error())
 		return
 	}
+	req.ClientIP = c.ClientIP()
 	resp, err := h.userService.RegAndLogin(&req)
 	if err != nil {
 		util.Error(c, err.Error())
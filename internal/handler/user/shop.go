@@ -0,0 +1,112 @@
+package user
+
+import (
+	"bgame/internal/service"
+	"bgame/internal/util"
+	"github.com/gin-gonic/gin"
+)
+
+type ShopHandler struct {
+	shopService *service.ShopService
+}
+
+func NewShopHandler() *ShopHandler {
+	return &ShopHandler{
+		shopService: service.NewShopService(),
+	}
+}
+
+// ListSkus 查询在售商品
+// @Summary      查询在售商品
+// @Description  分页查询当前在售的商品，按排序值倒序，返回当前成交价（已计算折扣）、剩余库存和当前用户已购份数；已售罄的商品也会返回
+// @Tags         用户接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page       query     int  false  "页码"
+// @Param        page_size  query     int  false  "每页数量"
+// @Success      200  {object}  util.Response{data=util.PageResult{list=[]service.ShopSkuResponse}}
+// @Failure      400  {object}  util.Response
+// @Router       /api/user/shop [get]
+func (h *ShopHandler) ListSkus(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		util.Unauthorized(c, "未获取到用户信息")
+		return
+	}
+	var req util.PageQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+	resp, err := h.shopService.ListOnSale(userID.(uint), &req)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+	util.Success(c, resp)
+}
+
+// Purchase 购买商品
+// @Summary      购买商品
+// @Description  使用余额或活动余额购买商品，校验上架状态、销售时间、库存和每人限购，在一个事务内扣款、发放道具并写入购买记录，返回收据号。建议携带 Idempotency-Key 防止重复购买
+// @Tags         用户接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      service.PurchaseRequest  true  "购买请求"
+// @Success      200  {object}  util.Response{data=service.PurchaseResponse}
+// @Failure      400  {object}  util.Response
+// @Router       /api/user/shop/purchase [post]
+func (h *ShopHandler) Purchase(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		util.Unauthorized(c, "未获取到用户信息")
+		return
+	}
+	var req service.PurchaseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+	req.ClientIP = c.ClientIP()
+	resp, err := h.shopService.Purchase(userID.(uint), &req)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+	util.SuccessWithMessage(c, "购买成功", resp)
+}
+
+// ListPurchases 查询购买记录
+// @Summary      查询购买记录
+// @Description  分页查询当前用户的商城购买记录，可按商品或收据号筛选
+// @Tags         用户接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page        query     int     false  "页码"
+// @Param        page_size   query     int     false  "每页数量"
+// @Param        sku_id      query     int     false  "商品ID"
+// @Param        receipt_no  query     string  false  "收据号"
+// @Success      200  {object}  util.Response{data=util.PageResult{list=[]model.ShopPurchase}}
+// @Failure      400  {object}  util.Response
+// @Router       /api/user/shop/purchases [get]
+func (h *ShopHandler) ListPurchases(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		util.Unauthorized(c, "未获取到用户信息")
+		return
+	}
+	var req service.ShopPurchaseQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+	resp, err := h.shopService.ListUserPurchases(userID.(uint), &req)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+	util.Success(c, resp)
+}
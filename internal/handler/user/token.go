@@ -0,0 +1,62 @@
+package user
+
+import (
+	"bgame/internal/service"
+	"bgame/internal/util"
+	"github.com/gin-gonic/gin"
+)
+
+// RefreshToken 刷新用户token
+// @Summary      刷新用户token
+// @Description  使用refresh token换取新的access token，旧的refresh token随即失效；重放已使用的refresh token会吊销该设备的全部会话
+// @Tags         用户接口
+// @Accept       json
+// @Produce      json
+// @Param        request body service.RefreshTokenRequest true "刷新token请求"
+// @Success      200  {object}  util.Response{data=service.TokenPair}
+// @Failure      400  {object}  util.Response
+// @Router       /api/user/token/refresh [post]
+func (h *UserHandler) RefreshToken(c *gin.Context) {
+	var req service.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+	resp, err := h.tokenService.RefreshUser(req.RefreshToken)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+	util.Success(c, resp)
+}
+
+// Logout 用户退出登录
+// @Summary      用户退出登录
+// @Description  吊销当前access token；传入refresh_token时同时吊销该设备的refresh token
+// @Tags         用户接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body service.LogoutRequest false "退出登录请求"
+// @Success      200  {object}  util.Response
+// @Failure      401  {object}  util.Response
+// @Router       /api/user/logout [post]
+func (h *UserHandler) Logout(c *gin.Context) {
+	claims, exists := c.Get("claims")
+	if !exists {
+		util.Unauthorized(c, "未获取到用户信息")
+		return
+	}
+	var req service.LogoutRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			util.Error(c, "参数错误: "+err.Error())
+			return
+		}
+	}
+	if err := h.tokenService.Logout(claims.(*util.Claims), req.RefreshToken); err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+	util.SuccessWithMessage(c, "退出登录成功", nil)
+}
@@ -0,0 +1,75 @@
+package user
+
+import (
+	"bgame/internal/service"
+	"bgame/internal/util"
+	"github.com/gin-gonic/gin"
+)
+
+type WalletHandler struct {
+	walletService *service.WalletService
+}
+
+func NewWalletHandler() *WalletHandler {
+	return &WalletHandler{
+		walletService: service.NewWalletService(),
+	}
+}
+
+// GetWallet 获取钱包余额
+// @Summary      获取钱包余额
+// @Description  获取当前用户的余额和活动余额
+// @Tags         用户接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  util.Response{data=service.WalletResponse}
+// @Failure      400  {object}  util.Response
+// @Router       /api/user/wallet [get]
+func (h *WalletHandler) GetWallet(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		util.Unauthorized(c, "未获取到用户信息")
+		return
+	}
+	resp, err := h.walletService.GetWallet(userID.(uint))
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+	util.Success(c, resp)
+}
+
+// ListTransactions 查询钱包流水
+// @Summary      查询钱包流水
+// @Description  分页查询当前用户的钱包流水，按时间倒序
+// @Tags         用户接口
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page         query     int     false  "页码"
+// @Param        page_size    query     int     false  "每页数量"
+// @Param        currency     query     string  false  "币种：balance, activity_balance"
+// @Param        direction    query     string  false  "方向：credit, debit"
+// @Param        reason_code  query     string  false  "原因码"
+// @Success      200  {object}  util.Response{data=util.PageResult{list=[]model.WalletLedgerEntry}}
+// @Failure      400  {object}  util.Response
+// @Router       /api/user/wallet/transactions [get]
+func (h *WalletHandler) ListTransactions(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		util.Unauthorized(c, "未获取到用户信息")
+		return
+	}
+	var req service.WalletTransactionQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		util.Error(c, "参数错误: "+err.Error())
+		return
+	}
+	resp, err := h.walletService.ListUserTransactions(userID.(uint), &req)
+	if err != nil {
+		util.Error(c, err.Error())
+		return
+	}
+	util.Success(c, resp)
+}
@@ -0,0 +1,176 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strconv"
+
+	"bgame/internal/model"
+	"bgame/internal/service"
+	"bgame/internal/util"
+	"github.com/gin-gonic/gin"
+)
+
+// auditBodyMaxSize 审计时读取的请求体上限，超出部分不记录请求参数
+const auditBodyMaxSize = 64 << 10
+
+var auditService = service.NewAuditService()
+
+// 审计目标ID的来源
+const (
+	auditTargetNone    = iota // 无具体目标
+	auditTargetParam          // 路径参数 :id
+	auditTargetBody           // 请求体字段
+	auditTargetSelf           // 当前管理员
+	auditTargetCreated        // 响应数据中的新建对象ID
+)
+
+// auditRoute 管理端写接口的审计定义
+type auditRoute struct {
+	action     string
+	targetType string
+	source     int
+	field      string // source 为 auditTargetBody 时的字段名
+}
+
+// auditRoutes 以 "方法 路由" 为键；未登记的写接口仍会记录，动作名使用 "方法 路由"
+var auditRoutes = map[string]auditRoute{
+	"POST /api/admin/logout":                     {action: "admin.logout", targetType: model.AuditTargetAdmin, source: auditTargetSelf},
+	"POST /api/admin/2fa/setup":                  {action: "admin.2fa_setup", targetType: model.AuditTargetAdmin, source: auditTargetSelf},
+	"POST /api/admin/2fa/enable":                 {action: "admin.2fa_enable", targetType: model.AuditTargetAdmin, source: auditTargetSelf},
+	"POST /api/admin/2fa/disable":                {action: "admin.2fa_disable", targetType: model.AuditTargetAdmin, source: auditTargetSelf},
+	"POST /api/admin/2fa/recovery-codes":         {action: "admin.2fa_recovery_codes", targetType: model.AuditTargetAdmin, source: auditTargetSelf},
+	"PUT /api/admin/users/:id":                   {action: "user.update", targetType: model.AuditTargetUser, source: auditTargetParam},
+	"POST /api/admin/users/:id/ban":              {action: "user.ban", targetType: model.AuditTargetUser, source: auditTargetParam},
+	"POST /api/admin/users/:id/unban":            {action: "user.unban", targetType: model.AuditTargetUser, source: auditTargetParam},
+	"POST /api/admin/users/:id/sessions/revoke":  {action: "user.revoke_sessions", targetType: model.AuditTargetUser, source: auditTargetParam},
+	"POST /api/admin/security/users/unlock":      {action: "user.unlock_login", targetType: model.AuditTargetUser},
+	"POST /api/admin/users/:id/experience":       {action: "user.grant_experience", targetType: model.AuditTargetLevel, source: auditTargetParam},
+	"POST /api/admin/wallet/adjust":              {action: "wallet.adjust", targetType: model.AuditTargetWallet, source: auditTargetBody, field: "user_id"},
+	"POST /api/admin/create":                     {action: "admin.create", targetType: model.AuditTargetAdmin, source: auditTargetCreated},
+	"PUT /api/admin/admins/:id/role":             {action: "admin.update_role", targetType: model.AuditTargetAdmin, source: auditTargetParam},
+	"PUT /api/admin/admins/:id/status":           {action: "admin.update_status", targetType: model.AuditTargetAdmin, source: auditTargetParam},
+	"POST /api/admin/admins/:id/password/reset":  {action: "admin.reset_password", targetType: model.AuditTargetAdmin, source: auditTargetParam},
+	"DELETE /api/admin/admins/:id":               {action: "admin.delete", targetType: model.AuditTargetAdmin, source: auditTargetParam},
+	"POST /api/admin/admins/:id/sessions/revoke": {action: "admin.revoke_sessions", targetType: model.AuditTargetAdmin, source: auditTargetParam},
+	"POST /api/admin/admins/:id/2fa/reset":       {action: "admin.2fa_reset", targetType: model.AuditTargetAdmin, source: auditTargetParam},
+	"POST /api/admin/security/admins/unlock":     {action: "admin.unlock_login", targetType: model.AuditTargetAdmin},
+	"POST /api/admin/rbac/roles":                 {action: "role.create", targetType: model.AuditTargetRole, source: auditTargetCreated},
+	"PUT /api/admin/rbac/roles/:id":              {action: "role.update", targetType: model.AuditTargetRole, source: auditTargetParam},
+	"DELETE /api/admin/rbac/roles/:id":           {action: "role.delete", targetType: model.AuditTargetRole, source: auditTargetParam},
+	"POST /api/admin/redeem/batches":             {action: "redeem.create_batch", targetType: model.AuditTargetRedeemBatch, source: auditTargetCreated},
+	"PUT /api/admin/redeem/batches/:id/status":   {action: "redeem.update_status", targetType: model.AuditTargetRedeemBatch, source: auditTargetParam},
+	"POST /api/admin/items":                      {action: "item.create", targetType: model.AuditTargetItem, source: auditTargetCreated},
+	"PUT /api/admin/items/:id":                   {action: "item.update", targetType: model.AuditTargetItem, source: auditTargetParam},
+	"POST /api/admin/users/:id/items/grant":      {action: "user.grant_item", targetType: model.AuditTargetInventory, source: auditTargetParam},
+	"POST /api/admin/users/:id/items/consume":    {action: "user.consume_item", targetType: model.AuditTargetInventory, source: auditTargetParam},
+	"POST /api/admin/shop/skus":                  {action: "shop.create_sku", targetType: model.AuditTargetShopSku, source: auditTargetCreated},
+	"PUT /api/admin/shop/skus/:id":               {action: "shop.update_sku", targetType: model.AuditTargetShopSku, source: auditTargetParam},
+	"POST /api/admin/payment/orders/:id/refund":  {action: "payment.refund", targetType: model.AuditTargetPayment, source: auditTargetParam},
+	"POST /api/admin/mail":                       {action: "mail.send", targetType: model.AuditTargetMail, source: auditTargetCreated},
+}
+
+// AdminAudit 管理端写操作审计中间件：记录操作人、目标、变更前后差异、IP和请求ID
+// 需放在 AuthAdmin 之后；失败（含权限不足）的操作同样记录
+func AdminAudit() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !isMutatingMethod(c.Request.Method) {
+			c.Next()
+			return
+		}
+
+		key := c.Request.Method + " " + c.FullPath()
+		route, ok := auditRoutes[key]
+		if !ok {
+			route = auditRoute{action: key, source: auditTargetParam}
+		}
+
+		var body []byte
+		if c.Request.ContentLength >= 0 && c.Request.ContentLength <= auditBodyMaxSize {
+			data, err := io.ReadAll(c.Request.Body)
+			if err != nil {
+				util.Error(c, "读取请求失败")
+				c.Abort()
+				return
+			}
+			c.Request.Body = io.NopCloser(bytes.NewReader(data))
+			body = data
+		}
+
+		adminID := c.GetUint("admin_id")
+		targetID := auditTargetID(c, &route, adminID, body)
+		before := auditService.Snapshot(route.targetType, targetID)
+
+		writer := &bodyCaptureWriter{ResponseWriter: c.Writer, body: &bytes.Buffer{}}
+		c.Writer = writer
+		c.Next()
+
+		var resp struct {
+			Code    int             `json:"code"`
+			Message string          `json:"message"`
+			Data    json.RawMessage `json:"data"`
+		}
+		parsed := json.Unmarshal(writer.body.Bytes(), &resp) == nil
+		success := writer.Status() < 400 && parsed && resp.Code == util.CodeSuccess
+
+		if route.source == auditTargetCreated && success {
+			var created struct {
+				ID uint `json:"id"`
+			}
+			if json.Unmarshal(resp.Data, &created) == nil {
+				targetID = created.ID
+			}
+		}
+
+		var after map[string]interface{}
+		if success {
+			after = auditService.Snapshot(route.targetType, targetID)
+		} else {
+			before = nil
+		}
+
+		entry := &service.AuditEntry{
+			AdminID:    adminID,
+			AdminName:  c.GetString("username"),
+			Action:     route.action,
+			Method:     c.Request.Method,
+			Path:       c.Request.URL.Path,
+			TargetType: route.targetType,
+			Before:     before,
+			After:      after,
+			Request:    body,
+			Success:    success,
+			Message:    resp.Message,
+			ClientIP:   c.ClientIP(),
+			RequestID:  c.GetString("request_id"),
+		}
+		if targetID > 0 {
+			entry.TargetID = strconv.FormatUint(uint64(targetID), 10)
+		} else if route.source == auditTargetParam {
+			entry.TargetID = c.Param("id")
+		}
+		if err := auditService.Record(entry); err != nil {
+			util.LogError("写入审计日志失败: admin_id=%d action=%s request_id=%s err=%v", adminID, route.action, entry.RequestID, err)
+		}
+	}
+}
+
+// auditTargetID 按审计定义解析目标ID，无法解析时返回 0
+func auditTargetID(c *gin.Context, route *auditRoute, adminID uint, body []byte) uint {
+	switch route.source {
+	case auditTargetParam:
+		id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+		return uint(id)
+	case auditTargetBody:
+		var fields map[string]json.RawMessage
+		if json.Unmarshal(body, &fields) != nil {
+			return 0
+		}
+		id, _ := strconv.ParseUint(string(fields[route.field]), 10, 64)
+		return uint(id)
+	case auditTargetSelf:
+		return adminID
+	}
+	return 0
+}
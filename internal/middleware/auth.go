@@ -3,10 +3,17 @@ package middleware
 import (
 	"strings"
 
+	"bgame/internal/model"
+	"bgame/internal/service"
 	"bgame/internal/util"
 	"github.com/gin-gonic/gin"
 )
 
+var (
+	tokenService = service.NewTokenService()
+	roleService  = service.NewRoleService()
+)
+
 // AuthUser 用户认证中间件
 func AuthUser() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -30,9 +37,16 @@ func AuthUser() gin.HandlerFunc {
 			return
 		}
 
+		if tokenService.IsRevoked(claims) {
+			util.Unauthorized(c, "token已失效，请重新登录")
+			c.Abort()
+			return
+		}
+
 		// 将用户信息存入上下文
 		c.Set("user_id", claims.UserID)
 		c.Set("username", claims.Username)
+		c.Set("claims", claims)
 		c.Next()
 	}
 }
@@ -60,16 +74,23 @@ func AuthAdmin() gin.HandlerFunc {
 			return
 		}
 
+		if tokenService.IsRevoked(claims) {
+			util.Unauthorized(c, "token已失效，请重新登录")
+			c.Abort()
+			return
+		}
+
 		// 将管理员信息存入上下文
 		c.Set("admin_id", claims.UserID)
 		c.Set("username", claims.Username)
 		c.Set("role", claims.Role)
+		c.Set("claims", claims)
 		c.Next()
 	}
 }
 
-// RequireRole 要求特定角色的中间件
-func RequireRole(requiredRole int) gin.HandlerFunc {
+// RequirePermission 要求当前管理员角色拥有指定权限的中间件
+func RequirePermission(permission string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		role, exists := c.Get("role")
 		if !exists {
@@ -85,14 +106,14 @@ func RequireRole(requiredRole int) gin.HandlerFunc {
 			return
 		}
 
-		// 超级管理员(1)拥有所有权限
-		if adminRole == 1 {
-			c.Next()
+		allowed, err := roleService.HasPermission(uint(adminRole), permission)
+		if err != nil {
+			util.LogError("权限校验失败: role=%d permission=%s err=%v", adminRole, permission, err)
+			util.Forbidden(c, "权限校验失败")
+			c.Abort()
 			return
 		}
-
-		// 检查角色权限
-		if adminRole > requiredRole {
+		if !allowed {
 			util.Forbidden(c, "权限不足")
 			c.Abort()
 			return
@@ -102,6 +123,20 @@ func RequireRole(requiredRole int) gin.HandlerFunc {
 	}
 }
 
+// RequireSuperAdmin 仅允许超级管理员访问的中间件
+func RequireSuperAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, ok := c.Get("role")
+		if !ok || role != int(model.RoleSuperAdmin) {
+			util.Forbidden(c, "仅超级管理员可访问")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
 // extractToken 从请求头中提取token
 func extractToken(c *gin.Context) string {
 	// 优先从 Authorization header 获取
@@ -0,0 +1,205 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"bgame/internal/config"
+	"bgame/internal/util"
+	redisPkg "bgame/pkg/redis"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	idempotencyHeader    = "Idempotency-Key"
+	idempotencyKeyMaxLen = 128
+	idempotencyMaxBody   = 1 << 20 // 计算指纹时读取的请求体上限（1MB）
+
+	idempotencyProcessing = "processing"
+	idempotencyCompleted  = "completed"
+)
+
+// idempotencyRecord Redis 中保存的幂等记录
+type idempotencyRecord struct {
+	State       string `json:"state"`
+	Fingerprint string `json:"fingerprint"` // 请求指纹（方法+路径+请求体摘要）
+	Status      int    `json:"status,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+	BodyHash    string `json:"body_hash,omitempty"` // 不缓存响应体的路由只保存摘要
+}
+
+// idempotencyNoStoreRoutes 响应中包含 token、密码、TOTP 密钥或恢复码的路由
+// 这些路由只记录状态码和响应体摘要，不把凭证写入 Redis，重放时返回409
+var idempotencyNoStoreRoutes = map[string]bool{
+	"POST /api/user/register":                   true,
+	"POST /api/user/regAndLogin":                true,
+	"POST /api/admin/2fa/setup":                 true,
+	"POST /api/admin/2fa/enable":                true,
+	"POST /api/admin/2fa/recovery-codes":        true,
+	"POST /api/admin/admins/:id/password/reset": true,
+}
+
+// bodyCaptureWriter 在写出响应的同时保留一份响应体
+type bodyCaptureWriter struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w *bodyCaptureWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyCaptureWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency 幂等中间件：携带 Idempotency-Key 的写请求只执行一次，重放时返回首次响应
+// 需放在认证中间件之后，以便按调用方（用户/管理员/IP）隔离幂等键
+func Idempotency() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(idempotencyHeader)
+		if !config.Cfg.Idempotency.Enabled || key == "" || !isMutatingMethod(c.Request.Method) {
+			c.Next()
+			return
+		}
+
+		if len(key) > idempotencyKeyMaxLen {
+			util.Error(c, "Idempotency-Key 过长")
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, idempotencyMaxBody))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				util.Error(c, "请求体过大")
+			} else {
+				util.Error(c, "读取请求失败")
+			}
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := context.Background()
+		rdb := redisPkg.Client
+		redisKey := fmt.Sprintf("idempotency:%s:%s", idempotencyCaller(c), key)
+		fingerprint := util.HashToken(c.Request.Method + " " + c.FullPath() + "\n" + string(body))
+
+		// 抢占处理权
+		processing, _ := json.Marshal(&idempotencyRecord{State: idempotencyProcessing, Fingerprint: fingerprint})
+		lockTTL := time.Duration(config.Cfg.Idempotency.LockTTL) * time.Second
+		acquired, err := rdb.SetNX(ctx, redisKey, processing, lockTTL).Result()
+		if err != nil {
+			// Redis 不可用时不做幂等保护
+			util.LogError("幂等键检查失败: %v", err)
+			c.Next()
+			return
+		}
+
+		if !acquired {
+			replayIdempotentResponse(c, redisKey, fingerprint)
+			return
+		}
+
+		writer := &bodyCaptureWriter{ResponseWriter: c.Writer, body: &bytes.Buffer{}}
+		c.Writer = writer
+		c.Next()
+
+		// 服务端错误不缓存，允许客户端使用同一个键重试
+		status := writer.Status()
+		if status >= 500 {
+			rdb.Del(ctx, redisKey)
+			return
+		}
+
+		record := &idempotencyRecord{
+			State:       idempotencyCompleted,
+			Fingerprint: fingerprint,
+			Status:      status,
+			ContentType: writer.Header().Get("Content-Type"),
+		}
+		if idempotencyNoStoreRoutes[c.Request.Method+" "+c.FullPath()] {
+			record.BodyHash = util.HashToken(writer.body.String())
+		} else {
+			record.Body = writer.body.Bytes()
+		}
+		completed, err := json.Marshal(record)
+		if err != nil {
+			rdb.Del(ctx, redisKey)
+			return
+		}
+		rdb.Set(ctx, redisKey, completed, time.Duration(config.Cfg.Idempotency.TTL)*time.Second)
+	}
+}
+
+// replayIdempotentResponse 处理重复请求：处理中或不可重放时返回409，参数不一致返回422，否则重放首次响应
+func replayIdempotentResponse(c *gin.Context, redisKey, fingerprint string) {
+	data, err := redisPkg.Client.Get(context.Background(), redisKey).Bytes()
+	if err != nil {
+		util.Conflict(c, "请求正在处理中，请稍后再试")
+		c.Abort()
+		return
+	}
+
+	var record idempotencyRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		util.Conflict(c, "请求正在处理中，请稍后再试")
+		c.Abort()
+		return
+	}
+
+	if record.Fingerprint != fingerprint {
+		util.UnprocessableEntity(c, "Idempotency-Key 已用于不同的请求")
+		c.Abort()
+		return
+	}
+
+	if record.State != idempotencyCompleted {
+		util.Conflict(c, "请求正在处理中，请稍后再试")
+		c.Abort()
+		return
+	}
+
+	if record.BodyHash != "" {
+		util.Conflict(c, "请求已处理，响应包含凭证不能重放，请使用新的 Idempotency-Key")
+		c.Abort()
+		return
+	}
+
+	c.Header("Idempotent-Replayed", "true")
+	c.Data(record.Status, record.ContentType, record.Body)
+	c.Abort()
+}
+
+// idempotencyCaller 幂等键的调用方作用域：已认证时按用户/管理员/服务端调用方，否则按客户端IP
+func idempotencyCaller(c *gin.Context) string {
+	if client, ok := c.Get("api_client"); ok {
+		return fmt.Sprintf("server:%s", client)
+	}
+	if adminID, ok := c.Get("admin_id"); ok {
+		return fmt.Sprintf("admin:%d", adminID)
+	}
+	if userID, ok := c.Get("user_id"); ok {
+		return fmt.Sprintf("user:%d", userID)
+	}
+	return "ip:" + c.ClientIP()
+}
+
+func isMutatingMethod(method string) bool {
+	switch method {
+	case "POST", "PUT", "PATCH", "DELETE":
+		return true
+	}
+	return false
+}
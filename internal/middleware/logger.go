@@ -27,7 +27,8 @@ func Logger() gin.HandlerFunc {
 		}
 
 		// 根据状态码选择日志级别
-		logMsg := fmt.Sprintf("[%s] %s %s %d %v %s",
+		logMsg := fmt.Sprintf("[%s] [%s] %s %s %d %v %s",
+			c.GetString("request_id"),
 			clientIP,
 			method,
 			path,
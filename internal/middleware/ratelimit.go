@@ -3,15 +3,55 @@ package middleware
 import (
 	"context"
 	"fmt"
-	"time"
+	"net/http"
+	"strconv"
 
 	"bgame/internal/config"
+	"bgame/internal/util"
 	redisPkg "bgame/pkg/redis"
 	"github.com/gin-gonic/gin"
 	"github.com/go-redis/redis/v8"
 )
 
-// RateLimit 基于Redis的限流中间件
+// tokenBucketScript 基于 GCRA 的令牌桶，检查与扣减在一个脚本内原子完成
+// KEYS[1] 限流key；ARGV[1] 每秒补充令牌数；ARGV[2] 桶容量；ARGV[3] 本次消耗令牌数
+// 返回 {是否放行, 剩余令牌, 重试等待微秒, 桶回满微秒}
+var tokenBucketScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local cost = tonumber(ARGV[3])
+
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+local interval = 1000000 / rate
+local tolerance = interval * burst
+
+local tat = tonumber(redis.call('GET', KEYS[1]))
+if not tat or tat < now then
+	tat = now
+end
+
+local new_tat = tat + interval * cost
+local allow_at = new_tat - tolerance
+if allow_at > now then
+	return {0, 0, math.ceil(allow_at - now), math.ceil(tat - now)}
+end
+
+local ttl_ms = math.ceil((new_tat - now) / 1000) + 1
+redis.call('SET', KEYS[1], string.format('%.0f', new_tat), 'PX', ttl_ms)
+return {1, math.floor((now - allow_at) / interval), 0, math.ceil(new_tat - now)}
+`)
+
+// rateLimitResult 一次限流判定的结果
+type rateLimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int64
+	RetryAfter int64 // 秒
+	Reset      int64 // 秒
+}
+
+// RateLimit 基于Redis令牌桶的限流中间件，按路由选择策略，按策略选择限流维度
 func RateLimit() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		if !config.Cfg.RateLimit.Enabled {
@@ -19,47 +59,87 @@ func RateLimit() gin.HandlerFunc {
 			return
 		}
 
-		// 使用IP作为限流key
-		clientIP := c.ClientIP()
-		key := fmt.Sprintf("ratelimit:%s", clientIP)
-
-		ctx := context.Background()
-		rdb := redisPkg.Client
-
-		// 使用滑动窗口算法
-		now := time.Now().Unix()
-		windowStart := now - int64(config.Cfg.RateLimit.RPS)
-
-		// 清理过期记录
-		rdb.ZRemRangeByScore(ctx, key, "0", fmt.Sprintf("%d", windowStart))
+		policy := getRateLimitRules().match(c)
+		if policy == nil || policy.rps <= 0 {
+			c.Next()
+			return
+		}
 
-		// 获取当前窗口内的请求数
-		count, err := rdb.ZCard(ctx, key).Result()
+		key := fmt.Sprintf("ratelimit:%s:%s", policy.name, rateLimitIdentity(c, policy.keyBy))
+		result, err := allowRequest(context.Background(), key, policy.rps, policy.burst)
 		if err != nil {
-			c.Next()
+			handleRateLimitError(c, err)
 			return
 		}
 
-		// 检查是否超过限制
-		if count >= int64(config.Cfg.RateLimit.Burst) {
-			c.JSON(429, gin.H{
-				"code":    429,
+		writeRateLimitHeaders(c, result)
+		if !result.Allowed {
+			c.JSON(http.StatusTooManyRequests, gin.H{
+				"code":    http.StatusTooManyRequests,
 				"message": "请求过于频繁，请稍后再试",
 			})
 			c.Abort()
 			return
 		}
 
-		// 添加当前请求记录
-		rdb.ZAdd(ctx, key, &redis.Z{
-			Score:  float64(now),
-			Member: fmt.Sprintf("%d", now),
-		})
+		c.Next()
+	}
+}
+
+// allowRequest 执行令牌桶脚本：rps 为每秒补充的令牌数，burst 为桶容量（未配置时等于 rps）
+func allowRequest(ctx context.Context, key string, rps, burst int) (*rateLimitResult, error) {
+	if rps <= 0 {
+		return nil, fmt.Errorf("无效的限流配置: rps=%d", rps)
+	}
+	if burst <= 0 {
+		burst = rps
+	}
 
-		// 设置key过期时间
-		rdb.Expire(ctx, key, time.Duration(config.Cfg.RateLimit.RPS)*time.Second)
+	values, err := tokenBucketScript.Run(ctx, redisPkg.Client, []string{key}, rps, burst, 1).Int64Slice()
+	if err != nil {
+		return nil, err
+	}
+	if len(values) != 4 {
+		return nil, fmt.Errorf("限流脚本返回值异常: %v", values)
+	}
 
-		c.Next()
+	return &rateLimitResult{
+		Allowed:    values[0] == 1,
+		Limit:      burst,
+		Remaining:  values[1],
+		RetryAfter: ceilSeconds(values[2]),
+		Reset:      ceilSeconds(values[3]),
+	}, nil
+}
+
+// handleRateLimitError Redis 不可用时按 fail_mode 放行或拒绝
+func handleRateLimitError(c *gin.Context, err error) {
+	util.LogError("限流检查失败: %v", err)
+	if config.Cfg.RateLimit.FailMode == "closed" {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"code":    http.StatusServiceUnavailable,
+			"message": "服务繁忙，请稍后再试",
+		})
+		c.Abort()
+		return
+	}
+	c.Next()
+}
+
+func writeRateLimitHeaders(c *gin.Context, result *rateLimitResult) {
+	c.Header("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+	c.Header("X-RateLimit-Remaining", strconv.FormatInt(result.Remaining, 10))
+	c.Header("X-RateLimit-Reset", strconv.FormatInt(result.Reset, 10))
+	if !result.Allowed {
+		retryAfter := result.RetryAfter
+		if retryAfter < 1 {
+			retryAfter = 1
+		}
+		c.Header("Retry-After", strconv.FormatInt(retryAfter, 10))
 	}
 }
 
+// ceilSeconds 微秒向上取整为秒
+func ceilSeconds(us int64) int64 {
+	return (us + 999999) / 1000000
+}
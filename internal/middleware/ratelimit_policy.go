@@ -0,0 +1,189 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+
+	"bgame/internal/config"
+	"bgame/internal/util"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	rateLimitKeyByIP     = "ip"
+	rateLimitKeyByUser   = "user"
+	rateLimitKeyByAPIKey = "api_key"
+
+	apiKeyHeader = "X-API-Key"
+)
+
+// rateLimitPolicy 编译后的限流策略
+type rateLimitPolicy struct {
+	name  string
+	rps   int
+	burst int
+	keyBy string
+}
+
+// rateLimitRules 由配置编译得到的限流规则
+type rateLimitRules struct {
+	defaultPolicy *rateLimitPolicy
+	routes        map[string]*rateLimitPolicy // 精确匹配
+	prefixes      []rateLimitPrefix           // 前缀匹配，按前缀长度倒序
+	exclude       []string
+	allowIPs      []net.IP
+	allowNets     []*net.IPNet
+}
+
+type rateLimitPrefix struct {
+	prefix string
+	policy *rateLimitPolicy
+}
+
+var (
+	rateLimitRulesOnce sync.Once
+	compiledRules      *rateLimitRules
+)
+
+// getRateLimitRules 首次使用时编译限流配置
+func getRateLimitRules() *rateLimitRules {
+	rateLimitRulesOnce.Do(func() {
+		compiledRules = compileRateLimitRules(&config.Cfg.RateLimit)
+	})
+	return compiledRules
+}
+
+func compileRateLimitRules(cfg *config.RateLimitConfig) *rateLimitRules {
+	rules := &rateLimitRules{
+		defaultPolicy: &rateLimitPolicy{
+			name:  "default",
+			rps:   cfg.RPS,
+			burst: cfg.Burst,
+			keyBy: normalizeKeyBy(cfg.KeyBy),
+		},
+		routes:  make(map[string]*rateLimitPolicy),
+		exclude: cfg.Exclude,
+	}
+
+	for _, p := range cfg.Policies {
+		policy := &rateLimitPolicy{
+			name:  p.Name,
+			rps:   p.RPS,
+			burst: p.Burst,
+			keyBy: normalizeKeyBy(p.KeyBy),
+		}
+		if policy.name == "" {
+			policy.name = strings.Join(p.Paths, ",")
+		}
+		for _, path := range p.Paths {
+			if prefix, ok := strings.CutSuffix(path, "*"); ok {
+				rules.prefixes = append(rules.prefixes, rateLimitPrefix{prefix: prefix, policy: policy})
+			} else {
+				rules.routes[path] = policy
+			}
+		}
+	}
+	// 最长前缀优先
+	sort.SliceStable(rules.prefixes, func(i, j int) bool {
+		return len(rules.prefixes[i].prefix) > len(rules.prefixes[j].prefix)
+	})
+
+	for _, entry := range cfg.Allowlist {
+		if strings.Contains(entry, "/") {
+			if _, ipNet, err := net.ParseCIDR(entry); err == nil {
+				rules.allowNets = append(rules.allowNets, ipNet)
+				continue
+			}
+		} else if ip := net.ParseIP(entry); ip != nil {
+			rules.allowIPs = append(rules.allowIPs, ip)
+			continue
+		}
+		util.Warn("忽略无效的限流白名单配置: %s", entry)
+	}
+
+	return rules
+}
+
+// match 返回请求适用的限流策略，nil 表示不限流
+func (r *rateLimitRules) match(c *gin.Context) *rateLimitPolicy {
+	route := c.FullPath()
+	if route == "" {
+		route = c.Request.URL.Path
+	}
+
+	for _, pattern := range r.exclude {
+		if matchRoute(pattern, route) {
+			return nil
+		}
+	}
+	if r.isAllowed(c.ClientIP()) {
+		return nil
+	}
+
+	if policy, ok := r.routes[route]; ok {
+		return policy
+	}
+	for _, p := range r.prefixes {
+		if strings.HasPrefix(route, p.prefix) {
+			return p.policy
+		}
+	}
+	return r.defaultPolicy
+}
+
+func (r *rateLimitRules) isAllowed(clientIP string) bool {
+	if len(r.allowIPs) == 0 && len(r.allowNets) == 0 {
+		return false
+	}
+	ip := net.ParseIP(clientIP)
+	if ip == nil {
+		return false
+	}
+	for _, allowed := range r.allowIPs {
+		if allowed.Equal(ip) {
+			return true
+		}
+	}
+	for _, ipNet := range r.allowNets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// rateLimitIdentity 按策略选择限流维度，无法识别身份时退化为客户端IP
+func rateLimitIdentity(c *gin.Context, keyBy string) string {
+	switch keyBy {
+	case rateLimitKeyByUser:
+		if token := extractToken(c); token != "" {
+			if claims, err := util.ParseToken(token); err == nil {
+				return fmt.Sprintf("%s:%d", claims.Type, claims.UserID)
+			}
+		}
+	case rateLimitKeyByAPIKey:
+		if apiKey := c.GetHeader(apiKeyHeader); apiKey != "" {
+			return "key:" + util.HashToken(apiKey)
+		}
+	}
+	return "ip:" + c.ClientIP()
+}
+
+func matchRoute(pattern, route string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return strings.HasPrefix(route, prefix)
+	}
+	return pattern == route
+}
+
+func normalizeKeyBy(keyBy string) string {
+	switch keyBy {
+	case rateLimitKeyByUser, rateLimitKeyByAPIKey:
+		return keyBy
+	default:
+		return rateLimitKeyByIP
+	}
+}
@@ -0,0 +1,155 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	redisPkg "bgame/pkg/redis"
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+)
+
+// useTestRedis 连接 REDIS_TEST_ADDR 指定的 Redis，未配置或不可用时跳过测试
+func useTestRedis(t *testing.T) {
+	t.Helper()
+	addr := os.Getenv("REDIS_TEST_ADDR")
+	if addr == "" {
+		t.Skip("未设置 REDIS_TEST_ADDR，跳过需要 Redis 的测试")
+	}
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		t.Skipf("Redis 不可用: %v", err)
+	}
+	prev := redisPkg.Client
+	redisPkg.Client = client
+	t.Cleanup(func() {
+		redisPkg.Client = prev
+		client.Close()
+	})
+}
+
+func testRateLimitKey(t *testing.T) string {
+	key := fmt.Sprintf("ratelimit:test:%s:%d", t.Name(), time.Now().UnixNano())
+	t.Cleanup(func() { redisPkg.Client.Del(context.Background(), key) })
+	return key
+}
+
+func TestCeilSeconds(t *testing.T) {
+	tests := []struct {
+		us   int64
+		want int64
+	}{
+		{0, 0},
+		{1, 1},
+		{999999, 1},
+		{1000000, 1},
+		{1000001, 2},
+		{2500000, 3},
+	}
+	for _, tt := range tests {
+		if got := ceilSeconds(tt.us); got != tt.want {
+			t.Errorf("ceilSeconds(%d) = %d, want %d", tt.us, got, tt.want)
+		}
+	}
+}
+
+func TestAllowRequestInvalidRate(t *testing.T) {
+	if _, err := allowRequest(context.Background(), "ratelimit:test", 0, 10); err == nil {
+		t.Error("rps=0 should be rejected")
+	}
+}
+
+func TestWriteRateLimitHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	writeRateLimitHeaders(c, &rateLimitResult{Allowed: false, Limit: 5, Remaining: 0, RetryAfter: 0, Reset: 3})
+
+	h := w.Header()
+	if h.Get("X-RateLimit-Limit") != "5" || h.Get("X-RateLimit-Remaining") != "0" || h.Get("X-RateLimit-Reset") != "3" {
+		t.Errorf("unexpected headers: %v", h)
+	}
+	if h.Get("Retry-After") != "1" {
+		t.Errorf("Retry-After = %q, want at least 1", h.Get("Retry-After"))
+	}
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	writeRateLimitHeaders(c, &rateLimitResult{Allowed: true, Limit: 5, Remaining: 4})
+	if w.Header().Get("Retry-After") != "" {
+		t.Error("allowed request should not set Retry-After")
+	}
+}
+
+func TestTokenBucketBurst(t *testing.T) {
+	useTestRedis(t)
+	key := testRateLimitKey(t)
+	ctx := context.Background()
+
+	// 每秒1个令牌、容量3：连续3次放行，剩余依次为2、1、0，第4次拒绝
+	for i := 0; i < 3; i++ {
+		result, err := allowRequest(ctx, key, 1, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !result.Allowed {
+			t.Fatalf("request %d should be allowed", i+1)
+		}
+		if result.Limit != 3 {
+			t.Errorf("Limit = %d, want 3", result.Limit)
+		}
+		if want := int64(2 - i); result.Remaining != want {
+			t.Errorf("request %d remaining = %d, want %d", i+1, result.Remaining, want)
+		}
+		if want := int64(i + 1); result.Reset != want {
+			t.Errorf("request %d reset = %d, want %d", i+1, result.Reset, want)
+		}
+	}
+
+	result, err := allowRequest(ctx, key, 1, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Allowed {
+		t.Fatal("request beyond burst should be rejected")
+	}
+	if result.Remaining != 0 || result.RetryAfter != 1 {
+		t.Errorf("rejected result = %+v, want remaining 0 and retry after 1s", result)
+	}
+}
+
+func TestTokenBucketRefill(t *testing.T) {
+	useTestRedis(t)
+	key := testRateLimitKey(t)
+	ctx := context.Background()
+
+	// 每秒20个令牌、容量1：令牌耗尽后等待一个补充间隔（50ms）即可再次放行
+	if result, err := allowRequest(ctx, key, 20, 1); err != nil || !result.Allowed {
+		t.Fatalf("first request = %+v, %v", result, err)
+	}
+	if result, err := allowRequest(ctx, key, 20, 1); err != nil || result.Allowed {
+		t.Fatalf("second request = %+v, %v, want rejected", result, err)
+	}
+	time.Sleep(60 * time.Millisecond)
+	if result, err := allowRequest(ctx, key, 20, 1); err != nil || !result.Allowed {
+		t.Fatalf("request after refill = %+v, %v, want allowed", result, err)
+	}
+}
+
+func TestTokenBucketDefaultBurst(t *testing.T) {
+	useTestRedis(t)
+	key := testRateLimitKey(t)
+
+	// 未配置 burst 时容量等于 rps
+	result, err := allowRequest(context.Background(), key, 5, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Allowed || result.Limit != 5 || result.Remaining != 4 {
+		t.Errorf("result = %+v, want allowed with limit 5 and remaining 4", result)
+	}
+}
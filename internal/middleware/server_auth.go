@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"crypto/subtle"
+
+	"bgame/internal/config"
+	"bgame/internal/util"
+	"github.com/gin-gonic/gin"
+)
+
+// AuthServer 服务端接口认证中间件：校验 X-API-Key 请求头，通过后将调用方名称存入上下文
+func AuthServer() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(apiKeyHeader)
+		if key == "" {
+			util.Unauthorized(c, "未提供 API Key")
+			c.Abort()
+			return
+		}
+
+		// 比较摘要并遍历全部凭证，避免通过响应时间推测密钥
+		keyHash := util.HashToken(key)
+		client := ""
+		for _, k := range config.Cfg.ServerAPI.Keys {
+			if k.Key == "" {
+				continue
+			}
+			if subtle.ConstantTimeCompare([]byte(keyHash), []byte(util.HashToken(k.Key))) == 1 {
+				client = k.Name
+			}
+		}
+		if client == "" {
+			util.Security("服务端接口认证失败: ip=%s path=%s", c.ClientIP(), c.Request.URL.Path)
+			util.Unauthorized(c, "无效的 API Key")
+			c.Abort()
+			return
+		}
+
+		c.Set("api_client", client)
+		c.Next()
+	}
+}
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"time"
+)
+
+// AdminTwoFactor 管理员 TOTP 两步验证配置
+// 绑定流程中先写入密钥（Enabled=false），验证首个验证码后启用
+type AdminTwoFactor struct {
+	AdminID   uint       `gorm:"primaryKey;autoIncrement:false;comment:管理员ID" json:"admin_id"`
+	Secret    string     `gorm:"type:varchar(64);not null;comment:TOTP密钥（base32）" json:"-"`
+	Enabled   bool       `gorm:"default:false;not null;comment:是否已启用" json:"enabled"`
+	EnabledAt *time.Time `gorm:"comment:启用时间" json:"enabled_at"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+func (AdminTwoFactor) TableName() string {
+	return "admin_two_factors"
+}
+
+// AdminRecoveryCode 两步验证恢复码，仅保存摘要，每个只能使用一次
+type AdminRecoveryCode struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	AdminID   uint       `gorm:"index;not null;comment:管理员ID" json:"admin_id"`
+	CodeHash  string     `gorm:"type:char(64);uniqueIndex;not null;comment:恢复码摘要" json:"-"`
+	UsedAt    *time.Time `gorm:"comment:使用时间" json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (AdminRecoveryCode) TableName() string {
+	return "admin_recovery_codes"
+}
@@ -0,0 +1,46 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// 审计目标类型
+const (
+	AuditTargetAdmin       = "admin"
+	AuditTargetUser        = "user"
+	AuditTargetRole        = "role"
+	AuditTargetWallet      = "wallet" // 目标ID为用户ID
+	AuditTargetLevel       = "level"  // 用户等级和经验，目标ID为用户ID
+	AuditTargetRedeemBatch = "redeem_batch"
+	AuditTargetItem        = "item"
+	AuditTargetInventory   = "inventory" // 玩家背包道具数量，目标ID为用户ID
+	AuditTargetShopSku     = "shop_sku"
+	AuditTargetPayment     = "payment_order"
+	AuditTargetMail        = "mail"
+)
+
+// AdminAuditLog 管理员操作审计日志（只追加不修改）
+// Before/After 只保留发生变化的字段，新建时 Before 为空，删除时 After 为空
+type AdminAuditLog struct {
+	ID         uint            `gorm:"primaryKey" json:"id"`
+	AdminID    uint            `gorm:"index;not null;comment:操作管理员ID" json:"admin_id"`
+	AdminName  string          `gorm:"type:varchar(50);comment:操作管理员用户名" json:"admin_name"`
+	Action     string          `gorm:"type:varchar(100);index;not null;comment:操作" json:"action"`
+	Method     string          `gorm:"type:varchar(10);comment:请求方法" json:"method"`
+	Path       string          `gorm:"type:varchar(255);comment:请求路径" json:"path"`
+	TargetType string          `gorm:"type:varchar(50);index:idx_audit_target,priority:1;comment:目标类型" json:"target_type"`
+	TargetID   string          `gorm:"type:varchar(64);index:idx_audit_target,priority:2;comment:目标ID" json:"target_id"`
+	Before     json.RawMessage `gorm:"type:json;comment:变更前" json:"before" swaggertype:"object"`
+	After      json.RawMessage `gorm:"type:json;comment:变更后" json:"after" swaggertype:"object"`
+	Request    json.RawMessage `gorm:"type:json;comment:请求参数（已脱敏）" json:"request" swaggertype:"object"`
+	Success    bool            `gorm:"not null;comment:是否成功" json:"success"`
+	Message    string          `gorm:"type:varchar(255);comment:响应消息" json:"message"`
+	ClientIP   string          `gorm:"type:varchar(64);comment:客户端IP" json:"client_ip"`
+	RequestID  string          `gorm:"type:varchar(64);index;comment:请求ID" json:"request_id"`
+	CreatedAt  time.Time       `gorm:"index" json:"created_at"`
+}
+
+func (AdminAuditLog) TableName() string {
+	return "admin_audit_logs"
+}
@@ -0,0 +1,23 @@
+package model
+
+import (
+	"time"
+)
+
+// UserCheckin 签到记录，每个用户每个自然日（按配置时区）一条
+type UserCheckin struct {
+	ID                    uint        `gorm:"primaryKey" json:"id"`
+	UserID                uint        `gorm:"uniqueIndex:idx_user_checkin_date,priority:1;not null;comment:用户ID" json:"user_id"`
+	CheckinDate           string      `gorm:"type:varchar(10);uniqueIndex:idx_user_checkin_date,priority:2;not null;comment:签到日期 YYYY-MM-DD" json:"checkin_date"`
+	Streak                int         `gorm:"not null;comment:截至当天的连续签到天数" json:"streak"`
+	Makeup                bool        `gorm:"not null;default:false;comment:是否补签" json:"makeup"`
+	MakeupCost            Money       `gorm:"type:decimal(10,2);default:0.00;comment:补签扣除余额" json:"makeup_cost" swaggertype:"number"`
+	RewardExperience      int         `gorm:"default:0;comment:奖励经验" json:"reward_experience"`
+	RewardActivityBalance Money       `gorm:"type:decimal(10,2);default:0.00;comment:奖励活动余额" json:"reward_activity_balance" swaggertype:"number"`
+	RewardItems           RewardItems `gorm:"type:json;comment:奖励道具" json:"reward_items"`
+	CreatedAt             time.Time   `json:"created_at"`
+}
+
+func (UserCheckin) TableName() string {
+	return "user_checkins"
+}
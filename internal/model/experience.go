@@ -0,0 +1,41 @@
+package model
+
+import (
+	"time"
+)
+
+// 经验发放来源
+const (
+	ExperienceSourceServer = "server" // 服务端调用
+	ExperienceSourceAdmin  = "admin"  // 管理员发放
+	ExperienceSourceSystem = "system" // 系统玩法（签到等）
+)
+
+// 经验原因码
+const (
+	ExperienceReasonAdminGrant = "admin_grant" // 管理员发放
+	ExperienceReasonCheckin    = "checkin"     // 每日签到
+	ExperienceReasonRedeem     = "redeem"      // 兑换码奖励
+	ExperienceReasonMail       = "mail"        // 邮件附件
+)
+
+// ExperienceLog 经验流水（只追加不修改），UserProfile.Experience 为累计经验
+type ExperienceLog struct {
+	ID              uint      `gorm:"primaryKey" json:"id"`
+	UserID          uint      `gorm:"index;not null;comment:用户ID" json:"user_id"`
+	Amount          int       `gorm:"not null;comment:发放经验" json:"amount"`
+	ExperienceAfter int       `gorm:"not null;comment:发放后累计经验" json:"experience_after"`
+	LevelBefore     int       `gorm:"not null;comment:发放前等级" json:"level_before"`
+	LevelAfter      int       `gorm:"not null;comment:发放后等级" json:"level_after"`
+	Source          string    `gorm:"type:varchar(20);not null;comment:来源" json:"source"`
+	ReasonCode      string    `gorm:"type:varchar(50);index;not null;comment:原因码" json:"reason_code"`
+	ReferenceID     string    `gorm:"type:varchar(64);index;comment:关联业务ID，同一原因码下不可重复" json:"reference_id"`
+	OperatorID      uint      `gorm:"default:0;comment:操作管理员ID" json:"operator_id"`
+	Operator        string    `gorm:"type:varchar(50);comment:操作方（管理员用户名或服务端调用方）" json:"operator"`
+	Remark          string    `gorm:"type:varchar(255);comment:备注" json:"remark"`
+	CreatedAt       time.Time `gorm:"index" json:"created_at"`
+}
+
+func (ExperienceLog) TableName() string {
+	return "experience_logs"
+}
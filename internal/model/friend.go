@@ -0,0 +1,53 @@
+package model
+
+import (
+	"time"
+)
+
+// 好友申请状态
+const (
+	FriendRequestPending  = "pending"  // 待处理
+	FriendRequestAccepted = "accepted" // 已同意
+	FriendRequestDeclined = "declined" // 已拒绝
+	FriendRequestCanceled = "canceled" // 申请人撤回，或任一方屏蔽对方后自动关闭
+)
+
+// FriendRequest 好友申请，同一对玩家之间同时只有一条待处理的申请
+type FriendRequest struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	FromUserID uint       `gorm:"index:idx_friend_request_from,priority:1;not null;comment:申请人ID" json:"from_user_id"`
+	ToUserID   uint       `gorm:"index:idx_friend_request_to,priority:1;not null;comment:被申请人ID" json:"to_user_id"`
+	Message    string     `gorm:"type:varchar(100);comment:附言" json:"message"`
+	Status     string     `gorm:"type:varchar(20);index:idx_friend_request_from,priority:2;index:idx_friend_request_to,priority:2;not null;comment:状态" json:"status"`
+	HandledAt  *time.Time `gorm:"comment:处理时间" json:"handled_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+func (FriendRequest) TableName() string {
+	return "friend_requests"
+}
+
+// Friendship 好友关系，双向存储：A、B 成为好友时写入 (A,B) 和 (B,A) 两条记录
+type Friendship struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"uniqueIndex:idx_friendship,priority:1;not null;comment:用户ID" json:"user_id"`
+	FriendID  uint      `gorm:"uniqueIndex:idx_friendship,priority:2;index;not null;comment:好友ID" json:"friend_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (Friendship) TableName() string {
+	return "friendships"
+}
+
+// UserBlock 屏蔽关系（单向），被屏蔽的玩家不能向屏蔽者发送好友申请和消息
+type UserBlock struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	UserID        uint      `gorm:"uniqueIndex:idx_user_block,priority:1;not null;comment:屏蔽者ID" json:"user_id"`
+	BlockedUserID uint      `gorm:"uniqueIndex:idx_user_block,priority:2;index;not null;comment:被屏蔽者ID" json:"blocked_user_id"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func (UserBlock) TableName() string {
+	return "user_blocks"
+}
@@ -0,0 +1,109 @@
+package model
+
+import (
+	"time"
+)
+
+// 道具分类
+const (
+	ItemCategoryConsumable = "consumable" // 消耗品
+	ItemCategoryEquipment  = "equipment"  // 装备
+	ItemCategoryMaterial   = "material"   // 材料
+	ItemCategoryCosmetic   = "cosmetic"   // 外观
+	ItemCategoryGift       = "gift"       // 礼包
+)
+
+// ItemCategories 全部道具分类
+var ItemCategories = []string{
+	ItemCategoryConsumable,
+	ItemCategoryEquipment,
+	ItemCategoryMaterial,
+	ItemCategoryCosmetic,
+	ItemCategoryGift,
+}
+
+// 道具状态
+const (
+	ItemStatusDisabled = 0 // 停用：不能再发放，已持有的不受影响
+	ItemStatusActive   = 1 // 启用
+)
+
+// Item 道具目录
+type Item struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	Code         string    `gorm:"type:varchar(50);uniqueIndex;not null;comment:道具标识" json:"code"`
+	Name         string    `gorm:"type:varchar(100);not null;comment:名称" json:"name"`
+	Description  string    `gorm:"type:varchar(500);comment:描述" json:"description"`
+	Icon         string    `gorm:"type:varchar(255);comment:图标" json:"icon"`
+	Category     string    `gorm:"type:varchar(30);index;not null;comment:分类" json:"category"`
+	Stackable    bool      `gorm:"not null;default:true;comment:是否可堆叠" json:"stackable"`
+	HoldLimit    int       `gorm:"not null;default:0;comment:单个玩家持有数量上限，0表示不限" json:"hold_limit"`
+	ValidSeconds int       `gorm:"not null;default:0;comment:获得后的有效期（秒），0表示永久" json:"valid_seconds"`
+	Status       int       `gorm:"type:tinyint;default:1;comment:状态" json:"status"` // 1:启用 0:停用
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+func (Item) TableName() string {
+	return "items"
+}
+
+// UserItem 玩家背包中的一组道具
+// 可堆叠道具按过期时间合并为一组，不可堆叠道具每件一行（数量为1）
+type UserItem struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"index:idx_user_item,priority:1;not null;comment:用户ID" json:"user_id"`
+	ItemID    uint       `gorm:"index:idx_user_item,priority:2;not null;comment:道具ID" json:"item_id"`
+	Quantity  int        `gorm:"not null;comment:数量" json:"quantity"`
+	ExpireAt  *time.Time `gorm:"index;comment:过期时间，为空表示永久" json:"expire_at"`
+	Item      *Item      `gorm:"foreignKey:ItemID" json:"item,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+func (UserItem) TableName() string {
+	return "user_items"
+}
+
+// 道具来源
+const (
+	ItemSourceServer = "server" // 服务端调用
+	ItemSourceAdmin  = "admin"  // 管理员操作
+	ItemSourceSystem = "system" // 系统玩法（签到、兑换码等）
+)
+
+// 道具变动原因码
+const (
+	ItemReasonAdminGrant   = "admin_grant"   // 管理员发放
+	ItemReasonAdminConsume = "admin_consume" // 管理员扣除
+)
+
+// ItemLog 道具变动流水（只追加不修改），用于追溯道具来源和去向
+type ItemLog struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	UserID        uint      `gorm:"index;not null;comment:用户ID" json:"user_id"`
+	ItemID        uint      `gorm:"index;not null;comment:道具ID" json:"item_id"`
+	Change        int       `gorm:"not null;comment:变动数量，获得为正、消耗为负" json:"change"`
+	QuantityAfter int       `gorm:"not null;comment:变动后持有数量（不含已过期）" json:"quantity_after"`
+	Source        string    `gorm:"type:varchar(20);not null;comment:来源" json:"source"`
+	ReasonCode    string    `gorm:"type:varchar(50);index;not null;comment:原因码" json:"reason_code"`
+	ReferenceID   string    `gorm:"type:varchar(64);index;comment:关联业务ID，同一原因码下同一道具不可重复" json:"reference_id"`
+	OperatorID    uint      `gorm:"default:0;comment:操作管理员ID" json:"operator_id"`
+	Operator      string    `gorm:"type:varchar(50);comment:操作方（管理员用户名或服务端调用方）" json:"operator"`
+	Remark        string    `gorm:"type:varchar(255);comment:备注" json:"remark"`
+	CreatedAt     time.Time `gorm:"index" json:"created_at"`
+}
+
+func (ItemLog) TableName() string {
+	return "item_logs"
+}
+
+// IsValidItemCategory 道具分类是否合法
+func IsValidItemCategory(category string) bool {
+	for _, c := range ItemCategories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
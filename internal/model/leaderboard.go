@@ -0,0 +1,43 @@
+package model
+
+import (
+	"time"
+)
+
+// 排行榜计分方式
+const (
+	LeaderboardModeBest   = "best"   // 取最好成绩
+	LeaderboardModeSum    = "sum"    // 累加
+	LeaderboardModeLatest = "latest" // 取最新
+)
+
+// 排行榜周期
+const (
+	LeaderboardPeriodAll    = "all"    // 永久，不重置
+	LeaderboardPeriodDaily  = "daily"  // 每日零点重置
+	LeaderboardPeriodWeekly = "weekly" // 每周一零点重置
+	LeaderboardPeriodSeason = "season" // 按赛季重置
+)
+
+// 排行榜数据来源
+const (
+	LeaderboardSourceLevel      = "level"      // 等级
+	LeaderboardSourceExperience = "experience" // 经验：sum 模式累加获得的经验，其他模式取累计经验
+)
+
+// LeaderboardArchive 周期结束时归档的最终名次
+type LeaderboardArchive struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Board       string    `gorm:"type:varchar(50);uniqueIndex:idx_leaderboard_archive,priority:1;not null;comment:榜单标识" json:"board"`
+	Period      string    `gorm:"type:varchar(20);uniqueIndex:idx_leaderboard_archive,priority:2;not null;comment:周期" json:"period"`
+	Rank        int       `gorm:"uniqueIndex:idx_leaderboard_archive,priority:3;not null;comment:名次" json:"rank"`
+	UserID      uint      `gorm:"index;not null;comment:用户ID" json:"user_id"`
+	Score       int64     `gorm:"not null;comment:分数" json:"score"`
+	PeriodStart time.Time `gorm:"comment:周期开始时间" json:"period_start"`
+	PeriodEnd   time.Time `gorm:"comment:周期结束时间" json:"period_end"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func (LeaderboardArchive) TableName() string {
+	return "leaderboard_archives"
+}
@@ -0,0 +1,125 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// 邮件发送对象
+const (
+	MailTargetUsers   = "users"   // 指定玩家，发送时直接投递
+	MailTargetSegment = "segment" // 按条件筛选的玩家，玩家打开邮箱时按当时的等级和注册时间判断，判断结果不再改变
+	MailTargetAll     = "all"     // 全服玩家，玩家打开邮箱时投递
+)
+
+// MailSegment 分群条件，零值字段不参与筛选
+type MailSegment struct {
+	MinLevel         int        `json:"min_level,omitempty"`
+	MaxLevel         int        `json:"max_level,omitempty"`
+	RegisteredAfter  *time.Time `json:"registered_after,omitempty"`
+	RegisteredBefore *time.Time `json:"registered_before,omitempty"`
+}
+
+// Match 玩家是否满足分群条件
+func (s MailSegment) Match(level int, registerTime time.Time) bool {
+	if s.MinLevel > 0 && level < s.MinLevel {
+		return false
+	}
+	if s.MaxLevel > 0 && level > s.MaxLevel {
+		return false
+	}
+	if s.RegisteredAfter != nil && registerTime.Before(*s.RegisteredAfter) {
+		return false
+	}
+	if s.RegisteredBefore != nil && !registerTime.Before(*s.RegisteredBefore) {
+		return false
+	}
+	return true
+}
+
+// IsEmpty 是否未设置任何条件
+func (s MailSegment) IsEmpty() bool {
+	return s.MinLevel <= 0 && s.MaxLevel <= 0 && s.RegisteredAfter == nil && s.RegisteredBefore == nil
+}
+
+// Value 实现 driver.Valuer
+func (s MailSegment) Value() (driver.Value, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan 实现 sql.Scanner
+func (s *MailSegment) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*s = MailSegment{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, s)
+	case string:
+		return json.Unmarshal([]byte(v), s)
+	default:
+		return fmt.Errorf("无法将 %T 转换为分群条件", value)
+	}
+}
+
+// Mail 管理员发送的邮件，每个收件人在 user_mails 中有一条投递记录
+// 全服和分群邮件不在发送时展开，玩家打开邮箱时才为其创建投递记录，只投递给发送时已注册的玩家
+type Mail struct {
+	ID             uint         `gorm:"primaryKey" json:"id"`
+	Title          string       `gorm:"type:varchar(100);not null;comment:标题" json:"title"`
+	Content        string       `gorm:"type:text;comment:正文" json:"content"`
+	Sender         string       `gorm:"type:varchar(50);not null;comment:发件人显示名称" json:"sender"`
+	Attachments    RewardBundle `gorm:"type:json;comment:附件" json:"attachments"`
+	TargetType     string       `gorm:"type:varchar(20);index:idx_mail_target_expire,priority:1;not null;comment:发送对象 users/segment/all" json:"target_type"`
+	Segment        MailSegment  `gorm:"type:json;comment:分群条件" json:"segment"`
+	RecipientCount int          `gorm:"default:0;comment:指定玩家数量，全服和分群邮件为0" json:"recipient_count"`
+	ExpireAt       time.Time    `gorm:"index:idx_mail_target_expire,priority:2;not null;comment:过期时间" json:"expire_at"`
+	CreatedBy      uint         `gorm:"comment:发送管理员ID" json:"created_by"`
+	CreatedAt      time.Time    `gorm:"index" json:"created_at"`
+}
+
+func (Mail) TableName() string {
+	return "mails"
+}
+
+// IsBroadcast 是否为按需投递的全服或分群邮件
+func (m *Mail) IsBroadcast() bool {
+	return m.TargetType == MailTargetAll || m.TargetType == MailTargetSegment
+}
+
+// UserMail 邮件投递记录，同一封邮件每个玩家只有一条；玩家删除邮件时只做标记，避免全服邮件被重新投递。
+// 不满足分群条件的玩家同样记录一条（Skipped 且 Deleted），之后打开邮箱时不再重复筛选
+type UserMail struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	UserID         uint       `gorm:"uniqueIndex:idx_user_mail,priority:1;index:idx_user_mail_box,priority:1;not null;comment:用户ID" json:"user_id"`
+	MailID         uint       `gorm:"uniqueIndex:idx_user_mail,priority:2;index;not null;comment:邮件ID" json:"mail_id"`
+	HasAttachments bool       `gorm:"not null;default:false;comment:是否有附件" json:"has_attachments"`
+	ExpireAt       time.Time  `gorm:"index:idx_user_mail_box,priority:3;not null;comment:过期时间" json:"expire_at"`
+	ReadAt         *time.Time `gorm:"comment:阅读时间" json:"read_at"`
+	ClaimedAt      *time.Time `gorm:"comment:附件领取时间" json:"claimed_at"`
+	Deleted        bool       `gorm:"index:idx_user_mail_box,priority:2;not null;default:false;comment:玩家是否已删除" json:"deleted"`
+	Skipped        bool       `gorm:"not null;default:false;comment:不满足分群条件，未投递" json:"-"`
+	Mail           *Mail      `gorm:"foreignKey:MailID" json:"mail,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+func (UserMail) TableName() string {
+	return "user_mails"
+}
+
+// IsExpired 邮件是否已过期
+func (m *UserMail) IsExpired(now time.Time) bool {
+	return !now.Before(m.ExpireAt)
+}
+
+// Claimable 附件是否可以领取
+func (m *UserMail) Claimable(now time.Time) bool {
+	return m.HasAttachments && m.ClaimedAt == nil && !m.Deleted && !m.IsExpired(now)
+}
@@ -0,0 +1,212 @@
+package model
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Money 定点金额，以分为单位的整数存储，对应数据库 decimal(10,2)
+// 数据库读写和 JSON 编解码均按十进制文本处理，不经过浮点数，保证精确往返
+type Money int64
+
+const moneyScale = 100
+
+// MaxMoney decimal(10,2) 可存储的最大金额 99999999.99
+const MaxMoney Money = 9999999999
+
+var (
+	ErrInvalidMoney  = errors.New("无效的金额")
+	ErrMoneyOverflow = errors.New("金额超出范围")
+)
+
+// NewMoneyFromCents 由分构造金额
+func NewMoneyFromCents(cents int64) Money {
+	return Money(cents)
+}
+
+// ParseMoney 解析十进制金额文本，如 "12.34"、"-0.5"、"100"，最多两位小数
+func ParseMoney(s string) (Money, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, ErrInvalidMoney
+	}
+
+	negative := false
+	switch s[0] {
+	case '-':
+		negative = true
+		s = s[1:]
+	case '+':
+		s = s[1:]
+	}
+
+	intPart, fracPart := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		intPart, fracPart = s[:i], s[i+1:]
+	}
+	if intPart == "" && fracPart == "" {
+		return 0, ErrInvalidMoney
+	}
+	if intPart == "" {
+		intPart = "0"
+	}
+	// 符号只能出现在最前面，整数和小数部分只能是数字
+	if !isDigits(intPart) || !isDigits(fracPart) {
+		return 0, ErrInvalidMoney
+	}
+
+	// 超过两位的小数只允许是0
+	if len(fracPart) > 2 {
+		if strings.Trim(fracPart[2:], "0") != "" {
+			return 0, fmt.Errorf("%w: 最多保留两位小数", ErrInvalidMoney)
+		}
+		fracPart = fracPart[:2]
+	}
+	for len(fracPart) < 2 {
+		fracPart += "0"
+	}
+
+	units, err := strconv.ParseInt(intPart, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: 金额超出范围", ErrInvalidMoney)
+	}
+	cents, err := strconv.ParseInt(fracPart, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidMoney
+	}
+	if units > (math.MaxInt64-cents)/moneyScale {
+		return 0, fmt.Errorf("%w: 金额超出范围", ErrInvalidMoney)
+	}
+
+	v := units*moneyScale + cents
+	if negative {
+		v = -v
+	}
+	return Money(v), nil
+}
+
+// Cents 返回以分为单位的整数
+func (m Money) Cents() int64 {
+	return int64(m)
+}
+
+// Add 加法，结果溢出 int64 时返回 ErrMoneyOverflow
+func (m Money) Add(o Money) (Money, error) {
+	r := m + o
+	if (o > 0 && r < m) || (o < 0 && r > m) {
+		return 0, ErrMoneyOverflow
+	}
+	return r, nil
+}
+
+// Sub 减法，结果溢出 int64 时返回 ErrMoneyOverflow
+func (m Money) Sub(o Money) (Money, error) {
+	r := m - o
+	if (o > 0 && r > m) || (o < 0 && r < m) {
+		return 0, ErrMoneyOverflow
+	}
+	return r, nil
+}
+
+// Mul 乘以整数数量（如单价 × 数量），结果溢出 int64 时返回 ErrMoneyOverflow
+func (m Money) Mul(n int64) (Money, error) {
+	if m == 0 || n == 0 {
+		return 0, nil
+	}
+	r := m * Money(n)
+	if r/Money(n) != m || (m == -1 && n == math.MinInt64) || (n == -1 && m == math.MinInt64) {
+		return 0, ErrMoneyOverflow
+	}
+	return r, nil
+}
+
+// InRange 是否在 decimal(10,2) 的存储范围内
+func (m Money) InRange() bool {
+	return m >= -MaxMoney && m <= MaxMoney
+}
+
+func (m Money) IsPositive() bool {
+	return m > 0
+}
+
+func (m Money) IsNegative() bool {
+	return m < 0
+}
+
+// String 格式化为两位小数的十进制文本
+func (m Money) String() string {
+	v := int64(m)
+	sign := ""
+	if v < 0 {
+		sign = "-"
+		v = -v
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, v/moneyScale, v%moneyScale)
+}
+
+// Value 实现 driver.Valuer，以十进制文本写入数据库
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+// Scan 实现 sql.Scanner
+func (m *Money) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*m = 0
+		return nil
+	case []byte:
+		return m.scanString(string(v))
+	case string:
+		return m.scanString(v)
+	case int64:
+		*m = Money(v * moneyScale)
+		return nil
+	case float64:
+		*m = Money(math.Round(v * moneyScale))
+		return nil
+	default:
+		return fmt.Errorf("无法将 %T 转换为金额", value)
+	}
+}
+
+func (m *Money) scanString(s string) error {
+	v, err := ParseMoney(s)
+	if err != nil {
+		return err
+	}
+	*m = v
+	return nil
+}
+
+// MarshalJSON 输出为 JSON 数字，如 12.34
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON 接受 JSON 数字或字符串，按十进制文本解析
+func (m *Money) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "null" {
+		return nil
+	}
+	v, err := ParseMoney(s)
+	if err != nil {
+		return err
+	}
+	*m = v
+	return nil
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
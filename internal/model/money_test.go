@@ -0,0 +1,158 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Money
+	}{
+		{"0", 0},
+		{"100", 10000},
+		{"12.34", 1234},
+		{"12.3", 1230},
+		{"12.", 1200},
+		{".5", 50},
+		{"-0.5", -50},
+		{"+1.05", 105},
+		{"1.2300", 123},
+		{" 7.01 ", 701},
+		{"99999999.99", MaxMoney},
+	}
+	for _, tt := range tests {
+		got, err := ParseMoney(tt.in)
+		if err != nil {
+			t.Errorf("ParseMoney(%q) error: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseMoney(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseMoneyInvalid(t *testing.T) {
+	for _, in := range []string{
+		"", ".", "-", "+", "abc", "1.2.3", "1.234", "1,00", "1e2",
+		"1.+5", "1.-5", "-+5", "+-5", "--5", "1.5-", "- 1", "0x10",
+		"92233720368547758.08", "99999999999999999999",
+	} {
+		if got, err := ParseMoney(in); err == nil {
+			t.Errorf("ParseMoney(%q) = %d, want error", in, got)
+		} else if !errors.Is(err, ErrInvalidMoney) {
+			t.Errorf("ParseMoney(%q) error = %v, want ErrInvalidMoney", in, err)
+		}
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	tests := []struct {
+		m    Money
+		want string
+	}{
+		{0, "0.00"},
+		{5, "0.05"},
+		{1234, "12.34"},
+		{-50, "-0.50"},
+		{-1234, "-12.34"},
+		{MaxMoney, "99999999.99"},
+	}
+	for _, tt := range tests {
+		if got := tt.m.String(); got != tt.want {
+			t.Errorf("Money(%d).String() = %q, want %q", tt.m, got, tt.want)
+		}
+		back, err := ParseMoney(tt.want)
+		if err != nil || back != tt.m {
+			t.Errorf("ParseMoney(%q) = %d, %v, want %d", tt.want, back, err, tt.m)
+		}
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	type payload struct {
+		Amount Money `json:"amount"`
+	}
+	for _, m := range []Money{0, 1, 1234, -50, MaxMoney} {
+		data, err := json.Marshal(payload{Amount: m})
+		if err != nil {
+			t.Fatalf("Marshal(%d) error: %v", m, err)
+		}
+		var got payload
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatalf("Unmarshal(%s) error: %v", data, err)
+		}
+		if got.Amount != m {
+			t.Errorf("round trip %d via %s = %d", m, data, got.Amount)
+		}
+	}
+
+	var p payload
+	if err := json.Unmarshal([]byte(`{"amount":"12.34"}`), &p); err != nil || p.Amount != 1234 {
+		t.Errorf("Unmarshal string amount = %d, %v, want 1234", p.Amount, err)
+	}
+	if err := json.Unmarshal([]byte(`{"amount":0.1}`), &p); err != nil || p.Amount != 10 {
+		t.Errorf("Unmarshal 0.1 = %d, %v, want 10", p.Amount, err)
+	}
+	if err := json.Unmarshal([]byte(`{"amount":1.001}`), &p); err == nil {
+		t.Error("Unmarshal 1.001 should fail")
+	}
+}
+
+func TestMoneyScan(t *testing.T) {
+	var m Money
+	if err := m.Scan([]byte("12.34")); err != nil || m != 1234 {
+		t.Errorf("Scan([]byte) = %d, %v, want 1234", m, err)
+	}
+	if err := m.Scan(int64(3)); err != nil || m != 300 {
+		t.Errorf("Scan(int64) = %d, %v, want 300", m, err)
+	}
+	if err := m.Scan(nil); err != nil || m != 0 {
+		t.Errorf("Scan(nil) = %d, %v, want 0", m, err)
+	}
+	v, err := Money(-1234).Value()
+	if err != nil || v != "-12.34" {
+		t.Errorf("Value() = %v, %v, want -12.34", v, err)
+	}
+}
+
+func TestMoneyArithmeticOverflow(t *testing.T) {
+	if got, err := Money(100).Add(250); err != nil || got != 350 {
+		t.Errorf("Add = %d, %v, want 350", got, err)
+	}
+	if got, err := Money(100).Sub(250); err != nil || got != -150 {
+		t.Errorf("Sub = %d, %v, want -150", got, err)
+	}
+	if got, err := Money(199).Mul(3); err != nil || got != 597 {
+		t.Errorf("Mul = %d, %v, want 597", got, err)
+	}
+
+	if _, err := Money(math.MaxInt64).Add(1); !errors.Is(err, ErrMoneyOverflow) {
+		t.Errorf("Add overflow error = %v", err)
+	}
+	if _, err := Money(math.MinInt64).Add(-1); !errors.Is(err, ErrMoneyOverflow) {
+		t.Errorf("Add underflow error = %v", err)
+	}
+	if _, err := Money(math.MinInt64).Sub(1); !errors.Is(err, ErrMoneyOverflow) {
+		t.Errorf("Sub underflow error = %v", err)
+	}
+	if _, err := Money(math.MaxInt64 / 2).Mul(3); !errors.Is(err, ErrMoneyOverflow) {
+		t.Errorf("Mul overflow error = %v", err)
+	}
+	if _, err := Money(math.MinInt64).Mul(-1); !errors.Is(err, ErrMoneyOverflow) {
+		t.Errorf("Mul MinInt64 * -1 error = %v", err)
+	}
+}
+
+func TestMoneyInRange(t *testing.T) {
+	if !MaxMoney.InRange() || !(-MaxMoney).InRange() {
+		t.Error("MaxMoney should be in range")
+	}
+	if (MaxMoney + 1).InRange() || (-MaxMoney - 1).InRange() {
+		t.Error("MaxMoney+1 should be out of range")
+	}
+}
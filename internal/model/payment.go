@@ -0,0 +1,78 @@
+package model
+
+import (
+	"time"
+)
+
+// 支付订单状态
+const (
+	PaymentStatusPending   = "pending"   // 待支付
+	PaymentStatusPaid      = "paid"      // 已支付，余额已入账
+	PaymentStatusFailed    = "failed"    // 支付失败或超时关闭
+	PaymentStatusRefunding = "refunding" // 退款中，余额已扣回，等待渠道退款结果
+	PaymentStatusRefunded  = "refunded"  // 已退款，余额已扣回
+)
+
+// PaymentProviderMock 内置模拟支付渠道，用于本地联调和测试
+const PaymentProviderMock = "mock"
+
+// paymentTransitions 订单状态机：超时关闭的订单仍可能收到渠道的支付成功回调，允许 failed -> paid；
+// 管理员退款先进入 refunding，渠道退款失败时回到 paid，渠道发起的退款直接 paid -> refunded
+var paymentTransitions = map[string][]string{
+	PaymentStatusPending:   {PaymentStatusPaid, PaymentStatusFailed},
+	PaymentStatusFailed:    {PaymentStatusPaid},
+	PaymentStatusPaid:      {PaymentStatusRefunding, PaymentStatusRefunded},
+	PaymentStatusRefunding: {PaymentStatusRefunded, PaymentStatusPaid},
+}
+
+// CanTransitPayment 订单能否从 from 状态变更为 to 状态
+func CanTransitPayment(from, to string) bool {
+	for _, s := range paymentTransitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// PaymentOrder 充值订单，支付成功后按订单金额为余额入账
+type PaymentOrder struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	OrderNo      string     `gorm:"type:varchar(32);uniqueIndex;not null;comment:订单号" json:"order_no"`
+	UserID       uint       `gorm:"index;not null;comment:用户ID" json:"user_id"`
+	Provider     string     `gorm:"type:varchar(20);not null;comment:支付渠道" json:"provider"`
+	Amount       Money      `gorm:"type:decimal(10,2);not null;comment:充值金额" json:"amount" swaggertype:"number"`
+	Status       string     `gorm:"type:varchar(20);index:idx_payment_status_expire,priority:1;not null;comment:状态" json:"status"`
+	TradeNo      string     `gorm:"type:varchar(64);index;comment:渠道交易号" json:"trade_no"`
+	ExpireAt     time.Time  `gorm:"index:idx_payment_status_expire,priority:2;comment:支付截止时间" json:"expire_at"`
+	PaidAt       *time.Time `gorm:"comment:支付时间" json:"paid_at"`
+	FailReason   string     `gorm:"type:varchar(255);comment:失败原因" json:"fail_reason"`
+	RefundedAt   *time.Time `gorm:"comment:退款时间" json:"refunded_at"`
+	RefundReason string     `gorm:"type:varchar(255);comment:退款原因" json:"refund_reason"`
+	RefundedBy   uint       `gorm:"default:0;comment:退款管理员ID，渠道发起的退款为0" json:"refunded_by"`
+	ClientIP     string     `gorm:"type:varchar(64);comment:下单IP" json:"client_ip"`
+	CreatedAt    time.Time  `gorm:"index" json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+func (PaymentOrder) TableName() string {
+	return "payment_orders"
+}
+
+// PaymentCallbackLog 支付回调记录，包括签名校验失败和重复的回调，用于对账和排查
+type PaymentCallbackLog struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Provider  string    `gorm:"type:varchar(20);not null;comment:支付渠道" json:"provider"`
+	OrderNo   string    `gorm:"type:varchar(32);index;comment:订单号" json:"order_no"`
+	TradeNo   string    `gorm:"type:varchar(64);comment:渠道交易号" json:"trade_no"`
+	Status    string    `gorm:"type:varchar(20);comment:回调通知的状态" json:"status"`
+	Verified  bool      `gorm:"not null;default:false;comment:签名是否有效" json:"verified"`
+	Result    string    `gorm:"type:varchar(255);comment:处理结果" json:"result"`
+	Payload   string    `gorm:"type:text;comment:回调原文" json:"payload"`
+	ClientIP  string    `gorm:"type:varchar(64);comment:回调IP" json:"client_ip"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+}
+
+func (PaymentCallbackLog) TableName() string {
+	return "payment_callback_logs"
+}
@@ -0,0 +1,70 @@
+package model
+
+import (
+	"time"
+)
+
+// 兑换码类型
+const (
+	RedeemTypeSingle = "single" // 一码一用：批量生成，每个码只能被兑换一次
+	RedeemTypeMulti  = "multi"  // 通用码：同一个码可被多个玩家兑换
+)
+
+// 兑换批次状态
+const (
+	RedeemBatchDisabled = 0 // 停用
+	RedeemBatchActive   = 1 // 启用
+)
+
+// RedeemBatch 兑换码批次，同一批次的码共用奖励、有效期和每人兑换上限
+type RedeemBatch struct {
+	ID           uint         `gorm:"primaryKey" json:"id"`
+	Name         string       `gorm:"type:varchar(100);not null;comment:批次名称" json:"name"`
+	Type         string       `gorm:"type:varchar(10);not null;comment:类型 single/multi" json:"type"`
+	CodeCount    int          `gorm:"not null;comment:兑换码数量" json:"code_count"`
+	MaxUses      int          `gorm:"not null;default:1;comment:每个码可兑换次数，0表示不限" json:"max_uses"`
+	PerUserLimit int          `gorm:"not null;default:1;comment:每个玩家在本批次的兑换次数上限" json:"per_user_limit"`
+	Rewards      RewardBundle `gorm:"type:json;comment:奖励" json:"rewards"`
+	StartAt      *time.Time   `gorm:"comment:生效时间，为空表示立即生效" json:"start_at"`
+	EndAt        *time.Time   `gorm:"comment:过期时间，为空表示永不过期" json:"end_at"`
+	Status       int          `gorm:"type:tinyint;default:1;comment:状态" json:"status"` // 1:启用 0:停用
+	RedeemCount  int64        `gorm:"not null;default:0;comment:已兑换次数" json:"redeem_count"`
+	Remark       string       `gorm:"type:varchar(255);comment:备注" json:"remark"`
+	CreatedBy    uint         `gorm:"default:0;comment:创建管理员ID" json:"created_by"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+}
+
+func (RedeemBatch) TableName() string {
+	return "redeem_batches"
+}
+
+// RedeemCode 兑换码
+type RedeemCode struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	BatchID   uint      `gorm:"index;not null;comment:批次ID" json:"batch_id"`
+	Code      string    `gorm:"type:varchar(32);uniqueIndex;not null;comment:兑换码" json:"code"`
+	MaxUses   int       `gorm:"not null;default:1;comment:可兑换次数，0表示不限" json:"max_uses"`
+	UsedCount int       `gorm:"not null;default:0;comment:已兑换次数" json:"used_count"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (RedeemCode) TableName() string {
+	return "redeem_codes"
+}
+
+// RedeemRecord 兑换记录，同一玩家对同一个码只能兑换一次
+type RedeemRecord struct {
+	ID        uint         `gorm:"primaryKey" json:"id"`
+	BatchID   uint         `gorm:"index:idx_redeem_batch_user,priority:1;not null;comment:批次ID" json:"batch_id"`
+	CodeID    uint         `gorm:"uniqueIndex:idx_redeem_code_user,priority:1;not null;comment:兑换码ID" json:"code_id"`
+	Code      string       `gorm:"type:varchar(32);not null;comment:兑换码" json:"code"`
+	UserID    uint         `gorm:"index:idx_redeem_batch_user,priority:2;uniqueIndex:idx_redeem_code_user,priority:2;not null;comment:用户ID" json:"user_id"`
+	Rewards   RewardBundle `gorm:"type:json;comment:发放的奖励" json:"rewards"`
+	ClientIP  string       `gorm:"type:varchar(64);comment:兑换IP" json:"client_ip"`
+	CreatedAt time.Time    `gorm:"index" json:"created_at"`
+}
+
+func (RedeemRecord) TableName() string {
+	return "redeem_records"
+}
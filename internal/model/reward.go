@@ -0,0 +1,80 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// RewardBundle 奖励包，兑换码、签到等玩法统一使用，数据库中以 JSON 存储
+type RewardBundle struct {
+	Experience      int         `json:"experience"`
+	Balance         Money       `json:"balance" swaggertype:"number"`
+	ActivityBalance Money       `json:"activity_balance" swaggertype:"number"`
+	Items           RewardItems `json:"items,omitempty"`
+}
+
+// RewardItem 奖励包中的道具
+type RewardItem struct {
+	ItemID   uint `json:"item_id"`
+	Quantity int  `json:"quantity"`
+}
+
+// RewardItems 奖励道具列表，可单独作为 JSON 列存储
+type RewardItems []RewardItem
+
+// IsEmpty 是否不包含任何奖励
+func (b RewardBundle) IsEmpty() bool {
+	return b.Experience <= 0 && !b.Balance.IsPositive() && !b.ActivityBalance.IsPositive() && len(b.Items) == 0
+}
+
+// Value 实现 driver.Valuer
+func (b RewardBundle) Value() (driver.Value, error) {
+	data, err := json.Marshal(b)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan 实现 sql.Scanner
+func (b *RewardBundle) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*b = RewardBundle{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, b)
+	case string:
+		return json.Unmarshal([]byte(v), b)
+	default:
+		return fmt.Errorf("无法将 %T 转换为奖励包", value)
+	}
+}
+
+// Value 实现 driver.Valuer
+func (items RewardItems) Value() (driver.Value, error) {
+	if items == nil {
+		items = RewardItems{}
+	}
+	data, err := json.Marshal(items)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan 实现 sql.Scanner
+func (items *RewardItems) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*items = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, items)
+	case string:
+		return json.Unmarshal([]byte(v), items)
+	default:
+		return fmt.Errorf("无法将 %T 转换为奖励道具", value)
+	}
+}
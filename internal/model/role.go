@@ -0,0 +1,146 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// Role 管理员角色，ID 与 Admin.Role 对应；ID 1-3 为内置角色
+type Role struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Name        string    `gorm:"type:varchar(50);uniqueIndex;not null;comment:角色名称" json:"name"`
+	Description string    `gorm:"type:varchar(255);comment:角色描述" json:"description"`
+	BuiltIn     bool      `gorm:"default:false;not null;comment:是否内置角色" json:"built_in"`
+	Permissions []string  `gorm:"-" json:"permissions"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (Role) TableName() string {
+	return "roles"
+}
+
+// RolePermission 角色拥有的权限
+type RolePermission struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	RoleID     uint      `gorm:"uniqueIndex:idx_role_permission,priority:1;not null;comment:角色ID" json:"role_id"`
+	Permission string    `gorm:"type:varchar(64);uniqueIndex:idx_role_permission,priority:2;not null;comment:权限标识" json:"permission"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (RolePermission) TableName() string {
+	return "role_permissions"
+}
+
+// 权限标识，格式为 "资源:操作"；"*" 表示全部权限，"资源:*" 表示该资源的全部操作
+const (
+	PermAll = "*"
+
+	PermUserView          = "user:view"           // 查看玩家
+	PermUserEdit          = "user:edit"           // 修改玩家资料
+	PermUserBan           = "user:ban"            // 封禁/解封玩家
+	PermUserRevokeSession = "user:revoke_session" // 强制玩家下线
+	PermUserUnlock        = "user:unlock"         // 解除玩家登录锁定
+	PermUserExperience    = "user:experience"     // 发放玩家经验
+
+	PermWalletView   = "wallet:view"   // 查看钱包流水
+	PermWalletAdjust = "wallet:adjust" // 调整玩家余额
+
+	PermAdminView   = "admin:view"   // 查看管理员
+	PermAdminCreate = "admin:create" // 创建管理员
+	PermAdminManage = "admin:manage" // 管理管理员（吊销会话、解除锁定、重置两步验证）
+
+	PermRoleManage = "role:manage" // 管理角色和权限
+
+	PermRedeemView   = "redeem:view"   // 查看兑换码及兑换记录
+	PermRedeemManage = "redeem:manage" // 生成和停用兑换码
+
+	PermItemView   = "item:view"   // 查看道具目录、玩家背包和道具流水
+	PermItemManage = "item:manage" // 创建和修改道具
+	PermItemGrant  = "item:grant"  // 发放和扣除玩家道具
+
+	PermShopView   = "shop:view"   // 查看商品和购买记录
+	PermShopManage = "shop:manage" // 创建和修改商品
+
+	PermPaymentView   = "payment:view"   // 查看充值订单和回调记录
+	PermPaymentRefund = "payment:refund" // 充值订单退款
+
+	PermMailView = "mail:view" // 查看已发送的邮件
+	PermMailSend = "mail:send" // 发送邮件（含附件）
+)
+
+// PermissionDef 权限定义，用于权限列表展示和校验
+type PermissionDef struct {
+	Code        string `json:"code"`
+	Description string `json:"description"`
+}
+
+// Permissions 系统支持的全部权限
+var Permissions = []PermissionDef{
+	{PermUserView, "查看玩家"},
+	{PermUserEdit, "修改玩家资料"},
+	{PermUserBan, "封禁/解封玩家"},
+	{PermUserRevokeSession, "强制玩家下线"},
+	{PermUserUnlock, "解除玩家登录锁定"},
+	{PermUserExperience, "发放玩家经验"},
+	{PermWalletView, "查看钱包流水"},
+	{PermWalletAdjust, "调整玩家余额"},
+	{PermAdminView, "查看管理员"},
+	{PermAdminCreate, "创建管理员"},
+	{PermAdminManage, "管理管理员"},
+	{PermRoleManage, "管理角色和权限"},
+	{PermRedeemView, "查看兑换码"},
+	{PermRedeemManage, "管理兑换码"},
+	{PermItemView, "查看道具"},
+	{PermItemManage, "管理道具"},
+	{PermItemGrant, "发放和扣除道具"},
+	{PermShopView, "查看商城"},
+	{PermShopManage, "管理商城商品"},
+	{PermPaymentView, "查看充值订单"},
+	{PermPaymentRefund, "充值订单退款"},
+	{PermMailView, "查看邮件"},
+	{PermMailSend, "发送邮件"},
+}
+
+// DefaultRolePermissions 内置角色首次初始化时的默认权限
+var DefaultRolePermissions = map[AdminRole][]string{
+	RoleSuperAdmin: {PermAll},
+	RoleAdmin: {
+		PermUserView, PermUserEdit, PermUserBan, PermUserRevokeSession, PermUserUnlock, PermUserExperience,
+		PermWalletView, PermWalletAdjust,
+		PermAdminView,
+		PermRedeemView, PermRedeemManage,
+		PermItemView, PermItemManage, PermItemGrant,
+		PermShopView, PermShopManage,
+		PermPaymentView,
+		PermMailView, PermMailSend,
+	},
+	RoleOperator: {PermUserView},
+}
+
+// IsValidPermission 权限标识是否合法（已定义的权限或通配符）
+func IsValidPermission(permission string) bool {
+	if permission == PermAll {
+		return true
+	}
+	for _, def := range Permissions {
+		if def.Code == permission {
+			return true
+		}
+		if resource, ok := strings.CutSuffix(permission, ":*"); ok && strings.HasPrefix(def.Code, resource+":") {
+			return true
+		}
+	}
+	return false
+}
+
+// HasPermission 权限集合是否包含所需权限，支持 "*" 和 "资源:*" 通配
+func HasPermission(granted []string, required string) bool {
+	resource, _, _ := strings.Cut(required, ":")
+	for _, p := range granted {
+		if p == PermAll || p == required || p == resource+":*" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+package model
+
+import (
+	"time"
+)
+
+// 商品状态
+const (
+	ShopSkuOffShelf = 0 // 下架
+	ShopSkuOnSale   = 1 // 上架
+)
+
+// ShopSku 商城商品，每份包含一组道具，可分别以余额和活动余额定价
+type ShopSku struct {
+	ID              uint        `gorm:"primaryKey" json:"id"`
+	Name            string      `gorm:"type:varchar(100);not null;comment:商品名称" json:"name"`
+	Description     string      `gorm:"type:varchar(500);comment:描述" json:"description"`
+	Icon            string      `gorm:"type:varchar(255);comment:图标" json:"icon"`
+	Items           RewardItems `gorm:"type:json;comment:每份包含的道具" json:"items"`
+	Price           Money       `gorm:"type:decimal(10,2);not null;default:0;comment:余额价格，0表示不能用余额购买" json:"price" swaggertype:"number"`
+	ActivityPrice   Money       `gorm:"type:decimal(10,2);not null;default:0;comment:活动余额价格，0表示不能用活动余额购买" json:"activity_price" swaggertype:"number"`
+	Discount        int         `gorm:"not null;default:100;comment:折扣百分比，100表示原价" json:"discount"`
+	DiscountStartAt *time.Time  `gorm:"comment:折扣开始时间，为空表示不限" json:"discount_start_at"`
+	DiscountEndAt   *time.Time  `gorm:"comment:折扣结束时间，为空表示不限" json:"discount_end_at"`
+	Stock           int         `gorm:"not null;default:0;comment:总库存，0表示不限" json:"stock"`
+	SoldCount       int         `gorm:"not null;default:0;comment:已售数量" json:"sold_count"`
+	UserLimit       int         `gorm:"not null;default:0;comment:每个玩家限购数量，0表示不限" json:"user_limit"`
+	SaleStartAt     *time.Time  `gorm:"comment:开售时间，为空表示立即开售" json:"sale_start_at"`
+	SaleEndAt       *time.Time  `gorm:"comment:停售时间，为空表示不限" json:"sale_end_at"`
+	SortOrder       int         `gorm:"not null;default:0;comment:排序，越大越靠前" json:"sort_order"`
+	Status          int         `gorm:"type:tinyint;index;default:1;comment:状态" json:"status"` // 1:上架 0:下架
+	CreatedBy       uint        `gorm:"default:0;comment:创建管理员ID" json:"created_by"`
+	CreatedAt       time.Time   `json:"created_at"`
+	UpdatedAt       time.Time   `json:"updated_at"`
+}
+
+func (ShopSku) TableName() string {
+	return "shop_skus"
+}
+
+// OnSale 商品在指定时间是否可以购买
+func (s *ShopSku) OnSale(now time.Time) bool {
+	if s.Status != ShopSkuOnSale {
+		return false
+	}
+	if s.SaleStartAt != nil && now.Before(*s.SaleStartAt) {
+		return false
+	}
+	return s.SaleEndAt == nil || now.Before(*s.SaleEndAt)
+}
+
+// Remaining 剩余库存，不限库存时返回 -1
+func (s *ShopSku) Remaining() int {
+	if s.Stock <= 0 {
+		return -1
+	}
+	if s.SoldCount >= s.Stock {
+		return 0
+	}
+	return s.Stock - s.SoldCount
+}
+
+// UnitPrice 指定币种在指定时间的成交单价（已计算折扣，四舍五入到分），不支持该币种时返回0
+func (s *ShopSku) UnitPrice(currency WalletCurrency, now time.Time) Money {
+	price := s.Price
+	if currency == CurrencyActivityBalance {
+		price = s.ActivityPrice
+	}
+	if !price.IsPositive() || !s.discountActive(now) {
+		return price
+	}
+	cents := (price.Cents()*int64(s.Discount) + 50) / 100
+	if cents < 1 {
+		cents = 1
+	}
+	return NewMoneyFromCents(cents)
+}
+
+func (s *ShopSku) discountActive(now time.Time) bool {
+	if s.Discount <= 0 || s.Discount >= 100 {
+		return false
+	}
+	if s.DiscountStartAt != nil && now.Before(*s.DiscountStartAt) {
+		return false
+	}
+	return s.DiscountEndAt == nil || now.Before(*s.DiscountEndAt)
+}
+
+// ShopPurchase 商城购买记录，收据号返回给玩家用于查询和客服核对
+type ShopPurchase struct {
+	ID        uint           `gorm:"primaryKey" json:"id"`
+	ReceiptNo string         `gorm:"type:varchar(32);uniqueIndex;not null;comment:收据号" json:"receipt_no"`
+	UserID    uint           `gorm:"index:idx_shop_purchase_user_sku,priority:1;not null;comment:用户ID" json:"user_id"`
+	SkuID     uint           `gorm:"index:idx_shop_purchase_user_sku,priority:2;index;not null;comment:商品ID" json:"sku_id"`
+	SkuName   string         `gorm:"type:varchar(100);comment:商品名称" json:"sku_name"`
+	Quantity  int            `gorm:"not null;comment:购买份数" json:"quantity"`
+	Currency  WalletCurrency `gorm:"type:varchar(20);not null;comment:支付币种" json:"currency"`
+	UnitPrice Money          `gorm:"type:decimal(10,2);not null;comment:成交单价" json:"unit_price" swaggertype:"number"`
+	Amount    Money          `gorm:"type:decimal(10,2);not null;comment:支付金额" json:"amount" swaggertype:"number"`
+	Items     RewardItems    `gorm:"type:json;comment:发放的道具" json:"items"`
+	ClientIP  string         `gorm:"type:varchar(64);comment:购买IP" json:"client_ip"`
+	CreatedAt time.Time      `gorm:"index" json:"created_at"`
+}
+
+func (ShopPurchase) TableName() string {
+	return "shop_purchases"
+}
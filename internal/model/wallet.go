@@ -0,0 +1,70 @@
+package model
+
+import (
+	"time"
+)
+
+type WalletCurrency string
+
+const (
+	CurrencyBalance         WalletCurrency = "balance"          // 余额
+	CurrencyActivityBalance WalletCurrency = "activity_balance" // 活动余额
+)
+
+type WalletDirection string
+
+const (
+	WalletCredit WalletDirection = "credit" // 入账
+	WalletDebit  WalletDirection = "debit"  // 出账
+)
+
+type WalletAccountType string
+
+const (
+	WalletAccountUser   WalletAccountType = "user"   // 用户钱包
+	WalletAccountSystem WalletAccountType = "system" // 系统对手账户（发放/回收）
+)
+
+// 流水原因码
+const (
+	WalletReasonAdminAdjust   = "admin_adjust"   // 管理员调整
+	WalletReasonLevelUp       = "level_up"       // 升级奖励
+	WalletReasonCheckin       = "checkin"        // 签到奖励
+	WalletReasonCheckinMakeup = "checkin_makeup" // 补签扣费
+	WalletReasonRedeem        = "redeem"         // 兑换码奖励
+	WalletReasonShopPurchase  = "shop_purchase"  // 商城购买
+	WalletReasonPaymentTopup  = "payment_topup"  // 充值到账
+	WalletReasonPaymentRefund = "payment_refund" // 充值退款
+	WalletReasonRefundRevert  = "refund_revert"  // 渠道退款失败，退还扣回的余额
+	WalletReasonMail          = "mail"           // 邮件附件
+)
+
+// WalletLedgerEntry 钱包流水（复式记账，只追加不修改）
+// 每笔资金变动写入两条分录：用户账户一条，系统对手账户一条，方向相反、金额相同、TxNo 相同
+type WalletLedgerEntry struct {
+	ID           uint              `gorm:"primaryKey" json:"id"`
+	TxNo         string            `gorm:"type:varchar(64);index;not null;comment:交易号" json:"tx_no"`
+	AccountType  WalletAccountType `gorm:"type:varchar(20);index:idx_wallet_account,priority:1;not null;comment:账户类型" json:"account_type"`
+	AccountID    uint              `gorm:"index:idx_wallet_account,priority:2;not null;comment:账户ID（用户ID，系统账户为0）" json:"account_id"`
+	Currency     WalletCurrency    `gorm:"type:varchar(20);index:idx_wallet_account,priority:3;not null;comment:币种" json:"currency"`
+	Direction    WalletDirection   `gorm:"type:varchar(10);not null;comment:方向" json:"direction"`
+	Amount       Money             `gorm:"type:decimal(10,2);not null;comment:金额" json:"amount" swaggertype:"number"`
+	BalanceAfter Money             `gorm:"type:decimal(10,2);not null;comment:变动后余额" json:"balance_after" swaggertype:"number"`
+	ReasonCode   string            `gorm:"type:varchar(50);index;not null;comment:原因码" json:"reason_code"`
+	ReferenceID  string            `gorm:"type:varchar(64);index;comment:关联业务ID" json:"reference_id"`
+	OperatorID   uint              `gorm:"default:0;comment:操作管理员ID，系统操作为0" json:"operator_id"`
+	Remark       string            `gorm:"type:varchar(255);comment:备注" json:"remark"`
+	CreatedAt    time.Time         `gorm:"index" json:"created_at"`
+}
+
+func (WalletLedgerEntry) TableName() string {
+	return "wallet_ledger_entries"
+}
+
+func (c WalletCurrency) Valid() bool {
+	return c == CurrencyBalance || c == CurrencyActivityBalance
+}
+
+func (d WalletDirection) Valid() bool {
+	return d == WalletCredit || d == WalletDebit
+}
@@ -3,28 +3,121 @@ package router
 import (
 	"bgame/internal/handler/admin"
 	"bgame/internal/middleware"
+	"bgame/internal/model"
 
 	"github.com/gin-gonic/gin"
 )
 
 func setupAdminRoutes(r *gin.Engine) {
 	adminHandler := admin.NewAdminHandler()
+	walletHandler := admin.NewWalletHandler()
+	securityHandler := admin.NewSecurityHandler()
+	roleHandler := admin.NewRoleHandler()
+	userHandler := admin.NewUserHandler()
+	auditHandler := admin.NewAuditHandler()
+	progressionHandler := admin.NewProgressionHandler()
+	redeemHandler := admin.NewRedeemHandler()
+	itemHandler := admin.NewItemHandler()
+	shopHandler := admin.NewShopHandler()
+	paymentHandler := admin.NewPaymentHandler()
+	mailHandler := admin.NewMailHandler()
 	adminGroup := r.Group("/api/admin")
 	{
 		// 公开接口
 		adminGroup.POST("/login", adminHandler.Login)
+		adminGroup.POST("/login/2fa", adminHandler.LoginTwoFactor)
+		adminGroup.POST("/login/2fa/setup", adminHandler.LoginTwoFactorSetup)
+		adminGroup.POST("/token/refresh", adminHandler.RefreshToken)
 		adminGroup.GET("/roles", adminHandler.GetRoles)
-		adminGroup.POST("/create", adminHandler.CreateAdmin)
+		adminGroup.POST("/setup", adminHandler.Setup)
 
 		// 需要认证的接口
-		adminGroup.Use(middleware.AuthAdmin())
+		adminGroup.Use(middleware.AuthAdmin(), middleware.Idempotency(), middleware.AdminAudit())
 		{
 			adminGroup.GET("/info", adminHandler.GetAdminInfo)
+			adminGroup.POST("/logout", adminHandler.Logout)
+			adminGroup.GET("/2fa", adminHandler.GetTwoFactorStatus)
+			adminGroup.POST("/2fa/setup", adminHandler.SetupTwoFactor)
+			adminGroup.POST("/2fa/enable", adminHandler.EnableTwoFactor)
+			adminGroup.POST("/2fa/disable", adminHandler.DisableTwoFactor)
+			adminGroup.POST("/2fa/recovery-codes", adminHandler.RegenerateRecoveryCodes)
 
-			// 需要超级管理员权限的接口
-			adminGroup.Use(middleware.RequireRole(1))
+			// 玩家管理
+			adminGroup.GET("/users", middleware.RequirePermission(model.PermUserView), userHandler.SearchUsers)
+			adminGroup.GET("/users/:id", middleware.RequirePermission(model.PermUserView), userHandler.GetUser)
+			adminGroup.PUT("/users/:id", middleware.RequirePermission(model.PermUserEdit), userHandler.UpdateUser)
+			adminGroup.POST("/users/:id/ban", middleware.RequirePermission(model.PermUserBan), userHandler.BanUser)
+			adminGroup.POST("/users/:id/unban", middleware.RequirePermission(model.PermUserBan), userHandler.UnbanUser)
+			adminGroup.POST("/users/:id/sessions/revoke", middleware.RequirePermission(model.PermUserRevokeSession), adminHandler.RevokeUserSessions)
+			adminGroup.POST("/security/users/unlock", middleware.RequirePermission(model.PermUserUnlock), securityHandler.UnlockUser)
+
+			// 等级与经验
+			adminGroup.POST("/users/:id/experience", middleware.RequirePermission(model.PermUserExperience), progressionHandler.GrantExperience)
+			adminGroup.GET("/experience/logs", middleware.RequirePermission(model.PermUserView), progressionHandler.ListExperienceLogs)
+
+			// 钱包管理
+			adminGroup.GET("/wallet/transactions", middleware.RequirePermission(model.PermWalletView), walletHandler.ListTransactions)
+			adminGroup.POST("/wallet/adjust", middleware.RequirePermission(model.PermWalletAdjust), walletHandler.Adjust)
+
+			// 兑换码
+			adminGroup.POST("/redeem/batches", middleware.RequirePermission(model.PermRedeemManage), redeemHandler.CreateBatch)
+			adminGroup.GET("/redeem/batches", middleware.RequirePermission(model.PermRedeemView), redeemHandler.ListBatches)
+			adminGroup.GET("/redeem/batches/:id", middleware.RequirePermission(model.PermRedeemView), redeemHandler.GetBatch)
+			adminGroup.PUT("/redeem/batches/:id/status", middleware.RequirePermission(model.PermRedeemManage), redeemHandler.UpdateBatchStatus)
+			adminGroup.GET("/redeem/batches/:id/codes", middleware.RequirePermission(model.PermRedeemView), redeemHandler.ListCodes)
+			adminGroup.GET("/redeem/records", middleware.RequirePermission(model.PermRedeemView), redeemHandler.ListRecords)
+
+			// 道具与背包
+			adminGroup.POST("/items", middleware.RequirePermission(model.PermItemManage), itemHandler.CreateItem)
+			adminGroup.GET("/items", middleware.RequirePermission(model.PermItemView), itemHandler.ListItems)
+			adminGroup.GET("/items/:id", middleware.RequirePermission(model.PermItemView), itemHandler.GetItem)
+			adminGroup.PUT("/items/:id", middleware.RequirePermission(model.PermItemManage), itemHandler.UpdateItem)
+			adminGroup.GET("/users/:id/inventory", middleware.RequirePermission(model.PermItemView), itemHandler.GetUserInventory)
+			adminGroup.POST("/users/:id/items/grant", middleware.RequirePermission(model.PermItemGrant), itemHandler.GrantItem)
+			adminGroup.POST("/users/:id/items/consume", middleware.RequirePermission(model.PermItemGrant), itemHandler.ConsumeItem)
+			adminGroup.GET("/inventory/logs", middleware.RequirePermission(model.PermItemView), itemHandler.ListItemLogs)
+
+			// 商城
+			adminGroup.POST("/shop/skus", middleware.RequirePermission(model.PermShopManage), shopHandler.CreateSku)
+			adminGroup.GET("/shop/skus", middleware.RequirePermission(model.PermShopView), shopHandler.ListSkus)
+			adminGroup.GET("/shop/skus/:id", middleware.RequirePermission(model.PermShopView), shopHandler.GetSku)
+			adminGroup.PUT("/shop/skus/:id", middleware.RequirePermission(model.PermShopManage), shopHandler.UpdateSku)
+			adminGroup.GET("/shop/purchases", middleware.RequirePermission(model.PermShopView), shopHandler.ListPurchases)
+
+			// 充值订单
+			adminGroup.GET("/payment/orders", middleware.RequirePermission(model.PermPaymentView), paymentHandler.ListOrders)
+			adminGroup.GET("/payment/orders/:id", middleware.RequirePermission(model.PermPaymentView), paymentHandler.GetOrder)
+			adminGroup.POST("/payment/orders/:id/refund", middleware.RequirePermission(model.PermPaymentRefund), paymentHandler.Refund)
+
+			// 邮件
+			adminGroup.POST("/mail", middleware.RequirePermission(model.PermMailSend), mailHandler.SendMail)
+			adminGroup.GET("/mail", middleware.RequirePermission(model.PermMailView), mailHandler.ListMails)
+			adminGroup.GET("/mail/:id", middleware.RequirePermission(model.PermMailView), mailHandler.GetMail)
+
+			// 管理员管理
+			adminGroup.POST("/create", middleware.RequirePermission(model.PermAdminCreate), adminHandler.CreateAdmin)
+			adminGroup.GET("/admins", middleware.RequirePermission(model.PermAdminView), adminHandler.ListAdmins)
+			adminGroup.GET("/admins/:id", middleware.RequirePermission(model.PermAdminView), adminHandler.GetAdmin)
+			adminGroup.PUT("/admins/:id/role", middleware.RequirePermission(model.PermAdminManage), adminHandler.UpdateAdminRole)
+			adminGroup.PUT("/admins/:id/status", middleware.RequirePermission(model.PermAdminManage), adminHandler.UpdateAdminStatus)
+			adminGroup.POST("/admins/:id/password/reset", middleware.RequirePermission(model.PermAdminManage), adminHandler.ResetAdminPassword)
+			adminGroup.DELETE("/admins/:id", middleware.RequirePermission(model.PermAdminManage), adminHandler.DeleteAdmin)
+			adminGroup.POST("/admins/:id/sessions/revoke", middleware.RequirePermission(model.PermAdminManage), adminHandler.RevokeAdminSessions)
+			adminGroup.POST("/admins/:id/2fa/reset", middleware.RequirePermission(model.PermAdminManage), adminHandler.ResetTwoFactor)
+			adminGroup.POST("/security/admins/unlock", middleware.RequirePermission(model.PermAdminManage), securityHandler.UnlockAdmin)
+
+			// 审计日志
+			adminGroup.GET("/audit-logs", middleware.RequireSuperAdmin(), auditHandler.ListAuditLogs)
+
+			// 角色与权限管理
+			rbacGroup := adminGroup.Group("", middleware.RequirePermission(model.PermRoleManage))
 			{
-				// adminGroup.POST("/create", adminHandler.CreateAdmin)
+				rbacGroup.GET("/permissions", roleHandler.ListPermissions)
+				rbacGroup.GET("/rbac/roles", roleHandler.ListRoles)
+				rbacGroup.GET("/rbac/roles/:id", roleHandler.GetRole)
+				rbacGroup.POST("/rbac/roles", roleHandler.CreateRole)
+				rbacGroup.PUT("/rbac/roles/:id", roleHandler.UpdateRole)
+				rbacGroup.DELETE("/rbac/roles/:id", roleHandler.DeleteRole)
 			}
 		}
 	}
@@ -0,0 +1,17 @@
+package router
+
+import (
+	"bgame/internal/handler/payment"
+
+	"github.com/gin-gonic/gin"
+)
+
+// setupPaymentRoutes 支付渠道回调，无需认证，由各渠道自行验签
+func setupPaymentRoutes(r *gin.Engine) {
+	paymentHandler := payment.NewPaymentHandler()
+	paymentGroup := r.Group("/api/payment")
+	{
+		paymentGroup.POST("/callback/:provider", paymentHandler.Callback)
+		paymentGroup.POST("/mock/pay", paymentHandler.MockPay)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bgame/docs"
 	"bgame/internal/config"
 	"bgame/internal/middleware"
+	"bgame/internal/util"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -25,6 +26,7 @@ func SetupRouter() *gin.Engine {
 
 	// 全局中间件
 	r.Use(middleware.Recovery())
+	r.Use(middleware.RequestID())
 	r.Use(middleware.Logger())
 	r.Use(middleware.CORS())
 	r.Use(middleware.RateLimit())
@@ -43,6 +45,9 @@ func SetupRouter() *gin.Engine {
 		})
 	})
 
+	// JWKS 公钥发布，供其他服务验证 token
+	r.GET("/.well-known/jwks.json", JWKS)
+
 	// Swagger 文档
 	docs.SwaggerInfo.Title = "bGame API 文档"
 	docs.SwaggerInfo.Description = "高性能 Go API 服务，单机 QPS > 20000"
@@ -55,7 +60,20 @@ func SetupRouter() *gin.Engine {
 	// 设置路由
 	setupUserRoutes(r)
 	setupAdminRoutes(r)
+	setupServerRoutes(r)
+	setupPaymentRoutes(r)
 
 	return r
 }
 
+// JWKS 发布 JWT 验签公钥
+// @Summary      JWKS 公钥
+// @Description  返回当前有效的 JWT 验签公钥（RFC 7517），响应可缓存 5 分钟
+// @Tags         系统
+// @Produce      json
+// @Success      200  {object}  util.JWKSet
+// @Router       /.well-known/jwks.json [get]
+func JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(200, util.GetJWKS())
+}
@@ -0,0 +1,25 @@
+package router
+
+import (
+	"bgame/internal/handler/server"
+	"bgame/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// setupServerRoutes 服务端接口，供游戏服等内部服务通过 X-API-Key 调用
+func setupServerRoutes(r *gin.Engine) {
+	progressionHandler := server.NewProgressionHandler()
+	leaderboardHandler := server.NewLeaderboardHandler()
+	inventoryHandler := server.NewInventoryHandler()
+	serverGroup := r.Group("/api/server")
+	serverGroup.Use(middleware.AuthServer(), middleware.Idempotency())
+	{
+		serverGroup.POST("/experience/grant", progressionHandler.GrantExperience)
+		serverGroup.GET("/users/:id/level", progressionHandler.GetLevel)
+		serverGroup.POST("/leaderboard/:name/scores", leaderboardHandler.SubmitScore)
+		serverGroup.POST("/items/grant", inventoryHandler.GrantItem)
+		serverGroup.POST("/items/consume", inventoryHandler.ConsumeItem)
+		serverGroup.GET("/users/:id/inventory", inventoryHandler.GetInventory)
+	}
+}
@@ -9,15 +9,67 @@ import (
 
 func setupUserRoutes(r *gin.Engine) {
 	userHandler := user.NewUserHandler()
+	walletHandler := user.NewWalletHandler()
+	progressionHandler := user.NewProgressionHandler()
+	leaderboardHandler := user.NewLeaderboardHandler()
+	checkinHandler := user.NewCheckinHandler()
+	redeemHandler := user.NewRedeemHandler()
+	inventoryHandler := user.NewInventoryHandler()
+	shopHandler := user.NewShopHandler()
+	paymentHandler := user.NewPaymentHandler()
+	mailHandler := user.NewMailHandler()
+	friendHandler := user.NewFriendHandler()
 	userGroup := r.Group("/api/user")
 	{
 		// 公开接口
-		userGroup.POST("/regAndLogin", userHandler.RegAndLogin)
+		userGroup.POST("/register", middleware.Idempotency(), userHandler.Register)
+		userGroup.POST("/login", userHandler.Login)
+		userGroup.POST("/regAndLogin", middleware.Idempotency(), userHandler.RegAndLogin)
+		userGroup.POST("/token/refresh", userHandler.RefreshToken)
 
 		// 需要认证的接口
-		userGroup.Use(middleware.AuthUser())
+		userGroup.Use(middleware.AuthUser(), middleware.Idempotency())
 		{
 			userGroup.GET("/info", userHandler.GetUserInfo)
+			userGroup.POST("/logout", userHandler.Logout)
+			userGroup.GET("/wallet", walletHandler.GetWallet)
+			userGroup.GET("/wallet/transactions", walletHandler.ListTransactions)
+			userGroup.GET("/level", progressionHandler.GetLevel)
+			userGroup.GET("/experience/logs", progressionHandler.ListExperienceLogs)
+			userGroup.GET("/leaderboard", leaderboardHandler.ListBoards)
+			userGroup.GET("/leaderboard/:name", leaderboardHandler.GetTop)
+			userGroup.GET("/leaderboard/:name/around", leaderboardHandler.GetAroundMe)
+			userGroup.GET("/leaderboard/:name/archive", leaderboardHandler.GetArchive)
+			userGroup.GET("/checkin", checkinHandler.GetStatus)
+			userGroup.POST("/checkin", checkinHandler.Checkin)
+			userGroup.POST("/checkin/makeup", checkinHandler.Makeup)
+			userGroup.GET("/checkin/history", checkinHandler.ListHistory)
+			userGroup.POST("/redeem", redeemHandler.Redeem)
+			userGroup.GET("/inventory", inventoryHandler.GetInventory)
+			userGroup.GET("/inventory/logs", inventoryHandler.ListItemLogs)
+			userGroup.GET("/shop", shopHandler.ListSkus)
+			userGroup.POST("/shop/purchase", shopHandler.Purchase)
+			userGroup.GET("/shop/purchases", shopHandler.ListPurchases)
+			userGroup.POST("/payment/orders", paymentHandler.CreateOrder)
+			userGroup.GET("/payment/orders", paymentHandler.ListOrders)
+			userGroup.GET("/payment/orders/:order_no", paymentHandler.GetOrder)
+			userGroup.GET("/mail", mailHandler.ListMails)
+			userGroup.GET("/mail/unread", mailHandler.GetUnread)
+			userGroup.POST("/mail/claim", mailHandler.ClaimAll)
+			userGroup.GET("/mail/:id", mailHandler.ReadMail)
+			userGroup.DELETE("/mail/:id", mailHandler.DeleteMail)
+			userGroup.POST("/mail/:id/claim", mailHandler.ClaimMail)
+			userGroup.GET("/friends", friendHandler.ListFriends)
+			userGroup.POST("/friends/heartbeat", friendHandler.Heartbeat)
+			userGroup.GET("/friends/requests", friendHandler.ListRequests)
+			userGroup.POST("/friends/requests", friendHandler.SendRequest)
+			userGroup.POST("/friends/requests/:id/accept", friendHandler.AcceptRequest)
+			userGroup.POST("/friends/requests/:id/decline", friendHandler.DeclineRequest)
+			userGroup.POST("/friends/requests/:id/cancel", friendHandler.CancelRequest)
+			userGroup.GET("/friends/blocks", friendHandler.ListBlocks)
+			userGroup.POST("/friends/blocks", friendHandler.Block)
+			userGroup.DELETE("/friends/blocks/:user_id", friendHandler.Unblock)
+			userGroup.DELETE("/friends/:user_id", friendHandler.RemoveFriend)
 		}
 	}
 }
@@ -2,6 +2,8 @@ package service
 
 import (
 	"errors"
+	"strings"
+	"time"
 
 	"bgame/internal/dao"
 	"bgame/internal/model"
@@ -9,23 +11,42 @@ import (
 )
 
 type AdminService struct {
-	adminDAO *dao.AdminDAO
+	adminDAO     *dao.AdminDAO
+	tokenService *TokenService
+	loginGuard   *LoginGuardService
+	twoFactor    *TwoFactorService
+	roleService  *RoleService
 }
 
 func NewAdminService() *AdminService {
 	return &AdminService{
-		adminDAO: dao.NewAdminDAO(),
+		adminDAO:     dao.NewAdminDAO(),
+		tokenService: NewTokenService(),
+		loginGuard:   NewLoginGuardService(),
+		twoFactor:    NewTwoFactorService(),
+		roleService:  NewRoleService(),
 	}
 }
 
 type AdminLoginRequest struct {
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
+	DeviceID string `json:"device_id" binding:"omitempty,max=64"`
+	ClientIP string `json:"-"`
 }
 
 type AdminLoginResponse struct {
-	Token     string       `json:"token"`
-	AdminInfo *model.Admin `json:"admin_info"`
+	Token        string       `json:"token"`
+	RefreshToken string       `json:"refresh_token"`
+	ExpiresIn    int          `json:"expires_in"` // access token 有效期（秒）
+	AdminInfo    *model.Admin `json:"admin_info"`
+
+	// 需要两步验证时不签发 token，返回登录挑战
+	TwoFactorRequired      bool     `json:"two_factor_required,omitempty"`
+	TwoFactorSetupRequired bool     `json:"two_factor_setup_required,omitempty"` // 需先绑定认证器
+	ChallengeToken         string   `json:"challenge_token,omitempty"`
+	ChallengeExpiresIn     int      `json:"challenge_expires_in,omitempty"` // 登录挑战有效期（秒）
+	RecoveryCodes          []string `json:"recovery_codes,omitempty"`       // 登录时完成绑定才会返回
 }
 
 type CreateAdminRequest struct {
@@ -34,11 +55,42 @@ type CreateAdminRequest struct {
 	Role     model.AdminRole  `json:"role" binding:"required"`
 }
 
+type AdminListQuery struct {
+	util.PageQuery
+	Username  string          `form:"username"` // 模糊匹配
+	Role      model.AdminRole `form:"role"`
+	Status    *int            `form:"status"`
+	StartTime time.Time       `form:"start_time" time_format:"2006-01-02 15:04:05"`
+	EndTime   time.Time       `form:"end_time" time_format:"2006-01-02 15:04:05"`
+}
+
+type UpdateAdminRoleRequest struct {
+	Role model.AdminRole `json:"role" binding:"required"`
+}
+
+type UpdateAdminStatusRequest struct {
+	Status *int `json:"status" binding:"required,oneof=0 1"` // 1:正常 0:禁用
+}
+
+type ResetAdminPasswordRequest struct {
+	Password string `json:"password" binding:"omitempty,min=6,max=50"` // 为空时生成随机临时密码
+}
+
+type ResetAdminPasswordResponse struct {
+	Password string `json:"password,omitempty"` // 仅在生成随机密码时返回
+}
+
 // Login 管理员登录
 func (s *AdminService) Login(req *AdminLoginRequest) (*AdminLoginResponse, error) {
+	// 检查登录锁定
+	if err := s.loginGuard.Check(loginScopeAdmin, req.Username, req.ClientIP); err != nil {
+		return nil, err
+	}
+
 	// 获取管理员
 	admin, err := s.adminDAO.GetByUsername(req.Username)
 	if err != nil {
+		s.loginGuard.RecordFailure(loginScopeAdmin, req.Username, req.ClientIP)
 		return nil, errors.New("用户名或密码错误")
 	}
 
@@ -49,36 +101,112 @@ func (s *AdminService) Login(req *AdminLoginRequest) (*AdminLoginResponse, error
 
 	// 验证密码
 	if !util.CheckPassword(req.Password, admin.Password) {
+		s.loginGuard.RecordFailure(loginScopeAdmin, admin.Username, req.ClientIP)
 		return nil, errors.New("用户名或密码错误")
 	}
+	s.loginGuard.RecordSuccess(loginScopeAdmin, admin.Username)
 
-	// 生成token
-	token, err := util.GenerateAdminToken(admin.ID, admin.Username, int(admin.Role))
+	// 已启用或被强制要求两步验证时，先返回登录挑战
+	required, setupPending, err := s.twoFactor.LoginRequirement(admin)
 	if err != nil {
-		return nil, errors.New("生成token失败")
+		return nil, errors.New("登录失败，请稍后再试")
+	}
+	if required {
+		challengeToken, expiresIn, err := s.twoFactor.CreateChallenge(admin.ID, req.DeviceID, req.ClientIP, setupPending)
+		if err != nil {
+			return nil, err
+		}
+		return &AdminLoginResponse{
+			TwoFactorRequired:      true,
+			TwoFactorSetupRequired: setupPending,
+			ChallengeToken:         challengeToken,
+			ChallengeExpiresIn:     expiresIn,
+		}, nil
+	}
+
+	return s.buildLoginResponse(admin, req.DeviceID)
+}
+
+// LoginTwoFactor 登录第二步：校验动态验证码或恢复码后签发token
+func (s *AdminService) LoginTwoFactor(req *TwoFactorLoginRequest) (*AdminLoginResponse, error) {
+	challenge, recovery, err := s.twoFactor.CompleteChallenge(req.ChallengeToken, req.Code)
+	if err != nil {
+		if challenge != nil {
+			if admin, e := s.adminDAO.GetByID(challenge.AdminID); e == nil {
+				s.loginGuard.RecordFailure(loginScopeAdmin, admin.Username, challenge.ClientIP)
+			}
+		}
+		return nil, err
+	}
+
+	admin, err := s.adminDAO.GetByID(challenge.AdminID)
+	if err != nil {
+		return nil, errors.New("管理员不存在或已被禁用")
+	}
+
+	resp, err := s.buildLoginResponse(admin, challenge.DeviceID)
+	if err != nil {
+		return nil, err
+	}
+	if recovery != nil {
+		resp.RecoveryCodes = recovery.RecoveryCodes
+	}
+	return resp, nil
+}
+
+// buildLoginResponse 签发token并组装登录响应（清除敏感信息）
+func (s *AdminService) buildLoginResponse(admin *model.Admin, deviceID string) (*AdminLoginResponse, error) {
+	tokens, err := s.tokenService.IssueAdminTokens(admin.ID, admin.Username, int(admin.Role), deviceID)
+	if err != nil {
+		return nil, err
 	}
 
-	// 清除敏感信息
 	admin.Password = ""
 
 	return &AdminLoginResponse{
-		Token:     token,
-		AdminInfo: admin,
+		Token:        tokens.Token,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+		AdminInfo:    admin,
 	}, nil
 }
 
-// CreateAdmin 创建管理员（需要超级管理员权限）
-func (s *AdminService) CreateAdmin(req *CreateAdminRequest) error {
+// CreateAdmin 创建管理员（需要 admin:create 权限），只有超级管理员可以创建超级管理员
+// 新管理员角色的权限必须是操作者自己拥有的
+func (s *AdminService) CreateAdmin(operatorID uint, operatorRole model.AdminRole, req *CreateAdminRequest) (*model.Admin, error) {
+	if req.Role == model.RoleSuperAdmin && operatorRole != model.RoleSuperAdmin {
+		return nil, errors.New("只有超级管理员可以创建超级管理员")
+	}
+	if err := s.roleService.CheckAssignable(operatorRole, uint(req.Role)); err != nil {
+		return nil, err
+	}
+
+	admin, err := s.createAdmin(req)
+	if err != nil {
+		return nil, err
+	}
+
+	util.Info("创建管理员: admin_id=%d username=%s role=%d operator_id=%d", admin.ID, admin.Username, admin.Role, operatorID)
+	return admin, nil
+}
+
+// createAdmin 校验用户名和角色后创建管理员
+func (s *AdminService) createAdmin(req *CreateAdminRequest) (*model.Admin, error) {
 	// 检查用户名是否已存在
 	_, err := s.adminDAO.GetByUsername(req.Username)
 	if err == nil {
-		return errors.New("用户名已存在")
+		return nil, errors.New("用户名已存在")
+	}
+
+	// 检查角色是否存在
+	if !s.roleService.Exists(uint(req.Role)) {
+		return nil, errors.New("角色不存在")
 	}
 
 	// 加密密码
 	hashedPassword, err := util.HashPassword(req.Password)
 	if err != nil {
-		return errors.New("密码加密失败")
+		return nil, errors.New("密码加密失败")
 	}
 
 	// 创建管理员
@@ -90,10 +218,10 @@ func (s *AdminService) CreateAdmin(req *CreateAdminRequest) error {
 	}
 
 	if err := s.adminDAO.Create(admin); err != nil {
-		return errors.New("创建管理员失败")
+		return nil, errors.New("创建管理员失败")
 	}
 
-	return nil
+	return admin, nil
 }
 
 // GetAdminInfo 获取管理员信息
@@ -112,20 +240,213 @@ func (s *AdminService) GetAdminInfo(adminID uint) (*model.Admin, error) {
 	return admin, nil
 }
 
-// CheckRole 检查角色权限
-func (s *AdminService) CheckRole(adminRole model.AdminRole, requiredRole model.AdminRole) bool {
-	// 超级管理员拥有所有权限
-	if adminRole == model.RoleSuperAdmin {
-		return true
+// ListAdmins 分页查询管理员
+func (s *AdminService) ListAdmins(req *AdminListQuery) (*util.PageResult, error) {
+	req.Normalize()
+	admins, total, err := s.adminDAO.List(&dao.AdminFilter{
+		Username:  req.Username,
+		Role:      req.Role,
+		Status:    req.Status,
+		StartTime: req.StartTime,
+		EndTime:   req.EndTime,
+	}, req.Offset(), req.PageSize)
+	if err != nil {
+		return nil, errors.New("查询管理员失败")
 	}
+	return util.NewPageResult(admins, total, &req.PageQuery), nil
+}
 
-	// 角色权限等级：超级管理员 > 管理员 > 操作员
-	roleLevel := map[model.AdminRole]int{
-		model.RoleSuperAdmin: 1,
-		model.RoleAdmin:      2,
-		model.RoleOperator:   3,
+// GetAdmin 获取管理员详情（包括已禁用的管理员）
+func (s *AdminService) GetAdmin(adminID uint) (*model.Admin, error) {
+	admin, err := s.adminDAO.FindByID(adminID)
+	if err != nil {
+		return nil, errors.New("管理员不存在")
 	}
+	admin.Password = ""
+	return admin, nil
+}
 
-	return roleLevel[adminRole] <= roleLevel[requiredRole]
+// UpdateAdminRole 修改管理员角色，并吊销其全部会话使新角色立即生效
+// 新角色的权限必须是操作者自己拥有的
+func (s *AdminService) UpdateAdminRole(operatorID uint, operatorRole model.AdminRole, adminID uint, req *UpdateAdminRoleRequest) error {
+	admin, err := s.manageableAdmin(operatorID, operatorRole, adminID)
+	if err != nil {
+		return err
+	}
+	if admin.Role == req.Role {
+		return nil
+	}
+	if req.Role == model.RoleSuperAdmin && operatorRole != model.RoleSuperAdmin {
+		return errors.New("只有超级管理员可以授予超级管理员角色")
+	}
+	if !s.roleService.Exists(uint(req.Role)) {
+		return errors.New("角色不存在")
+	}
+	if err := s.roleService.CheckAssignable(operatorRole, uint(req.Role)); err != nil {
+		return err
+	}
+	if err := s.ensureSuperAdminRemains(admin); err != nil {
+		return err
+	}
+
+	if err := s.adminDAO.UpdateFields(admin.ID, map[string]interface{}{"role": req.Role}); err != nil {
+		return errors.New("修改角色失败")
+	}
+	s.revokeSessions(admin.ID)
+
+	util.Info("修改管理员角色: admin_id=%d role=%d->%d operator_id=%d", admin.ID, admin.Role, req.Role, operatorID)
+	return nil
+}
+
+// UpdateAdminStatus 禁用或启用管理员，禁用时吊销其全部会话
+func (s *AdminService) UpdateAdminStatus(operatorID uint, operatorRole model.AdminRole, adminID uint, req *UpdateAdminStatusRequest) error {
+	admin, err := s.manageableAdmin(operatorID, operatorRole, adminID)
+	if err != nil {
+		return err
+	}
+	status := *req.Status
+	if admin.Status == status {
+		return nil
+	}
+	if status != 1 {
+		if err := s.ensureSuperAdminRemains(admin); err != nil {
+			return err
+		}
+	}
+
+	if err := s.adminDAO.UpdateFields(admin.ID, map[string]interface{}{"status": status}); err != nil {
+		return errors.New("修改状态失败")
+	}
+	if status != 1 {
+		s.revokeSessions(admin.ID)
+	}
+
+	util.Info("修改管理员状态: admin_id=%d status=%d->%d operator_id=%d", admin.ID, admin.Status, status, operatorID)
+	return nil
+}
+
+// ResetAdminPassword 重置管理员密码并吊销其全部会话；未指定新密码时生成随机临时密码
+func (s *AdminService) ResetAdminPassword(operatorID uint, operatorRole model.AdminRole, adminID uint, req *ResetAdminPasswordRequest) (*ResetAdminPasswordResponse, error) {
+	admin, err := s.manageableAdmin(operatorID, operatorRole, adminID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &ResetAdminPasswordResponse{}
+	password := req.Password
+	if password == "" {
+		if password, err = util.RandomToken(12); err != nil {
+			return nil, errors.New("生成密码失败")
+		}
+		resp.Password = password
+	}
+
+	hashedPassword, err := util.HashPassword(password)
+	if err != nil {
+		return nil, errors.New("密码加密失败")
+	}
+	if err := s.adminDAO.UpdateFields(admin.ID, map[string]interface{}{"password": hashedPassword}); err != nil {
+		return nil, errors.New("重置密码失败")
+	}
+	s.revokeSessions(admin.ID)
+
+	util.Security("重置管理员密码: admin_id=%d operator_id=%d", admin.ID, operatorID)
+	return resp, nil
+}
+
+// DeleteAdmin 软删除管理员并吊销其全部会话
+func (s *AdminService) DeleteAdmin(operatorID uint, operatorRole model.AdminRole, adminID uint) error {
+	admin, err := s.manageableAdmin(operatorID, operatorRole, adminID)
+	if err != nil {
+		return err
+	}
+	if err := s.ensureSuperAdminRemains(admin); err != nil {
+		return err
+	}
+
+	if err := s.adminDAO.Delete(admin.ID); err != nil {
+		return errors.New("删除管理员失败")
+	}
+	s.revokeSessions(admin.ID)
+
+	util.Info("删除管理员: admin_id=%d username=%s operator_id=%d", admin.ID, admin.Username, operatorID)
+	return nil
+}
+
+// RevokeAdminSessions 吊销指定管理员的全部会话
+func (s *AdminService) RevokeAdminSessions(operatorID uint, operatorRole model.AdminRole, adminID uint) error {
+	admin, err := s.manageableAdmin(operatorID, operatorRole, adminID)
+	if err != nil {
+		return err
+	}
+	if err := s.tokenService.RevokeAdminSessions(admin.ID); err != nil {
+		return err
+	}
+	util.Security("吊销管理员会话: admin_id=%d operator_id=%d", admin.ID, operatorID)
+	return nil
+}
+
+// ResetTwoFactor 重置指定管理员的两步验证并吊销其全部会话
+func (s *AdminService) ResetTwoFactor(operatorID uint, operatorRole model.AdminRole, adminID uint) error {
+	admin, err := s.manageableAdmin(operatorID, operatorRole, adminID)
+	if err != nil {
+		return err
+	}
+	return s.twoFactor.Reset(operatorID, admin.ID)
+}
+
+// UnlockAdmin 解除管理员账号或IP的登录锁定；指定用户名时目标管理员必须存在且可被操作人管理
+func (s *AdminService) UnlockAdmin(operatorID uint, operatorRole model.AdminRole, req *UnlockLoginRequest) error {
+	if username := strings.TrimSpace(req.Username); username != "" {
+		admin, err := s.adminDAO.GetByUsername(username)
+		if err != nil {
+			return errors.New("管理员不存在")
+		}
+		if _, err := s.manageableAdmin(operatorID, operatorRole, admin.ID); err != nil {
+			return err
+		}
+	}
+	return s.loginGuard.UnlockAdmin(operatorID, req)
+}
+
+// manageableAdmin 获取可被当前操作人管理的目标管理员：不能管理自己，只有超级管理员可以管理超级管理员，
+// 目标管理员角色的权限必须是操作人自己拥有的，避免通过重置密码等操作接管权限更高的账号
+func (s *AdminService) manageableAdmin(operatorID uint, operatorRole model.AdminRole, adminID uint) (*model.Admin, error) {
+	if operatorID == adminID {
+		return nil, errors.New("不能对自己执行该操作")
+	}
+	admin, err := s.adminDAO.FindByID(adminID)
+	if err != nil {
+		return nil, errors.New("管理员不存在")
+	}
+	if admin.Role == model.RoleSuperAdmin && operatorRole != model.RoleSuperAdmin {
+		return nil, errors.New("只有超级管理员可以管理超级管理员")
+	}
+	if err := s.roleService.CheckManageable(operatorRole, admin.Role); err != nil {
+		return nil, err
+	}
+	return admin, nil
+}
+
+// ensureSuperAdminRemains 降级、禁用或删除超级管理员时，至少保留一个可用的超级管理员
+func (s *AdminService) ensureSuperAdminRemains(admin *model.Admin) error {
+	if admin.Role != model.RoleSuperAdmin || admin.Status != 1 {
+		return nil
+	}
+	count, err := s.adminDAO.CountActiveByRole(model.RoleSuperAdmin)
+	if err != nil {
+		return errors.New("校验超级管理员数量失败")
+	}
+	if count <= 1 {
+		return errors.New("至少需要保留一个可用的超级管理员")
+	}
+	return nil
+}
+
+// revokeSessions 吊销管理员全部会话，失败时仅记录日志（数据已变更，且 access token 有效期较短）
+func (s *AdminService) revokeSessions(adminID uint) {
+	if err := s.tokenService.RevokeAdminSessions(adminID); err != nil {
+		util.LogError("吊销管理员会话失败: admin_id=%d err=%v", adminID, err)
+	}
 }
 
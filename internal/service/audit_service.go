@@ -0,0 +1,289 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"time"
+
+	"bgame/internal/dao"
+	"bgame/internal/model"
+	"bgame/internal/util"
+)
+
+// auditRedacted 审计日志中敏感字段的替换值
+const auditRedacted = "***"
+
+// auditSensitiveKeys 审计日志中需要脱敏的字段（按字段名包含匹配，不区分大小写）
+var auditSensitiveKeys = []string{"password", "token", "secret", "otpauth"}
+
+// auditSensitiveExactKeys 需要脱敏的二次验证码字段（按字段名完全匹配）
+var auditSensitiveExactKeys = map[string]bool{
+	"code":           true,
+	"recovery_codes": true,
+}
+
+// auditIgnoredKeys 不参与变更对比的字段
+var auditIgnoredKeys = map[string]bool{
+	"created_at": true,
+	"updated_at": true,
+}
+
+// AuditService 管理员操作审计：记录操作前后的快照差异，并提供查询
+type AuditService struct {
+	auditLogDAO    *dao.AuditLogDAO
+	adminDAO       *dao.AdminDAO
+	userDAO        *dao.UserDAO
+	userProfileDAO *dao.UserProfileDAO
+	roleDAO        *dao.RoleDAO
+	redeemDAO      *dao.RedeemDAO
+	itemDAO        *dao.ItemDAO
+	inventoryDAO   *dao.InventoryDAO
+	shopDAO        *dao.ShopDAO
+	paymentDAO     *dao.PaymentDAO
+	mailDAO        *dao.MailDAO
+}
+
+func NewAuditService() *AuditService {
+	return &AuditService{
+		auditLogDAO:    dao.NewAuditLogDAO(),
+		adminDAO:       dao.NewAdminDAO(),
+		userDAO:        dao.NewUserDAO(),
+		userProfileDAO: dao.NewUserProfileDAO(),
+		roleDAO:        dao.NewRoleDAO(),
+		redeemDAO:      dao.NewRedeemDAO(),
+		itemDAO:        dao.NewItemDAO(),
+		inventoryDAO:   dao.NewInventoryDAO(),
+		shopDAO:        dao.NewShopDAO(),
+		paymentDAO:     dao.NewPaymentDAO(),
+		mailDAO:        dao.NewMailDAO(),
+	}
+}
+
+// AuditEntry 一次管理员写操作的审计信息
+type AuditEntry struct {
+	AdminID    uint
+	AdminName  string
+	Action     string
+	Method     string
+	Path       string
+	TargetType string
+	TargetID   string
+	Before     map[string]interface{} // 操作前快照，新建时为空
+	After      map[string]interface{} // 操作后快照，删除时为空
+	Request    []byte                 // 原始请求体
+	Success    bool
+	Message    string
+	ClientIP   string
+	RequestID  string
+}
+
+type AuditLogQuery struct {
+	util.PageQuery
+	AdminID    uint      `form:"admin_id"`
+	Action     string    `form:"action"`
+	TargetType string    `form:"target_type"`
+	TargetID   string    `form:"target_id"`
+	RequestID  string    `form:"request_id"`
+	StartTime  time.Time `form:"start_time" time_format:"2006-01-02 15:04:05"`
+	EndTime    time.Time `form:"end_time" time_format:"2006-01-02 15:04:05"`
+}
+
+// Snapshot 获取审计目标的当前状态，不支持的类型或目标不存在时返回 nil
+func (s *AuditService) Snapshot(targetType string, id uint) map[string]interface{} {
+	if id == 0 {
+		return nil
+	}
+
+	var (
+		target interface{}
+		err    error
+	)
+	switch targetType {
+	case model.AuditTargetAdmin:
+		target, err = s.adminDAO.FindByID(id)
+	case model.AuditTargetUser:
+		target, err = s.userDAO.FindByID(id)
+	case model.AuditTargetRole:
+		target, err = s.roleDAO.GetByID(id)
+	case model.AuditTargetWallet:
+		var profile *model.UserProfile
+		profile, err = s.userProfileDAO.GetUserProfileByUserID(id)
+		if err == nil {
+			target = &WalletResponse{Balance: profile.Balance, ActivityBalance: profile.ActivityBalance}
+		}
+	case model.AuditTargetLevel:
+		var profile *model.UserProfile
+		profile, err = s.userProfileDAO.GetUserProfileByUserID(id)
+		if err == nil {
+			target = map[string]interface{}{"level": profile.Level, "experience": profile.Experience}
+		}
+	case model.AuditTargetRedeemBatch:
+		target, err = s.redeemDAO.GetBatch(id)
+	case model.AuditTargetItem:
+		target, err = s.itemDAO.GetByID(id)
+	case model.AuditTargetInventory:
+		target, err = s.inventoryDAO.SumUserItems(id, time.Now())
+	case model.AuditTargetShopSku:
+		target, err = s.shopDAO.GetSku(id)
+	case model.AuditTargetPayment:
+		target, err = s.paymentDAO.GetOrder(id)
+	case model.AuditTargetMail:
+		target, err = s.mailDAO.GetMail(id)
+	default:
+		return nil
+	}
+	if err != nil {
+		return nil
+	}
+	return toAuditMap(target)
+}
+
+// Record 写入一条审计日志，Before/After 只保留发生变化的字段
+func (s *AuditService) Record(entry *AuditEntry) error {
+	before, after := diffAuditSnapshots(entry.Before, entry.After)
+
+	log := &model.AdminAuditLog{
+		AdminID:    entry.AdminID,
+		AdminName:  entry.AdminName,
+		Action:     entry.Action,
+		Method:     entry.Method,
+		Path:       entry.Path,
+		TargetType: entry.TargetType,
+		TargetID:   entry.TargetID,
+		Before:     marshalAuditJSON(before),
+		After:      marshalAuditJSON(after),
+		Request:    redactAuditRequest(entry.Request),
+		Success:    entry.Success,
+		Message:    truncateString(entry.Message, 255),
+		ClientIP:   entry.ClientIP,
+		RequestID:  entry.RequestID,
+	}
+	return s.auditLogDAO.Create(log)
+}
+
+// List 分页查询审计日志
+func (s *AuditService) List(req *AuditLogQuery) (*util.PageResult, error) {
+	req.Normalize()
+	logs, total, err := s.auditLogDAO.List(&dao.AuditLogFilter{
+		AdminID:    req.AdminID,
+		Action:     req.Action,
+		TargetType: req.TargetType,
+		TargetID:   req.TargetID,
+		RequestID:  req.RequestID,
+		StartTime:  req.StartTime,
+		EndTime:    req.EndTime,
+	}, req.Offset(), req.PageSize)
+	if err != nil {
+		return nil, errors.New("查询审计日志失败")
+	}
+	return util.NewPageResult(logs, total, &req.PageQuery), nil
+}
+
+// diffAuditSnapshots 对比前后快照，只保留变化的字段；一方为空时保留另一方全部字段
+func diffAuditSnapshots(before, after map[string]interface{}) (map[string]interface{}, map[string]interface{}) {
+	if before == nil || after == nil {
+		return before, after
+	}
+
+	changedBefore := make(map[string]interface{})
+	changedAfter := make(map[string]interface{})
+	for key, value := range before {
+		if auditIgnoredKeys[key] {
+			continue
+		}
+		if newValue, ok := after[key]; !ok || !reflect.DeepEqual(value, newValue) {
+			changedBefore[key] = value
+			if ok {
+				changedAfter[key] = newValue
+			}
+		}
+	}
+	for key, value := range after {
+		if _, ok := before[key]; !ok && !auditIgnoredKeys[key] {
+			changedAfter[key] = value
+		}
+	}
+	if len(changedBefore) == 0 && len(changedAfter) == 0 {
+		return nil, nil
+	}
+	return changedBefore, changedAfter
+}
+
+// toAuditMap 将对象按 JSON 字段转换为 map 并脱敏
+func toAuditMap(v interface{}) map[string]interface{} {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil
+	}
+	redactAuditValue(m)
+	return m
+}
+
+// redactAuditRequest 请求体为 JSON 时脱敏后保存，否则不保存
+func redactAuditRequest(body []byte) json.RawMessage {
+	if len(body) == 0 {
+		return nil
+	}
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return nil
+	}
+	redactAuditValue(v)
+	return marshalAuditJSON(v)
+}
+
+// redactAuditValue 递归替换敏感字段的值
+func redactAuditValue(v interface{}) {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for key, item := range val {
+			if isAuditSensitiveKey(key) {
+				val[key] = auditRedacted
+				continue
+			}
+			redactAuditValue(item)
+		}
+	case []interface{}:
+		for _, item := range val {
+			redactAuditValue(item)
+		}
+	}
+}
+
+func isAuditSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	if auditSensitiveExactKeys[key] {
+		return true
+	}
+	for _, sensitive := range auditSensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+func marshalAuditJSON(v interface{}) json.RawMessage {
+	if m, ok := v.(map[string]interface{}); v == nil || (ok && m == nil) {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+func truncateString(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max])
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"bgame/internal/dao"
+	"bgame/internal/model"
+	"bgame/internal/util"
+)
+
+const setupTokenTTL = 24 * time.Hour
+
+// BootstrapService 系统初始化：在没有任何管理员时创建第一个超级管理员
+type BootstrapService struct {
+	adminDAO     *dao.AdminDAO
+	adminService *AdminService
+	roleService  *RoleService
+}
+
+func NewBootstrapService() *BootstrapService {
+	return &BootstrapService{
+		adminDAO:     dao.NewAdminDAO(),
+		adminService: NewAdminService(),
+		roleService:  NewRoleService(),
+	}
+}
+
+type SetupAdminRequest struct {
+	SetupToken string `json:"setup_token" binding:"required"` // 服务启动时输出的一次性初始化 token
+	Username   string `json:"username" binding:"required,min=3,max=50"`
+	Password   string `json:"password" binding:"required,min=6,max=50"`
+}
+
+// NeedsSetup 系统中是否还没有任何管理员
+func (s *BootstrapService) NeedsSetup() (bool, error) {
+	count, err := s.adminDAO.Count()
+	if err != nil {
+		return false, err
+	}
+	return count == 0, nil
+}
+
+// PrepareSetupToken 没有任何管理员时生成一次性初始化 token，已初始化时返回空串
+func (s *BootstrapService) PrepareSetupToken() (string, error) {
+	needsSetup, err := s.NeedsSetup()
+	if err != nil || !needsSetup {
+		return "", err
+	}
+
+	token, err := util.RandomToken(24)
+	if err != nil {
+		return "", err
+	}
+	if err := s.adminDAO.SaveSetupToken(util.HashToken(token), setupTokenTTL); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// Setup 使用初始化 token 创建第一个超级管理员，token 使用后立即失效
+func (s *BootstrapService) Setup(req *SetupAdminRequest, clientIP string) error {
+	needsSetup, err := s.NeedsSetup()
+	if err != nil {
+		return errors.New("检查初始化状态失败")
+	}
+	if !needsSetup {
+		return errors.New("系统已初始化")
+	}
+
+	ok, err := s.adminDAO.ConsumeSetupToken(util.HashToken(req.SetupToken))
+	if err != nil {
+		return errors.New("校验初始化token失败")
+	}
+	if !ok {
+		util.Security("初始化token无效: ip=%s", clientIP)
+		return errors.New("初始化token无效或已使用")
+	}
+
+	admin, err := s.adminService.createAdmin(&CreateAdminRequest{
+		Username: req.Username,
+		Password: req.Password,
+		Role:     model.RoleSuperAdmin,
+	})
+	if err != nil {
+		return err
+	}
+
+	util.Security("初始化超级管理员: admin_id=%d username=%s ip=%s", admin.ID, admin.Username, clientIP)
+	return nil
+}
+
+// CreateAdmin 命令行创建管理员，不需要认证，用于初始化或找回访问权限
+func (s *BootstrapService) CreateAdmin(req *CreateAdminRequest) (*model.Admin, error) {
+	if err := s.roleService.SeedDefaults(); err != nil {
+		return nil, errors.New("初始化内置角色失败")
+	}
+
+	admin, err := s.adminService.createAdmin(req)
+	if err != nil {
+		return nil, err
+	}
+
+	util.Security("命令行创建管理员: admin_id=%d username=%s role=%d", admin.ID, admin.Username, admin.Role)
+	return admin, nil
+}
@@ -0,0 +1,424 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"bgame/internal/config"
+	"bgame/internal/dao"
+	"bgame/internal/model"
+	"bgame/internal/util"
+	"gorm.io/gorm"
+)
+
+const checkinDateLayout = "2006-01-02"
+
+// CheckinService 每日签到：按配置时区划分自然日，记录连续签到天数，按奖励日历发放经验、活动余额和道具
+type CheckinService struct {
+	checkinDAO    *dao.CheckinDAO
+	walletService *WalletService
+	rewardService *RewardService
+}
+
+func NewCheckinService() *CheckinService {
+	return &CheckinService{
+		checkinDAO:    dao.NewCheckinDAO(),
+		walletService: NewWalletService(),
+		rewardService: NewRewardService(),
+	}
+}
+
+type CheckinReward struct {
+	Experience      int               `json:"experience"`
+	ActivityBalance model.Money       `json:"activity_balance" swaggertype:"number"`
+	Items           model.RewardItems `json:"items,omitempty"`
+}
+
+type CheckinStatusResponse struct {
+	Today           string           `json:"today"`
+	CheckedIn       bool             `json:"checked_in"`  // 今天是否已签到
+	Streak          int              `json:"streak"`      // 当前连续签到天数，今天未签到时为截至昨天的天数
+	NextReward      *CheckinReward   `json:"next_reward"` // 下一次签到（今天未签到时为今天）可获得的奖励
+	Calendar        []*CheckinReward `json:"calendar"`    // 奖励日历，第 i 项为连续签到第 i+1 天的奖励
+	MakeupDates     []string         `json:"makeup_dates"`
+	MakeupRemaining int              `json:"makeup_remaining"` // 本月剩余补签次数，-1 表示不限制
+	MakeupCost      model.Money      `json:"makeup_cost" swaggertype:"number"`
+}
+
+type CheckinResponse struct {
+	Date     string                 `json:"date"`
+	Streak   int                    `json:"streak"`
+	Makeup   bool                   `json:"makeup"`
+	Reward   *CheckinReward         `json:"reward,omitempty"`
+	Progress *LevelProgressResponse `json:"progress,omitempty"` // 发放经验后的等级进度
+}
+
+type MakeupCheckinRequest struct {
+	Date string `json:"date" binding:"required"` // 补签日期 YYYY-MM-DD
+}
+
+type CheckinHistoryQuery struct {
+	util.PageQuery
+	Month string `form:"month"` // 月份 YYYY-MM，为空时不过滤
+}
+
+// Status 获取签到状态
+func (s *CheckinService) Status(userID uint) (*CheckinStatusResponse, error) {
+	now := time.Now().In(checkinLocation())
+	today := now.Format(checkinDateLayout)
+
+	latest, err := s.checkinDAO.GetLatest(userID)
+	if err != nil {
+		return nil, errors.New("查询签到状态失败")
+	}
+
+	resp := &CheckinStatusResponse{
+		Today:       today,
+		Calendar:    checkinCalendar(),
+		MakeupDates: []string{},
+		MakeupCost:  checkinMakeupCost(),
+	}
+	if latest != nil && latest.CheckinDate == today {
+		resp.CheckedIn = true
+		resp.Streak = latest.Streak
+	} else if latest != nil && latest.CheckinDate == addCheckinDays(today, -1) {
+		resp.Streak = latest.Streak
+	}
+	resp.NextReward = checkinRewardFor(resp.Streak + 1)
+
+	remaining, err := makeupRemaining(now, func(since time.Time) (int64, error) {
+		return s.checkinDAO.CountMakeupSince(userID, since)
+	})
+	if err != nil {
+		return nil, errors.New("查询签到状态失败")
+	}
+	resp.MakeupRemaining = remaining
+	if remaining != 0 {
+		checkins, _, err := s.checkinDAO.List(userID, addCheckinDays(today, -config.Cfg.Checkin.MakeupDays), addCheckinDays(today, -1), 0, config.Cfg.Checkin.MakeupDays)
+		if err != nil {
+			return nil, errors.New("查询签到状态失败")
+		}
+		signed := make(map[string]bool, len(checkins))
+		for _, c := range checkins {
+			signed[c.CheckinDate] = true
+		}
+		for i := config.Cfg.Checkin.MakeupDays; i >= 1; i-- {
+			if date := addCheckinDays(today, -i); !signed[date] {
+				resp.MakeupDates = append(resp.MakeupDates, date)
+			}
+		}
+	}
+	return resp, nil
+}
+
+// Checkin 今日签到
+func (s *CheckinService) Checkin(userID uint) (*CheckinResponse, error) {
+	today := time.Now().In(checkinLocation()).Format(checkinDateLayout)
+	return s.checkin(userID, today, false)
+}
+
+// Makeup 补签最近 makeup_days 天内漏签的日期
+func (s *CheckinService) Makeup(userID uint, req *MakeupCheckinRequest) (*CheckinResponse, error) {
+	cfg := config.Cfg.Checkin
+	if cfg.MakeupDays <= 0 {
+		return nil, errors.New("未开放补签")
+	}
+
+	loc := checkinLocation()
+	date, err := time.ParseInLocation(checkinDateLayout, req.Date, loc)
+	if err != nil {
+		return nil, errors.New("日期格式错误，应为 YYYY-MM-DD")
+	}
+	today := time.Now().In(loc).Format(checkinDateLayout)
+	dateStr := date.Format(checkinDateLayout)
+	if dateStr >= today {
+		return nil, errors.New("只能补签今天之前的日期")
+	}
+	if dateStr < addCheckinDays(today, -cfg.MakeupDays) {
+		return nil, fmt.Errorf("只能补签最近%d天的日期", cfg.MakeupDays)
+	}
+	return s.checkin(userID, dateStr, true)
+}
+
+// History 分页查询签到记录
+func (s *CheckinService) History(userID uint, req *CheckinHistoryQuery) (*util.PageResult, error) {
+	req.Normalize()
+
+	var startDate, endDate string
+	if req.Month != "" {
+		month, err := time.Parse("2006-01", req.Month)
+		if err != nil {
+			return nil, errors.New("月份格式错误，应为 YYYY-MM")
+		}
+		startDate = month.Format(checkinDateLayout)
+		endDate = month.AddDate(0, 1, -1).Format(checkinDateLayout)
+	}
+
+	checkins, total, err := s.checkinDAO.List(userID, startDate, endDate, req.Offset(), req.PageSize)
+	if err != nil {
+		return nil, errors.New("查询签到记录失败")
+	}
+	return util.NewPageResult(checkins, total, &req.PageQuery), nil
+}
+
+// checkin 在一个事务中写入签到记录、计算连续天数、扣除补签费用并发放奖励
+func (s *CheckinService) checkin(userID uint, date string, makeup bool) (*CheckinResponse, error) {
+	cfg := config.Cfg.Checkin
+	resp := &CheckinResponse{Date: date, Makeup: makeup}
+	var result *RewardResult
+
+	err := dao.Transaction(func(tx *gorm.DB) error {
+		if err := s.checkinDAO.LockUser(tx, userID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("用户不存在")
+			}
+			return checkinFailed(userID, date, err)
+		}
+
+		existing, err := s.checkinDAO.GetByDate(tx, userID, date)
+		if err != nil {
+			return checkinFailed(userID, date, err)
+		}
+		if existing != nil {
+			if makeup {
+				return errors.New("该日期已签到")
+			}
+			return errors.New("今天已签到")
+		}
+
+		if makeup {
+			// 在用户锁内统计，并发补签不会超出每月次数
+			remaining, err := makeupRemaining(time.Now().In(checkinLocation()), func(since time.Time) (int64, error) {
+				return s.checkinDAO.CountMakeupSinceTx(tx, userID, since)
+			})
+			if err != nil {
+				return checkinFailed(userID, date, err)
+			}
+			if remaining == 0 {
+				return errors.New("本月补签次数已用完")
+			}
+		}
+
+		prev, err := s.checkinDAO.GetByDate(tx, userID, addCheckinDays(date, -1))
+		if err != nil {
+			return checkinFailed(userID, date, err)
+		}
+		streak := 1
+		if prev != nil {
+			streak = prev.Streak + 1
+		}
+		resp.Streak = streak
+
+		checkin := &model.UserCheckin{
+			UserID:      userID,
+			CheckinDate: date,
+			Streak:      streak,
+			Makeup:      makeup,
+		}
+		if makeup {
+			if cost := checkinMakeupCost(); cost.IsPositive() {
+				if _, err := s.walletService.ChangeTx(tx, &dao.WalletChange{
+					UserID:      userID,
+					Currency:    model.CurrencyBalance,
+					Direction:   model.WalletDebit,
+					Amount:      cost,
+					ReasonCode:  model.WalletReasonCheckinMakeup,
+					ReferenceID: "checkin:" + date,
+					Remark:      "补签 " + date,
+				}); err != nil {
+					return err
+				}
+				checkin.MakeupCost = cost
+			}
+		}
+
+		var reward *CheckinReward
+		if !makeup || cfg.MakeupReward {
+			reward = checkinRewardFor(streak)
+		}
+		if reward != nil {
+			checkin.RewardExperience = reward.Experience
+			checkin.RewardActivityBalance = reward.ActivityBalance
+			checkin.RewardItems = reward.Items
+			resp.Reward = reward
+		}
+		if err := s.checkinDAO.Create(tx, checkin); err != nil {
+			return checkinFailed(userID, date, err)
+		}
+
+		// 补签填上空缺后，之后连续的签到记录天数顺延（已发放的奖励不变）
+		if makeup {
+			if err := s.restreak(tx, userID, date, streak); err != nil {
+				return checkinFailed(userID, date, err)
+			}
+		}
+
+		if reward == nil {
+			return nil
+		}
+		bundle := model.RewardBundle{
+			Experience:      reward.Experience,
+			ActivityBalance: reward.ActivityBalance,
+			Items:           reward.Items,
+		}
+		if bundle.IsEmpty() {
+			return nil
+		}
+		result, err = s.rewardService.GrantTx(tx, &RewardGrant{
+			UserID:      userID,
+			Bundle:      bundle,
+			Source:      model.ExperienceSourceSystem,
+			ReasonCode:  model.WalletReasonCheckin,
+			ReferenceID: "checkin:" + date,
+			Remark:      fmt.Sprintf("签到奖励（连续%d天）", streak),
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.rewardService.AfterGrant(result)
+	if result != nil && result.Progress != nil {
+		resp.Progress = &result.Progress.LevelProgressResponse
+	}
+	util.Info("签到: user_id=%d date=%s streak=%d makeup=%t", userID, date, resp.Streak, makeup)
+	return resp, nil
+}
+
+// restreak 从补签日期的后一天起，顺延连续的签到记录天数
+func (s *CheckinService) restreak(tx *gorm.DB, userID uint, date string, streak int) error {
+	checkins, err := s.checkinDAO.ListSince(tx, userID, addCheckinDays(date, 1))
+	if err != nil {
+		return err
+	}
+	for _, c := range followingStreaks(date, streak, checkins) {
+		if err := s.checkinDAO.UpdateStreak(tx, c.ID, c.Streak); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// followingStreaks 补签 date（连续 streak 天）后，紧随其后的连续签到记录顺延后的天数，遇到断签即停止
+// checkins 为 date 之后的签到记录（按日期升序）
+func followingStreaks(date string, streak int, checkins []model.UserCheckin) []model.UserCheckin {
+	var updated []model.UserCheckin
+	expected := addCheckinDays(date, 1)
+	for _, c := range checkins {
+		if c.CheckinDate != expected {
+			break
+		}
+		streak++
+		c.Streak = streak
+		updated = append(updated, c)
+		expected = addCheckinDays(expected, 1)
+	}
+	return updated
+}
+
+// makeupRemaining 本月剩余补签次数，-1 表示不限制；count 统计 since 之后已补签的次数
+func makeupRemaining(now time.Time, count func(since time.Time) (int64, error)) (int, error) {
+	cfg := config.Cfg.Checkin
+	if cfg.MakeupDays <= 0 {
+		return 0, nil
+	}
+	if cfg.MakeupLimit <= 0 {
+		return -1, nil
+	}
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	used, err := count(monthStart)
+	if err != nil {
+		return 0, err
+	}
+	if remaining := cfg.MakeupLimit - int(used); remaining > 0 {
+		return remaining, nil
+	}
+	return 0, nil
+}
+
+// checkinFailed 记录数据库错误并返回通用错误
+func checkinFailed(userID uint, date string, err error) error {
+	util.LogError("签到失败: user_id=%d date=%s err=%v", userID, date, err)
+	return errors.New("签到失败")
+}
+
+// checkinLocation 签到使用的时区，配置无效时使用服务器时区
+func checkinLocation() *time.Location {
+	name := config.Cfg.Checkin.Timezone
+	if name == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		util.LogError("签到时区配置错误: timezone=%s err=%v", name, err)
+		return time.Local
+	}
+	return loc
+}
+
+// addCheckinDays 日期加减天数
+func addCheckinDays(date string, days int) string {
+	t, err := time.Parse(checkinDateLayout, date)
+	if err != nil {
+		return date
+	}
+	return t.AddDate(0, 0, days).Format(checkinDateLayout)
+}
+
+// checkinRewardFor 连续签到第 streak 天的奖励，超过周期后循环
+func checkinRewardFor(streak int) *CheckinReward {
+	calendar := checkinCalendar()
+	if len(calendar) == 0 || streak < 1 {
+		return nil
+	}
+	cycle := config.Cfg.Checkin.CycleDays
+	if cycle <= 0 {
+		cycle = len(calendar)
+	}
+	day := (streak - 1) % cycle
+	if day >= len(calendar) {
+		day = len(calendar) - 1
+	}
+	return calendar[day]
+}
+
+// checkinCalendar 解析配置的奖励日历，金额或道具数量配置错误时忽略该项
+func checkinCalendar() []*CheckinReward {
+	rewards := config.Cfg.Checkin.Rewards
+	calendar := make([]*CheckinReward, 0, len(rewards))
+	for i, r := range rewards {
+		reward := &CheckinReward{Experience: r.Experience}
+		if r.ActivityBalance != "" {
+			amount, err := model.ParseMoney(r.ActivityBalance)
+			if err != nil || amount.IsNegative() {
+				util.LogError("签到奖励配置错误: day=%d activity_balance=%s", i+1, r.ActivityBalance)
+			} else {
+				reward.ActivityBalance = amount
+			}
+		}
+		for _, item := range r.Items {
+			if item.ItemID == 0 || item.Quantity <= 0 {
+				util.LogError("签到奖励配置错误: day=%d item_id=%d quantity=%d", i+1, item.ItemID, item.Quantity)
+				continue
+			}
+			reward.Items = append(reward.Items, model.RewardItem{ItemID: item.ItemID, Quantity: item.Quantity})
+		}
+		calendar = append(calendar, reward)
+	}
+	return calendar
+}
+
+func checkinMakeupCost() model.Money {
+	cost := config.Cfg.Checkin.MakeupCost
+	if cost == "" {
+		return 0
+	}
+	amount, err := model.ParseMoney(cost)
+	if err != nil || amount.IsNegative() {
+		util.LogError("补签费用配置错误: makeup_cost=%s", cost)
+		return 0
+	}
+	return amount
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"bgame/internal/config"
+	"bgame/internal/model"
+)
+
+func TestAddCheckinDays(t *testing.T) {
+	tests := []struct {
+		date string
+		days int
+		want string
+	}{
+		{"2026-10-18", 1, "2026-10-19"},
+		{"2026-10-31", 1, "2026-11-01"},
+		{"2026-03-01", -1, "2026-02-28"},
+		{"2028-03-01", -1, "2028-02-29"},
+		{"2026-12-31", 1, "2027-01-01"},
+		{"bad", 1, "bad"},
+	}
+	for _, tt := range tests {
+		if got := addCheckinDays(tt.date, tt.days); got != tt.want {
+			t.Errorf("addCheckinDays(%q, %d) = %q, want %q", tt.date, tt.days, got, tt.want)
+		}
+	}
+}
+
+func TestCheckinRewardForCycle(t *testing.T) {
+	rewards := make([]config.CheckinRewardConfig, 7)
+	for i := range rewards {
+		rewards[i].Experience = (i + 1) * 10
+	}
+	useConfig(t, &config.Config{Checkin: config.CheckinConfig{Rewards: rewards}})
+
+	// 未配置周期时等于奖励天数，第8天从第1天重新开始
+	for streak, want := range map[int]int{1: 10, 7: 70, 8: 10, 14: 70, 15: 10} {
+		if got := checkinRewardFor(streak); got == nil || got.Experience != want {
+			t.Errorf("checkinRewardFor(%d) = %+v, want experience %d", streak, got, want)
+		}
+	}
+	if got := checkinRewardFor(0); got != nil {
+		t.Errorf("checkinRewardFor(0) = %+v, want nil", got)
+	}
+}
+
+func TestCheckinRewardForLongCycle(t *testing.T) {
+	// 周期长于奖励天数时，超出部分沿用最后一天的奖励
+	useConfig(t, &config.Config{Checkin: config.CheckinConfig{
+		CycleDays: 5,
+		Rewards:   []config.CheckinRewardConfig{{Experience: 10}, {Experience: 20}, {Experience: 30}},
+	}})
+
+	for streak, want := range map[int]int{3: 30, 4: 30, 5: 30, 6: 10} {
+		if got := checkinRewardFor(streak); got == nil || got.Experience != want {
+			t.Errorf("checkinRewardFor(%d) = %+v, want experience %d", streak, got, want)
+		}
+	}
+}
+
+func TestCheckinCalendar(t *testing.T) {
+	useConfig(t, &config.Config{Checkin: config.CheckinConfig{
+		Rewards: []config.CheckinRewardConfig{
+			{Experience: 10, ActivityBalance: "0.50", Items: []config.RewardItemConfig{{ItemID: 1, Quantity: 2}}},
+			{ActivityBalance: "bad", Items: []config.RewardItemConfig{{ItemID: 0, Quantity: 1}, {ItemID: 2, Quantity: 0}}},
+		},
+	}})
+
+	calendar := checkinCalendar()
+	if len(calendar) != 2 {
+		t.Fatalf("len(calendar) = %d, want 2", len(calendar))
+	}
+	if calendar[0].ActivityBalance != 50 || len(calendar[0].Items) != 1 || calendar[0].Items[0].Quantity != 2 {
+		t.Errorf("day 1 = %+v", calendar[0])
+	}
+	// 配置错误的金额和道具被忽略，该天仍保留
+	if calendar[1].ActivityBalance != 0 || len(calendar[1].Items) != 0 {
+		t.Errorf("day 2 = %+v, want invalid entries dropped", calendar[1])
+	}
+}
+
+func TestCheckinMakeupCost(t *testing.T) {
+	for cost, want := range map[string]model.Money{"": 0, "1.00": 100, "0.5": 50, "-1": 0, "abc": 0} {
+		useConfig(t, &config.Config{Checkin: config.CheckinConfig{MakeupCost: cost}})
+		if got := checkinMakeupCost(); got != want {
+			t.Errorf("makeup_cost %q = %s, want %s", cost, got, want)
+		}
+	}
+}
+
+func TestMakeupRemaining(t *testing.T) {
+	now := time.Date(2026, 10, 18, 15, 0, 0, 0, time.UTC)
+	var since time.Time
+	count := func(used int64) func(time.Time) (int64, error) {
+		return func(s time.Time) (int64, error) {
+			since = s
+			return used, nil
+		}
+	}
+
+	useConfig(t, &config.Config{Checkin: config.CheckinConfig{MakeupDays: 0, MakeupLimit: 3}})
+	if got, _ := makeupRemaining(now, count(0)); got != 0 {
+		t.Errorf("makeup disabled: remaining = %d, want 0", got)
+	}
+
+	useConfig(t, &config.Config{Checkin: config.CheckinConfig{MakeupDays: 7}})
+	if got, _ := makeupRemaining(now, count(100)); got != -1 {
+		t.Errorf("no limit: remaining = %d, want -1", got)
+	}
+
+	useConfig(t, &config.Config{Checkin: config.CheckinConfig{MakeupDays: 7, MakeupLimit: 3}})
+	for used, want := range map[int64]int{0: 3, 2: 1, 3: 0, 5: 0} {
+		got, err := makeupRemaining(now, count(used))
+		if err != nil || got != want {
+			t.Errorf("used %d: remaining = %d, %v, want %d", used, got, err, want)
+		}
+	}
+	if want := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC); !since.Equal(want) {
+		t.Errorf("counted since %v, want month start %v", since, want)
+	}
+
+	failure := errors.New("db down")
+	if _, err := makeupRemaining(now, func(time.Time) (int64, error) { return 0, failure }); !errors.Is(err, failure) {
+		t.Errorf("count error = %v, want %v", err, failure)
+	}
+}
+
+func TestFollowingStreaks(t *testing.T) {
+	// 10-15 漏签，10-16、10-17 连续签到，10-19 断签后再签
+	checkins := []model.UserCheckin{
+		{ID: 1, CheckinDate: "2026-10-16", Streak: 1},
+		{ID: 2, CheckinDate: "2026-10-17", Streak: 2},
+		{ID: 3, CheckinDate: "2026-10-19", Streak: 1},
+	}
+
+	// 补签 10-15，前一天已连续签到4天，补签当天为第5天
+	updated := followingStreaks("2026-10-15", 5, checkins)
+	if len(updated) != 2 {
+		t.Fatalf("updated %d checkins, want 2: %+v", len(updated), updated)
+	}
+	if updated[0].ID != 1 || updated[0].Streak != 6 || updated[1].ID != 2 || updated[1].Streak != 7 {
+		t.Errorf("updated = %+v, want ids 1,2 with streak 6,7", updated)
+	}
+	if checkins[0].Streak != 1 {
+		t.Error("input checkins should not be modified")
+	}
+
+	// 补签日期之后第一天没有签到时不需要顺延
+	if updated := followingStreaks("2026-10-14", 1, checkins); len(updated) != 0 {
+		t.Errorf("gap after makeup: updated = %+v, want none", updated)
+	}
+
+	// 补签 10-18 将 10-19 接上
+	updated = followingStreaks("2026-10-18", 3, checkins[2:])
+	if len(updated) != 1 || updated[0].ID != 3 || updated[0].Streak != 4 {
+		t.Errorf("updated = %+v, want id 3 with streak 4", updated)
+	}
+}
@@ -0,0 +1,536 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"bgame/internal/config"
+	"bgame/internal/dao"
+	"bgame/internal/model"
+	"bgame/internal/util"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultMaxFriends         = 100
+	defaultMaxPendingRequests = 50
+	defaultMaxBlocks          = 200
+	defaultPresenceTTL        = 60 * time.Second
+)
+
+// errFriendBlocked 被对方屏蔽时不透露屏蔽关系
+var errFriendBlocked = errors.New("无法向该玩家发送好友申请")
+
+// FriendService 好友：申请（发送、同意、拒绝、撤回）、双向好友关系、屏蔽和在线状态
+// 好友关系变更都在锁定双方用户行的事务内完成；在线状态由客户端定期心跳写入 Redis，键过期即视为离线
+type FriendService struct {
+	friendDAO *dao.FriendDAO
+	userDAO   *dao.UserDAO
+}
+
+func NewFriendService() *FriendService {
+	return &FriendService{
+		friendDAO: dao.NewFriendDAO(),
+		userDAO:   dao.NewUserDAO(),
+	}
+}
+
+type SendFriendRequestRequest struct {
+	UserID  uint   `json:"user_id" binding:"required"`
+	Message string `json:"message" binding:"max=100"` // 附言
+}
+
+type FriendRequestQuery struct {
+	util.PageQuery
+	Direction string `form:"direction" binding:"omitempty,oneof=incoming outgoing"` // incoming 收到的（默认），outgoing 发出的
+}
+
+type BlockUserRequest struct {
+	UserID uint `json:"user_id" binding:"required"`
+}
+
+// FriendResponse 好友及其在线状态
+type FriendResponse struct {
+	UserID        uint       `json:"user_id"`
+	Nickname      string     `json:"nickname"`
+	Online        bool       `json:"online"`
+	LastHeartbeat *time.Time `json:"last_heartbeat,omitempty"` // 在线时为最近一次心跳时间
+	Since         time.Time  `json:"since"`                    // 成为好友的时间
+}
+
+// FriendRequestResponse 好友申请，nickname 为对方昵称
+type FriendRequestResponse struct {
+	model.FriendRequest
+	Nickname string `json:"nickname"`
+}
+
+// SendFriendRequestResponse 发送好友申请结果，对方已向自己发出申请时直接成为好友
+type SendFriendRequestResponse struct {
+	Request  *model.FriendRequest `json:"request"`
+	Accepted bool                 `json:"accepted"`
+}
+
+// BlockResponse 屏蔽的玩家
+type BlockResponse struct {
+	UserID    uint      `json:"user_id"`
+	Nickname  string    `json:"nickname"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// HeartbeatResponse 心跳结果
+type HeartbeatResponse struct {
+	Interval int `json:"interval"` // 建议的心跳间隔（秒）
+}
+
+// SendRequest 发送好友申请
+func (s *FriendService) SendRequest(userID uint, req *SendFriendRequestRequest) (*SendFriendRequestResponse, error) {
+	targetID := req.UserID
+	if targetID == userID {
+		return nil, errors.New("不能添加自己为好友")
+	}
+
+	resp := &SendFriendRequestResponse{}
+	err := dao.Transaction(func(tx *gorm.DB) error {
+		if err := s.lockPair(tx, userID, targetID); err != nil {
+			return err
+		}
+		blocked, blockedBy, err := s.friendDAO.GetBlockDirections(tx, userID, targetID)
+		if err != nil {
+			return friendFailed(userID, targetID, err)
+		}
+		if blocked {
+			return errors.New("您已屏蔽该玩家，请先解除屏蔽")
+		}
+		if blockedBy {
+			return errFriendBlocked
+		}
+		isFriend, err := s.friendDAO.IsFriend(tx, userID, targetID)
+		if err != nil {
+			return friendFailed(userID, targetID, err)
+		}
+		if isFriend {
+			return errors.New("对方已经是您的好友")
+		}
+
+		existing, err := s.friendDAO.FindPendingRequest(tx, userID, targetID)
+		if err != nil {
+			return friendFailed(userID, targetID, err)
+		}
+		if existing != nil {
+			return errors.New("已发送过好友申请，请等待对方处理")
+		}
+
+		// 对方已向自己发出申请，视为双方同意
+		reverse, err := s.friendDAO.FindPendingRequest(tx, targetID, userID)
+		if err != nil {
+			return friendFailed(userID, targetID, err)
+		}
+		if reverse != nil {
+			if err := s.acceptTx(tx, reverse); err != nil {
+				return err
+			}
+			resp.Request = reverse
+			resp.Accepted = true
+			return nil
+		}
+
+		if err := s.checkFriendLimit(tx, userID, targetID); err != nil {
+			return err
+		}
+		pending, err := s.friendDAO.CountPendingSent(tx, userID)
+		if err != nil {
+			return friendFailed(userID, targetID, err)
+		}
+		if limit := maxPendingFriendRequests(); pending >= int64(limit) {
+			return fmt.Errorf("待处理的好友申请最多%d条，请等待对方处理或撤回部分申请", limit)
+		}
+
+		request := &model.FriendRequest{
+			FromUserID: userID,
+			ToUserID:   targetID,
+			Message:    req.Message,
+			Status:     model.FriendRequestPending,
+		}
+		if err := s.friendDAO.CreateRequest(tx, request); err != nil {
+			return friendFailed(userID, targetID, err)
+		}
+		resp.Request = request
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	util.Info("发送好友申请: user_id=%d target_id=%d request_id=%d accepted=%v", userID, targetID, resp.Request.ID, resp.Accepted)
+	return resp, nil
+}
+
+// AcceptRequest 同意好友申请
+func (s *FriendService) AcceptRequest(userID, requestID uint) (*model.FriendRequest, error) {
+	request, err := s.handleRequest(userID, requestID, func(tx *gorm.DB, request *model.FriendRequest) error {
+		if request.ToUserID != userID {
+			return errors.New("好友申请不存在")
+		}
+		blocked, blockedBy, err := s.friendDAO.GetBlockDirections(tx, userID, request.FromUserID)
+		if err != nil {
+			return friendFailed(userID, request.FromUserID, err)
+		}
+		if blocked || blockedBy {
+			return errors.New("无法添加该玩家为好友")
+		}
+		return s.acceptTx(tx, request)
+	})
+	if err != nil {
+		return nil, err
+	}
+	util.Info("同意好友申请: user_id=%d friend_id=%d request_id=%d", userID, request.FromUserID, request.ID)
+	return request, nil
+}
+
+// DeclineRequest 拒绝好友申请
+func (s *FriendService) DeclineRequest(userID, requestID uint) (*model.FriendRequest, error) {
+	return s.handleRequest(userID, requestID, func(tx *gorm.DB, request *model.FriendRequest) error {
+		if request.ToUserID != userID {
+			return errors.New("好友申请不存在")
+		}
+		return s.closeTx(tx, request, model.FriendRequestDeclined)
+	})
+}
+
+// CancelRequest 撤回自己发出的好友申请
+func (s *FriendService) CancelRequest(userID, requestID uint) (*model.FriendRequest, error) {
+	return s.handleRequest(userID, requestID, func(tx *gorm.DB, request *model.FriendRequest) error {
+		if request.FromUserID != userID {
+			return errors.New("好友申请不存在")
+		}
+		return s.closeTx(tx, request, model.FriendRequestCanceled)
+	})
+}
+
+// ListRequests 分页查询收到或发出的待处理申请
+func (s *FriendService) ListRequests(userID uint, req *FriendRequestQuery) (*util.PageResult, error) {
+	req.Normalize()
+	outgoing := req.Direction == "outgoing"
+	requests, total, err := s.friendDAO.ListPendingRequests(userID, outgoing, req.Offset(), req.PageSize)
+	if err != nil {
+		return nil, errors.New("查询好友申请失败")
+	}
+
+	ids := make([]uint, 0, len(requests))
+	for _, r := range requests {
+		if outgoing {
+			ids = append(ids, r.ToUserID)
+		} else {
+			ids = append(ids, r.FromUserID)
+		}
+	}
+	nicknames, err := s.userDAO.GetNicknames(ids)
+	if err != nil {
+		return nil, errors.New("查询好友申请失败")
+	}
+
+	list := make([]FriendRequestResponse, 0, len(requests))
+	for i, r := range requests {
+		list = append(list, FriendRequestResponse{FriendRequest: r, Nickname: nicknames[ids[i]]})
+	}
+	return util.NewPageResult(list, total, &req.PageQuery), nil
+}
+
+// ListFriends 分页查询好友及其在线状态
+func (s *FriendService) ListFriends(userID uint, req *util.PageQuery) (*util.PageResult, error) {
+	req.Normalize()
+	friendships, total, err := s.friendDAO.ListFriends(userID, req.Offset(), req.PageSize)
+	if err != nil {
+		return nil, errors.New("查询好友失败")
+	}
+
+	ids := make([]uint, 0, len(friendships))
+	for _, f := range friendships {
+		ids = append(ids, f.FriendID)
+	}
+	nicknames, err := s.userDAO.GetNicknames(ids)
+	if err != nil {
+		return nil, errors.New("查询好友失败")
+	}
+	presence, err := s.friendDAO.GetPresence(ids)
+	if err != nil {
+		// Redis 不可用时好友列表仍可查询，全部显示为离线
+		util.LogError("查询好友在线状态失败: user_id=%d err=%v", userID, err)
+		presence = map[uint]time.Time{}
+	}
+
+	list := make([]FriendResponse, 0, len(friendships))
+	for _, f := range friendships {
+		item := FriendResponse{
+			UserID:   f.FriendID,
+			Nickname: nicknames[f.FriendID],
+			Since:    f.CreatedAt,
+		}
+		if t, ok := presence[f.FriendID]; ok {
+			item.Online = true
+			item.LastHeartbeat = &t
+		}
+		list = append(list, item)
+	}
+	return util.NewPageResult(list, total, req), nil
+}
+
+// RemoveFriend 删除好友，双方的好友关系同时解除
+func (s *FriendService) RemoveFriend(userID, friendID uint) error {
+	err := dao.Transaction(func(tx *gorm.DB) error {
+		if err := s.lockPair(tx, userID, friendID); err != nil {
+			return err
+		}
+		removed, err := s.friendDAO.DeleteFriendship(tx, userID, friendID)
+		if err != nil {
+			return friendFailed(userID, friendID, err)
+		}
+		if !removed {
+			return errors.New("对方不是您的好友")
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	util.Info("删除好友: user_id=%d friend_id=%d", userID, friendID)
+	return nil
+}
+
+// Block 屏蔽玩家：解除好友关系并关闭双方之间待处理的申请，之后对方不能再发送申请和消息
+func (s *FriendService) Block(userID uint, req *BlockUserRequest) error {
+	targetID := req.UserID
+	if targetID == userID {
+		return errors.New("不能屏蔽自己")
+	}
+	err := dao.Transaction(func(tx *gorm.DB) error {
+		if err := s.lockPair(tx, userID, targetID); err != nil {
+			return err
+		}
+		blocked, _, err := s.friendDAO.GetBlockDirections(tx, userID, targetID)
+		if err != nil {
+			return friendFailed(userID, targetID, err)
+		}
+		if blocked {
+			return errors.New("已屏蔽该玩家")
+		}
+		count, err := s.friendDAO.CountBlocks(tx, userID)
+		if err != nil {
+			return friendFailed(userID, targetID, err)
+		}
+		if limit := maxBlocks(); count >= int64(limit) {
+			return fmt.Errorf("最多屏蔽%d名玩家", limit)
+		}
+
+		if err := s.friendDAO.CreateBlock(tx, userID, targetID); err != nil {
+			return friendFailed(userID, targetID, err)
+		}
+		if _, err := s.friendDAO.DeleteFriendship(tx, userID, targetID); err != nil {
+			return friendFailed(userID, targetID, err)
+		}
+		if err := s.friendDAO.ClosePendingBetween(tx, userID, targetID, model.FriendRequestCanceled, time.Now()); err != nil {
+			return friendFailed(userID, targetID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	util.Info("屏蔽玩家: user_id=%d target_id=%d", userID, targetID)
+	return nil
+}
+
+// Unblock 解除屏蔽，不会恢复之前的好友关系
+func (s *FriendService) Unblock(userID, targetID uint) error {
+	removed, err := s.friendDAO.DeleteBlock(userID, targetID)
+	if err != nil {
+		return errors.New("解除屏蔽失败")
+	}
+	if !removed {
+		return errors.New("未屏蔽该玩家")
+	}
+	util.Info("解除屏蔽: user_id=%d target_id=%d", userID, targetID)
+	return nil
+}
+
+// ListBlocks 分页查询屏蔽的玩家
+func (s *FriendService) ListBlocks(userID uint, req *util.PageQuery) (*util.PageResult, error) {
+	req.Normalize()
+	blocks, total, err := s.friendDAO.ListBlocks(userID, req.Offset(), req.PageSize)
+	if err != nil {
+		return nil, errors.New("查询屏蔽列表失败")
+	}
+
+	ids := make([]uint, 0, len(blocks))
+	for _, b := range blocks {
+		ids = append(ids, b.BlockedUserID)
+	}
+	nicknames, err := s.userDAO.GetNicknames(ids)
+	if err != nil {
+		return nil, errors.New("查询屏蔽列表失败")
+	}
+
+	list := make([]BlockResponse, 0, len(blocks))
+	for _, b := range blocks {
+		list = append(list, BlockResponse{
+			UserID:    b.BlockedUserID,
+			Nickname:  nicknames[b.BlockedUserID],
+			CreatedAt: b.CreatedAt,
+		})
+	}
+	return util.NewPageResult(list, total, req), nil
+}
+
+// CanMessage 发送方能否向接收方发送消息：接收方屏蔽了发送方时不能发送，供聊天等功能调用
+func (s *FriendService) CanMessage(fromUserID, toUserID uint) (bool, error) {
+	blocked, err := s.friendDAO.HasBlock(toUserID, fromUserID)
+	if err != nil {
+		return false, err
+	}
+	return !blocked, nil
+}
+
+// Heartbeat 记录在线心跳，客户端应按返回的间隔定期调用
+func (s *FriendService) Heartbeat(userID uint) (*HeartbeatResponse, error) {
+	ttl := presenceTTL()
+	if err := s.friendDAO.Heartbeat(userID, time.Now(), ttl); err != nil {
+		util.LogError("写入在线心跳失败: user_id=%d err=%v", userID, err)
+		return nil, errors.New("心跳失败")
+	}
+	interval := int(ttl/time.Second) / 2
+	if interval < 1 {
+		interval = 1
+	}
+	return &HeartbeatResponse{Interval: interval}, nil
+}
+
+// handleRequest 在锁定双方用户行和申请的事务内处理待处理的好友申请
+// 与发送申请相同，先锁用户行再锁申请，避免加锁顺序不一致导致死锁
+func (s *FriendService) handleRequest(userID, requestID uint, fn func(tx *gorm.DB, request *model.FriendRequest) error) (*model.FriendRequest, error) {
+	request, err := s.friendDAO.GetRequest(requestID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("好友申请不存在")
+		}
+		return nil, errors.New("查询好友申请失败")
+	}
+	if request.FromUserID != userID && request.ToUserID != userID {
+		return nil, errors.New("好友申请不存在")
+	}
+
+	err = dao.Transaction(func(tx *gorm.DB) error {
+		if err := s.lockPair(tx, request.FromUserID, request.ToUserID); err != nil {
+			return err
+		}
+		var err error
+		request, err = s.friendDAO.LockRequest(tx, requestID)
+		if err != nil {
+			return friendFailed(userID, 0, err)
+		}
+		if request.Status != model.FriendRequestPending {
+			return errors.New("好友申请已处理")
+		}
+		return fn(tx, request)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+// acceptTx 在事务内同意申请：校验双方好友数上限，写入双向好友关系，同时关闭双方之间的其他待处理申请
+func (s *FriendService) acceptTx(tx *gorm.DB, request *model.FriendRequest) error {
+	if err := s.checkFriendLimit(tx, request.ToUserID, request.FromUserID); err != nil {
+		return err
+	}
+	if err := s.friendDAO.CreateFriendship(tx, request.FromUserID, request.ToUserID); err != nil {
+		return friendFailed(request.ToUserID, request.FromUserID, err)
+	}
+	now := time.Now()
+	if err := s.friendDAO.UpdateRequestStatus(tx, request.ID, model.FriendRequestAccepted, now); err != nil {
+		return friendFailed(request.ToUserID, request.FromUserID, err)
+	}
+	if err := s.friendDAO.ClosePendingBetween(tx, request.FromUserID, request.ToUserID, model.FriendRequestAccepted, now); err != nil {
+		return friendFailed(request.ToUserID, request.FromUserID, err)
+	}
+	request.Status = model.FriendRequestAccepted
+	request.HandledAt = &now
+	return nil
+}
+
+// closeTx 在事务内拒绝或撤回申请
+func (s *FriendService) closeTx(tx *gorm.DB, request *model.FriendRequest, status string) error {
+	now := time.Now()
+	if err := s.friendDAO.UpdateRequestStatus(tx, request.ID, status, now); err != nil {
+		return friendFailed(request.FromUserID, request.ToUserID, err)
+	}
+	request.Status = status
+	request.HandledAt = &now
+	return nil
+}
+
+// checkFriendLimit 校验双方的好友数是否已达上限，userID 为当前操作的玩家
+func (s *FriendService) checkFriendLimit(tx *gorm.DB, userID, otherID uint) error {
+	limit := int64(maxFriends())
+	count, err := s.friendDAO.CountFriends(tx, userID)
+	if err != nil {
+		return friendFailed(userID, otherID, err)
+	}
+	if count >= limit {
+		return fmt.Errorf("好友数量已达上限（%d）", limit)
+	}
+	count, err = s.friendDAO.CountFriends(tx, otherID)
+	if err != nil {
+		return friendFailed(userID, otherID, err)
+	}
+	if count >= limit {
+		return errors.New("对方好友数量已达上限")
+	}
+	return nil
+}
+
+// lockPair 锁定双方用户行，对方不存在时返回错误
+func (s *FriendService) lockPair(tx *gorm.DB, userID, otherID uint) error {
+	existing, err := s.friendDAO.LockUsers(tx, userID, otherID)
+	if err != nil {
+		return friendFailed(userID, otherID, err)
+	}
+	if len(existing) != 2 {
+		return errors.New("玩家不存在")
+	}
+	return nil
+}
+
+// friendFailed 记录数据库错误并返回通用错误
+func friendFailed(userID, otherID uint, err error) error {
+	util.LogError("好友操作失败: user_id=%d other_id=%d err=%v", userID, otherID, err)
+	return errors.New("操作失败，请重试")
+}
+
+func maxFriends() int {
+	if n := config.Cfg.Friend.MaxFriends; n > 0 {
+		return n
+	}
+	return defaultMaxFriends
+}
+
+func maxPendingFriendRequests() int {
+	if n := config.Cfg.Friend.MaxPendingRequests; n > 0 {
+		return n
+	}
+	return defaultMaxPendingRequests
+}
+
+func maxBlocks() int {
+	if n := config.Cfg.Friend.MaxBlocks; n > 0 {
+		return n
+	}
+	return defaultMaxBlocks
+}
+
+func presenceTTL() time.Duration {
+	if ttl := config.Cfg.Friend.HeartbeatTTL; ttl > 0 {
+		return time.Duration(ttl) * time.Second
+	}
+	return defaultPresenceTTL
+}
@@ -0,0 +1,419 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"bgame/internal/dao"
+	"bgame/internal/model"
+	"bgame/internal/util"
+	"gorm.io/gorm"
+)
+
+// maxNonStackableGrant 不可堆叠道具单次发放数量上限（每件单独一行）
+const maxNonStackableGrant = 100
+
+// InventoryService 玩家背包：道具的发放和消耗在调用方事务中原子执行，每次变动写入道具流水
+type InventoryService struct {
+	inventoryDAO *dao.InventoryDAO
+	itemDAO      *dao.ItemDAO
+}
+
+func NewInventoryService() *InventoryService {
+	return &InventoryService{
+		inventoryDAO: dao.NewInventoryDAO(),
+		itemDAO:      dao.NewItemDAO(),
+	}
+}
+
+// ItemChange 一次道具发放或消耗
+type ItemChange struct {
+	UserID      uint
+	ItemID      uint
+	Quantity    int // 发放或消耗的数量，必须大于0
+	Source      string
+	ReasonCode  string
+	ReferenceID string // 不为空时同一用户、道具、原因码下只处理一次
+	OperatorID  uint
+	Operator    string
+	Remark      string
+}
+
+// ServerItemChangeRequest 服务端发放/消耗道具请求，item_id 和 item_code 二选一
+type ServerItemChangeRequest struct {
+	UserID      uint   `json:"user_id" binding:"required"`
+	ItemID      uint   `json:"item_id"`
+	ItemCode    string `json:"item_code" binding:"max=50"`
+	Quantity    int    `json:"quantity" binding:"required,gt=0"`
+	ReasonCode  string `json:"reason_code" binding:"required,max=50"`
+	ReferenceID string `json:"reference_id" binding:"max=64"`
+	Remark      string `json:"remark" binding:"max=255"`
+}
+
+// AdminItemChangeRequest 管理员发放/扣除道具请求
+type AdminItemChangeRequest struct {
+	ItemID   uint   `json:"item_id" binding:"required"`
+	Quantity int    `json:"quantity" binding:"required,gt=0"`
+	Remark   string `json:"remark" binding:"required,max=255"`
+}
+
+type ItemChangeResponse struct {
+	LogID     uint `json:"log_id"`
+	UserID    uint `json:"user_id"`
+	ItemID    uint `json:"item_id"`
+	Change    int  `json:"change"`    // 获得为正、消耗为负
+	Quantity  int  `json:"quantity"`  // 变动后持有数量（不含已过期）
+	Duplicate bool `json:"duplicate"` // 关联业务ID已处理过，本次未重复变动
+}
+
+type InventoryQuery struct {
+	util.PageQuery
+	Category string `form:"category"`
+}
+
+type ItemLogQuery struct {
+	util.PageQuery
+	ItemID     uint   `form:"item_id"`
+	ReasonCode string `form:"reason_code"`
+}
+
+type AdminItemLogQuery struct {
+	ItemLogQuery
+	UserID      uint      `form:"user_id"`
+	Source      string    `form:"source"`
+	ReferenceID string    `form:"reference_id"`
+	StartTime   time.Time `form:"start_time" time_format:"2006-01-02 15:04:05"`
+	EndTime     time.Time `form:"end_time" time_format:"2006-01-02 15:04:05"`
+}
+
+// ServerGrant 服务端发放道具
+func (s *InventoryService) ServerGrant(client string, req *ServerItemChangeRequest) (*ItemChangeResponse, error) {
+	change, err := s.serverChange(client, req)
+	if err != nil {
+		return nil, err
+	}
+	return s.Grant(change)
+}
+
+// ServerConsume 服务端消耗道具
+func (s *InventoryService) ServerConsume(client string, req *ServerItemChangeRequest) (*ItemChangeResponse, error) {
+	change, err := s.serverChange(client, req)
+	if err != nil {
+		return nil, err
+	}
+	return s.Consume(change)
+}
+
+// AdminGrant 管理员发放道具
+func (s *InventoryService) AdminGrant(operatorID uint, operator string, userID uint, req *AdminItemChangeRequest) (*ItemChangeResponse, error) {
+	return s.Grant(&ItemChange{
+		UserID:     userID,
+		ItemID:     req.ItemID,
+		Quantity:   req.Quantity,
+		Source:     model.ItemSourceAdmin,
+		ReasonCode: model.ItemReasonAdminGrant,
+		OperatorID: operatorID,
+		Operator:   operator,
+		Remark:     req.Remark,
+	})
+}
+
+// AdminConsume 管理员扣除道具
+func (s *InventoryService) AdminConsume(operatorID uint, operator string, userID uint, req *AdminItemChangeRequest) (*ItemChangeResponse, error) {
+	return s.Consume(&ItemChange{
+		UserID:     userID,
+		ItemID:     req.ItemID,
+		Quantity:   req.Quantity,
+		Source:     model.ItemSourceAdmin,
+		ReasonCode: model.ItemReasonAdminConsume,
+		OperatorID: operatorID,
+		Operator:   operator,
+		Remark:     req.Remark,
+	})
+}
+
+// Grant 发放道具（独立事务）
+func (s *InventoryService) Grant(change *ItemChange) (*ItemChangeResponse, error) {
+	var resp *ItemChangeResponse
+	err := dao.Transaction(func(tx *gorm.DB) error {
+		var err error
+		resp, err = s.GrantTx(tx, change)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Duplicate {
+		util.Info("发放道具: user_id=%d item_id=%d quantity=%d source=%s reason_code=%s operator=%s",
+			change.UserID, change.ItemID, change.Quantity, change.Source, change.ReasonCode, change.Operator)
+	}
+	return resp, nil
+}
+
+// Consume 消耗道具（独立事务）
+func (s *InventoryService) Consume(change *ItemChange) (*ItemChangeResponse, error) {
+	var resp *ItemChangeResponse
+	err := dao.Transaction(func(tx *gorm.DB) error {
+		var err error
+		resp, err = s.ConsumeTx(tx, change)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Duplicate {
+		util.Info("消耗道具: user_id=%d item_id=%d quantity=%d source=%s reason_code=%s operator=%s",
+			change.UserID, change.ItemID, change.Quantity, change.Source, change.ReasonCode, change.Operator)
+	}
+	return resp, nil
+}
+
+// GrantTx 在调用方事务中发放道具：校验道具状态和持有上限，可堆叠道具按过期时间合并
+func (s *InventoryService) GrantTx(tx *gorm.DB, change *ItemChange) (*ItemChangeResponse, error) {
+	if err := validateItemChange(change); err != nil {
+		return nil, err
+	}
+	item, err := s.itemDAO.GetByID(change.ItemID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("道具不存在: %d", change.ItemID)
+		}
+		return nil, inventoryFailed(change, err)
+	}
+	if item.Status != model.ItemStatusActive {
+		return nil, fmt.Errorf("道具已停用: %s", item.Name)
+	}
+	if !item.Stackable && change.Quantity > maxNonStackableGrant {
+		return nil, fmt.Errorf("不可堆叠道具单次最多发放%d件", maxNonStackableGrant)
+	}
+
+	resp, total, err := s.begin(tx, change)
+	if err != nil || resp != nil {
+		return resp, err
+	}
+	if item.HoldLimit > 0 && total+change.Quantity > item.HoldLimit {
+		return nil, fmt.Errorf("超出道具持有上限: %s 最多持有%d", item.Name, item.HoldLimit)
+	}
+
+	var expireAt *time.Time
+	if item.ValidSeconds > 0 {
+		t := time.Now().Add(time.Duration(item.ValidSeconds) * time.Second).Truncate(time.Second)
+		expireAt = &t
+	}
+
+	if item.Stackable {
+		stack, err := s.inventoryDAO.FindStack(tx, change.UserID, item.ID, expireAt)
+		if err != nil {
+			return nil, inventoryFailed(change, err)
+		}
+		if stack != nil {
+			err = s.inventoryDAO.AddQuantity(tx, stack.ID, change.Quantity)
+		} else {
+			err = s.inventoryDAO.CreateStacks(tx, []model.UserItem{
+				{UserID: change.UserID, ItemID: item.ID, Quantity: change.Quantity, ExpireAt: expireAt},
+			})
+		}
+		if err != nil {
+			return nil, inventoryFailed(change, err)
+		}
+	} else {
+		stacks := make([]model.UserItem, change.Quantity)
+		for i := range stacks {
+			stacks[i] = model.UserItem{UserID: change.UserID, ItemID: item.ID, Quantity: 1, ExpireAt: expireAt}
+		}
+		if err := s.inventoryDAO.CreateStacks(tx, stacks); err != nil {
+			return nil, inventoryFailed(change, err)
+		}
+	}
+
+	return s.writeLog(tx, change, change.Quantity, total+change.Quantity)
+}
+
+// ConsumeTx 在调用方事务中消耗道具：数量不足时整体失败，优先消耗最早过期的道具
+func (s *InventoryService) ConsumeTx(tx *gorm.DB, change *ItemChange) (*ItemChangeResponse, error) {
+	if err := validateItemChange(change); err != nil {
+		return nil, err
+	}
+
+	resp, total, err := s.begin(tx, change)
+	if err != nil || resp != nil {
+		return resp, err
+	}
+	if total < change.Quantity {
+		return nil, errors.New("道具数量不足")
+	}
+
+	stacks, err := s.inventoryDAO.ListAvailable(tx, change.UserID, change.ItemID, time.Now())
+	if err != nil {
+		return nil, inventoryFailed(change, err)
+	}
+	remaining := change.Quantity
+	for _, stack := range stacks {
+		if remaining == 0 {
+			break
+		}
+		used := stack.Quantity
+		if used > remaining {
+			used = remaining
+		}
+		if used == stack.Quantity {
+			err = s.inventoryDAO.DeleteStack(tx, stack.ID)
+		} else {
+			err = s.inventoryDAO.AddQuantity(tx, stack.ID, -used)
+		}
+		if errors.Is(err, dao.ErrInsufficientItems) {
+			return nil, err
+		}
+		if err != nil {
+			return nil, inventoryFailed(change, err)
+		}
+		remaining -= used
+	}
+
+	return s.writeLog(tx, change, -change.Quantity, total-change.Quantity)
+}
+
+// ListUserItems 分页查询玩家背包中未过期的道具
+func (s *InventoryService) ListUserItems(userID uint, req *InventoryQuery) (*util.PageResult, error) {
+	req.Normalize()
+	stacks, total, err := s.inventoryDAO.ListUserItems(userID, req.Category, time.Now(), req.Offset(), req.PageSize)
+	if err != nil {
+		return nil, errors.New("查询背包失败")
+	}
+	return util.NewPageResult(stacks, total, &req.PageQuery), nil
+}
+
+// ListUserLogs 分页查询玩家的道具流水
+func (s *InventoryService) ListUserLogs(userID uint, req *ItemLogQuery) (*util.PageResult, error) {
+	req.Normalize()
+	logs, total, err := s.inventoryDAO.ListLogs(&dao.ItemLogFilter{
+		UserID:     userID,
+		ItemID:     req.ItemID,
+		ReasonCode: req.ReasonCode,
+	}, req.Offset(), req.PageSize)
+	if err != nil {
+		return nil, errors.New("查询道具流水失败")
+	}
+	return util.NewPageResult(logs, total, &req.PageQuery), nil
+}
+
+// ListLogs 管理员分页查询道具流水
+func (s *InventoryService) ListLogs(req *AdminItemLogQuery) (*util.PageResult, error) {
+	req.Normalize()
+	logs, total, err := s.inventoryDAO.ListLogs(&dao.ItemLogFilter{
+		UserID:      req.UserID,
+		ItemID:      req.ItemID,
+		Source:      req.Source,
+		ReasonCode:  req.ReasonCode,
+		ReferenceID: req.ReferenceID,
+		StartTime:   req.StartTime,
+		EndTime:     req.EndTime,
+	}, req.Offset(), req.PageSize)
+	if err != nil {
+		return nil, errors.New("查询道具流水失败")
+	}
+	return util.NewPageResult(logs, total, &req.PageQuery), nil
+}
+
+// begin 锁定用户并检查关联业务ID是否已处理；已处理时返回原流水对应的响应
+func (s *InventoryService) begin(tx *gorm.DB, change *ItemChange) (*ItemChangeResponse, int, error) {
+	if err := s.inventoryDAO.LockUser(tx, change.UserID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, 0, errors.New("用户不存在")
+		}
+		return nil, 0, inventoryFailed(change, err)
+	}
+
+	if change.ReferenceID != "" {
+		existing, err := s.inventoryDAO.FindLogByReference(tx, change.UserID, change.ItemID, change.ReasonCode, change.ReferenceID)
+		if err != nil {
+			return nil, 0, inventoryFailed(change, err)
+		}
+		if existing != nil {
+			return buildItemChangeResponse(existing, true), 0, nil
+		}
+	}
+
+	total, err := s.inventoryDAO.SumAvailable(tx, change.UserID, change.ItemID, time.Now())
+	if err != nil {
+		return nil, 0, inventoryFailed(change, err)
+	}
+	return nil, total, nil
+}
+
+// writeLog 写入道具流水
+func (s *InventoryService) writeLog(tx *gorm.DB, change *ItemChange, delta, quantityAfter int) (*ItemChangeResponse, error) {
+	log := &model.ItemLog{
+		UserID:        change.UserID,
+		ItemID:        change.ItemID,
+		Change:        delta,
+		QuantityAfter: quantityAfter,
+		Source:        change.Source,
+		ReasonCode:    change.ReasonCode,
+		ReferenceID:   change.ReferenceID,
+		OperatorID:    change.OperatorID,
+		Operator:      change.Operator,
+		Remark:        change.Remark,
+	}
+	if err := s.inventoryDAO.CreateLog(tx, log); err != nil {
+		return nil, inventoryFailed(change, err)
+	}
+	return buildItemChangeResponse(log, false), nil
+}
+
+// serverChange 将服务端请求转换为道具变动，按 item_code 查找道具
+func (s *InventoryService) serverChange(client string, req *ServerItemChangeRequest) (*ItemChange, error) {
+	itemID := req.ItemID
+	if itemID == 0 {
+		if req.ItemCode == "" {
+			return nil, errors.New("item_id 和 item_code 不能同时为空")
+		}
+		item, err := s.itemDAO.GetByCode(req.ItemCode)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errors.New("道具不存在")
+			}
+			return nil, errors.New("查询道具失败")
+		}
+		itemID = item.ID
+	}
+	return &ItemChange{
+		UserID:      req.UserID,
+		ItemID:      itemID,
+		Quantity:    req.Quantity,
+		Source:      model.ItemSourceServer,
+		ReasonCode:  req.ReasonCode,
+		ReferenceID: req.ReferenceID,
+		Operator:    client,
+		Remark:      req.Remark,
+	}, nil
+}
+
+func validateItemChange(change *ItemChange) error {
+	if change.Quantity <= 0 {
+		return errors.New("道具数量必须大于0")
+	}
+	if change.ReasonCode == "" {
+		return errors.New("原因码不能为空")
+	}
+	return nil
+}
+
+func buildItemChangeResponse(log *model.ItemLog, duplicate bool) *ItemChangeResponse {
+	return &ItemChangeResponse{
+		LogID:     log.ID,
+		UserID:    log.UserID,
+		ItemID:    log.ItemID,
+		Change:    log.Change,
+		Quantity:  log.QuantityAfter,
+		Duplicate: duplicate,
+	}
+}
+
+// inventoryFailed 记录数据库错误并返回通用错误
+func inventoryFailed(change *ItemChange, err error) error {
+	util.LogError("背包变动失败: user_id=%d item_id=%d quantity=%d reason_code=%s err=%v",
+		change.UserID, change.ItemID, change.Quantity, change.ReasonCode, err)
+	return errors.New("背包变动失败")
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"bgame/internal/dao"
+	"bgame/internal/model"
+	"bgame/internal/util"
+	"gorm.io/gorm"
+)
+
+// itemCodePattern 道具标识：小写字母、数字和下划线
+var itemCodePattern = regexp.MustCompile(`^[a-z0-9_]{2,50}$`)
+
+// ItemService 道具目录管理
+type ItemService struct {
+	itemDAO *dao.ItemDAO
+}
+
+func NewItemService() *ItemService {
+	return &ItemService{
+		itemDAO: dao.NewItemDAO(),
+	}
+}
+
+type CreateItemRequest struct {
+	Code         string `json:"code" binding:"required,max=50"` // 道具标识，如 potion_small，创建后不可修改
+	Name         string `json:"name" binding:"required,max=100"`
+	Description  string `json:"description" binding:"max=500"`
+	Icon         string `json:"icon" binding:"max=255"`
+	Category     string `json:"category" binding:"required"`
+	Stackable    *bool  `json:"stackable"`                     // 是否可堆叠，默认 true，创建后不可修改
+	HoldLimit    int    `json:"hold_limit" binding:"min=0"`    // 单个玩家持有数量上限，0表示不限
+	ValidSeconds int    `json:"valid_seconds" binding:"min=0"` // 获得后的有效期（秒），0表示永久
+}
+
+type UpdateItemRequest struct {
+	Name         *string `json:"name" binding:"omitempty,min=1,max=100"`
+	Description  *string `json:"description" binding:"omitempty,max=500"`
+	Icon         *string `json:"icon" binding:"omitempty,max=255"`
+	Category     *string `json:"category"`
+	HoldLimit    *int    `json:"hold_limit" binding:"omitempty,min=0"`
+	ValidSeconds *int    `json:"valid_seconds" binding:"omitempty,min=0"` // 只影响之后获得的道具
+	Status       *int    `json:"status" binding:"omitempty,oneof=0 1"`    // 1:启用 0:停用
+}
+
+type ItemQuery struct {
+	util.PageQuery
+	Keyword  string `form:"keyword"` // 按标识或名称模糊匹配
+	Category string `form:"category"`
+	Status   *int   `form:"status"`
+}
+
+// CreateItem 创建道具
+func (s *ItemService) CreateItem(operatorID uint, req *CreateItemRequest) (*model.Item, error) {
+	code := strings.ToLower(strings.TrimSpace(req.Code))
+	if !itemCodePattern.MatchString(code) {
+		return nil, errors.New("道具标识只能包含小写字母、数字和下划线，长度2到50位")
+	}
+	if !model.IsValidItemCategory(req.Category) {
+		return nil, errors.New("无效的道具分类")
+	}
+	if _, err := s.itemDAO.GetByCode(code); err == nil {
+		return nil, errors.New("道具标识已存在")
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("创建道具失败")
+	}
+
+	item := &model.Item{
+		Code:         code,
+		Name:         req.Name,
+		Description:  req.Description,
+		Icon:         req.Icon,
+		Category:     req.Category,
+		Stackable:    true,
+		HoldLimit:    req.HoldLimit,
+		ValidSeconds: req.ValidSeconds,
+		Status:       model.ItemStatusActive,
+	}
+	if req.Stackable != nil {
+		item.Stackable = *req.Stackable
+	}
+	if err := s.itemDAO.Create(item); err != nil {
+		util.LogError("创建道具失败: code=%s err=%v", code, err)
+		return nil, errors.New("创建道具失败")
+	}
+
+	util.Info("创建道具: item_id=%d code=%s operator_id=%d", item.ID, item.Code, operatorID)
+	return item, nil
+}
+
+// UpdateItem 修改道具信息
+func (s *ItemService) UpdateItem(operatorID, itemID uint, req *UpdateItemRequest) (*model.Item, error) {
+	if _, err := s.GetItem(itemID); err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]interface{})
+	if req.Name != nil {
+		fields["name"] = *req.Name
+	}
+	if req.Description != nil {
+		fields["description"] = *req.Description
+	}
+	if req.Icon != nil {
+		fields["icon"] = *req.Icon
+	}
+	if req.Category != nil {
+		if !model.IsValidItemCategory(*req.Category) {
+			return nil, errors.New("无效的道具分类")
+		}
+		fields["category"] = *req.Category
+	}
+	if req.HoldLimit != nil {
+		fields["hold_limit"] = *req.HoldLimit
+	}
+	if req.ValidSeconds != nil {
+		fields["valid_seconds"] = *req.ValidSeconds
+	}
+	if req.Status != nil {
+		fields["status"] = *req.Status
+	}
+	if len(fields) == 0 {
+		return nil, errors.New("没有需要修改的字段")
+	}
+
+	if err := s.itemDAO.UpdateFields(itemID, fields); err != nil {
+		return nil, errors.New("修改道具失败")
+	}
+
+	util.Info("修改道具: item_id=%d fields=%v operator_id=%d", itemID, fields, operatorID)
+	return s.itemDAO.GetByID(itemID)
+}
+
+// GetItem 获取道具
+func (s *ItemService) GetItem(itemID uint) (*model.Item, error) {
+	item, err := s.itemDAO.GetByID(itemID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("道具不存在")
+		}
+		return nil, errors.New("查询道具失败")
+	}
+	return item, nil
+}
+
+// ListItems 分页查询道具目录
+func (s *ItemService) ListItems(req *ItemQuery) (*util.PageResult, error) {
+	req.Normalize()
+	items, total, err := s.itemDAO.List(&dao.ItemFilter{
+		Keyword:  req.Keyword,
+		Category: req.Category,
+		Status:   req.Status,
+	}, req.Offset(), req.PageSize)
+	if err != nil {
+		return nil, errors.New("查询道具失败")
+	}
+	return util.NewPageResult(items, total, &req.PageQuery), nil
+}
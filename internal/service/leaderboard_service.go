@@ -0,0 +1,495 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"bgame/internal/config"
+	"bgame/internal/dao"
+	"bgame/internal/model"
+	"bgame/internal/util"
+)
+
+const (
+	defaultLeaderboardArchiveSize     = 1000
+	defaultLeaderboardArchiveInterval = 60
+	defaultLeaderboardRetention       = 7 * 24 * 3600
+	leaderboardArchiveLockTTL         = 5 * time.Minute
+
+	defaultLeaderboardLimit = 10
+	maxLeaderboardLimit     = 100
+	defaultLeaderboardRange = 5
+	maxLeaderboardRange     = 50
+)
+
+// LeaderboardService 基于 Redis 有序集合的排行榜：按配置定义多个榜单，周期结束后自动切换并归档最终名次
+type LeaderboardService struct {
+	leaderboardDAO *dao.LeaderboardDAO
+	userDAO        *dao.UserDAO
+}
+
+func NewLeaderboardService() *LeaderboardService {
+	return &LeaderboardService{
+		leaderboardDAO: dao.NewLeaderboardDAO(),
+		userDAO:        dao.NewUserDAO(),
+	}
+}
+
+// LeaderboardPeriod 榜单的一个周期，永久榜单的 Start/End 为零值
+type LeaderboardPeriod struct {
+	Key   string
+	Start time.Time
+	End   time.Time
+}
+
+type LeaderboardInfo struct {
+	Name          string     `json:"name"`
+	Title         string     `json:"title"`
+	Mode          string     `json:"mode"`
+	Period        string     `json:"period"`
+	Order         string     `json:"order"`
+	CurrentPeriod string     `json:"current_period"`
+	PeriodStart   *time.Time `json:"period_start,omitempty"`
+	PeriodEnd     *time.Time `json:"period_end,omitempty"`
+}
+
+type LeaderboardEntryResponse struct {
+	Rank     int64  `json:"rank"`
+	UserID   uint   `json:"user_id"`
+	Nickname string `json:"nickname"`
+	Score    int64  `json:"score"`
+}
+
+type LeaderboardResponse struct {
+	Board  string                      `json:"board"`
+	Period string                      `json:"period"`
+	Total  int64                       `json:"total"` // 上榜人数
+	List   []*LeaderboardEntryResponse `json:"list"`
+	Me     *LeaderboardEntryResponse   `json:"me,omitempty"` // 当前用户，未上榜时为空
+}
+
+type LeaderboardTopQuery struct {
+	Limit int `form:"limit"` // 默认10，最大100
+}
+
+type LeaderboardAroundQuery struct {
+	Range int `form:"range"` // 前后各取的人数，默认5，最大50
+}
+
+type LeaderboardArchiveQuery struct {
+	util.PageQuery
+	Period string `form:"period"` // 周期，为空时取最近一次归档
+}
+
+// SubmitScoreRequest 服务端提交分数请求
+type SubmitScoreRequest struct {
+	UserID uint  `json:"user_id" binding:"required"`
+	Score  int64 `json:"score"`
+}
+
+// Boards 全部榜单及其当前周期
+func (s *LeaderboardService) Boards() []*LeaderboardInfo {
+	now := time.Now()
+	boards := config.Cfg.Leaderboard.Boards
+	list := make([]*LeaderboardInfo, 0, len(boards))
+	for i := range boards {
+		board := &boards[i]
+		info := &LeaderboardInfo{
+			Name:   board.Name,
+			Title:  board.Title,
+			Mode:   leaderboardMode(board),
+			Period: leaderboardPeriodType(board),
+			Order:  leaderboardOrder(board),
+		}
+		if period, err := leaderboardPeriod(board, now); err == nil {
+			info.CurrentPeriod = period.Key
+			if !period.Start.IsZero() {
+				info.PeriodStart = &period.Start
+				info.PeriodEnd = &period.End
+			}
+		}
+		list = append(list, info)
+	}
+	return list
+}
+
+// Submit 服务端提交分数，按榜单的计分方式写入当前周期，返回该用户的名次
+func (s *LeaderboardService) Submit(name string, req *SubmitScoreRequest) (*LeaderboardEntryResponse, error) {
+	board, err := findLeaderboard(name)
+	if err != nil {
+		return nil, err
+	}
+	if board.Source != "" {
+		return nil, errors.New("该排行榜由系统自动更新，不能提交分数")
+	}
+	if _, err := s.userDAO.FindByID(req.UserID); err != nil {
+		return nil, errors.New("用户不存在")
+	}
+
+	period, err := s.submit(board, req.UserID, req.Score)
+	if err != nil {
+		return nil, err
+	}
+	return s.entryOf(board, period, req.UserID)
+}
+
+// SubmitProgress 等级或经验变化后更新由等级系统驱动的榜单，失败只记录日志
+func (s *LeaderboardService) SubmitProgress(userID uint, level, experience, gained int) {
+	boards := config.Cfg.Leaderboard.Boards
+	for i := range boards {
+		board := &boards[i]
+		var score int64
+		switch board.Source {
+		case model.LeaderboardSourceLevel:
+			score = int64(level)
+		case model.LeaderboardSourceExperience:
+			score = int64(experience)
+			if leaderboardMode(board) == model.LeaderboardModeSum {
+				score = int64(gained)
+			}
+		default:
+			continue
+		}
+		if _, err := s.submit(board, userID, score); err != nil {
+			util.LogError("更新排行榜失败: board=%s user_id=%d err=%v", board.Name, userID, err)
+		}
+	}
+}
+
+func (s *LeaderboardService) submit(board *config.LeaderboardBoard, userID uint, score int64) (*LeaderboardPeriod, error) {
+	period, err := leaderboardPeriod(board, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	var expireAt time.Time
+	if !period.End.IsZero() {
+		expireAt = period.End.Add(leaderboardRetention())
+	}
+	if _, err := s.leaderboardDAO.Submit(board.Name, period.Key, leaderboardMode(board), leaderboardAsc(board), userID, score, expireAt); err != nil {
+		util.LogError("提交排行榜分数失败: board=%s period=%s user_id=%d err=%v", board.Name, period.Key, userID, err)
+		return nil, errors.New("提交分数失败")
+	}
+	return period, nil
+}
+
+// Top 当前周期前 N 名，userID 不为0时附带该用户的名次
+func (s *LeaderboardService) Top(name string, userID uint, req *LeaderboardTopQuery) (*LeaderboardResponse, error) {
+	board, err := findLeaderboard(name)
+	if err != nil {
+		return nil, err
+	}
+	period, err := leaderboardPeriod(board, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	limit := clampInt(req.Limit, defaultLeaderboardLimit, maxLeaderboardLimit)
+	resp, err := s.rangeOf(board, period, 0, int64(limit)-1)
+	if err != nil {
+		return nil, err
+	}
+	if userID > 0 {
+		resp.Me, err = s.entryOf(board, period, userID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+// AroundMe 当前周期中用户前后各 range 名
+func (s *LeaderboardService) AroundMe(name string, userID uint, req *LeaderboardAroundQuery) (*LeaderboardResponse, error) {
+	board, err := findLeaderboard(name)
+	if err != nil {
+		return nil, err
+	}
+	period, err := leaderboardPeriod(board, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	rank, err := s.leaderboardDAO.Rank(board.Name, period.Key, leaderboardAsc(board), userID)
+	if err != nil {
+		util.LogError("查询排行榜名次失败: board=%s user_id=%d err=%v", board.Name, userID, err)
+		return nil, errors.New("查询排行榜失败")
+	}
+	if rank < 0 {
+		total, _ := s.leaderboardDAO.Count(board.Name, period.Key)
+		return &LeaderboardResponse{Board: board.Name, Period: period.Key, Total: total, List: []*LeaderboardEntryResponse{}}, nil
+	}
+
+	n := int64(clampInt(req.Range, defaultLeaderboardRange, maxLeaderboardRange))
+	start := rank - n
+	if start < 0 {
+		start = 0
+	}
+	resp, err := s.rangeOf(board, period, start, rank+n)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range resp.List {
+		if entry.UserID == userID {
+			resp.Me = entry
+			break
+		}
+	}
+	return resp, nil
+}
+
+// Archive 分页查询已结束周期的最终名次
+func (s *LeaderboardService) Archive(name string, req *LeaderboardArchiveQuery) (*util.PageResult, error) {
+	board, err := findLeaderboard(name)
+	if err != nil {
+		return nil, err
+	}
+	req.Normalize()
+
+	period := req.Period
+	if period == "" {
+		periods, err := s.leaderboardDAO.ListArchivedPeriods(board.Name, 1)
+		if err != nil {
+			return nil, errors.New("查询排行榜归档失败")
+		}
+		if len(periods) == 0 {
+			return util.NewPageResult([]model.LeaderboardArchive{}, 0, &req.PageQuery), nil
+		}
+		period = periods[0]
+	}
+
+	archives, total, err := s.leaderboardDAO.ListArchive(board.Name, period, req.Offset(), req.PageSize)
+	if err != nil {
+		return nil, errors.New("查询排行榜归档失败")
+	}
+	return util.NewPageResult(archives, total, &req.PageQuery), nil
+}
+
+// StartArchiver 启动后台归档任务，ctx 取消后退出；多实例部署时通过 Redis 锁保证只归档一次
+func (s *LeaderboardService) StartArchiver(ctx context.Context) {
+	interval := config.Cfg.Leaderboard.ArchiveInterval
+	if interval <= 0 {
+		interval = defaultLeaderboardArchiveInterval
+	}
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(interval) * time.Second)
+		defer ticker.Stop()
+		for {
+			s.ArchiveEnded(time.Now())
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// ArchiveEnded 归档各周期榜单已结束且尚未归档的周期
+// 从上一个周期向前检查到保留期为止，服务停机期间错过的周期在恢复后补归档（Redis 中的榜单数据保留到周期结束后 retention）
+func (s *LeaderboardService) ArchiveEnded(now time.Time) {
+	cutoff := now.Add(-leaderboardRetention())
+	boards := config.Cfg.Leaderboard.Boards
+	for i := range boards {
+		board := &boards[i]
+		if leaderboardPeriodType(board) == model.LeaderboardPeriodAll {
+			continue
+		}
+		current, err := leaderboardPeriod(board, now)
+		if err != nil {
+			continue
+		}
+		for t := current.Start.Add(-time.Second); ; {
+			period, err := leaderboardPeriod(board, t)
+			if err != nil || !period.End.After(cutoff) {
+				break
+			}
+			if err := s.archive(board, period); err != nil {
+				util.LogError("排行榜归档失败: board=%s period=%s err=%v", board.Name, period.Key, err)
+			}
+			t = period.Start.Add(-time.Second)
+		}
+	}
+}
+
+func (s *LeaderboardService) archive(board *config.LeaderboardBoard, period *LeaderboardPeriod) error {
+	archived, err := s.leaderboardDAO.IsArchived(board.Name, period.Key)
+	if err != nil || archived {
+		return err
+	}
+	locked, err := s.leaderboardDAO.AcquireArchiveLock(board.Name, period.Key, leaderboardArchiveLockTTL)
+	if err != nil || !locked {
+		return err
+	}
+	defer s.leaderboardDAO.ReleaseArchiveLock(board.Name, period.Key)
+
+	size := config.Cfg.Leaderboard.ArchiveSize
+	if size <= 0 {
+		size = defaultLeaderboardArchiveSize
+	}
+	entries, err := s.leaderboardDAO.Range(board.Name, period.Key, leaderboardAsc(board), 0, int64(size)-1)
+	if err != nil {
+		return err
+	}
+
+	archives := make([]model.LeaderboardArchive, 0, len(entries))
+	for _, entry := range entries {
+		archives = append(archives, model.LeaderboardArchive{
+			Board:       board.Name,
+			Period:      period.Key,
+			Rank:        int(entry.Rank),
+			UserID:      entry.UserID,
+			Score:       entry.Score,
+			PeriodStart: period.Start,
+			PeriodEnd:   period.End,
+		})
+	}
+	if err := s.leaderboardDAO.SaveArchive(archives); err != nil {
+		return err
+	}
+	if err := s.leaderboardDAO.MarkArchived(board.Name, period.Key, leaderboardRetention()); err != nil {
+		return err
+	}
+
+	util.Info("排行榜归档完成: board=%s period=%s count=%d", board.Name, period.Key, len(archives))
+	return nil
+}
+
+// rangeOf 查询名次区间并补充昵称
+func (s *LeaderboardService) rangeOf(board *config.LeaderboardBoard, period *LeaderboardPeriod, start, stop int64) (*LeaderboardResponse, error) {
+	entries, err := s.leaderboardDAO.Range(board.Name, period.Key, leaderboardAsc(board), start, stop)
+	if err != nil {
+		util.LogError("查询排行榜失败: board=%s period=%s err=%v", board.Name, period.Key, err)
+		return nil, errors.New("查询排行榜失败")
+	}
+	total, err := s.leaderboardDAO.Count(board.Name, period.Key)
+	if err != nil {
+		return nil, errors.New("查询排行榜失败")
+	}
+
+	ids := make([]uint, 0, len(entries))
+	for _, entry := range entries {
+		ids = append(ids, entry.UserID)
+	}
+	nicknames, err := s.userDAO.GetNicknames(ids)
+	if err != nil {
+		util.LogError("查询排行榜昵称失败: board=%s err=%v", board.Name, err)
+	}
+
+	list := make([]*LeaderboardEntryResponse, 0, len(entries))
+	for _, entry := range entries {
+		list = append(list, &LeaderboardEntryResponse{
+			Rank:     entry.Rank,
+			UserID:   entry.UserID,
+			Nickname: nicknames[entry.UserID],
+			Score:    entry.Score,
+		})
+	}
+	return &LeaderboardResponse{Board: board.Name, Period: period.Key, Total: total, List: list}, nil
+}
+
+// entryOf 查询用户在当前周期的名次，未上榜时返回 nil
+func (s *LeaderboardService) entryOf(board *config.LeaderboardBoard, period *LeaderboardPeriod, userID uint) (*LeaderboardEntryResponse, error) {
+	rank, err := s.leaderboardDAO.Rank(board.Name, period.Key, leaderboardAsc(board), userID)
+	if err != nil {
+		util.LogError("查询排行榜名次失败: board=%s user_id=%d err=%v", board.Name, userID, err)
+		return nil, errors.New("查询排行榜失败")
+	}
+	if rank < 0 {
+		return nil, nil
+	}
+	resp, err := s.rangeOf(board, period, rank, rank)
+	if err != nil || len(resp.List) == 0 {
+		return nil, err
+	}
+	return resp.List[0], nil
+}
+
+func findLeaderboard(name string) (*config.LeaderboardBoard, error) {
+	boards := config.Cfg.Leaderboard.Boards
+	for i := range boards {
+		if boards[i].Name == name {
+			return &boards[i], nil
+		}
+	}
+	return nil, errors.New("排行榜不存在")
+}
+
+// leaderboardPeriod 计算时间 t 所在的周期（按服务器本地时区）
+func leaderboardPeriod(board *config.LeaderboardBoard, t time.Time) (*LeaderboardPeriod, error) {
+	t = t.In(time.Local)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+
+	switch leaderboardPeriodType(board) {
+	case model.LeaderboardPeriodDaily:
+		return &LeaderboardPeriod{Key: day.Format("20060102"), Start: day, End: day.AddDate(0, 0, 1)}, nil
+	case model.LeaderboardPeriodWeekly:
+		start := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+		year, week := start.ISOWeek()
+		return &LeaderboardPeriod{Key: fmt.Sprintf("%dW%02d", year, week), Start: start, End: start.AddDate(0, 0, 7)}, nil
+	case model.LeaderboardPeriodSeason:
+		cfg := config.Cfg.Leaderboard
+		seasonStart, err := time.Parse(time.RFC3339, cfg.SeasonStart)
+		if err != nil || cfg.SeasonDays <= 0 {
+			return nil, errors.New("赛季配置错误")
+		}
+		if t.Before(seasonStart) {
+			return nil, errors.New("赛季尚未开始")
+		}
+		n := int(t.Sub(seasonStart) / (time.Duration(cfg.SeasonDays) * 24 * time.Hour))
+		start := seasonStart.AddDate(0, 0, n*cfg.SeasonDays)
+		return &LeaderboardPeriod{Key: fmt.Sprintf("S%d", n+1), Start: start, End: start.AddDate(0, 0, cfg.SeasonDays)}, nil
+	default:
+		return &LeaderboardPeriod{Key: model.LeaderboardPeriodAll}, nil
+	}
+}
+
+func leaderboardMode(board *config.LeaderboardBoard) string {
+	switch board.Mode {
+	case model.LeaderboardModeSum, model.LeaderboardModeLatest:
+		return board.Mode
+	default:
+		return model.LeaderboardModeBest
+	}
+}
+
+func leaderboardPeriodType(board *config.LeaderboardBoard) string {
+	switch board.Period {
+	case model.LeaderboardPeriodDaily, model.LeaderboardPeriodWeekly, model.LeaderboardPeriodSeason:
+		return board.Period
+	default:
+		return model.LeaderboardPeriodAll
+	}
+}
+
+func leaderboardOrder(board *config.LeaderboardBoard) string {
+	if leaderboardAsc(board) {
+		return "asc"
+	}
+	return "desc"
+}
+
+func leaderboardAsc(board *config.LeaderboardBoard) bool {
+	return board.Order == "asc"
+}
+
+func leaderboardRetention() time.Duration {
+	retention := config.Cfg.Leaderboard.Retention
+	if retention <= 0 {
+		retention = defaultLeaderboardRetention
+	}
+	return time.Duration(retention) * time.Second
+}
+
+// clampInt 为0或负数时取默认值，超过上限时取上限
+func clampInt(v, def, max int) int {
+	if v <= 0 {
+		return def
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"bgame/internal/config"
+	"bgame/internal/dao"
+	"bgame/internal/util"
+)
+
+const (
+	loginScopeUser  = "user"
+	loginScopeAdmin = "admin"
+
+	lockCountWindow = 24 * time.Hour
+)
+
+// LoginGuardService 登录防暴力破解：按用户名和IP计数失败次数，指数退避并临时锁定
+type LoginGuardService struct {
+	loginAttemptDAO *dao.LoginAttemptDAO
+}
+
+func NewLoginGuardService() *LoginGuardService {
+	return &LoginGuardService{
+		loginAttemptDAO: dao.NewLoginAttemptDAO(),
+	}
+}
+
+type UnlockLoginRequest struct {
+	Username string `json:"username"`
+	IP       string `json:"ip"`
+}
+
+// Check 登录前检查用户名和IP是否处于锁定或退避中，Redis 不可用时放行
+func (s *LoginGuardService) Check(scope, username, ip string) error {
+	if !config.Cfg.LoginGuard.Enabled {
+		return nil
+	}
+
+	lock, _, err := s.loginAttemptDAO.GetBlockedTTL(scope, ipSubject(ip))
+	if err != nil {
+		util.LogError("检查登录锁定状态失败: %v", err)
+		return nil
+	}
+	if lock > 0 {
+		return fmt.Errorf("登录尝试过于频繁，请%d秒后再试", ceilDurationSeconds(lock))
+	}
+
+	lock, backoff, err := s.loginAttemptDAO.GetBlockedTTL(scope, userSubject(username))
+	if err != nil {
+		util.LogError("检查登录锁定状态失败: %v", err)
+		return nil
+	}
+	if lock > 0 {
+		return fmt.Errorf("账号已被临时锁定，请%d秒后再试", ceilDurationSeconds(lock))
+	}
+	if backoff > 0 {
+		return fmt.Errorf("登录失败次数过多，请%d秒后再试", ceilDurationSeconds(backoff))
+	}
+	return nil
+}
+
+// RecordFailure 记录一次登录失败，达到阈值时锁定用户名或IP
+func (s *LoginGuardService) RecordFailure(scope, username, ip string) {
+	cfg := config.Cfg.LoginGuard
+	if !cfg.Enabled {
+		return
+	}
+	window := time.Duration(cfg.FailureWindow) * time.Second
+
+	subject := userSubject(username)
+	failures, err := s.loginAttemptDAO.IncrFailure(scope, subject, window)
+	if err != nil {
+		util.LogError("记录登录失败次数失败: %v", err)
+		return
+	}
+
+	if cfg.MaxFailures > 0 && failures >= int64(cfg.MaxFailures) {
+		lockCount, _ := s.loginAttemptDAO.IncrLockCount(scope, subject, lockCountWindow)
+		duration := lockoutDuration(lockCount)
+		s.loginAttemptDAO.Lock(scope, subject, duration)
+		s.loginAttemptDAO.ClearFailures(scope, subject)
+		util.Security("登录锁定: scope=%s username=%s ip=%s failures=%d lock_count=%d duration=%s",
+			scope, username, ip, failures, lockCount, duration)
+	} else if cfg.BackoffBase > 0 {
+		s.loginAttemptDAO.SetBackoff(scope, subject, backoffDuration(failures))
+	}
+
+	if cfg.IPMaxFailures <= 0 || ip == "" {
+		return
+	}
+	ipFailures, err := s.loginAttemptDAO.IncrFailure(scope, ipSubject(ip), window)
+	if err != nil {
+		return
+	}
+	if ipFailures >= int64(cfg.IPMaxFailures) {
+		duration := lockoutDuration(1)
+		s.loginAttemptDAO.Lock(scope, ipSubject(ip), duration)
+		s.loginAttemptDAO.ClearFailures(scope, ipSubject(ip))
+		util.Security("IP登录锁定: scope=%s ip=%s failures=%d duration=%s", scope, ip, ipFailures, duration)
+	}
+}
+
+// RecordSuccess 登录成功后清除该用户名的失败记录
+func (s *LoginGuardService) RecordSuccess(scope, username string) {
+	if !config.Cfg.LoginGuard.Enabled {
+		return
+	}
+	s.loginAttemptDAO.ClearFailures(scope, userSubject(username))
+}
+
+// UnlockUser 管理员解除玩家账号或IP的登录锁定
+func (s *LoginGuardService) UnlockUser(operatorID uint, req *UnlockLoginRequest) error {
+	return s.unlock(loginScopeUser, operatorID, req)
+}
+
+// UnlockAdmin 解除管理员账号或IP的登录锁定，调用前由 AdminService 校验目标管理员
+func (s *LoginGuardService) UnlockAdmin(operatorID uint, req *UnlockLoginRequest) error {
+	return s.unlock(loginScopeAdmin, operatorID, req)
+}
+
+func (s *LoginGuardService) unlock(scope string, operatorID uint, req *UnlockLoginRequest) error {
+	username := strings.TrimSpace(req.Username)
+	ip := strings.TrimSpace(req.IP)
+	if username == "" && ip == "" {
+		return errors.New("用户名和IP至少填写一项")
+	}
+
+	if username != "" {
+		if err := s.loginAttemptDAO.Unlock(scope, userSubject(username)); err != nil {
+			return errors.New("解除锁定失败")
+		}
+	}
+	if ip != "" {
+		if err := s.loginAttemptDAO.Unlock(scope, ipSubject(ip)); err != nil {
+			return errors.New("解除锁定失败")
+		}
+	}
+
+	util.Security("解除登录锁定: scope=%s username=%s ip=%s operator_id=%d", scope, username, ip, operatorID)
+	return nil
+}
+
+// backoffDuration 第 n 次失败后的等待时间：base * 2^(n-1)，不超过首次锁定时长
+func backoffDuration(failures int64) time.Duration {
+	cfg := config.Cfg.LoginGuard
+	return exponential(cfg.BackoffBase, failures-1, cfg.LockoutDuration)
+}
+
+// lockoutDuration 第 n 次锁定的时长：lockout * 2^(n-1)，不超过上限
+func lockoutDuration(lockCount int64) time.Duration {
+	cfg := config.Cfg.LoginGuard
+	if lockCount < 1 {
+		lockCount = 1
+	}
+	return exponential(cfg.LockoutDuration, lockCount-1, cfg.MaxLockoutDuration)
+}
+
+func exponential(baseSeconds int, exp int64, maxSeconds int) time.Duration {
+	if exp < 0 {
+		exp = 0
+	}
+	if exp > 30 {
+		exp = 30
+	}
+	seconds := int64(baseSeconds) << exp
+	if maxSeconds > 0 && seconds > int64(maxSeconds) {
+		seconds = int64(maxSeconds)
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+func ceilDurationSeconds(d time.Duration) int64 {
+	return int64((d + time.Second - 1) / time.Second)
+}
+
+func userSubject(username string) string {
+	return "user:" + strings.ToLower(username)
+}
+
+func ipSubject(ip string) string {
+	return "ip:" + ip
+}
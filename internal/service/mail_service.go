@@ -0,0 +1,571 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"bgame/internal/config"
+	"bgame/internal/dao"
+	"bgame/internal/model"
+	"bgame/internal/util"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultMailExpireDays    = 30
+	defaultMailMaxRecipients = 1000
+	defaultMailSender        = "系统"
+	defaultMailUnreadTTL     = 10 * time.Minute
+)
+
+// MailService 邮件：管理员向指定玩家、分群或全服发送公告和补偿，玩家阅读、删除邮件并领取附件
+// 指定玩家的邮件发送时直接投递；全服和分群邮件在玩家打开邮箱时按需投递，避免一次写入全部玩家
+// 未读数缓存在 Redis，缓存键带全服邮件版本号，发送全服或分群邮件后所有玩家的缓存自动失效
+type MailService struct {
+	mailDAO        *dao.MailDAO
+	userProfileDAO *dao.UserProfileDAO
+	rewardService  *RewardService
+}
+
+func NewMailService() *MailService {
+	return &MailService{
+		mailDAO:        dao.NewMailDAO(),
+		userProfileDAO: dao.NewUserProfileDAO(),
+		rewardService:  NewRewardService(),
+	}
+}
+
+// SendMailRequest 发送邮件请求
+type SendMailRequest struct {
+	Title       string             `json:"title" binding:"required,max=100"`
+	Content     string             `json:"content" binding:"max=5000"`
+	Sender      string             `json:"sender" binding:"max=50"` // 发件人显示名称，为空时使用配置的默认值
+	Attachments model.RewardBundle `json:"attachments"`             // 附件，为空表示纯公告
+	TargetType  string             `json:"target_type" binding:"required,oneof=users segment all"`
+	UserIDs     []uint             `json:"user_ids"`  // target_type 为 users 时的收件人
+	Segment     model.MailSegment  `json:"segment"`   // target_type 为 segment 时的分群条件，按玩家打开邮箱时的数据判断
+	ExpireAt    *time.Time         `json:"expire_at"` // 过期时间（RFC3339），为空时按配置的默认有效期
+}
+
+type MailQuery struct {
+	util.PageQuery
+	Title      string    `form:"title"` // 模糊匹配
+	TargetType string    `form:"target_type"`
+	StartTime  time.Time `form:"start_time" time_format:"2006-01-02 15:04:05"`
+	EndTime    time.Time `form:"end_time" time_format:"2006-01-02 15:04:05"`
+}
+
+// MailDetail 邮件详情及投递统计
+type MailDetail struct {
+	model.Mail
+	Stats *dao.MailStats `json:"stats"`
+}
+
+// UserMailResponse 玩家看到的邮件
+type UserMailResponse struct {
+	ID          uint                `json:"id"`
+	Title       string              `json:"title"`
+	Content     string              `json:"content"`
+	Sender      string              `json:"sender"`
+	Attachments *model.RewardBundle `json:"attachments,omitempty"`
+	Read        bool                `json:"read"`
+	Claimed     bool                `json:"claimed"` // 附件是否已领取
+	ExpireAt    time.Time           `json:"expire_at"`
+	CreatedAt   time.Time           `json:"created_at"`
+}
+
+// MailUnreadResponse 未读邮件数
+type MailUnreadResponse struct {
+	Unread int64 `json:"unread"`
+}
+
+// ClaimMailResponse 领取附件结果
+type ClaimMailResponse struct {
+	ID          uint                   `json:"id"`
+	Attachments model.RewardBundle     `json:"attachments"`
+	Progress    *LevelProgressResponse `json:"progress,omitempty"` // 发放经验后的等级进度
+}
+
+// ClaimAllMailResponse 一键领取结果，单封邮件领取失败不影响其他邮件
+type ClaimAllMailResponse struct {
+	Claimed []ClaimMailResponse `json:"claimed"`
+	Failed  int                 `json:"failed"`
+}
+
+// Send 发送邮件
+func (s *MailService) Send(operatorID uint, req *SendMailRequest) (*model.Mail, error) {
+	if err := s.validateAttachments(&req.Attachments); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	expireAt := now.AddDate(0, 0, mailExpireDays())
+	if req.ExpireAt != nil {
+		if !req.ExpireAt.After(now) {
+			return nil, errors.New("过期时间必须晚于当前时间")
+		}
+		expireAt = *req.ExpireAt
+	}
+	sender := req.Sender
+	if sender == "" {
+		sender = config.Cfg.Mail.DefaultSender
+	}
+	if sender == "" {
+		sender = defaultMailSender
+	}
+
+	mail := &model.Mail{
+		Title:       req.Title,
+		Content:     req.Content,
+		Sender:      sender,
+		Attachments: req.Attachments,
+		TargetType:  req.TargetType,
+		ExpireAt:    expireAt,
+		CreatedBy:   operatorID,
+	}
+
+	var recipients []uint
+	switch req.TargetType {
+	case model.MailTargetUsers:
+		ids, err := s.resolveRecipients(req.UserIDs)
+		if err != nil {
+			return nil, err
+		}
+		recipients = ids
+		mail.RecipientCount = len(ids)
+	case model.MailTargetSegment:
+		if err := validateMailSegment(&req.Segment); err != nil {
+			return nil, err
+		}
+		mail.Segment = req.Segment
+	}
+
+	err := dao.Transaction(func(tx *gorm.DB) error {
+		if err := s.mailDAO.CreateMail(tx, mail); err != nil {
+			return err
+		}
+		userMails := make([]model.UserMail, 0, len(recipients))
+		for _, userID := range recipients {
+			userMails = append(userMails, newUserMail(mail, userID))
+		}
+		return s.mailDAO.CreateUserMails(tx, userMails)
+	})
+	if err != nil {
+		util.LogError("发送邮件失败: title=%s target_type=%s err=%v", req.Title, req.TargetType, err)
+		return nil, errors.New("发送邮件失败")
+	}
+
+	if mail.IsBroadcast() {
+		if err := s.mailDAO.IncrBroadcastVersion(); err != nil {
+			util.LogError("更新全服邮件版本失败: mail_id=%d err=%v", mail.ID, err)
+		}
+	} else {
+		s.invalidateUnread(recipients...)
+	}
+	util.Info("发送邮件: mail_id=%d target_type=%s recipients=%d operator_id=%d",
+		mail.ID, mail.TargetType, mail.RecipientCount, operatorID)
+	return mail, nil
+}
+
+// ListMails 分页查询已发送的邮件（管理端）
+func (s *MailService) ListMails(req *MailQuery) (*util.PageResult, error) {
+	req.Normalize()
+	mails, total, err := s.mailDAO.ListMails(&dao.MailFilter{
+		Title:      req.Title,
+		TargetType: req.TargetType,
+		StartTime:  req.StartTime,
+		EndTime:    req.EndTime,
+	}, req.Offset(), req.PageSize)
+	if err != nil {
+		return nil, errors.New("查询邮件失败")
+	}
+	return util.NewPageResult(mails, total, &req.PageQuery), nil
+}
+
+// GetMail 获取邮件详情及投递统计（管理端）
+func (s *MailService) GetMail(id uint) (*MailDetail, error) {
+	mail, err := s.mailDAO.GetMail(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("邮件不存在")
+		}
+		return nil, errors.New("查询邮件失败")
+	}
+	stats, err := s.mailDAO.GetMailStats(id)
+	if err != nil {
+		return nil, errors.New("查询邮件统计失败")
+	}
+	return &MailDetail{Mail: *mail, Stats: stats}, nil
+}
+
+// ListUserMails 分页查询玩家邮箱，先投递玩家尚未收到的全服和分群邮件
+func (s *MailService) ListUserMails(userID uint, req *util.PageQuery) (*util.PageResult, error) {
+	req.Normalize()
+	if err := s.deliverBroadcasts(userID); err != nil {
+		util.LogError("投递全服邮件失败: user_id=%d err=%v", userID, err)
+	}
+	userMails, total, err := s.mailDAO.ListUserMails(userID, time.Now(), req.Offset(), req.PageSize)
+	if err != nil {
+		return nil, errors.New("查询邮件失败")
+	}
+	list := make([]UserMailResponse, 0, len(userMails))
+	for i := range userMails {
+		list = append(list, toUserMailResponse(&userMails[i]))
+	}
+	return util.NewPageResult(list, total, req), nil
+}
+
+// ReadMail 阅读邮件并标记已读
+func (s *MailService) ReadMail(userID, id uint) (*UserMailResponse, error) {
+	userMail, err := s.getUserMail(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if userMail.ReadAt == nil {
+		now := time.Now()
+		updated, err := s.mailDAO.MarkRead(userMail.ID, now)
+		if err != nil {
+			return nil, errors.New("标记已读失败")
+		}
+		if updated {
+			s.invalidateUnread(userID)
+		}
+		userMail.ReadAt = &now
+	}
+	resp := toUserMailResponse(userMail)
+	return &resp, nil
+}
+
+// DeleteMail 删除邮件，有未领取的附件时需先领取（附件已过期的除外）
+func (s *MailService) DeleteMail(userID, id uint) error {
+	userMail, err := s.getUserMail(userID, id)
+	if err != nil {
+		return err
+	}
+	if userMail.Claimable(time.Now()) {
+		return errors.New("请先领取附件")
+	}
+	if err := s.mailDAO.MarkDeleted(userMail.ID); err != nil {
+		return errors.New("删除邮件失败")
+	}
+	if userMail.ReadAt == nil {
+		s.invalidateUnread(userID)
+	}
+	return nil
+}
+
+// ClaimAttachments 领取邮件附件，在一个事务内锁定投递记录、标记已领取并发放奖励，保证只领取一次
+func (s *MailService) ClaimAttachments(userID, id uint) (*ClaimMailResponse, error) {
+	resp, err := s.claim(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidateUnread(userID)
+	return resp, nil
+}
+
+// ClaimAll 一键领取所有可领取的附件，每封邮件单独一个事务
+func (s *MailService) ClaimAll(userID uint) (*ClaimAllMailResponse, error) {
+	if err := s.deliverBroadcasts(userID); err != nil {
+		util.LogError("投递全服邮件失败: user_id=%d err=%v", userID, err)
+	}
+	ids, err := s.mailDAO.ListClaimableIDs(userID, time.Now())
+	if err != nil {
+		return nil, errors.New("查询邮件失败")
+	}
+	if len(ids) == 0 {
+		return nil, errors.New("没有可领取的附件")
+	}
+
+	resp := &ClaimAllMailResponse{Claimed: make([]ClaimMailResponse, 0, len(ids))}
+	for _, id := range ids {
+		claimed, err := s.claim(userID, id)
+		if err != nil {
+			resp.Failed++
+			continue
+		}
+		resp.Claimed = append(resp.Claimed, *claimed)
+	}
+	s.invalidateUnread(userID)
+	return resp, nil
+}
+
+// UnreadCount 获取未读邮件数，优先读取 Redis 缓存
+func (s *MailService) UnreadCount(userID uint) (*MailUnreadResponse, error) {
+	version, err := s.mailDAO.GetBroadcastVersion()
+	cacheable := err == nil
+	if err != nil {
+		util.LogError("读取全服邮件版本失败: err=%v", err)
+	}
+	if cacheable {
+		if count, ok, err := s.mailDAO.GetUnreadCache(userID, version); err == nil && ok {
+			return &MailUnreadResponse{Unread: count}, nil
+		}
+	}
+
+	if err := s.deliverBroadcasts(userID); err != nil {
+		util.LogError("投递全服邮件失败: user_id=%d err=%v", userID, err)
+	}
+	count, err := s.mailDAO.CountUnread(userID, time.Now())
+	if err != nil {
+		return nil, errors.New("查询未读邮件失败")
+	}
+	if cacheable {
+		if err := s.mailDAO.SetUnreadCache(userID, version, count, mailUnreadTTL()); err != nil {
+			util.LogError("写入未读邮件缓存失败: user_id=%d err=%v", userID, err)
+		}
+	}
+	return &MailUnreadResponse{Unread: count}, nil
+}
+
+// claim 领取一封邮件的附件
+func (s *MailService) claim(userID, id uint) (*ClaimMailResponse, error) {
+	var (
+		userMail *model.UserMail
+		result   *RewardResult
+	)
+	err := dao.Transaction(func(tx *gorm.DB) error {
+		var err error
+		userMail, err = s.mailDAO.LockUserMail(tx, userID, id)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("邮件不存在")
+			}
+			return claimMailFailed(userID, id, err)
+		}
+		now := time.Now()
+		switch {
+		case userMail.Deleted:
+			return errors.New("邮件不存在")
+		case !userMail.HasAttachments:
+			return errors.New("邮件没有附件")
+		case userMail.ClaimedAt != nil:
+			return errors.New("附件已领取")
+		case userMail.IsExpired(now):
+			return errors.New("邮件已过期")
+		}
+
+		updated, err := s.mailDAO.MarkClaimed(tx, userMail.ID, now)
+		if err != nil {
+			return claimMailFailed(userID, id, err)
+		}
+		if !updated {
+			return errors.New("附件已领取")
+		}
+		result, err = s.rewardService.GrantTx(tx, &RewardGrant{
+			UserID:      userID,
+			Bundle:      userMail.Mail.Attachments,
+			Source:      model.ExperienceSourceSystem,
+			ReasonCode:  model.WalletReasonMail,
+			ReferenceID: "mail:" + strconv.FormatUint(uint64(userMail.ID), 10),
+			OperatorID:  userMail.Mail.CreatedBy,
+			Remark:      "邮件 " + userMail.Mail.Title,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.rewardService.AfterGrant(result)
+	util.Info("领取邮件附件: user_id=%d user_mail_id=%d mail_id=%d", userID, userMail.ID, userMail.MailID)
+
+	resp := &ClaimMailResponse{
+		ID:          userMail.ID,
+		Attachments: userMail.Mail.Attachments,
+	}
+	if result.Progress != nil {
+		resp.Progress = &result.Progress.LevelProgressResponse
+	}
+	return resp, nil
+}
+
+// deliverBroadcasts 为玩家投递尚未收到的全服和分群邮件。分群条件按投递时（玩家打开邮箱时）的等级和注册时间判断，
+// 不满足的邮件记录为已跳过，之后即使玩家进入分群范围也不再投递，也不会在每次打开邮箱时重复筛选
+func (s *MailService) deliverBroadcasts(userID uint) error {
+	profile, err := s.userProfileDAO.GetUserProfileByUserID(userID)
+	if err != nil {
+		return err
+	}
+	mails, err := s.mailDAO.ListPendingBroadcasts(userID, profile.RegisterTime, time.Now())
+	if err != nil {
+		return err
+	}
+
+	userMails := make([]model.UserMail, 0, len(mails))
+	for i := range mails {
+		mail := &mails[i]
+		if mail.TargetType == model.MailTargetSegment && !mail.Segment.Match(profile.Level, profile.RegisterTime) {
+			userMails = append(userMails, skippedUserMail(mail, userID))
+			continue
+		}
+		userMails = append(userMails, newUserMail(mail, userID))
+	}
+	if len(userMails) == 0 {
+		return nil
+	}
+	return dao.Transaction(func(tx *gorm.DB) error {
+		return s.mailDAO.CreateUserMails(tx, userMails)
+	})
+}
+
+// getUserMail 获取玩家未删除、未过期的邮件
+func (s *MailService) getUserMail(userID, id uint) (*model.UserMail, error) {
+	userMail, err := s.mailDAO.GetUserMail(userID, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("邮件不存在")
+		}
+		return nil, errors.New("查询邮件失败")
+	}
+	if userMail.Deleted || userMail.Mail == nil {
+		return nil, errors.New("邮件不存在")
+	}
+	if userMail.IsExpired(time.Now()) {
+		return nil, errors.New("邮件已过期")
+	}
+	return userMail, nil
+}
+
+// resolveRecipients 去重并校验收件人
+func (s *MailService) resolveRecipients(userIDs []uint) ([]uint, error) {
+	ids := make([]uint, 0, len(userIDs))
+	seen := make(map[uint]bool, len(userIDs))
+	for _, id := range userIDs {
+		if id == 0 || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return nil, errors.New("收件人不能为空")
+	}
+	maxRecipients := config.Cfg.Mail.MaxRecipients
+	if maxRecipients <= 0 {
+		maxRecipients = defaultMailMaxRecipients
+	}
+	if len(ids) > maxRecipients {
+		return nil, fmt.Errorf("单次最多发送给%d名玩家，更多玩家请使用分群或全服邮件", maxRecipients)
+	}
+
+	existing, err := s.mailDAO.ExistingUserIDs(ids)
+	if err != nil {
+		return nil, errors.New("查询玩家失败")
+	}
+	if len(existing) != len(ids) {
+		found := make(map[uint]bool, len(existing))
+		for _, id := range existing {
+			found[id] = true
+		}
+		for _, id := range ids {
+			if !found[id] {
+				return nil, fmt.Errorf("玩家不存在: %d", id)
+			}
+		}
+	}
+	return ids, nil
+}
+
+// validateAttachments 校验附件，允许不带附件
+func (s *MailService) validateAttachments(bundle *model.RewardBundle) error {
+	if bundle.Experience < 0 || bundle.Balance.IsNegative() || bundle.ActivityBalance.IsNegative() {
+		return errors.New("附件数量不能为负数")
+	}
+	if bundle.IsEmpty() {
+		*bundle = model.RewardBundle{}
+		return nil
+	}
+	return s.rewardService.Validate(bundle)
+}
+
+// invalidateUnread 删除玩家的未读数缓存
+func (s *MailService) invalidateUnread(userIDs ...uint) {
+	version, err := s.mailDAO.GetBroadcastVersion()
+	if err == nil {
+		err = s.mailDAO.DeleteUnreadCache(version, userIDs...)
+	}
+	if err != nil {
+		util.LogError("删除未读邮件缓存失败: users=%d err=%v", len(userIDs), err)
+	}
+}
+
+// validateMailSegment 校验分群条件
+func validateMailSegment(segment *model.MailSegment) error {
+	if segment.IsEmpty() {
+		return errors.New("分群条件不能为空，发送给全部玩家请使用全服邮件")
+	}
+	if segment.MinLevel < 0 || segment.MaxLevel < 0 {
+		return errors.New("等级条件不能为负数")
+	}
+	if segment.MinLevel > 0 && segment.MaxLevel > 0 && segment.MinLevel > segment.MaxLevel {
+		return errors.New("最低等级不能高于最高等级")
+	}
+	if segment.RegisteredAfter != nil && segment.RegisteredBefore != nil &&
+		!segment.RegisteredBefore.After(*segment.RegisteredAfter) {
+		return errors.New("注册时间范围无效")
+	}
+	return nil
+}
+
+func newUserMail(mail *model.Mail, userID uint) model.UserMail {
+	return model.UserMail{
+		UserID:         userID,
+		MailID:         mail.ID,
+		HasAttachments: !mail.Attachments.IsEmpty(),
+		ExpireAt:       mail.ExpireAt,
+	}
+}
+
+// skippedUserMail 不满足分群条件的投递记录，标记为已删除使玩家侧的查询都不可见
+func skippedUserMail(mail *model.Mail, userID uint) model.UserMail {
+	return model.UserMail{
+		UserID:   userID,
+		MailID:   mail.ID,
+		ExpireAt: mail.ExpireAt,
+		Deleted:  true,
+		Skipped:  true,
+	}
+}
+
+func toUserMailResponse(userMail *model.UserMail) UserMailResponse {
+	resp := UserMailResponse{
+		ID:        userMail.ID,
+		Read:      userMail.ReadAt != nil,
+		Claimed:   userMail.ClaimedAt != nil,
+		ExpireAt:  userMail.ExpireAt,
+		CreatedAt: userMail.CreatedAt,
+	}
+	if mail := userMail.Mail; mail != nil {
+		resp.Title = mail.Title
+		resp.Content = mail.Content
+		resp.Sender = mail.Sender
+		if userMail.HasAttachments {
+			attachments := mail.Attachments
+			resp.Attachments = &attachments
+		}
+	}
+	return resp
+}
+
+// claimMailFailed 记录数据库错误并返回通用错误
+func claimMailFailed(userID, id uint, err error) error {
+	util.LogError("领取邮件附件失败: user_id=%d user_mail_id=%d err=%v", userID, id, err)
+	return errors.New("领取失败，请重试")
+}
+
+func mailExpireDays() int {
+	if days := config.Cfg.Mail.DefaultExpireDays; days > 0 {
+		return days
+	}
+	return defaultMailExpireDays
+}
+
+func mailUnreadTTL() time.Duration {
+	if ttl := config.Cfg.Mail.UnreadCacheTTL; ttl > 0 {
+		return time.Duration(ttl) * time.Second
+	}
+	return defaultMailUnreadTTL
+}
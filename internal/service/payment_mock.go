@@ -0,0 +1,98 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"bgame/internal/config"
+	"bgame/internal/model"
+	"bgame/internal/util"
+)
+
+func init() {
+	RegisterPaymentProvider(model.PaymentProviderMock, func(cfg *config.PaymentProviderConfig) PaymentProvider {
+		return &mockPaymentProvider{secret: cfg.Secret}
+	})
+}
+
+// mockPaymentProvider 内置模拟渠道：不对接外部网关，通过 /api/payment/mock/pay 生成签名回调，
+// 走与真实渠道相同的验签和入账流程，用于本地联调和测试，生产环境不要启用
+type mockPaymentProvider struct {
+	secret string
+}
+
+// mockPaymentNotice 模拟渠道的回调报文
+type mockPaymentNotice struct {
+	OrderNo string      `json:"order_no"`
+	TradeNo string      `json:"trade_no"`
+	Status  string      `json:"status"`
+	Amount  model.Money `json:"amount"`
+	Message string      `json:"message,omitempty"`
+}
+
+func (p *mockPaymentProvider) Name() string {
+	return model.PaymentProviderMock
+}
+
+func (p *mockPaymentProvider) CreatePayment(order *model.PaymentOrder) (*PaymentIntent, error) {
+	return &PaymentIntent{
+		Provider: p.Name(),
+		PayURL:   "/api/payment/mock/pay",
+		Params: map[string]string{
+			"order_no": order.OrderNo,
+			"amount":   order.Amount.String(),
+		},
+	}, nil
+}
+
+func (p *mockPaymentProvider) VerifyCallback(header http.Header, body []byte) (*PaymentNotification, error) {
+	tolerance := time.Duration(config.Cfg.Payment.CallbackTolerance) * time.Second
+	if tolerance <= 0 {
+		tolerance = defaultPaymentCallbackTolerance
+	}
+	if err := util.VerifyHMAC(p.secret, header.Get(PaymentTimestampHeader), body, header.Get(PaymentSignatureHeader), tolerance); err != nil {
+		return nil, err
+	}
+
+	var notice mockPaymentNotice
+	if err := json.Unmarshal(body, &notice); err != nil {
+		return nil, err
+	}
+	return &PaymentNotification{
+		OrderNo: notice.OrderNo,
+		TradeNo: notice.TradeNo,
+		Status:  notice.Status,
+		Amount:  notice.Amount,
+		Message: notice.Message,
+	}, nil
+}
+
+func (p *mockPaymentProvider) Refund(order *model.PaymentOrder, reason string) error {
+	util.Info("模拟渠道退款: order_no=%s trade_no=%s amount=%s reason=%s", order.OrderNo, order.TradeNo, order.Amount, reason)
+	return nil
+}
+
+// Notify 构造一条已签名的回调报文，模拟渠道向回调地址发送通知
+func (p *mockPaymentProvider) Notify(order *model.PaymentOrder, status, message string) (http.Header, []byte, error) {
+	tradeNo := order.TradeNo
+	if tradeNo == "" {
+		tradeNo = util.NewSerialNo("MOCK")
+	}
+	body, err := json.Marshal(&mockPaymentNotice{
+		OrderNo: order.OrderNo,
+		TradeNo: tradeNo,
+		Status:  status,
+		Amount:  order.Amount,
+		Message: message,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	header := http.Header{}
+	header.Set(PaymentTimestampHeader, timestamp)
+	header.Set(PaymentSignatureHeader, util.SignHMAC(p.secret, timestamp, body))
+	return header, body, nil
+}
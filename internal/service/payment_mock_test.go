@@ -0,0 +1,131 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"bgame/internal/config"
+	"bgame/internal/model"
+	"bgame/internal/util"
+)
+
+// useMockProvider 启用模拟渠道并返回渠道实例
+func useMockProvider(t *testing.T) *mockPaymentProvider {
+	t.Helper()
+	useConfig(t, &config.Config{Payment: config.PaymentConfig{
+		CallbackTolerance: 300,
+		Providers: []config.PaymentProviderConfig{
+			{Name: model.PaymentProviderMock, Enabled: true, Secret: "mock-secret"},
+		},
+	}})
+	provider, err := getPaymentProvider(model.PaymentProviderMock)
+	if err != nil {
+		t.Fatalf("getPaymentProvider: %v", err)
+	}
+	return provider.(*mockPaymentProvider)
+}
+
+func testPaymentOrder() *model.PaymentOrder {
+	return &model.PaymentOrder{OrderNo: "P20261018000000abcd", Provider: model.PaymentProviderMock, Amount: 1000}
+}
+
+func TestMockProviderDisabled(t *testing.T) {
+	useConfig(t, &config.Config{Payment: config.PaymentConfig{
+		Providers: []config.PaymentProviderConfig{{Name: model.PaymentProviderMock, Enabled: false}},
+	}})
+	if _, err := getPaymentProvider(model.PaymentProviderMock); !errors.Is(err, errPaymentProviderUnavailable) {
+		t.Errorf("disabled provider err = %v, want %v", err, errPaymentProviderUnavailable)
+	}
+}
+
+func TestMockProviderNotifyRoundTrip(t *testing.T) {
+	provider := useMockProvider(t)
+	order := testPaymentOrder()
+
+	for _, status := range []string{model.PaymentStatusPaid, model.PaymentStatusFailed, model.PaymentStatusRefunded} {
+		header, body, err := provider.Notify(order, status, "备注")
+		if err != nil {
+			t.Fatalf("Notify(%s): %v", status, err)
+		}
+		notice, err := provider.VerifyCallback(header, body)
+		if err != nil {
+			t.Fatalf("VerifyCallback(%s): %v", status, err)
+		}
+		if notice.OrderNo != order.OrderNo || notice.Status != status || notice.Amount != order.Amount || notice.Message != "备注" {
+			t.Errorf("notice = %+v, want order %s status %s amount %s", notice, order.OrderNo, status, order.Amount)
+		}
+		if notice.TradeNo == "" {
+			t.Error("trade_no should be generated when the order has none")
+		}
+		if err := checkPaidAmount(order, notice); err != nil {
+			t.Errorf("checkPaidAmount: %v", err)
+		}
+	}
+}
+
+func TestMockProviderRejectsTampering(t *testing.T) {
+	provider := useMockProvider(t)
+	header, body, err := provider.Notify(testPaymentOrder(), model.PaymentStatusPaid, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 篡改金额
+	tampered := bytes.Replace(body, []byte(`"amount":10`), []byte(`"amount":99`), 1)
+	if bytes.Equal(tampered, body) {
+		t.Fatalf("amount not found in body %s", body)
+	}
+	if _, err := provider.VerifyCallback(header, tampered); !errors.Is(err, util.ErrSignatureInvalid) {
+		t.Errorf("tampered body err = %v, want %v", err, util.ErrSignatureInvalid)
+	}
+
+	// 篡改状态
+	tampered = bytes.Replace(body, []byte(`"status":"paid"`), []byte(`"status":"failed"`), 1)
+	if _, err := provider.VerifyCallback(header, tampered); !errors.Is(err, util.ErrSignatureInvalid) {
+		t.Errorf("tampered status err = %v, want %v", err, util.ErrSignatureInvalid)
+	}
+
+	// 其他密钥签名
+	other := &mockPaymentProvider{secret: "other-secret"}
+	if _, err := other.VerifyCallback(header, body); !errors.Is(err, util.ErrSignatureInvalid) {
+		t.Errorf("wrong secret err = %v, want %v", err, util.ErrSignatureInvalid)
+	}
+
+	// 缺少签名
+	if _, err := provider.VerifyCallback(http.Header{}, body); !errors.Is(err, util.ErrSignatureMissing) {
+		t.Errorf("missing header err = %v, want %v", err, util.ErrSignatureMissing)
+	}
+
+	// 超出 callback_tolerance 的重放
+	old := strconv.FormatInt(time.Now().Add(-10*time.Minute).Unix(), 10)
+	replay := http.Header{}
+	replay.Set(PaymentTimestampHeader, old)
+	replay.Set(PaymentSignatureHeader, util.SignHMAC(provider.secret, old, body))
+	if _, err := provider.VerifyCallback(replay, body); !errors.Is(err, util.ErrSignatureExpired) {
+		t.Errorf("replay err = %v, want %v", err, util.ErrSignatureExpired)
+	}
+}
+
+func TestCheckPaidAmount(t *testing.T) {
+	provider := useMockProvider(t)
+	order := testPaymentOrder()
+
+	// 渠道按另一笔金额签名的通知验签通过，但与订单金额不一致时拒绝入账
+	other := *order
+	other.Amount = 1
+	header, body, err := provider.Notify(&other, model.PaymentStatusPaid, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	notice, err := provider.VerifyCallback(header, body)
+	if err != nil {
+		t.Fatalf("VerifyCallback: %v", err)
+	}
+	if err := checkPaidAmount(order, notice); err == nil {
+		t.Error("amount mismatch should be rejected")
+	}
+}
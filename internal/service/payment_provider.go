@@ -0,0 +1,71 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+
+	"bgame/internal/config"
+	"bgame/internal/model"
+)
+
+// 回调签名请求头，内置渠道使用；接入第三方渠道时按其文档在 VerifyCallback 中自行解析
+const (
+	PaymentSignatureHeader = "X-Payment-Signature"
+	PaymentTimestampHeader = "X-Payment-Timestamp"
+)
+
+var errPaymentProviderUnavailable = errors.New("不支持的支付渠道")
+
+// PaymentProvider 支付渠道
+// 新增渠道时实现该接口，在 init 中调用 RegisterPaymentProvider 注册，并在配置 payment.providers 中启用
+type PaymentProvider interface {
+	// Name 渠道标识，与回调地址 /api/payment/callback/{provider} 对应
+	Name() string
+	// CreatePayment 向渠道下单，返回客户端拉起支付所需的参数
+	CreatePayment(order *model.PaymentOrder) (*PaymentIntent, error)
+	// VerifyCallback 校验回调签名并解析通知内容，签名无效时返回错误
+	VerifyCallback(header http.Header, body []byte) (*PaymentNotification, error)
+	// Refund 向渠道发起退款，在本地事务提交后调用；返回错误时本地退还余额并恢复为已支付
+	Refund(order *model.PaymentOrder, reason string) error
+}
+
+// PaymentProviderFactory 根据渠道配置创建渠道实例
+type PaymentProviderFactory func(cfg *config.PaymentProviderConfig) PaymentProvider
+
+// PaymentIntent 渠道下单结果
+type PaymentIntent struct {
+	Provider string            `json:"provider"`
+	PayURL   string            `json:"pay_url,omitempty"` // 支付页面地址
+	Params   map[string]string `json:"params,omitempty"`  // 客户端 SDK 拉起支付所需的参数
+}
+
+// PaymentNotification 渠道回调通知
+type PaymentNotification struct {
+	OrderNo string
+	TradeNo string      // 渠道交易号
+	Status  string      // paid、failed 或 refunded
+	Amount  model.Money // 实付金额，支付成功时必须与订单金额一致
+	Message string      // 失败或退款原因
+}
+
+var paymentProviderFactories = map[string]PaymentProviderFactory{}
+
+// RegisterPaymentProvider 注册支付渠道，应在 init 中调用
+func RegisterPaymentProvider(name string, factory PaymentProviderFactory) {
+	paymentProviderFactories[name] = factory
+}
+
+// getPaymentProvider 获取已注册且在配置中启用的渠道
+func getPaymentProvider(name string) (PaymentProvider, error) {
+	factory, ok := paymentProviderFactories[name]
+	if !ok {
+		return nil, errPaymentProviderUnavailable
+	}
+	for i := range config.Cfg.Payment.Providers {
+		cfg := &config.Cfg.Payment.Providers[i]
+		if cfg.Name == name && cfg.Enabled {
+			return factory(cfg), nil
+		}
+	}
+	return nil, errPaymentProviderUnavailable
+}
@@ -0,0 +1,582 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"bgame/internal/config"
+	"bgame/internal/dao"
+	"bgame/internal/model"
+	"bgame/internal/util"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultPaymentOrderExpire       = 30 * time.Minute
+	defaultPaymentCallbackTolerance = 5 * time.Minute
+	defaultPaymentSweepInterval     = 60
+	paymentExpiredReason            = "订单超时未支付"
+)
+
+var (
+	// errPaymentDuplicate 订单已处于回调通知的状态，重复回调直接确认
+	errPaymentDuplicate = errors.New("重复通知")
+	// errPaymentStale 状态机不允许的通知（如已退款订单收到支付成功），确认后忽略，避免渠道反复重试
+	errPaymentStale = errors.New("订单状态不允许变更")
+)
+
+// PaymentService 充值订单：下单 -> 待支付 -> 已支付/失败 -> 已退款
+// 回调在锁定订单行的事务内校验状态机并入账，同一订单只会入账一次
+type PaymentService struct {
+	paymentDAO    *dao.PaymentDAO
+	walletService *WalletService
+}
+
+func NewPaymentService() *PaymentService {
+	return &PaymentService{
+		paymentDAO:    dao.NewPaymentDAO(),
+		walletService: NewWalletService(),
+	}
+}
+
+type CreatePaymentOrderRequest struct {
+	Provider string      `json:"provider" binding:"required,max=20"` // 支付渠道，如 mock
+	Amount   model.Money `json:"amount" binding:"required,gt=0" swaggertype:"number"`
+	ClientIP string      `json:"-"`
+}
+
+// CreatePaymentOrderResponse 下单结果，客户端使用 intent 拉起支付
+type CreatePaymentOrderResponse struct {
+	Order  *model.PaymentOrder `json:"order"`
+	Intent *PaymentIntent      `json:"intent"`
+}
+
+type PaymentOrderQuery struct {
+	util.PageQuery
+	Status string `form:"status"`
+}
+
+type AdminPaymentOrderQuery struct {
+	PaymentOrderQuery
+	UserID    uint      `form:"user_id"`
+	OrderNo   string    `form:"order_no"`
+	TradeNo   string    `form:"trade_no"`
+	Provider  string    `form:"provider"`
+	StartTime time.Time `form:"start_time" time_format:"2006-01-02 15:04:05"`
+	EndTime   time.Time `form:"end_time" time_format:"2006-01-02 15:04:05"`
+}
+
+// PaymentOrderDetail 订单详情及回调记录
+type PaymentOrderDetail struct {
+	model.PaymentOrder
+	Callbacks []model.PaymentCallbackLog `json:"callbacks"`
+}
+
+type RefundPaymentRequest struct {
+	Reason string `json:"reason" binding:"required,max=255"`
+}
+
+type MockPayRequest struct {
+	OrderNo string `json:"order_no" binding:"required"`
+	Status  string `json:"status" binding:"omitempty,oneof=paid failed"` // 模拟的支付结果，默认 paid
+}
+
+// CreateOrder 创建充值订单并向渠道下单
+func (s *PaymentService) CreateOrder(userID uint, req *CreatePaymentOrderRequest) (*CreatePaymentOrderResponse, error) {
+	provider, err := getPaymentProvider(req.Provider)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPaymentAmount(req.Amount); err != nil {
+		return nil, err
+	}
+
+	order := &model.PaymentOrder{
+		OrderNo:  util.NewSerialNo("P"),
+		UserID:   userID,
+		Provider: provider.Name(),
+		Amount:   req.Amount,
+		Status:   model.PaymentStatusPending,
+		ExpireAt: time.Now().Add(paymentOrderExpire()),
+		ClientIP: req.ClientIP,
+	}
+	if err := s.paymentDAO.CreateOrder(order); err != nil {
+		util.LogError("创建充值订单失败: user_id=%d err=%v", userID, err)
+		return nil, errors.New("创建订单失败")
+	}
+
+	intent, err := provider.CreatePayment(order)
+	if err != nil {
+		util.LogError("渠道下单失败: order_no=%s provider=%s err=%v", order.OrderNo, order.Provider, err)
+		if err := dao.Transaction(func(tx *gorm.DB) error {
+			return s.transit(tx, order, map[string]interface{}{
+				"status":      model.PaymentStatusFailed,
+				"fail_reason": "渠道下单失败",
+			})
+		}); err != nil {
+			util.LogError("关闭充值订单失败: order_no=%s err=%v", order.OrderNo, err)
+		}
+		return nil, errors.New("支付渠道下单失败，请稍后重试")
+	}
+
+	util.Info("创建充值订单: user_id=%d order_no=%s provider=%s amount=%s", userID, order.OrderNo, order.Provider, order.Amount)
+	return &CreatePaymentOrderResponse{Order: order, Intent: intent}, nil
+}
+
+// GetUserOrder 查询玩家自己的订单
+func (s *PaymentService) GetUserOrder(userID uint, orderNo string) (*model.PaymentOrder, error) {
+	order, err := s.paymentDAO.GetOrderByNo(orderNo)
+	if err != nil || order.UserID != userID {
+		if err == nil || errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("订单不存在")
+		}
+		return nil, errors.New("查询订单失败")
+	}
+	return order, nil
+}
+
+// ListUserOrders 分页查询玩家自己的订单
+func (s *PaymentService) ListUserOrders(userID uint, req *PaymentOrderQuery) (*util.PageResult, error) {
+	req.Normalize()
+	orders, total, err := s.paymentDAO.ListOrders(&dao.PaymentOrderFilter{
+		UserID: userID,
+		Status: req.Status,
+	}, req.Offset(), req.PageSize)
+	if err != nil {
+		return nil, errors.New("查询订单失败")
+	}
+	return util.NewPageResult(orders, total, &req.PageQuery), nil
+}
+
+// ListOrders 分页查询全部订单（管理端）
+func (s *PaymentService) ListOrders(req *AdminPaymentOrderQuery) (*util.PageResult, error) {
+	req.Normalize()
+	orders, total, err := s.paymentDAO.ListOrders(&dao.PaymentOrderFilter{
+		UserID:    req.UserID,
+		OrderNo:   req.OrderNo,
+		TradeNo:   req.TradeNo,
+		Provider:  req.Provider,
+		Status:    req.Status,
+		StartTime: req.StartTime,
+		EndTime:   req.EndTime,
+	}, req.Offset(), req.PageSize)
+	if err != nil {
+		return nil, errors.New("查询订单失败")
+	}
+	return util.NewPageResult(orders, total, &req.PageQuery), nil
+}
+
+// GetOrderDetail 获取订单详情及回调记录（管理端）
+func (s *PaymentService) GetOrderDetail(id uint) (*PaymentOrderDetail, error) {
+	order, err := s.paymentDAO.GetOrder(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("订单不存在")
+		}
+		return nil, errors.New("查询订单失败")
+	}
+	callbacks, err := s.paymentDAO.ListCallbackLogs(order.OrderNo)
+	if err != nil {
+		return nil, errors.New("查询回调记录失败")
+	}
+	return &PaymentOrderDetail{PaymentOrder: *order, Callbacks: callbacks}, nil
+}
+
+// HandleCallback 处理渠道回调：验签、按状态机变更订单并入账，重复通知视为成功，每次回调都写入回调记录
+func (s *PaymentService) HandleCallback(providerName string, header http.Header, body []byte, clientIP string) error {
+	callbackLog := &model.PaymentCallbackLog{
+		Provider: providerName,
+		Payload:  string(body),
+		ClientIP: clientIP,
+	}
+	defer func() {
+		if err := s.paymentDAO.CreateCallbackLog(callbackLog); err != nil {
+			util.LogError("写入支付回调记录失败: provider=%s order_no=%s err=%v", providerName, callbackLog.OrderNo, err)
+		}
+	}()
+
+	provider, err := getPaymentProvider(providerName)
+	if err != nil {
+		callbackLog.Result = err.Error()
+		return err
+	}
+	notice, err := provider.VerifyCallback(header, body)
+	if err != nil {
+		util.Security("支付回调验签失败: provider=%s ip=%s err=%v", providerName, clientIP, err)
+		callbackLog.Result = "验签失败: " + err.Error()
+		return errors.New("签名校验失败")
+	}
+	callbackLog.Verified = true
+	callbackLog.OrderNo = notice.OrderNo
+	callbackLog.TradeNo = notice.TradeNo
+	callbackLog.Status = notice.Status
+
+	err = s.applyNotification(providerName, notice)
+	switch {
+	case errors.Is(err, errPaymentDuplicate):
+		callbackLog.Result = "重复通知，已忽略"
+		return nil
+	case errors.Is(err, errPaymentStale):
+		util.Security("支付回调状态不允许变更: order_no=%s status=%s", notice.OrderNo, notice.Status)
+		callbackLog.Result = "订单状态不允许变更，已忽略"
+		return nil
+	case err != nil:
+		callbackLog.Result = err.Error()
+		return err
+	}
+	callbackLog.Result = "处理成功"
+	return nil
+}
+
+// Refund 管理员退款：先在事务内扣回余额并标记退款中，提交后再调用渠道退款，
+// 避免渠道请求期间持有订单和钱包的行锁；渠道退款成功时标记已退款，失败时退还余额并恢复为已支付
+func (s *PaymentService) Refund(operatorID, id uint, req *RefundPaymentRequest) (*model.PaymentOrder, error) {
+	var order *model.PaymentOrder
+	var provider PaymentProvider
+	err := dao.Transaction(func(tx *gorm.DB) error {
+		locked, err := s.paymentDAO.LockOrder(tx, id)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("订单不存在")
+			}
+			return paymentFailed(fmt.Sprint(id), err)
+		}
+		if !model.CanTransitPayment(locked.Status, model.PaymentStatusRefunding) {
+			return errors.New("只有已支付的订单可以退款")
+		}
+		provider, err = getPaymentProvider(locked.Provider)
+		if err != nil {
+			return err
+		}
+		if err := s.transit(tx, locked, map[string]interface{}{
+			"status":        model.PaymentStatusRefunding,
+			"refund_reason": req.Reason,
+			"refunded_by":   operatorID,
+		}); err != nil {
+			return err
+		}
+		if err := s.debitRefundTx(tx, locked, req.Reason, operatorID); err != nil {
+			return err
+		}
+		locked.Status = model.PaymentStatusRefunding
+		order = locked
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := provider.Refund(order, req.Reason); err != nil {
+		util.LogError("渠道退款失败: order_no=%s provider=%s err=%v", order.OrderNo, order.Provider, err)
+		if err := s.revertRefund(order.ID, operatorID); err != nil {
+			util.LogError("退还退款扣回的余额失败，订单停留在退款中: order_no=%s err=%v", order.OrderNo, err)
+		}
+		return nil, errors.New("渠道退款失败，请稍后重试")
+	}
+	if err := s.finishRefund(order.ID); err != nil {
+		util.LogError("渠道退款成功但订单状态更新失败，订单停留在退款中: order_no=%s err=%v", order.OrderNo, err)
+		return nil, errors.New("渠道已退款，订单状态更新失败，请稍后刷新")
+	}
+
+	order, err = s.paymentDAO.GetOrder(id)
+	if err != nil {
+		return nil, errors.New("查询订单失败")
+	}
+	util.Info("充值订单退款: order_no=%s user_id=%d amount=%s operator_id=%d", order.OrderNo, order.UserID, order.Amount, operatorID)
+	return order, nil
+}
+
+// MockPay 模拟渠道支付：生成签名回调并按真实回调流程处理，仅在启用 mock 渠道时可用
+func (s *PaymentService) MockPay(req *MockPayRequest, clientIP string) (*model.PaymentOrder, error) {
+	provider, err := getPaymentProvider(model.PaymentProviderMock)
+	if err != nil {
+		return nil, err
+	}
+	order, err := s.paymentDAO.GetOrderByNo(req.OrderNo)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("订单不存在")
+		}
+		return nil, errors.New("查询订单失败")
+	}
+	if order.Provider != model.PaymentProviderMock {
+		return nil, errors.New("订单不属于模拟渠道")
+	}
+
+	status := req.Status
+	message := ""
+	if status == "" {
+		status = model.PaymentStatusPaid
+	}
+	if status == model.PaymentStatusFailed {
+		message = "模拟支付失败"
+	}
+	header, body, err := provider.(*mockPaymentProvider).Notify(order, status, message)
+	if err != nil {
+		return nil, errors.New("生成模拟回调失败")
+	}
+	if err := s.HandleCallback(model.PaymentProviderMock, header, body, clientIP); err != nil {
+		return nil, err
+	}
+	order, err = s.paymentDAO.GetOrderByNo(req.OrderNo)
+	if err != nil {
+		return nil, errors.New("查询订单失败")
+	}
+	return order, nil
+}
+
+// StartExpirer 启动后台任务，定期关闭超时未支付的订单，ctx 取消后退出
+func (s *PaymentService) StartExpirer(ctx context.Context) {
+	interval := config.Cfg.Payment.SweepInterval
+	if interval <= 0 {
+		interval = defaultPaymentSweepInterval
+	}
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(interval) * time.Second)
+		defer ticker.Stop()
+		for {
+			s.ExpireOrders(time.Now())
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// ExpireOrders 关闭已过支付截止时间的待支付订单；关闭后仍收到支付成功回调时照常入账
+func (s *PaymentService) ExpireOrders(now time.Time) {
+	count, err := s.paymentDAO.ExpirePending(now, paymentExpiredReason)
+	if err != nil {
+		util.LogError("关闭超时充值订单失败: err=%v", err)
+		return
+	}
+	if count > 0 {
+		util.Info("关闭超时充值订单: count=%d", count)
+	}
+}
+
+// applyNotification 在锁定订单行的事务内按回调通知变更订单状态
+func (s *PaymentService) applyNotification(providerName string, notice *PaymentNotification) error {
+	return dao.Transaction(func(tx *gorm.DB) error {
+		order, err := s.paymentDAO.LockOrderByNo(tx, notice.OrderNo)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("订单不存在")
+			}
+			return paymentFailed(notice.OrderNo, err)
+		}
+		if order.Provider != providerName {
+			util.Security("支付回调渠道与订单不一致: order_no=%s order_provider=%s callback_provider=%s", order.OrderNo, order.Provider, providerName)
+			return errors.New("支付渠道与订单不一致")
+		}
+		if order.Status == notice.Status {
+			return errPaymentDuplicate
+		}
+		if !canNotifyPayment(order.Status, notice.Status) {
+			return errPaymentStale
+		}
+
+		switch notice.Status {
+		case model.PaymentStatusPaid:
+			return s.payTx(tx, order, notice)
+		case model.PaymentStatusFailed:
+			reason := notice.Message
+			if reason == "" {
+				reason = "支付失败"
+			}
+			return s.transit(tx, order, map[string]interface{}{
+				"status":      model.PaymentStatusFailed,
+				"trade_no":    notice.TradeNo,
+				"fail_reason": reason,
+			})
+		case model.PaymentStatusRefunded:
+			if order.Status == model.PaymentStatusRefunding {
+				// 管理员发起的退款已扣回余额，渠道回调只完成状态变更
+				now := time.Now()
+				return s.transit(tx, order, map[string]interface{}{
+					"status":      model.PaymentStatusRefunded,
+					"refunded_at": &now,
+				})
+			}
+			return s.refundTx(tx, order, notice.Message)
+		}
+		return fmt.Errorf("无效的订单状态: %s", notice.Status)
+	})
+}
+
+// canNotifyPayment 回调通知能否变更订单状态：退款中只由本地退款流程进入，
+// refunding -> paid 只用于渠道退款失败后的本地回滚，都不接受回调触发
+func canNotifyPayment(from, to string) bool {
+	if to == model.PaymentStatusRefunding || (from == model.PaymentStatusRefunding && to != model.PaymentStatusRefunded) {
+		return false
+	}
+	return model.CanTransitPayment(from, to)
+}
+
+// payTx 支付成功：校验金额后为余额入账
+func (s *PaymentService) payTx(tx *gorm.DB, order *model.PaymentOrder, notice *PaymentNotification) error {
+	if err := checkPaidAmount(order, notice); err != nil {
+		return err
+	}
+	now := time.Now()
+	if err := s.transit(tx, order, map[string]interface{}{
+		"status":      model.PaymentStatusPaid,
+		"trade_no":    notice.TradeNo,
+		"paid_at":     &now,
+		"fail_reason": "",
+	}); err != nil {
+		return err
+	}
+	if _, err := s.walletService.ChangeTx(tx, &dao.WalletChange{
+		UserID:      order.UserID,
+		Currency:    model.CurrencyBalance,
+		Direction:   model.WalletCredit,
+		Amount:      order.Amount,
+		ReasonCode:  model.WalletReasonPaymentTopup,
+		ReferenceID: "payment:" + order.OrderNo,
+		Remark:      "充值订单 " + order.OrderNo,
+	}); err != nil {
+		return err
+	}
+	util.Info("充值到账: order_no=%s user_id=%d amount=%s trade_no=%s", order.OrderNo, order.UserID, order.Amount, notice.TradeNo)
+	return nil
+}
+
+// checkPaidAmount 校验渠道实付金额与订单金额一致
+func checkPaidAmount(order *model.PaymentOrder, notice *PaymentNotification) error {
+	if notice.Amount != order.Amount {
+		util.Security("支付回调金额与订单不一致: order_no=%s order_amount=%s paid_amount=%s", order.OrderNo, order.Amount, notice.Amount)
+		return errors.New("支付金额与订单不一致")
+	}
+	return nil
+}
+
+// refundTx 渠道发起的退款：扣回余额并标记已退款，余额不足时失败
+func (s *PaymentService) refundTx(tx *gorm.DB, order *model.PaymentOrder, reason string) error {
+	now := time.Now()
+	if err := s.transit(tx, order, map[string]interface{}{
+		"status":        model.PaymentStatusRefunded,
+		"refunded_at":   &now,
+		"refund_reason": reason,
+		"refunded_by":   0,
+	}); err != nil {
+		return err
+	}
+	return s.debitRefundTx(tx, order, reason, 0)
+}
+
+// debitRefundTx 按订单金额扣回余额，余额不足时失败
+func (s *PaymentService) debitRefundTx(tx *gorm.DB, order *model.PaymentOrder, reason string, operatorID uint) error {
+	if _, err := s.walletService.ChangeTx(tx, &dao.WalletChange{
+		UserID:      order.UserID,
+		Currency:    model.CurrencyBalance,
+		Direction:   model.WalletDebit,
+		Amount:      order.Amount,
+		ReasonCode:  model.WalletReasonPaymentRefund,
+		ReferenceID: "payment:" + order.OrderNo,
+		OperatorID:  operatorID,
+		Remark:      reason,
+	}); err != nil {
+		if errors.Is(err, dao.ErrInsufficientBalance) {
+			return errors.New("玩家余额不足，无法退款")
+		}
+		return err
+	}
+	return nil
+}
+
+// finishRefund 渠道退款成功后将退款中的订单标记为已退款，渠道回调已先行完成时直接返回
+func (s *PaymentService) finishRefund(id uint) error {
+	return dao.Transaction(func(tx *gorm.DB) error {
+		order, err := s.paymentDAO.LockOrder(tx, id)
+		if err != nil {
+			return paymentFailed(fmt.Sprint(id), err)
+		}
+		if order.Status != model.PaymentStatusRefunding {
+			return nil
+		}
+		now := time.Now()
+		return s.transit(tx, order, map[string]interface{}{
+			"status":      model.PaymentStatusRefunded,
+			"refunded_at": &now,
+		})
+	})
+}
+
+// revertRefund 渠道退款失败后退还扣回的余额，订单恢复为已支付；订单已不在退款中时（如渠道回调确认已退款）不做处理
+func (s *PaymentService) revertRefund(id, operatorID uint) error {
+	return dao.Transaction(func(tx *gorm.DB) error {
+		order, err := s.paymentDAO.LockOrder(tx, id)
+		if err != nil {
+			return paymentFailed(fmt.Sprint(id), err)
+		}
+		if order.Status != model.PaymentStatusRefunding {
+			return nil
+		}
+		if err := s.transit(tx, order, map[string]interface{}{
+			"status":        model.PaymentStatusPaid,
+			"refund_reason": "",
+			"refunded_by":   0,
+		}); err != nil {
+			return err
+		}
+		_, err = s.walletService.ChangeTx(tx, &dao.WalletChange{
+			UserID:      order.UserID,
+			Currency:    model.CurrencyBalance,
+			Direction:   model.WalletCredit,
+			Amount:      order.Amount,
+			ReasonCode:  model.WalletReasonRefundRevert,
+			ReferenceID: "payment:" + order.OrderNo,
+			OperatorID:  operatorID,
+			Remark:      "渠道退款失败",
+		})
+		return err
+	})
+}
+
+// transit 更新订单状态，订单已被并发修改时失败
+func (s *PaymentService) transit(tx *gorm.DB, order *model.PaymentOrder, fields map[string]interface{}) error {
+	ok, err := s.paymentDAO.UpdateOrderStatus(tx, order.ID, order.Status, fields)
+	if err != nil {
+		return paymentFailed(order.OrderNo, err)
+	}
+	if !ok {
+		return errors.New("订单状态已变更，请重试")
+	}
+	return nil
+}
+
+// checkPaymentAmount 校验单笔充值金额是否在配置范围内
+func checkPaymentAmount(amount model.Money) error {
+	cfg := config.Cfg.Payment
+	if cfg.MinAmount != "" {
+		if min, err := model.ParseMoney(cfg.MinAmount); err == nil && amount < min {
+			return fmt.Errorf("单笔充值金额不能低于%s", min)
+		}
+	}
+	if cfg.MaxAmount != "" {
+		if max, err := model.ParseMoney(cfg.MaxAmount); err == nil && amount > max {
+			return fmt.Errorf("单笔充值金额不能超过%s", max)
+		}
+	}
+	return nil
+}
+
+func paymentOrderExpire() time.Duration {
+	if expire := config.Cfg.Payment.OrderExpire; expire > 0 {
+		return time.Duration(expire) * time.Second
+	}
+	return defaultPaymentOrderExpire
+}
+
+// paymentFailed 记录数据库错误并返回通用错误
+func paymentFailed(orderNo string, err error) error {
+	util.LogError("处理充值订单失败: order_no=%s err=%v", orderNo, err)
+	return errors.New("处理订单失败，请重试")
+}
@@ -0,0 +1,31 @@
+package service
+
+import (
+	"testing"
+
+	"bgame/internal/model"
+)
+
+func TestCanNotifyPayment(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		{model.PaymentStatusPending, model.PaymentStatusPaid, true},
+		{model.PaymentStatusPending, model.PaymentStatusFailed, true},
+		{model.PaymentStatusFailed, model.PaymentStatusPaid, true},
+		{model.PaymentStatusPaid, model.PaymentStatusRefunded, true},
+		{model.PaymentStatusRefunding, model.PaymentStatusRefunded, true},
+		// 退款中只能由管理员退款进入，回滚到已支付也只由本地流程执行
+		{model.PaymentStatusPaid, model.PaymentStatusRefunding, false},
+		{model.PaymentStatusRefunding, model.PaymentStatusPaid, false},
+		{model.PaymentStatusRefunding, model.PaymentStatusFailed, false},
+		{model.PaymentStatusRefunded, model.PaymentStatusPaid, false},
+		{model.PaymentStatusPaid, model.PaymentStatusFailed, false},
+	}
+	for _, tt := range tests {
+		if got := canNotifyPayment(tt.from, tt.to); got != tt.want {
+			t.Errorf("canNotifyPayment(%s, %s) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,406 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"bgame/internal/config"
+	"bgame/internal/dao"
+	"bgame/internal/model"
+	"bgame/internal/util"
+	"gorm.io/gorm"
+)
+
+// defaultMaxLevel 公式模式下未配置等级上限时的默认值
+const defaultMaxLevel = 100
+
+// LevelUpEvent 一次经验发放引起的升级，FromLevel 到 ToLevel 之间可能跨越多级
+type LevelUpEvent struct {
+	UserID    uint
+	FromLevel int
+	ToLevel   int
+	Log       *model.ExperienceLog
+}
+
+// LevelUpHook 升级回调，与经验发放在同一事务中执行，返回错误时整笔发放回滚
+type LevelUpHook func(tx *gorm.DB, event *LevelUpEvent) error
+
+// ProgressionService 经验与等级：按配置的曲线计算等级，原子发放经验并触发升级回调
+type ProgressionService struct {
+	experienceDAO      *dao.ExperienceDAO
+	userProfileDAO     *dao.UserProfileDAO
+	walletService      *WalletService
+	leaderboardService *LeaderboardService
+	hooks              []LevelUpHook
+}
+
+func NewProgressionService() *ProgressionService {
+	s := &ProgressionService{
+		experienceDAO:      dao.NewExperienceDAO(),
+		userProfileDAO:     dao.NewUserProfileDAO(),
+		walletService:      NewWalletService(),
+		leaderboardService: NewLeaderboardService(),
+	}
+	s.RegisterLevelUpHook(s.levelUpRewardHook)
+	return s
+}
+
+// ExperienceGrant 一次经验发放
+type ExperienceGrant struct {
+	UserID      uint
+	Amount      int
+	Source      string
+	ReasonCode  string
+	ReferenceID string // 不为空时同一用户、原因码下只发放一次
+	OperatorID  uint
+	Operator    string
+	Remark      string
+}
+
+// GrantExperienceRequest 服务端发放经验请求
+type GrantExperienceRequest struct {
+	UserID      uint   `json:"user_id" binding:"required"`
+	Amount      int    `json:"amount" binding:"required,gt=0"`
+	ReasonCode  string `json:"reason_code" binding:"required,max=50"`
+	ReferenceID string `json:"reference_id" binding:"max=64"`
+	Remark      string `json:"remark" binding:"max=255"`
+}
+
+// AdminGrantExperienceRequest 管理员发放经验请求
+type AdminGrantExperienceRequest struct {
+	Amount int    `json:"amount" binding:"required,gt=0"`
+	Remark string `json:"remark" binding:"required,max=255"`
+}
+
+type LevelProgressResponse struct {
+	UserID              uint `json:"user_id"`
+	Level               int  `json:"level"`
+	Experience          int  `json:"experience"`            // 累计经验
+	LevelExperience     int  `json:"level_experience"`      // 达到当前等级所需的累计经验
+	NextLevelExperience int  `json:"next_level_experience"` // 达到下一级所需的累计经验，满级时为0
+	MaxLevel            int  `json:"max_level"`
+}
+
+type GrantExperienceResponse struct {
+	LevelProgressResponse
+	LogID        uint `json:"log_id"`
+	Amount       int  `json:"amount"`
+	LevelBefore  int  `json:"level_before"`
+	LevelsGained int  `json:"levels_gained"`
+	Duplicate    bool `json:"duplicate"` // 关联业务ID已发放过，本次未重复发放
+}
+
+type ExperienceLogQuery struct {
+	util.PageQuery
+	Source     string `form:"source"`
+	ReasonCode string `form:"reason_code"`
+}
+
+type AdminExperienceLogQuery struct {
+	ExperienceLogQuery
+	UserID      uint      `form:"user_id"`
+	ReferenceID string    `form:"reference_id"`
+	StartTime   time.Time `form:"start_time" time_format:"2006-01-02 15:04:05"`
+	EndTime     time.Time `form:"end_time" time_format:"2006-01-02 15:04:05"`
+}
+
+// RegisterLevelUpHook 注册升级回调，按注册顺序执行
+func (s *ProgressionService) RegisterLevelUpHook(hook LevelUpHook) {
+	s.hooks = append(s.hooks, hook)
+}
+
+// GetProgress 获取用户等级进度
+func (s *ProgressionService) GetProgress(userID uint) (*LevelProgressResponse, error) {
+	profile, err := s.userProfileDAO.GetUserProfileByUserID(userID)
+	if err != nil {
+		return nil, errors.New("用户不存在")
+	}
+	return buildLevelProgress(userID, profile.Level, profile.Experience), nil
+}
+
+// ServerGrant 服务端发放经验
+func (s *ProgressionService) ServerGrant(client string, req *GrantExperienceRequest) (*GrantExperienceResponse, error) {
+	return s.Grant(&ExperienceGrant{
+		UserID:      req.UserID,
+		Amount:      req.Amount,
+		Source:      model.ExperienceSourceServer,
+		ReasonCode:  req.ReasonCode,
+		ReferenceID: req.ReferenceID,
+		Operator:    client,
+		Remark:      req.Remark,
+	})
+}
+
+// AdminGrant 管理员发放经验
+func (s *ProgressionService) AdminGrant(operatorID uint, operator string, userID uint, req *AdminGrantExperienceRequest) (*GrantExperienceResponse, error) {
+	return s.Grant(&ExperienceGrant{
+		UserID:     userID,
+		Amount:     req.Amount,
+		Source:     model.ExperienceSourceAdmin,
+		ReasonCode: model.ExperienceReasonAdminGrant,
+		OperatorID: operatorID,
+		Operator:   operator,
+		Remark:     req.Remark,
+	})
+}
+
+// Grant 在一个事务中发放经验、计算升级（可连升多级）并执行升级回调
+func (s *ProgressionService) Grant(grant *ExperienceGrant) (*GrantExperienceResponse, error) {
+	var resp *GrantExperienceResponse
+	err := dao.Transaction(func(tx *gorm.DB) error {
+		var err error
+		resp, err = s.GrantTx(tx, grant)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.AfterGrant(grant, resp)
+	return resp, nil
+}
+
+// GrantTx 在调用方事务中发放经验，供需要与其他写操作保持原子性的业务使用；事务提交后需调用 AfterGrant
+func (s *ProgressionService) GrantTx(tx *gorm.DB, grant *ExperienceGrant) (*GrantExperienceResponse, error) {
+	if grant.Amount <= 0 {
+		return nil, errors.New("经验必须大于0")
+	}
+	if maxGrant := config.Cfg.Progression.MaxGrant; maxGrant > 0 && grant.Amount > maxGrant {
+		return nil, fmt.Errorf("单次发放经验不能超过%d", maxGrant)
+	}
+	if grant.ReasonCode == "" {
+		return nil, errors.New("原因码不能为空")
+	}
+
+	profile, err := s.experienceDAO.LockProfile(tx, grant.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("用户不存在")
+		}
+		return nil, grantFailed(grant, err)
+	}
+
+	if grant.ReferenceID != "" {
+		existing, err := s.experienceDAO.FindByReference(tx, grant.UserID, grant.ReasonCode, grant.ReferenceID)
+		if err != nil {
+			return nil, grantFailed(grant, err)
+		}
+		if existing != nil {
+			return buildGrantResponse(existing, profile, true), nil
+		}
+	}
+
+	levelBefore := profile.Level
+	if levelBefore < 1 {
+		levelBefore = 1
+	}
+	if profile.Experience > math.MaxInt32-grant.Amount {
+		return nil, errors.New("经验值超出上限")
+	}
+	experience := profile.Experience + grant.Amount
+	// 曲线调整后不降级
+	level := levelForExperience(experience)
+	if level < levelBefore {
+		level = levelBefore
+	}
+
+	if err := s.experienceDAO.UpdateProgress(tx, profile.ID, level, experience); err != nil {
+		return nil, grantFailed(grant, err)
+	}
+	log := &model.ExperienceLog{
+		UserID:          grant.UserID,
+		Amount:          grant.Amount,
+		ExperienceAfter: experience,
+		LevelBefore:     levelBefore,
+		LevelAfter:      level,
+		Source:          grant.Source,
+		ReasonCode:      grant.ReasonCode,
+		ReferenceID:     grant.ReferenceID,
+		OperatorID:      grant.OperatorID,
+		Operator:        grant.Operator,
+		Remark:          grant.Remark,
+	}
+	if err := s.experienceDAO.CreateLog(tx, log); err != nil {
+		return nil, grantFailed(grant, err)
+	}
+
+	if level > levelBefore {
+		event := &LevelUpEvent{UserID: grant.UserID, FromLevel: levelBefore, ToLevel: level, Log: log}
+		for _, hook := range s.hooks {
+			if err := hook(tx, event); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	profile.Level = level
+	profile.Experience = experience
+	return buildGrantResponse(log, profile, false), nil
+}
+
+// AfterGrant 经验发放事务提交后更新排行榜并记录日志
+func (s *ProgressionService) AfterGrant(grant *ExperienceGrant, resp *GrantExperienceResponse) {
+	if resp == nil || resp.Duplicate {
+		return
+	}
+	s.leaderboardService.SubmitProgress(grant.UserID, resp.Level, resp.Experience, grant.Amount)
+	util.Info("发放经验: user_id=%d amount=%d source=%s reason_code=%s operator=%s level=%d->%d",
+		grant.UserID, grant.Amount, grant.Source, grant.ReasonCode, grant.Operator, resp.LevelBefore, resp.Level)
+}
+
+// ListUserLogs 分页查询用户的经验流水
+func (s *ProgressionService) ListUserLogs(userID uint, req *ExperienceLogQuery) (*util.PageResult, error) {
+	req.Normalize()
+	logs, total, err := s.experienceDAO.ListLogs(&dao.ExperienceLogFilter{
+		UserID:     userID,
+		Source:     req.Source,
+		ReasonCode: req.ReasonCode,
+	}, req.Offset(), req.PageSize)
+	if err != nil {
+		return nil, errors.New("查询经验流水失败")
+	}
+	return util.NewPageResult(logs, total, &req.PageQuery), nil
+}
+
+// ListLogs 管理员分页查询经验流水
+func (s *ProgressionService) ListLogs(req *AdminExperienceLogQuery) (*util.PageResult, error) {
+	req.Normalize()
+	logs, total, err := s.experienceDAO.ListLogs(&dao.ExperienceLogFilter{
+		UserID:      req.UserID,
+		Source:      req.Source,
+		ReasonCode:  req.ReasonCode,
+		ReferenceID: req.ReferenceID,
+		StartTime:   req.StartTime,
+		EndTime:     req.EndTime,
+	}, req.Offset(), req.PageSize)
+	if err != nil {
+		return nil, errors.New("查询经验流水失败")
+	}
+	return util.NewPageResult(logs, total, &req.PageQuery), nil
+}
+
+// levelUpRewardHook 按配置为跨越的每一级发放活动余额奖励
+func (s *ProgressionService) levelUpRewardHook(tx *gorm.DB, event *LevelUpEvent) error {
+	for level := event.FromLevel + 1; level <= event.ToLevel; level++ {
+		for _, reward := range config.Cfg.Progression.Rewards {
+			if (reward.Level != 0 && reward.Level != level) || reward.ActivityBalance == "" {
+				continue
+			}
+			amount, err := model.ParseMoney(reward.ActivityBalance)
+			if err != nil || !amount.IsPositive() {
+				util.LogError("升级奖励配置错误: level=%d activity_balance=%s", reward.Level, reward.ActivityBalance)
+				continue
+			}
+			if _, err := s.walletService.ChangeTx(tx, &dao.WalletChange{
+				UserID:      event.UserID,
+				Currency:    model.CurrencyActivityBalance,
+				Direction:   model.WalletCredit,
+				Amount:      amount,
+				ReasonCode:  model.WalletReasonLevelUp,
+				ReferenceID: fmt.Sprintf("experience:%d", event.Log.ID),
+				Remark:      fmt.Sprintf("升级到%d级奖励", level),
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// grantFailed 记录数据库错误并返回通用错误
+func grantFailed(grant *ExperienceGrant, err error) error {
+	util.LogError("发放经验失败: user_id=%d amount=%d reason_code=%s err=%v", grant.UserID, grant.Amount, grant.ReasonCode, err)
+	return errors.New("发放经验失败")
+}
+
+func buildGrantResponse(log *model.ExperienceLog, profile *model.UserProfile, duplicate bool) *GrantExperienceResponse {
+	return &GrantExperienceResponse{
+		LevelProgressResponse: *buildLevelProgress(profile.UserID, profile.Level, profile.Experience),
+		LogID:                 log.ID,
+		Amount:                log.Amount,
+		LevelBefore:           log.LevelBefore,
+		LevelsGained:          log.LevelAfter - log.LevelBefore,
+		Duplicate:             duplicate,
+	}
+}
+
+func buildLevelProgress(userID uint, level, experience int) *LevelProgressResponse {
+	if level < 1 {
+		level = 1
+	}
+	max := maxLevel()
+	resp := &LevelProgressResponse{
+		UserID:          userID,
+		Level:           level,
+		Experience:      experience,
+		LevelExperience: levelThreshold(level),
+		MaxLevel:        max,
+	}
+	if level < max {
+		resp.NextLevelExperience = levelThreshold(level + 1)
+	}
+	return resp
+}
+
+// maxLevel 等级上限：优先使用配置值，其次为曲线表长度+1
+func maxLevel() int {
+	cfg := config.Cfg.Progression
+	if cfg.MaxLevel > 0 {
+		return cfg.MaxLevel
+	}
+	if len(cfg.Levels) > 0 {
+		return len(cfg.Levels) + 1
+	}
+	return defaultMaxLevel
+}
+
+// experienceToNext 从 level 级升到 level+1 级所需经验；曲线表不足时沿用最后一项，至少为1
+func experienceToNext(level int) int {
+	cfg := config.Cfg.Progression
+	var need int
+	if n := len(cfg.Levels); n > 0 {
+		if level-1 < n {
+			need = cfg.Levels[level-1]
+		} else {
+			need = cfg.Levels[n-1]
+		}
+	} else {
+		exponent := cfg.Exponent
+		if exponent <= 0 {
+			exponent = 1
+		}
+		need = int(math.Min(math.Round(float64(cfg.Base)*math.Pow(float64(level), exponent)), math.MaxInt32))
+	}
+	if need < 1 {
+		need = 1
+	}
+	return need
+}
+
+// levelThreshold 达到 level 级所需的累计经验
+func levelThreshold(level int) int {
+	total := 0
+	for l := 1; l < level; l++ {
+		total += experienceToNext(l)
+		if total >= math.MaxInt32 {
+			return math.MaxInt32
+		}
+	}
+	return total
+}
+
+// levelForExperience 累计经验对应的等级，不超过等级上限
+func levelForExperience(experience int) int {
+	max := maxLevel()
+	level, threshold := 1, 0
+	for level < max {
+		threshold += experienceToNext(level)
+		if experience < threshold {
+			break
+		}
+		level++
+	}
+	return level
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"math"
+	"testing"
+
+	"bgame/internal/config"
+)
+
+// useConfig 在测试期间替换全局配置，结束后恢复
+func useConfig(t *testing.T, cfg *config.Config) {
+	t.Helper()
+	prev := config.Cfg
+	config.Cfg = cfg
+	t.Cleanup(func() { config.Cfg = prev })
+}
+
+func TestExperienceCurveFormula(t *testing.T) {
+	useConfig(t, &config.Config{Progression: config.ProgressionConfig{Base: 100, Exponent: 1.5}})
+
+	// base * n^exponent，四舍五入
+	tests := []struct {
+		level int
+		want  int
+	}{
+		{1, 100},
+		{2, 283},
+		{3, 520},
+		{4, 800},
+		{10, 3162},
+	}
+	for _, tt := range tests {
+		if got := experienceToNext(tt.level); got != tt.want {
+			t.Errorf("experienceToNext(%d) = %d, want %d", tt.level, got, tt.want)
+		}
+	}
+	if got := maxLevel(); got != defaultMaxLevel {
+		t.Errorf("maxLevel() = %d, want %d", got, defaultMaxLevel)
+	}
+}
+
+func TestExperienceCurveFormulaDefaults(t *testing.T) {
+	// 未配置指数时按线性计算，所需经验至少为1
+	useConfig(t, &config.Config{Progression: config.ProgressionConfig{Base: 50}})
+	if got := experienceToNext(3); got != 150 {
+		t.Errorf("linear experienceToNext(3) = %d, want 150", got)
+	}
+
+	useConfig(t, &config.Config{})
+	if got := experienceToNext(5); got != 1 {
+		t.Errorf("zero base experienceToNext(5) = %d, want 1", got)
+	}
+}
+
+func TestExperienceCurveTable(t *testing.T) {
+	useConfig(t, &config.Config{Progression: config.ProgressionConfig{Levels: []int{100, 200, 400}}})
+
+	if got := maxLevel(); got != 4 {
+		t.Errorf("maxLevel() = %d, want 4", got)
+	}
+	for level, want := range map[int]int{1: 100, 2: 200, 3: 400, 4: 400, 10: 400} {
+		if got := experienceToNext(level); got != want {
+			t.Errorf("experienceToNext(%d) = %d, want %d", level, got, want)
+		}
+	}
+	for level, want := range map[int]int{1: 0, 2: 100, 3: 300, 4: 700} {
+		if got := levelThreshold(level); got != want {
+			t.Errorf("levelThreshold(%d) = %d, want %d", level, got, want)
+		}
+	}
+}
+
+func TestLevelForExperience(t *testing.T) {
+	useConfig(t, &config.Config{Progression: config.ProgressionConfig{Levels: []int{100, 200, 400}}})
+
+	tests := []struct {
+		experience int
+		want       int
+	}{
+		{0, 1},
+		{99, 1},
+		{100, 2},
+		{299, 2},
+		{300, 3},
+		{699, 3},
+		{700, 4},
+		{1000000, 4}, // 不超过等级上限
+	}
+	for _, tt := range tests {
+		if got := levelForExperience(tt.experience); got != tt.want {
+			t.Errorf("levelForExperience(%d) = %d, want %d", tt.experience, got, tt.want)
+		}
+	}
+}
+
+func TestLevelForExperienceMatchesThreshold(t *testing.T) {
+	useConfig(t, &config.Config{Progression: config.ProgressionConfig{MaxLevel: 50, Base: 100, Exponent: 1.5}})
+
+	for level := 1; level <= 50; level++ {
+		threshold := levelThreshold(level)
+		if got := levelForExperience(threshold); got != level {
+			t.Errorf("levelForExperience(levelThreshold(%d)) = %d", level, got)
+		}
+		if level > 1 {
+			if got := levelForExperience(threshold - 1); got != level-1 {
+				t.Errorf("levelForExperience(levelThreshold(%d)-1) = %d, want %d", level, got, level-1)
+			}
+		}
+	}
+}
+
+func TestLevelThresholdSaturates(t *testing.T) {
+	useConfig(t, &config.Config{Progression: config.ProgressionConfig{MaxLevel: 1000, Base: 1000000, Exponent: 3}})
+
+	if got := levelThreshold(1000); got != math.MaxInt32 {
+		t.Errorf("levelThreshold(1000) = %d, want MaxInt32", got)
+	}
+}
+
+func TestBuildLevelProgress(t *testing.T) {
+	useConfig(t, &config.Config{Progression: config.ProgressionConfig{Levels: []int{100, 200, 400}}})
+
+	resp := buildLevelProgress(7, 2, 150)
+	if resp.Level != 2 || resp.LevelExperience != 100 || resp.NextLevelExperience != 300 || resp.MaxLevel != 4 {
+		t.Errorf("buildLevelProgress(level 2) = %+v", resp)
+	}
+
+	resp = buildLevelProgress(7, 4, 900)
+	if resp.NextLevelExperience != 0 {
+		t.Errorf("max level NextLevelExperience = %d, want 0", resp.NextLevelExperience)
+	}
+
+	resp = buildLevelProgress(7, 0, 0)
+	if resp.Level != 1 {
+		t.Errorf("level below 1 normalized to %d, want 1", resp.Level)
+	}
+}
@@ -0,0 +1,468 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"bgame/internal/config"
+	"bgame/internal/dao"
+	"bgame/internal/model"
+	"bgame/internal/util"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultRedeemCodeLength   = 12
+	defaultRedeemMaxBatchSize = 10000
+	defaultRedeemCacheTTL     = 7 * 24 * time.Hour
+)
+
+// errRedeemUsed 玩家已兑换过该码，同时写入 Redis 标记
+var errRedeemUsed = errors.New("您已兑换过该兑换码")
+
+// redeemCodePattern 兑换码格式：大写字母和数字，4~32位（输入时忽略大小写、空格和连字符）
+var redeemCodePattern = regexp.MustCompile(`^[A-Z0-9]{4,32}$`)
+
+// RedeemService 兑换码：管理员按批次生成，玩家兑换时在一个事务内校验并发放奖励
+// MySQL 为准，Redis 缓存已兑换、已用完标记，重复提交无需进入数据库事务
+type RedeemService struct {
+	redeemDAO     *dao.RedeemDAO
+	rewardService *RewardService
+}
+
+func NewRedeemService() *RedeemService {
+	return &RedeemService{
+		redeemDAO:     dao.NewRedeemDAO(),
+		rewardService: NewRewardService(),
+	}
+}
+
+// CreateRedeemBatchRequest 创建兑换码批次请求
+type CreateRedeemBatchRequest struct {
+	Name         string             `json:"name" binding:"required,max=100"`
+	Type         string             `json:"type" binding:"required,oneof=single multi"` // single 一码一用，multi 通用码
+	Count        int                `json:"count" binding:"min=0"`                      // 一码一用：生成数量
+	Code         string             `json:"code" binding:"max=32"`                      // 通用码：自定义兑换码，为空时随机生成
+	MaxUses      int                `json:"max_uses" binding:"min=0"`                   // 通用码：可兑换总次数，0表示不限
+	PerUserLimit int                `json:"per_user_limit" binding:"min=0"`             // 一码一用：每个玩家在本批次最多兑换几个码，默认1
+	Rewards      model.RewardBundle `json:"rewards"`
+	StartAt      *time.Time         `json:"start_at"` // 生效时间（RFC3339），为空表示立即生效
+	EndAt        *time.Time         `json:"end_at"`   // 过期时间（RFC3339），为空表示永不过期
+	Remark       string             `json:"remark" binding:"max=255"`
+}
+
+type UpdateRedeemBatchStatusRequest struct {
+	Status *int `json:"status" binding:"required,oneof=0 1"` // 1:启用 0:停用
+}
+
+type RedeemBatchQuery struct {
+	util.PageQuery
+	Name   string `form:"name"` // 模糊匹配
+	Type   string `form:"type"`
+	Status *int   `form:"status"`
+}
+
+type RedeemCodeQuery struct {
+	util.PageQuery
+	Used *bool `form:"used"`
+}
+
+type RedeemRecordQuery struct {
+	util.PageQuery
+	BatchID   uint      `form:"batch_id"`
+	UserID    uint      `form:"user_id"`
+	Code      string    `form:"code"`
+	StartTime time.Time `form:"start_time" time_format:"2006-01-02 15:04:05"`
+	EndTime   time.Time `form:"end_time" time_format:"2006-01-02 15:04:05"`
+}
+
+// RedeemBatchDetail 批次详情及兑换统计
+type RedeemBatchDetail struct {
+	model.RedeemBatch
+	UsedCodes int64 `json:"used_codes"` // 至少被兑换过一次的码数量
+	Users     int64 `json:"users"`      // 兑换过的玩家数
+}
+
+type RedeemRequest struct {
+	Code     string `json:"code" binding:"required,max=64"`
+	ClientIP string `json:"-"`
+}
+
+type RedeemResponse struct {
+	RecordID  uint                   `json:"record_id"`
+	Code      string                 `json:"code"`
+	BatchName string                 `json:"batch_name"`
+	Rewards   model.RewardBundle     `json:"rewards"`
+	Progress  *LevelProgressResponse `json:"progress,omitempty"` // 发放经验后的等级进度
+}
+
+// CreateBatch 创建兑换码批次并生成兑换码
+func (s *RedeemService) CreateBatch(operatorID uint, req *CreateRedeemBatchRequest) (*model.RedeemBatch, error) {
+	if err := s.rewardService.Validate(&req.Rewards); err != nil {
+		return nil, err
+	}
+	if req.StartAt != nil && req.EndAt != nil && !req.EndAt.After(*req.StartAt) {
+		return nil, errors.New("过期时间必须晚于生效时间")
+	}
+	if req.EndAt != nil && !req.EndAt.After(time.Now()) {
+		return nil, errors.New("过期时间必须晚于当前时间")
+	}
+
+	batch := &model.RedeemBatch{
+		Name:      req.Name,
+		Type:      req.Type,
+		Rewards:   req.Rewards,
+		StartAt:   req.StartAt,
+		EndAt:     req.EndAt,
+		Status:    model.RedeemBatchActive,
+		Remark:    req.Remark,
+		CreatedBy: operatorID,
+	}
+
+	var codes []model.RedeemCode
+	switch req.Type {
+	case model.RedeemTypeSingle:
+		maxBatchSize := config.Cfg.Redeem.MaxBatchSize
+		if maxBatchSize <= 0 {
+			maxBatchSize = defaultRedeemMaxBatchSize
+		}
+		if req.Count < 1 || req.Count > maxBatchSize {
+			return nil, fmt.Errorf("生成数量必须在1到%d之间", maxBatchSize)
+		}
+		batch.MaxUses = 1
+		batch.PerUserLimit = req.PerUserLimit
+		if batch.PerUserLimit == 0 {
+			batch.PerUserLimit = 1
+		}
+		generated, err := generateRedeemCodes(req.Count)
+		if err != nil {
+			util.LogError("生成兑换码失败: err=%v", err)
+			return nil, errors.New("生成兑换码失败")
+		}
+		for _, code := range generated {
+			codes = append(codes, model.RedeemCode{Code: code, MaxUses: 1})
+		}
+	case model.RedeemTypeMulti:
+		code := normalizeRedeemCode(req.Code)
+		if code == "" {
+			generated, err := generateRedeemCodes(1)
+			if err != nil {
+				util.LogError("生成兑换码失败: err=%v", err)
+				return nil, errors.New("生成兑换码失败")
+			}
+			code = generated[0]
+		} else if !redeemCodePattern.MatchString(code) {
+			return nil, errors.New("兑换码只能包含字母和数字，长度4到32位")
+		}
+		existing, err := s.redeemDAO.FindCode(code)
+		if err != nil {
+			return nil, errors.New("创建兑换码失败")
+		}
+		if existing != nil {
+			return nil, errors.New("兑换码已存在")
+		}
+		batch.MaxUses = req.MaxUses
+		batch.PerUserLimit = 1
+		codes = append(codes, model.RedeemCode{Code: code, MaxUses: req.MaxUses})
+	}
+	batch.CodeCount = len(codes)
+
+	err := dao.Transaction(func(tx *gorm.DB) error {
+		return s.redeemDAO.CreateBatch(tx, batch, codes)
+	})
+	if err != nil {
+		util.LogError("创建兑换码批次失败: name=%s err=%v", req.Name, err)
+		return nil, errors.New("创建兑换码失败，请重试")
+	}
+
+	util.Info("创建兑换码批次: batch_id=%d name=%s type=%s codes=%d operator_id=%d", batch.ID, batch.Name, batch.Type, batch.CodeCount, operatorID)
+	return batch, nil
+}
+
+// ListBatches 分页查询兑换码批次
+func (s *RedeemService) ListBatches(req *RedeemBatchQuery) (*util.PageResult, error) {
+	req.Normalize()
+	batches, total, err := s.redeemDAO.ListBatches(req.Name, req.Type, req.Status, req.Offset(), req.PageSize)
+	if err != nil {
+		return nil, errors.New("查询兑换码批次失败")
+	}
+	return util.NewPageResult(batches, total, &req.PageQuery), nil
+}
+
+// GetBatch 获取批次详情及兑换统计
+func (s *RedeemService) GetBatch(id uint) (*RedeemBatchDetail, error) {
+	batch, err := s.redeemDAO.GetBatch(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("兑换码批次不存在")
+		}
+		return nil, errors.New("查询兑换码批次失败")
+	}
+	stats, err := s.redeemDAO.GetBatchStats(id)
+	if err != nil {
+		return nil, errors.New("查询兑换统计失败")
+	}
+	return &RedeemBatchDetail{
+		RedeemBatch: *batch,
+		UsedCodes:   stats.UsedCodes,
+		Users:       stats.Users,
+	}, nil
+}
+
+// UpdateBatchStatus 启用或停用批次，停用后该批次所有码都不能兑换
+func (s *RedeemService) UpdateBatchStatus(operatorID, id uint, req *UpdateRedeemBatchStatusRequest) error {
+	batch, err := s.redeemDAO.GetBatch(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("兑换码批次不存在")
+		}
+		return errors.New("查询兑换码批次失败")
+	}
+	status := *req.Status
+	if batch.Status == status {
+		return nil
+	}
+	if err := s.redeemDAO.UpdateBatchStatus(id, status); err != nil {
+		return errors.New("修改状态失败")
+	}
+	util.Info("修改兑换码批次状态: batch_id=%d status=%d->%d operator_id=%d", id, batch.Status, status, operatorID)
+	return nil
+}
+
+// ListCodes 分页查询批次内的兑换码
+func (s *RedeemService) ListCodes(batchID uint, req *RedeemCodeQuery) (*util.PageResult, error) {
+	req.Normalize()
+	codes, total, err := s.redeemDAO.ListCodes(batchID, req.Used, req.Offset(), req.PageSize)
+	if err != nil {
+		return nil, errors.New("查询兑换码失败")
+	}
+	return util.NewPageResult(codes, total, &req.PageQuery), nil
+}
+
+// ListRecords 分页查询兑换记录
+func (s *RedeemService) ListRecords(req *RedeemRecordQuery) (*util.PageResult, error) {
+	req.Normalize()
+	records, total, err := s.redeemDAO.ListRecords(&dao.RedeemRecordFilter{
+		BatchID:   req.BatchID,
+		UserID:    req.UserID,
+		Code:      normalizeRedeemCode(req.Code),
+		StartTime: req.StartTime,
+		EndTime:   req.EndTime,
+	}, req.Offset(), req.PageSize)
+	if err != nil {
+		return nil, errors.New("查询兑换记录失败")
+	}
+	return util.NewPageResult(records, total, &req.PageQuery), nil
+}
+
+// Redeem 玩家兑换：校验批次状态、有效期、兑换次数和每人上限，在一个事务内写入兑换记录并发放奖励
+func (s *RedeemService) Redeem(userID uint, req *RedeemRequest) (*RedeemResponse, error) {
+	cfg := config.Cfg.Redeem
+	if cfg.MaxFailures > 0 {
+		failures, err := s.redeemDAO.GetFailures(userID)
+		if err != nil {
+			util.LogError("查询兑换失败次数失败: user_id=%d err=%v", userID, err)
+		} else if failures >= int64(cfg.MaxFailures) {
+			return nil, errors.New("兑换码输入错误次数过多，请稍后再试")
+		}
+	}
+
+	code := normalizeRedeemCode(req.Code)
+	if !redeemCodePattern.MatchString(code) {
+		return nil, s.invalidCode(userID, code)
+	}
+
+	// Redis 快速拒绝：已用完的码、已兑换过的码
+	if exhausted, err := s.redeemDAO.IsExhaustedCached(code); err == nil && exhausted {
+		return nil, errors.New("兑换码已被领完")
+	}
+	redeemCode, err := s.redeemDAO.FindCode(code)
+	if err != nil {
+		util.LogError("查询兑换码失败: code=%s err=%v", code, err)
+		return nil, errors.New("兑换失败")
+	}
+	if redeemCode == nil {
+		return nil, s.invalidCode(userID, code)
+	}
+	if used, err := s.redeemDAO.IsUsedCached(redeemCode.ID, userID); err == nil && used {
+		return nil, errRedeemUsed
+	}
+
+	var (
+		batch     *model.RedeemBatch
+		record    *model.RedeemRecord
+		result    *RewardResult
+		exhausted bool
+	)
+	err = dao.Transaction(func(tx *gorm.DB) error {
+		// 先锁用户再锁兑换码：同一用户对同一批次不同兑换码的并发兑换也要串行，每人兑换次数才不会超限
+		// 发放奖励时钱包和背包锁定的也是用户资料行，加锁顺序一致
+		if err := s.redeemDAO.LockUser(tx, userID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("用户不存在")
+			}
+			return redeemFailed(userID, code, err)
+		}
+		locked, err := s.redeemDAO.LockCode(tx, redeemCode.ID)
+		if err != nil {
+			return redeemFailed(userID, code, err)
+		}
+		batch, err = s.redeemDAO.GetBatchTx(tx, locked.BatchID)
+		if err != nil {
+			return redeemFailed(userID, code, err)
+		}
+
+		now := time.Now()
+		if batch.Status != model.RedeemBatchActive {
+			return errors.New("兑换码已停用")
+		}
+		if batch.StartAt != nil && now.Before(*batch.StartAt) {
+			return errors.New("兑换码尚未生效")
+		}
+		if batch.EndAt != nil && !now.Before(*batch.EndAt) {
+			return errors.New("兑换码已过期")
+		}
+		if locked.MaxUses > 0 && locked.UsedCount >= locked.MaxUses {
+			exhausted = true
+			return errors.New("兑换码已被领完")
+		}
+
+		redeemed, err := s.redeemDAO.HasRedeemed(tx, locked.ID, userID)
+		if err != nil {
+			return redeemFailed(userID, code, err)
+		}
+		if redeemed {
+			return errRedeemUsed
+		}
+		if batch.PerUserLimit > 0 {
+			count, err := s.redeemDAO.CountUserRedeems(tx, batch.ID, userID)
+			if err != nil {
+				return redeemFailed(userID, code, err)
+			}
+			if count >= int64(batch.PerUserLimit) {
+				return errors.New("您已达到该活动的兑换次数上限")
+			}
+		}
+
+		record = &model.RedeemRecord{
+			BatchID:  batch.ID,
+			CodeID:   locked.ID,
+			Code:     locked.Code,
+			UserID:   userID,
+			Rewards:  batch.Rewards,
+			ClientIP: req.ClientIP,
+		}
+		if err := s.redeemDAO.CreateRecord(tx, record); err != nil {
+			return redeemFailed(userID, code, err)
+		}
+		result, err = s.rewardService.GrantTx(tx, &RewardGrant{
+			UserID:      userID,
+			Bundle:      batch.Rewards,
+			Source:      model.ExperienceSourceSystem,
+			ReasonCode:  model.WalletReasonRedeem,
+			ReferenceID: "redeem:" + strconv.FormatUint(uint64(record.ID), 10),
+			Remark:      "兑换码 " + locked.Code,
+		})
+		if err != nil {
+			return err
+		}
+		exhausted = locked.MaxUses > 0 && locked.UsedCount+1 >= locked.MaxUses
+		return nil
+	})
+
+	cacheTTL := redeemCacheTTL(batch)
+	if exhausted {
+		if err := s.redeemDAO.MarkExhausted(code, cacheTTL); err != nil {
+			util.LogError("写入兑换码已用完标记失败: code=%s err=%v", code, err)
+		}
+	}
+	if err != nil {
+		if errors.Is(err, errRedeemUsed) {
+			s.redeemDAO.MarkUsed(redeemCode.ID, userID, cacheTTL)
+		}
+		return nil, err
+	}
+
+	if err := s.redeemDAO.MarkUsed(redeemCode.ID, userID, cacheTTL); err != nil {
+		util.LogError("写入已兑换标记失败: code=%s user_id=%d err=%v", code, userID, err)
+	}
+	s.rewardService.AfterGrant(result)
+	util.Info("兑换码兑换: user_id=%d code=%s batch_id=%d record_id=%d", userID, code, batch.ID, record.ID)
+
+	resp := &RedeemResponse{
+		RecordID:  record.ID,
+		Code:      record.Code,
+		BatchName: batch.Name,
+		Rewards:   record.Rewards,
+	}
+	if result.Progress != nil {
+		resp.Progress = &result.Progress.LevelProgressResponse
+	}
+	return resp, nil
+}
+
+// invalidCode 记录无效兑换码尝试，用于限制暴力猜测
+func (s *RedeemService) invalidCode(userID uint, code string) error {
+	cfg := config.Cfg.Redeem
+	if cfg.MaxFailures > 0 && cfg.FailureWindow > 0 {
+		failures, err := s.redeemDAO.IncrFailures(userID, time.Duration(cfg.FailureWindow)*time.Second)
+		if err != nil {
+			util.LogError("记录兑换失败次数失败: user_id=%d err=%v", userID, err)
+		} else if failures >= int64(cfg.MaxFailures) {
+			util.Security("兑换码输入错误次数过多: user_id=%d failures=%d last_code=%s", userID, failures, code)
+		}
+	}
+	return errors.New("兑换码无效")
+}
+
+// redeemFailed 记录数据库错误并返回通用错误
+func redeemFailed(userID uint, code string, err error) error {
+	util.LogError("兑换失败: user_id=%d code=%s err=%v", userID, code, err)
+	return errors.New("兑换失败")
+}
+
+// redeemCacheTTL Redis 标记的缓存时间，不超过批次剩余有效期
+func redeemCacheTTL(batch *model.RedeemBatch) time.Duration {
+	ttl := defaultRedeemCacheTTL
+	if cfg := config.Cfg.Redeem.CacheTTL; cfg > 0 {
+		ttl = time.Duration(cfg) * time.Second
+	}
+	if batch != nil && batch.EndAt != nil {
+		if remaining := time.Until(*batch.EndAt); remaining > time.Minute && remaining < ttl {
+			ttl = remaining
+		}
+	}
+	return ttl
+}
+
+// normalizeRedeemCode 统一兑换码格式：去掉空格和连字符并转为大写
+func normalizeRedeemCode(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	return strings.NewReplacer(" ", "", "-", "").Replace(code)
+}
+
+// generateRedeemCodes 生成 n 个互不重复的随机兑换码
+func generateRedeemCodes(n int) ([]string, error) {
+	length := config.Cfg.Redeem.CodeLength
+	if length < 8 || length > 32 {
+		length = defaultRedeemCodeLength
+	}
+	seen := make(map[string]struct{}, n)
+	codes := make([]string, 0, n)
+	for len(codes) < n {
+		code, err := util.RandomCode(length)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := seen[code]; ok {
+			continue
+		}
+		seen[code] = struct{}{}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"bgame/internal/config"
+	"bgame/internal/dao"
+	"bgame/internal/model"
+	"gorm.io/gorm"
+)
+
+// RewardService 发放奖励包：货币通过钱包流水入账，经验通过等级系统发放，道具放入背包
+type RewardService struct {
+	walletService      *WalletService
+	progressionService *ProgressionService
+	inventoryService   *InventoryService
+	itemDAO            *dao.ItemDAO
+}
+
+func NewRewardService() *RewardService {
+	return &RewardService{
+		walletService:      NewWalletService(),
+		progressionService: NewProgressionService(),
+		inventoryService:   NewInventoryService(),
+		itemDAO:            dao.NewItemDAO(),
+	}
+}
+
+// RewardGrant 一次奖励包发放
+type RewardGrant struct {
+	UserID      uint
+	Bundle      model.RewardBundle
+	Source      string // 经验和道具来源
+	ReasonCode  string // 钱包、经验和道具流水的原因码
+	ReferenceID string // 关联业务ID，同一原因码下不可重复
+	OperatorID  uint
+	Operator    string
+	Remark      string
+}
+
+// RewardResult 奖励包发放结果，事务提交后需调用 RewardService.AfterGrant
+type RewardResult struct {
+	Progress   *GrantExperienceResponse // 发放经验后的等级进度，未发放经验时为 nil
+	experience *ExperienceGrant
+}
+
+// Validate 校验奖励包：至少包含一项奖励，数量不能为负，道具必须存在且已启用
+func (s *RewardService) Validate(bundle *model.RewardBundle) error {
+	if bundle.Experience < 0 || bundle.Balance.IsNegative() || bundle.ActivityBalance.IsNegative() {
+		return errors.New("奖励数量不能为负数")
+	}
+	if maxGrant := config.Cfg.Progression.MaxGrant; maxGrant > 0 && bundle.Experience > maxGrant {
+		return fmt.Errorf("奖励经验不能超过%d", maxGrant)
+	}
+	if bundle.IsEmpty() {
+		return errors.New("奖励不能为空")
+	}
+	if len(bundle.Items) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, 0, len(bundle.Items))
+	seen := make(map[uint]bool, len(bundle.Items))
+	for _, ri := range bundle.Items {
+		if ri.Quantity <= 0 {
+			return errors.New("奖励道具数量必须大于0")
+		}
+		if seen[ri.ItemID] {
+			return fmt.Errorf("奖励道具重复: %d", ri.ItemID)
+		}
+		seen[ri.ItemID] = true
+		ids = append(ids, ri.ItemID)
+	}
+	items, err := s.itemDAO.GetByIDs(ids)
+	if err != nil {
+		return errors.New("查询道具失败")
+	}
+	for _, id := range ids {
+		item, ok := items[id]
+		if !ok {
+			return fmt.Errorf("道具不存在: %d", id)
+		}
+		if item.Status != model.ItemStatusActive {
+			return fmt.Errorf("道具已停用: %s", item.Name)
+		}
+	}
+	return nil
+}
+
+// GrantTx 在调用方事务中发放奖励包
+func (s *RewardService) GrantTx(tx *gorm.DB, grant *RewardGrant) (*RewardResult, error) {
+	result := &RewardResult{}
+	bundle := grant.Bundle
+
+	currencies := []struct {
+		currency model.WalletCurrency
+		amount   model.Money
+	}{
+		{model.CurrencyBalance, bundle.Balance},
+		{model.CurrencyActivityBalance, bundle.ActivityBalance},
+	}
+	for _, c := range currencies {
+		if !c.amount.IsPositive() {
+			continue
+		}
+		if _, err := s.walletService.ChangeTx(tx, &dao.WalletChange{
+			UserID:      grant.UserID,
+			Currency:    c.currency,
+			Direction:   model.WalletCredit,
+			Amount:      c.amount,
+			ReasonCode:  grant.ReasonCode,
+			ReferenceID: grant.ReferenceID,
+			OperatorID:  grant.OperatorID,
+			Remark:      grant.Remark,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, ri := range bundle.Items {
+		if _, err := s.inventoryService.GrantTx(tx, &ItemChange{
+			UserID:      grant.UserID,
+			ItemID:      ri.ItemID,
+			Quantity:    ri.Quantity,
+			Source:      grant.Source,
+			ReasonCode:  grant.ReasonCode,
+			ReferenceID: grant.ReferenceID,
+			OperatorID:  grant.OperatorID,
+			Operator:    grant.Operator,
+			Remark:      grant.Remark,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	if bundle.Experience > 0 {
+		result.experience = &ExperienceGrant{
+			UserID:      grant.UserID,
+			Amount:      bundle.Experience,
+			Source:      grant.Source,
+			ReasonCode:  grant.ReasonCode,
+			ReferenceID: grant.ReferenceID,
+			OperatorID:  grant.OperatorID,
+			Operator:    grant.Operator,
+			Remark:      grant.Remark,
+		}
+		progress, err := s.progressionService.GrantTx(tx, result.experience)
+		if err != nil {
+			return nil, err
+		}
+		result.Progress = progress
+	}
+	return result, nil
+}
+
+// AfterGrant 奖励包发放事务提交后的处理（更新排行榜等）
+func (s *RewardService) AfterGrant(result *RewardResult) {
+	if result == nil || result.experience == nil {
+		return
+	}
+	s.progressionService.AfterGrant(result.experience, result.Progress)
+}
@@ -0,0 +1,300 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"bgame/internal/dao"
+	"bgame/internal/model"
+	"bgame/internal/util"
+)
+
+type RoleService struct {
+	roleDAO *dao.RoleDAO
+}
+
+func NewRoleService() *RoleService {
+	return &RoleService{
+		roleDAO: dao.NewRoleDAO(),
+	}
+}
+
+type CreateRoleRequest struct {
+	Name        string   `json:"name" binding:"required,max=50"`
+	Description string   `json:"description" binding:"max=255"`
+	Permissions []string `json:"permissions"`
+}
+
+type UpdateRoleRequest struct {
+	Name        *string  `json:"name" binding:"omitempty,max=50"`
+	Description *string  `json:"description" binding:"omitempty,max=255"`
+	Permissions []string `json:"permissions"` // 不传时保持不变，传空数组时清空
+}
+
+type RoleOption struct {
+	Value int    `json:"value"`
+	Label string `json:"label"`
+}
+
+// SeedDefaults 初始化三个内置角色及其默认权限，已存在的角色不会被覆盖
+func (s *RoleService) SeedDefaults() error {
+	builtIns := []model.AdminRole{model.RoleSuperAdmin, model.RoleAdmin, model.RoleOperator}
+	for _, r := range builtIns {
+		created, err := s.roleDAO.Seed(&model.Role{
+			ID:          uint(r),
+			Name:        r.String(),
+			BuiltIn:     true,
+			Permissions: model.DefaultRolePermissions[r],
+		})
+		if err != nil {
+			return err
+		}
+		if created {
+			util.Info("初始化内置角色: %s", r.String())
+		}
+	}
+	return nil
+}
+
+// HasPermission 角色是否拥有指定权限
+func (s *RoleService) HasPermission(roleID uint, permission string) (bool, error) {
+	perms, err := s.roleDAO.GetPermissions(roleID)
+	if err != nil {
+		return false, err
+	}
+	return model.HasPermission(perms, permission), nil
+}
+
+// ListOptions 角色下拉列表
+func (s *RoleService) ListOptions() ([]*RoleOption, error) {
+	roles, err := s.roleDAO.List()
+	if err != nil {
+		return nil, errors.New("获取角色列表失败")
+	}
+	options := make([]*RoleOption, 0, len(roles))
+	for _, role := range roles {
+		options = append(options, &RoleOption{Value: int(role.ID), Label: role.Name})
+	}
+	return options, nil
+}
+
+// List 获取全部角色及其权限
+func (s *RoleService) List() ([]*model.Role, error) {
+	roles, err := s.roleDAO.List()
+	if err != nil {
+		return nil, errors.New("获取角色列表失败")
+	}
+	return roles, nil
+}
+
+// Get 获取角色详情
+func (s *RoleService) Get(id uint) (*model.Role, error) {
+	role, err := s.roleDAO.GetByID(id)
+	if err != nil {
+		return nil, errors.New("角色不存在")
+	}
+	return role, nil
+}
+
+// Exists 角色是否存在
+func (s *RoleService) Exists(id uint) bool {
+	_, err := s.roleDAO.GetByID(id)
+	return err == nil
+}
+
+// Create 创建自定义角色，只能包含操作者自己拥有的权限
+func (s *RoleService) Create(operatorID uint, operatorRole model.AdminRole, req *CreateRoleRequest) (*model.Role, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("角色名称不能为空")
+	}
+	if _, err := s.roleDAO.GetByName(name); err == nil {
+		return nil, errors.New("角色名称已存在")
+	}
+
+	perms, err := normalizePermissions(req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkGrantable(operatorRole, perms); err != nil {
+		return nil, err
+	}
+
+	role := &model.Role{
+		Name:        name,
+		Description: req.Description,
+		Permissions: perms,
+	}
+	if err := s.roleDAO.Create(role); err != nil {
+		return nil, errors.New("创建角色失败")
+	}
+
+	util.Info("创建角色: id=%d name=%s permissions=%v operator_id=%d", role.ID, role.Name, perms, operatorID)
+	return role, nil
+}
+
+// Update 更新角色名称、描述或权限；超级管理员角色的权限不可修改
+// 非超级管理员不能修改权限超出自己的角色，新权限只能是自己拥有的权限
+func (s *RoleService) Update(operatorID uint, operatorRole model.AdminRole, id uint, req *UpdateRoleRequest) (*model.Role, error) {
+	role, err := s.roleDAO.GetByID(id)
+	if err != nil {
+		return nil, errors.New("角色不存在")
+	}
+	if operatorRole != model.RoleSuperAdmin {
+		if p, err := s.firstUngranted(operatorRole, role.Permissions); err != nil {
+			return nil, err
+		} else if p != "" {
+			return nil, errors.New("不能修改权限超出自己的角色")
+		}
+	}
+
+	fields := make(map[string]interface{})
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, errors.New("角色名称不能为空")
+		}
+		if existing, err := s.roleDAO.GetByName(name); err == nil && existing.ID != id {
+			return nil, errors.New("角色名称已存在")
+		}
+		fields["name"] = name
+	}
+	if req.Description != nil {
+		fields["description"] = *req.Description
+	}
+
+	var perms []string
+	if req.Permissions != nil {
+		if role.ID == uint(model.RoleSuperAdmin) {
+			return nil, errors.New("超级管理员角色的权限不可修改")
+		}
+		if perms, err = normalizePermissions(req.Permissions); err != nil {
+			return nil, err
+		}
+		if err := s.checkGrantable(operatorRole, perms); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.roleDAO.Update(id, fields, perms); err != nil {
+		return nil, errors.New("更新角色失败")
+	}
+
+	util.Info("更新角色: id=%d fields=%v permissions=%v operator_id=%d", id, fields, perms, operatorID)
+	return s.roleDAO.GetByID(id)
+}
+
+// Delete 删除自定义角色，内置角色和仍被管理员使用的角色不可删除
+func (s *RoleService) Delete(operatorID, id uint) error {
+	role, err := s.roleDAO.GetByID(id)
+	if err != nil {
+		return errors.New("角色不存在")
+	}
+	if role.BuiltIn {
+		return errors.New("内置角色不可删除")
+	}
+
+	count, err := s.roleDAO.CountAdmins(id)
+	if err != nil {
+		return errors.New("删除角色失败")
+	}
+	if count > 0 {
+		return errors.New("仍有管理员使用该角色，无法删除")
+	}
+
+	if err := s.roleDAO.Delete(id); err != nil {
+		return errors.New("删除角色失败")
+	}
+
+	util.Info("删除角色: id=%d name=%s operator_id=%d", id, role.Name, operatorID)
+	return nil
+}
+
+// CheckAssignable 校验操作者能否把角色分配给管理员：角色的权限必须是操作者自己拥有的
+func (s *RoleService) CheckAssignable(operatorRole model.AdminRole, roleID uint) error {
+	perms, err := s.roleDAO.GetPermissions(roleID)
+	if err != nil {
+		return errors.New("获取角色权限失败")
+	}
+	p, err := s.firstUngranted(operatorRole, perms)
+	if err != nil {
+		return err
+	}
+	if p != "" {
+		return errors.New("不能分配权限超出自己的角色")
+	}
+	return nil
+}
+
+// checkGrantable 校验操作者能否授予这些权限：通配权限只有超级管理员可以授予，其他权限必须是操作者自己拥有的
+func (s *RoleService) checkGrantable(operatorRole model.AdminRole, permissions []string) error {
+	if operatorRole != model.RoleSuperAdmin {
+		for _, p := range permissions {
+			if p == model.PermAll || strings.HasSuffix(p, ":*") {
+				return errors.New("只有超级管理员可以授予通配权限: " + p)
+			}
+		}
+	}
+	p, err := s.firstUngranted(operatorRole, permissions)
+	if err != nil {
+		return err
+	}
+	if p != "" {
+		return errors.New("不能授予自己没有的权限: " + p)
+	}
+	return nil
+}
+
+// CheckManageable 校验操作者能否管理某角色的管理员（重置密码、禁用、删除、修改角色等），
+// 与 CheckAssignable 规则一致：目标角色的权限必须是操作者自己拥有的
+func (s *RoleService) CheckManageable(operatorRole, targetRole model.AdminRole) error {
+	perms, err := s.roleDAO.GetPermissions(uint(targetRole))
+	if err != nil {
+		return errors.New("获取管理员权限失败")
+	}
+	p, err := s.firstUngranted(operatorRole, perms)
+	if err != nil {
+		return err
+	}
+	if p != "" {
+		return errors.New("不能管理权限超出自己角色的管理员")
+	}
+	return nil
+}
+
+// firstUngranted 返回 permissions 中第一个操作者角色未拥有的权限，全部拥有时返回空字符串
+func (s *RoleService) firstUngranted(operatorRole model.AdminRole, permissions []string) (string, error) {
+	granted, err := s.roleDAO.GetPermissions(uint(operatorRole))
+	if err != nil {
+		return "", errors.New("获取操作者权限失败")
+	}
+	return ungrantedPermission(granted, permissions), nil
+}
+
+// ungrantedPermission 返回 permissions 中第一个不被 granted 覆盖的权限，全部覆盖时返回空字符串
+func ungrantedPermission(granted, permissions []string) string {
+	for _, p := range permissions {
+		if !model.HasPermission(granted, p) {
+			return p
+		}
+	}
+	return ""
+}
+
+// normalizePermissions 去重并校验权限标识
+func normalizePermissions(permissions []string) ([]string, error) {
+	result := make([]string, 0, len(permissions))
+	seen := make(map[string]bool)
+	for _, p := range permissions {
+		p = strings.TrimSpace(p)
+		if p == "" || seen[p] {
+			continue
+		}
+		if !model.IsValidPermission(p) {
+			return nil, errors.New("无效的权限: " + p)
+		}
+		seen[p] = true
+		result = append(result, p)
+	}
+	return result, nil
+}
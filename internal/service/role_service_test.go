@@ -0,0 +1,37 @@
+package service
+
+import (
+	"testing"
+
+	"bgame/internal/model"
+)
+
+func TestUngrantedPermission(t *testing.T) {
+	// 自定义角色只有管理员管理权限
+	manager := []string{model.PermAdminView, model.PermAdminManage}
+
+	tests := []struct {
+		name        string
+		granted     []string
+		permissions []string
+		want        string
+	}{
+		// 不能管理权限更高的管理员：内置管理员角色、带余额调整或角色管理权限的自定义角色
+		{"内置管理员", manager, model.DefaultRolePermissions[model.RoleAdmin], model.PermUserView},
+		{"余额调整", manager, []string{model.PermAdminView, model.PermWalletAdjust}, model.PermWalletAdjust},
+		{"角色管理", manager, []string{model.PermRoleManage}, model.PermRoleManage},
+		{"超级管理员", manager, []string{model.PermAll}, model.PermAll},
+		{"资源通配", []string{model.PermWalletView, model.PermWalletAdjust}, []string{"wallet:*"}, "wallet:*"},
+
+		{"同等权限", manager, manager, ""},
+		{"权限更少", manager, []string{model.PermAdminView}, ""},
+		{"无权限", manager, nil, ""},
+		{"操作者拥有全部权限", []string{model.PermAll}, model.DefaultRolePermissions[model.RoleAdmin], ""},
+		{"操作者拥有资源通配", []string{"wallet:*"}, []string{model.PermWalletAdjust}, ""},
+	}
+	for _, tt := range tests {
+		if got := ungrantedPermission(tt.granted, tt.permissions); got != tt.want {
+			t.Errorf("%s: ungrantedPermission = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
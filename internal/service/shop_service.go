@@ -0,0 +1,485 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"bgame/internal/dao"
+	"bgame/internal/model"
+	"bgame/internal/util"
+	"gorm.io/gorm"
+)
+
+// ShopService 商城：管理员维护商品，玩家购买时在一个事务内扣款、发放道具并写入购买记录
+type ShopService struct {
+	shopDAO       *dao.ShopDAO
+	walletService *WalletService
+	rewardService *RewardService
+}
+
+func NewShopService() *ShopService {
+	return &ShopService{
+		shopDAO:       dao.NewShopDAO(),
+		walletService: NewWalletService(),
+		rewardService: NewRewardService(),
+	}
+}
+
+// CreateShopSkuRequest 创建商品请求，余额价格和活动余额价格至少设置一个
+type CreateShopSkuRequest struct {
+	Name            string            `json:"name" binding:"required,max=100"`
+	Description     string            `json:"description" binding:"max=500"`
+	Icon            string            `json:"icon" binding:"max=255"`
+	Items           model.RewardItems `json:"items" binding:"required"`                            // 每份包含的道具
+	Price           model.Money       `json:"price" binding:"min=0" swaggertype:"number"`          // 余额价格，0表示不能用余额购买
+	ActivityPrice   model.Money       `json:"activity_price" binding:"min=0" swaggertype:"number"` // 活动余额价格，0表示不能用活动余额购买
+	Discount        int               `json:"discount" binding:"omitempty,min=1,max=100"`          // 折扣百分比，如 80 表示八折，默认100原价
+	DiscountStartAt *time.Time        `json:"discount_start_at"`                                   // 折扣开始时间（RFC3339），为空表示不限
+	DiscountEndAt   *time.Time        `json:"discount_end_at"`                                     // 折扣结束时间（RFC3339），为空表示不限
+	Stock           int               `json:"stock" binding:"min=0"`                               // 总库存，0表示不限
+	UserLimit       int               `json:"user_limit" binding:"min=0"`                          // 每个玩家限购数量，0表示不限
+	SaleStartAt     *time.Time        `json:"sale_start_at"`                                       // 开售时间（RFC3339），为空表示立即开售
+	SaleEndAt       *time.Time        `json:"sale_end_at"`                                         // 停售时间（RFC3339），为空表示不限
+	SortOrder       int               `json:"sort_order"`                                          // 排序，越大越靠前
+}
+
+// UpdateShopSkuRequest 修改商品请求，只修改传入的字段；时间字段传 null 不修改，如需清空请传 clear_* 字段
+type UpdateShopSkuRequest struct {
+	Name            *string            `json:"name" binding:"omitempty,min=1,max=100"`
+	Description     *string            `json:"description" binding:"omitempty,max=500"`
+	Icon            *string            `json:"icon" binding:"omitempty,max=255"`
+	Items           *model.RewardItems `json:"items"`
+	Price           *model.Money       `json:"price" binding:"omitempty,min=0" swaggertype:"number"`
+	ActivityPrice   *model.Money       `json:"activity_price" binding:"omitempty,min=0" swaggertype:"number"`
+	Discount        *int               `json:"discount" binding:"omitempty,min=1,max=100"`
+	DiscountStartAt *time.Time         `json:"discount_start_at"`
+	DiscountEndAt   *time.Time         `json:"discount_end_at"`
+	ClearDiscount   bool               `json:"clear_discount"` // 清空折扣时间段
+	Stock           *int               `json:"stock" binding:"omitempty,min=0"`
+	UserLimit       *int               `json:"user_limit" binding:"omitempty,min=0"`
+	SaleStartAt     *time.Time         `json:"sale_start_at"`
+	SaleEndAt       *time.Time         `json:"sale_end_at"`
+	ClearSaleWindow bool               `json:"clear_sale_window"` // 清空开售和停售时间
+	SortOrder       *int               `json:"sort_order"`
+	Status          *int               `json:"status" binding:"omitempty,oneof=0 1"` // 1:上架 0:下架
+}
+
+type ShopSkuQuery struct {
+	util.PageQuery
+	Keyword string `form:"keyword"` // 按名称模糊匹配
+	Status  *int   `form:"status"`
+}
+
+type ShopPurchaseQuery struct {
+	util.PageQuery
+	SkuID     uint   `form:"sku_id"`
+	ReceiptNo string `form:"receipt_no"`
+}
+
+type AdminShopPurchaseQuery struct {
+	ShopPurchaseQuery
+	UserID    uint                 `form:"user_id"`
+	Currency  model.WalletCurrency `form:"currency"`
+	StartTime time.Time            `form:"start_time" time_format:"2006-01-02 15:04:05"`
+	EndTime   time.Time            `form:"end_time" time_format:"2006-01-02 15:04:05"`
+}
+
+// ShopSkuResponse 玩家看到的商品，附带当前成交价、剩余库存和已购数量
+type ShopSkuResponse struct {
+	model.ShopSku
+	CurrentPrice         model.Money `json:"current_price" swaggertype:"number"`          // 当前余额成交价（已计算折扣），0表示不能用余额购买
+	CurrentActivityPrice model.Money `json:"current_activity_price" swaggertype:"number"` // 当前活动余额成交价
+	Remaining            int         `json:"remaining"`                                   // 剩余库存，-1表示不限
+	Purchased            int         `json:"purchased"`                                   // 当前玩家已购份数
+}
+
+type PurchaseRequest struct {
+	SkuID    uint                 `json:"sku_id" binding:"required"`
+	Currency model.WalletCurrency `json:"currency" binding:"required"`                // balance 或 activity_balance
+	Quantity int                  `json:"quantity" binding:"omitempty,min=1,max=999"` // 购买份数，默认1
+	ClientIP string               `json:"-"`
+}
+
+// PurchaseResponse 购买结果，receipt_no 为收据号
+type PurchaseResponse struct {
+	ReceiptNo    string               `json:"receipt_no"`
+	SkuID        uint                 `json:"sku_id"`
+	SkuName      string               `json:"sku_name"`
+	Quantity     int                  `json:"quantity"`
+	Currency     model.WalletCurrency `json:"currency"`
+	UnitPrice    model.Money          `json:"unit_price" swaggertype:"number"`
+	Amount       model.Money          `json:"amount" swaggertype:"number"`
+	BalanceAfter model.Money          `json:"balance_after" swaggertype:"number"` // 扣款后该币种余额
+	Items        model.RewardItems    `json:"items"`                              // 发放的道具
+}
+
+// CreateSku 创建商品
+func (s *ShopService) CreateSku(operatorID uint, req *CreateShopSkuRequest) (*model.ShopSku, error) {
+	sku := &model.ShopSku{
+		Name:            req.Name,
+		Description:     req.Description,
+		Icon:            req.Icon,
+		Items:           req.Items,
+		Price:           req.Price,
+		ActivityPrice:   req.ActivityPrice,
+		Discount:        req.Discount,
+		DiscountStartAt: req.DiscountStartAt,
+		DiscountEndAt:   req.DiscountEndAt,
+		Stock:           req.Stock,
+		UserLimit:       req.UserLimit,
+		SaleStartAt:     req.SaleStartAt,
+		SaleEndAt:       req.SaleEndAt,
+		SortOrder:       req.SortOrder,
+		Status:          model.ShopSkuOnSale,
+		CreatedBy:       operatorID,
+	}
+	if sku.Discount == 0 {
+		sku.Discount = 100
+	}
+	if err := s.validateSku(sku); err != nil {
+		return nil, err
+	}
+
+	if err := s.shopDAO.CreateSku(sku); err != nil {
+		util.LogError("创建商品失败: name=%s err=%v", req.Name, err)
+		return nil, errors.New("创建商品失败")
+	}
+
+	util.Info("创建商品: sku_id=%d name=%s operator_id=%d", sku.ID, sku.Name, operatorID)
+	return sku, nil
+}
+
+// UpdateSku 修改商品，修改后的商品整体重新校验
+func (s *ShopService) UpdateSku(operatorID, id uint, req *UpdateShopSkuRequest) (*model.ShopSku, error) {
+	sku, err := s.GetSku(id)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]interface{})
+	if req.Name != nil {
+		sku.Name = *req.Name
+		fields["name"] = sku.Name
+	}
+	if req.Description != nil {
+		sku.Description = *req.Description
+		fields["description"] = sku.Description
+	}
+	if req.Icon != nil {
+		sku.Icon = *req.Icon
+		fields["icon"] = sku.Icon
+	}
+	if req.Items != nil {
+		sku.Items = *req.Items
+		fields["items"] = sku.Items
+	}
+	if req.Price != nil {
+		sku.Price = *req.Price
+		fields["price"] = sku.Price
+	}
+	if req.ActivityPrice != nil {
+		sku.ActivityPrice = *req.ActivityPrice
+		fields["activity_price"] = sku.ActivityPrice
+	}
+	if req.Discount != nil {
+		sku.Discount = *req.Discount
+		fields["discount"] = sku.Discount
+	}
+	if req.ClearDiscount {
+		sku.DiscountStartAt, sku.DiscountEndAt = nil, nil
+		fields["discount_start_at"] = nil
+		fields["discount_end_at"] = nil
+	}
+	if req.DiscountStartAt != nil {
+		sku.DiscountStartAt = req.DiscountStartAt
+		fields["discount_start_at"] = sku.DiscountStartAt
+	}
+	if req.DiscountEndAt != nil {
+		sku.DiscountEndAt = req.DiscountEndAt
+		fields["discount_end_at"] = sku.DiscountEndAt
+	}
+	if req.Stock != nil {
+		if *req.Stock > 0 && *req.Stock < sku.SoldCount {
+			return nil, fmt.Errorf("库存不能小于已售数量%d", sku.SoldCount)
+		}
+		sku.Stock = *req.Stock
+		fields["stock"] = sku.Stock
+	}
+	if req.UserLimit != nil {
+		sku.UserLimit = *req.UserLimit
+		fields["user_limit"] = sku.UserLimit
+	}
+	if req.ClearSaleWindow {
+		sku.SaleStartAt, sku.SaleEndAt = nil, nil
+		fields["sale_start_at"] = nil
+		fields["sale_end_at"] = nil
+	}
+	if req.SaleStartAt != nil {
+		sku.SaleStartAt = req.SaleStartAt
+		fields["sale_start_at"] = sku.SaleStartAt
+	}
+	if req.SaleEndAt != nil {
+		sku.SaleEndAt = req.SaleEndAt
+		fields["sale_end_at"] = sku.SaleEndAt
+	}
+	if req.SortOrder != nil {
+		sku.SortOrder = *req.SortOrder
+		fields["sort_order"] = sku.SortOrder
+	}
+	if req.Status != nil {
+		sku.Status = *req.Status
+		fields["status"] = sku.Status
+	}
+	if len(fields) == 0 {
+		return nil, errors.New("没有需要修改的字段")
+	}
+	if err := s.validateSku(sku); err != nil {
+		return nil, err
+	}
+
+	if err := s.shopDAO.UpdateSkuFields(id, fields); err != nil {
+		util.LogError("修改商品失败: sku_id=%d err=%v", id, err)
+		return nil, errors.New("修改商品失败")
+	}
+
+	util.Info("修改商品: sku_id=%d fields=%v operator_id=%d", id, fields, operatorID)
+	return s.GetSku(id)
+}
+
+// GetSku 获取商品
+func (s *ShopService) GetSku(id uint) (*model.ShopSku, error) {
+	sku, err := s.shopDAO.GetSku(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("商品不存在")
+		}
+		return nil, errors.New("查询商品失败")
+	}
+	return sku, nil
+}
+
+// ListSkus 分页查询全部商品（管理端）
+func (s *ShopService) ListSkus(req *ShopSkuQuery) (*util.PageResult, error) {
+	req.Normalize()
+	skus, total, err := s.shopDAO.ListSkus(&dao.ShopSkuFilter{
+		Keyword: req.Keyword,
+		Status:  req.Status,
+	}, req.Offset(), req.PageSize)
+	if err != nil {
+		return nil, errors.New("查询商品失败")
+	}
+	return util.NewPageResult(skus, total, &req.PageQuery), nil
+}
+
+// ListOnSale 分页查询玩家可见的在售商品
+func (s *ShopService) ListOnSale(userID uint, req *util.PageQuery) (*util.PageResult, error) {
+	req.Normalize()
+	now := time.Now()
+	skus, total, err := s.shopDAO.ListOnSale(now, req.Offset(), req.PageSize)
+	if err != nil {
+		return nil, errors.New("查询商品失败")
+	}
+
+	ids := make([]uint, 0, len(skus))
+	for _, sku := range skus {
+		ids = append(ids, sku.ID)
+	}
+	purchased, err := s.shopDAO.SumUserQuantities(userID, ids)
+	if err != nil {
+		return nil, errors.New("查询商品失败")
+	}
+
+	list := make([]ShopSkuResponse, 0, len(skus))
+	for i := range skus {
+		sku := &skus[i]
+		list = append(list, ShopSkuResponse{
+			ShopSku:              *sku,
+			CurrentPrice:         sku.UnitPrice(model.CurrencyBalance, now),
+			CurrentActivityPrice: sku.UnitPrice(model.CurrencyActivityBalance, now),
+			Remaining:            sku.Remaining(),
+			Purchased:            purchased[sku.ID],
+		})
+	}
+	return util.NewPageResult(list, total, req), nil
+}
+
+// Purchase 玩家购买商品：锁定商品行，校验上架状态、销售时间、库存和限购，在一个事务内扣款、发放道具并写入购买记录
+func (s *ShopService) Purchase(userID uint, req *PurchaseRequest) (*PurchaseResponse, error) {
+	if !req.Currency.Valid() {
+		return nil, errors.New("无效的币种")
+	}
+	quantity := req.Quantity
+	if quantity == 0 {
+		quantity = 1
+	}
+
+	var (
+		purchase *model.ShopPurchase
+		entry    *model.WalletLedgerEntry
+		result   *RewardResult
+	)
+	err := dao.Transaction(func(tx *gorm.DB) error {
+		sku, err := s.shopDAO.LockSku(tx, req.SkuID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("商品不存在")
+			}
+			return purchaseFailed(userID, req.SkuID, err)
+		}
+
+		now := time.Now()
+		if !sku.OnSale(now) {
+			return errors.New("商品未在售")
+		}
+		unitPrice := sku.UnitPrice(req.Currency, now)
+		if !unitPrice.IsPositive() {
+			return errors.New("该商品不支持使用此货币购买")
+		}
+		if remaining := sku.Remaining(); remaining == 0 {
+			return errors.New("商品已售罄")
+		} else if remaining > 0 && quantity > remaining {
+			return fmt.Errorf("库存不足，剩余%d件", remaining)
+		}
+		if sku.UserLimit > 0 {
+			bought, err := s.shopDAO.SumUserQuantity(tx, sku.ID, userID)
+			if err != nil {
+				return purchaseFailed(userID, sku.ID, err)
+			}
+			if bought+quantity > sku.UserLimit {
+				return fmt.Errorf("该商品每人限购%d件，您已购买%d件", sku.UserLimit, bought)
+			}
+		}
+
+		amount, err := unitPrice.Mul(int64(quantity))
+		if err != nil || !amount.InRange() {
+			return errors.New("购买金额超出范围")
+		}
+
+		items := make(model.RewardItems, 0, len(sku.Items))
+		for _, item := range sku.Items {
+			items = append(items, model.RewardItem{ItemID: item.ItemID, Quantity: item.Quantity * quantity})
+		}
+		purchase = &model.ShopPurchase{
+			ReceiptNo: util.NewSerialNo("S"),
+			UserID:    userID,
+			SkuID:     sku.ID,
+			SkuName:   sku.Name,
+			Quantity:  quantity,
+			Currency:  req.Currency,
+			UnitPrice: unitPrice,
+			Amount:    amount,
+			Items:     items,
+			ClientIP:  req.ClientIP,
+		}
+		if err := s.shopDAO.CreatePurchase(tx, purchase); err != nil {
+			return purchaseFailed(userID, sku.ID, err)
+		}
+
+		referenceID := "shop:" + purchase.ReceiptNo
+		remark := fmt.Sprintf("购买 %s x%d", sku.Name, quantity)
+		entry, err = s.walletService.ChangeTx(tx, &dao.WalletChange{
+			UserID:      userID,
+			Currency:    req.Currency,
+			Direction:   model.WalletDebit,
+			Amount:      purchase.Amount,
+			ReasonCode:  model.WalletReasonShopPurchase,
+			ReferenceID: referenceID,
+			Remark:      remark,
+		})
+		if err != nil {
+			return err
+		}
+		result, err = s.rewardService.GrantTx(tx, &RewardGrant{
+			UserID:      userID,
+			Bundle:      model.RewardBundle{Items: items},
+			Source:      model.ItemSourceSystem,
+			ReasonCode:  model.WalletReasonShopPurchase,
+			ReferenceID: referenceID,
+			Remark:      remark,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.rewardService.AfterGrant(result)
+	util.Info("商城购买: user_id=%d sku_id=%d quantity=%d currency=%s amount=%s receipt_no=%s",
+		userID, purchase.SkuID, purchase.Quantity, purchase.Currency, purchase.Amount, purchase.ReceiptNo)
+
+	return &PurchaseResponse{
+		ReceiptNo:    purchase.ReceiptNo,
+		SkuID:        purchase.SkuID,
+		SkuName:      purchase.SkuName,
+		Quantity:     purchase.Quantity,
+		Currency:     purchase.Currency,
+		UnitPrice:    purchase.UnitPrice,
+		Amount:       purchase.Amount,
+		BalanceAfter: entry.BalanceAfter,
+		Items:        purchase.Items,
+	}, nil
+}
+
+// ListUserPurchases 查询玩家自己的购买记录
+func (s *ShopService) ListUserPurchases(userID uint, req *ShopPurchaseQuery) (*util.PageResult, error) {
+	req.Normalize()
+	purchases, total, err := s.shopDAO.ListPurchases(&dao.ShopPurchaseFilter{
+		UserID:    userID,
+		SkuID:     req.SkuID,
+		ReceiptNo: req.ReceiptNo,
+	}, req.Offset(), req.PageSize)
+	if err != nil {
+		return nil, errors.New("查询购买记录失败")
+	}
+	return util.NewPageResult(purchases, total, &req.PageQuery), nil
+}
+
+// ListPurchases 分页查询全部购买记录（管理端）
+func (s *ShopService) ListPurchases(req *AdminShopPurchaseQuery) (*util.PageResult, error) {
+	req.Normalize()
+	purchases, total, err := s.shopDAO.ListPurchases(&dao.ShopPurchaseFilter{
+		UserID:    req.UserID,
+		SkuID:     req.SkuID,
+		ReceiptNo: req.ReceiptNo,
+		Currency:  req.Currency,
+		StartTime: req.StartTime,
+		EndTime:   req.EndTime,
+	}, req.Offset(), req.PageSize)
+	if err != nil {
+		return nil, errors.New("查询购买记录失败")
+	}
+	return util.NewPageResult(purchases, total, &req.PageQuery), nil
+}
+
+// validateSku 校验商品的道具、价格、折扣和时间段
+func (s *ShopService) validateSku(sku *model.ShopSku) error {
+	if len(sku.Items) == 0 {
+		return errors.New("商品至少包含一种道具")
+	}
+	if err := s.rewardService.Validate(&model.RewardBundle{Items: sku.Items}); err != nil {
+		return err
+	}
+	if sku.Price.IsNegative() || sku.ActivityPrice.IsNegative() {
+		return errors.New("价格不能为负数")
+	}
+	if !sku.Price.IsPositive() && !sku.ActivityPrice.IsPositive() {
+		return errors.New("余额价格和活动余额价格至少设置一个")
+	}
+	if sku.Discount < 1 || sku.Discount > 100 {
+		return errors.New("折扣必须在1到100之间")
+	}
+	if sku.DiscountStartAt != nil && sku.DiscountEndAt != nil && !sku.DiscountEndAt.After(*sku.DiscountStartAt) {
+		return errors.New("折扣结束时间必须晚于开始时间")
+	}
+	if sku.SaleStartAt != nil && sku.SaleEndAt != nil && !sku.SaleEndAt.After(*sku.SaleStartAt) {
+		return errors.New("停售时间必须晚于开售时间")
+	}
+	return nil
+}
+
+// purchaseFailed 记录数据库错误并返回通用错误
+func purchaseFailed(userID, skuID uint, err error) error {
+	util.LogError("商城购买失败: user_id=%d sku_id=%d err=%v", userID, skuID, err)
+	return errors.New("购买失败，请重试")
+}
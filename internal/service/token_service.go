@@ -0,0 +1,242 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"bgame/internal/config"
+	"bgame/internal/dao"
+	"bgame/internal/util"
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	tokenTypeUser  = "user"
+	tokenTypeAdmin = "admin"
+
+	defaultDeviceID = "default"
+)
+
+type TokenService struct {
+	refreshTokenDAO    *dao.RefreshTokenDAO
+	tokenRevocationDAO *dao.TokenRevocationDAO
+	userDAO            *dao.UserDAO
+	adminDAO           *dao.AdminDAO
+}
+
+func NewTokenService() *TokenService {
+	return &TokenService{
+		refreshTokenDAO:    dao.NewRefreshTokenDAO(),
+		tokenRevocationDAO: dao.NewTokenRevocationDAO(),
+		userDAO:            dao.NewUserDAO(),
+		adminDAO:           dao.NewAdminDAO(),
+	}
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"` // 可选，传入时同时吊销该设备的 refresh token
+}
+
+type TokenPair struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"` // access token 有效期（秒）
+}
+
+// IssueUserTokens 为用户签发 access token 和新的 refresh token 家族
+func (s *TokenService) IssueUserTokens(userID uint, username, deviceID string) (*TokenPair, error) {
+	token, err := util.GenerateUserToken(userID, username)
+	if err != nil {
+		return nil, errors.New("生成token失败")
+	}
+	return s.issue(tokenTypeUser, userID, deviceID, token, config.Cfg.JWT.UserExpire)
+}
+
+// IssueAdminTokens 为管理员签发 access token 和新的 refresh token 家族
+func (s *TokenService) IssueAdminTokens(adminID uint, username string, role int, deviceID string) (*TokenPair, error) {
+	token, err := util.GenerateAdminToken(adminID, username, role)
+	if err != nil {
+		return nil, errors.New("生成token失败")
+	}
+	return s.issue(tokenTypeAdmin, adminID, deviceID, token, config.Cfg.JWT.AdminExpire)
+}
+
+// RefreshUser 使用用户 refresh token 换取新的 token 对
+func (s *TokenService) RefreshUser(refreshToken string) (*TokenPair, error) {
+	record, err := s.rotate(refreshToken, tokenTypeUser)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userDAO.GetByID(record.SubjectID)
+	if err != nil {
+		s.refreshTokenDAO.RevokeFamily(record.FamilyID)
+		return nil, errors.New("用户不存在或已被禁用")
+	}
+
+	token, err := util.GenerateUserToken(user.ID, user.Username)
+	if err != nil {
+		return nil, errors.New("生成token失败")
+	}
+	return s.save(record, token, config.Cfg.JWT.UserExpire)
+}
+
+// RefreshAdmin 使用管理员 refresh token 换取新的 token 对
+func (s *TokenService) RefreshAdmin(refreshToken string) (*TokenPair, error) {
+	record, err := s.rotate(refreshToken, tokenTypeAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	admin, err := s.adminDAO.GetByID(record.SubjectID)
+	if err != nil {
+		s.refreshTokenDAO.RevokeFamily(record.FamilyID)
+		return nil, errors.New("管理员不存在或已被禁用")
+	}
+
+	token, err := util.GenerateAdminToken(admin.ID, admin.Username, int(admin.Role))
+	if err != nil {
+		return nil, errors.New("生成token失败")
+	}
+	return s.save(record, token, config.Cfg.JWT.AdminExpire)
+}
+
+// Logout 吊销当前 access token，并可选吊销对应设备的 refresh token 家族
+func (s *TokenService) Logout(claims *util.Claims, refreshToken string) error {
+	if claims.ID != "" && claims.ExpiresAt != nil {
+		if ttl := time.Until(claims.ExpiresAt.Time); ttl > 0 {
+			if err := s.tokenRevocationDAO.Deny(claims.ID, ttl); err != nil {
+				return errors.New("退出登录失败")
+			}
+		}
+	}
+
+	if refreshToken == "" {
+		return nil
+	}
+	record, err := s.refreshTokenDAO.Get(util.HashToken(refreshToken))
+	if err != nil {
+		return nil
+	}
+	if record.Type != claims.Type || record.SubjectID != claims.UserID {
+		return nil
+	}
+	if err := s.refreshTokenDAO.RevokeFamily(record.FamilyID); err != nil {
+		return errors.New("退出登录失败")
+	}
+	return nil
+}
+
+// RevokeUserSessions 吊销用户的全部会话（access token 与 refresh token）
+func (s *TokenService) RevokeUserSessions(userID uint) error {
+	return s.revokeAll(tokenTypeUser, userID, config.Cfg.JWT.UserExpire)
+}
+
+// RevokeAdminSessions 吊销管理员的全部会话（access token 与 refresh token）
+func (s *TokenService) RevokeAdminSessions(adminID uint) error {
+	return s.revokeAll(tokenTypeAdmin, adminID, config.Cfg.JWT.AdminExpire)
+}
+
+// IsRevoked 检查 access token 是否已被吊销，Redis 不可用时按 jwt.revocation_fail_mode 放行或拒绝
+func (s *TokenService) IsRevoked(claims *util.Claims) bool {
+	var issuedAt time.Time
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Time
+	}
+	revoked, err := s.tokenRevocationDAO.IsRevoked(claims.ID, claims.Type, claims.UserID, issuedAt)
+	if err != nil {
+		util.LogError("检查token吊销状态失败: %v", err)
+		return config.Cfg.JWT.RevocationFailMode == "closed"
+	}
+	return revoked
+}
+
+// revokeAll 使当前时刻之前签发的 access token 失效，并删除全部 refresh token 家族
+func (s *TokenService) revokeAll(subjectType string, subjectID uint, accessExpire int) error {
+	ttl := time.Duration(accessExpire) * time.Second
+	if err := s.tokenRevocationDAO.SetRevokeBefore(subjectType, subjectID, time.Now(), ttl); err != nil {
+		return errors.New("吊销会话失败")
+	}
+	if err := s.refreshTokenDAO.RevokeSubject(subjectType, subjectID); err != nil {
+		return errors.New("吊销会话失败")
+	}
+	util.Info("已吊销全部会话: type=%s subject_id=%d", subjectType, subjectID)
+	return nil
+}
+
+// issue 创建新的 token 家族，同一设备上旧的家族会被吊销
+func (s *TokenService) issue(subjectType string, subjectID uint, deviceID, accessToken string, expiresIn int) (*TokenPair, error) {
+	if deviceID == "" {
+		deviceID = defaultDeviceID
+	}
+
+	if oldFamily, err := s.refreshTokenDAO.GetDeviceFamily(subjectType, subjectID, deviceID); err == nil {
+		s.refreshTokenDAO.RevokeFamily(oldFamily)
+	}
+
+	familyID, err := util.RandomToken(16)
+	if err != nil {
+		return nil, errors.New("生成refresh token失败")
+	}
+	return s.save(&dao.RefreshTokenRecord{
+		Type:      subjectType,
+		SubjectID: subjectID,
+		DeviceID:  deviceID,
+		FamilyID:  familyID,
+	}, accessToken, expiresIn)
+}
+
+// rotate 校验 refresh token 并标记为已轮换；旧 token 被重放时吊销整个家族
+func (s *TokenService) rotate(refreshToken, subjectType string) (*dao.RefreshTokenRecord, error) {
+	tokenHash := util.HashToken(refreshToken)
+	record, err := s.refreshTokenDAO.Get(tokenHash)
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, errors.New("无效的refresh token")
+		}
+		return nil, errors.New("校验refresh token失败")
+	}
+
+	if record.Type != subjectType {
+		return nil, errors.New("refresh token类型错误")
+	}
+
+	fresh, err := s.refreshTokenDAO.MarkRotated(tokenHash, config.Cfg.GetRefreshExpire())
+	if err != nil {
+		return nil, errors.New("校验refresh token失败")
+	}
+	if !fresh {
+		util.Warn("检测到refresh token重放，吊销token家族: type=%s subject_id=%d device_id=%s",
+			record.Type, record.SubjectID, record.DeviceID)
+		s.refreshTokenDAO.RevokeFamily(record.FamilyID)
+		return nil, errors.New("refresh token已失效，请重新登录")
+	}
+
+	return record, nil
+}
+
+// save 为家族生成并保存新的 refresh token
+func (s *TokenService) save(record *dao.RefreshTokenRecord, accessToken string, expiresIn int) (*TokenPair, error) {
+	refreshToken, err := util.GenerateRefreshToken()
+	if err != nil {
+		return nil, errors.New("生成refresh token失败")
+	}
+
+	ttl := config.Cfg.GetRefreshExpire()
+	if err := s.refreshTokenDAO.Save(util.HashToken(refreshToken), record, ttl); err != nil {
+		return nil, errors.New("保存refresh token失败")
+	}
+	if err := s.refreshTokenDAO.SetDeviceFamily(record.Type, record.SubjectID, record.DeviceID, record.FamilyID, ttl); err != nil {
+		return nil, errors.New("保存refresh token失败")
+	}
+
+	return &TokenPair{
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    expiresIn,
+	}, nil
+}
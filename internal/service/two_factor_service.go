@@ -0,0 +1,419 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"bgame/internal/config"
+	"bgame/internal/dao"
+	"bgame/internal/model"
+	"bgame/internal/util"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultTwoFactorIssuer       = "BGame Admin"
+	defaultTwoFactorChallengeTTL = 300
+	defaultTwoFactorMaxAttempts  = 5
+	defaultRecoveryCodeCount     = 10
+
+	totpPeriodSeconds = 30
+)
+
+// TwoFactorService 管理员 TOTP 两步验证：绑定、校验、恢复码和登录挑战
+type TwoFactorService struct {
+	twoFactorDAO *dao.TwoFactorDAO
+	adminDAO     *dao.AdminDAO
+	tokenService *TokenService
+}
+
+func NewTwoFactorService() *TwoFactorService {
+	return &TwoFactorService{
+		twoFactorDAO: dao.NewTwoFactorDAO(),
+		adminDAO:     dao.NewAdminDAO(),
+		tokenService: NewTokenService(),
+	}
+}
+
+type TwoFactorChallengeRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+}
+
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required"` // 动态验证码或恢复码
+}
+
+type TwoFactorSetupResponse struct {
+	Secret     string `json:"secret"`      // base32 密钥，可手动输入认证器 App
+	OTPAuthURI string `json:"otpauth_uri"` // 可生成二维码供认证器 App 扫描
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"` // 6位动态验证码
+}
+
+type TwoFactorDisableRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"` // 动态验证码或恢复码
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"` // 仅展示一次，请妥善保存
+}
+
+type TwoFactorStatusResponse struct {
+	Enabled                bool  `json:"enabled"`
+	Required               bool  `json:"required"` // 当前角色是否强制启用
+	RecoveryCodesRemaining int64 `json:"recovery_codes_remaining"`
+}
+
+// Required 该管理员是否必须启用两步验证
+func (s *TwoFactorService) Required(admin *model.Admin) bool {
+	return config.Cfg.TwoFactor.RequireSuperAdmin && admin.Role == model.RoleSuperAdmin
+}
+
+// Status 获取两步验证状态
+func (s *TwoFactorService) Status(adminID uint) (*TwoFactorStatusResponse, error) {
+	admin, err := s.adminDAO.GetByID(adminID)
+	if err != nil {
+		return nil, errors.New("管理员不存在")
+	}
+
+	enabled, err := s.twoFactorDAO.IsEnabled(adminID)
+	if err != nil {
+		return nil, errors.New("获取两步验证状态失败")
+	}
+
+	resp := &TwoFactorStatusResponse{
+		Enabled:  enabled,
+		Required: s.Required(admin),
+	}
+	if enabled {
+		resp.RecoveryCodesRemaining, _ = s.twoFactorDAO.CountRecoveryCodes(adminID)
+	}
+	return resp, nil
+}
+
+// LoginRequirement 密码验证通过后是否需要两步验证，以及是否需要先绑定认证器
+func (s *TwoFactorService) LoginRequirement(admin *model.Admin) (required bool, setupPending bool, err error) {
+	enabled, err := s.twoFactorDAO.IsEnabled(admin.ID)
+	if err != nil {
+		return false, false, err
+	}
+	if enabled {
+		return true, false, nil
+	}
+	if s.Required(admin) {
+		return true, true, nil
+	}
+	return false, false, nil
+}
+
+// CreateChallenge 创建登录挑战，返回挑战 token 及有效期（秒）
+func (s *TwoFactorService) CreateChallenge(adminID uint, deviceID, clientIP string, setupPending bool) (string, int, error) {
+	token, err := util.RandomToken(32)
+	if err != nil {
+		return "", 0, errors.New("生成登录挑战失败")
+	}
+
+	ttl := twoFactorChallengeTTL()
+	challenge := &dao.TwoFactorChallenge{
+		AdminID:      adminID,
+		DeviceID:     deviceID,
+		ClientIP:     clientIP,
+		SetupPending: setupPending,
+	}
+	if err := s.twoFactorDAO.SaveChallenge(util.HashToken(token), challenge, ttl); err != nil {
+		return "", 0, errors.New("生成登录挑战失败")
+	}
+	return token, int(ttl / time.Second), nil
+}
+
+// SetupChallenge 强制启用两步验证但尚未绑定时，凭登录挑战生成密钥
+func (s *TwoFactorService) SetupChallenge(challengeToken string) (*TwoFactorSetupResponse, error) {
+	challenge, err := s.twoFactorDAO.GetChallenge(util.HashToken(challengeToken))
+	if err != nil {
+		return nil, errors.New("登录验证已过期，请重新登录")
+	}
+	if !challenge.SetupPending {
+		return nil, errors.New("两步验证已启用")
+	}
+	return s.Setup(challenge.AdminID)
+}
+
+// CompleteChallenge 校验登录挑战的验证码，成功后挑战作废；首次绑定时同时启用两步验证并返回恢复码
+// 挑战存在时总是返回挑战信息，便于调用方记录失败
+func (s *TwoFactorService) CompleteChallenge(challengeToken, code string) (*dao.TwoFactorChallenge, *RecoveryCodesResponse, error) {
+	tokenHash := util.HashToken(challengeToken)
+	challenge, err := s.twoFactorDAO.GetChallenge(tokenHash)
+	if err != nil {
+		return nil, nil, errors.New("登录验证已过期，请重新登录")
+	}
+
+	code = strings.TrimSpace(code)
+	enrolling := false
+	var ok bool
+	if challenge.SetupPending {
+		tf, err := s.twoFactorDAO.Get(challenge.AdminID)
+		if err != nil {
+			return challenge, nil, errors.New("请先生成两步验证密钥")
+		}
+		enrolling = !tf.Enabled
+		if enrolling {
+			ok = s.verifyTOTP(tf, code)
+		}
+	}
+	if !enrolling {
+		if ok, err = s.VerifyCode(challenge.AdminID, code); err != nil {
+			return challenge, nil, err
+		}
+	}
+
+	if !ok {
+		attempts, _ := s.twoFactorDAO.IncrChallengeAttempts(tokenHash, twoFactorChallengeTTL())
+		if attempts >= twoFactorMaxAttempts() {
+			s.twoFactorDAO.DeleteChallenge(tokenHash)
+			util.Security("两步验证失败次数过多: admin_id=%d ip=%s", challenge.AdminID, challenge.ClientIP)
+			return challenge, nil, errors.New("验证失败次数过多，请重新登录")
+		}
+		return challenge, nil, errors.New("验证码错误")
+	}
+
+	// 挑战只能完成一次
+	if deleted, err := s.twoFactorDAO.DeleteChallenge(tokenHash); err != nil || !deleted {
+		return nil, nil, errors.New("登录验证已过期，请重新登录")
+	}
+
+	if !enrolling {
+		return challenge, nil, nil
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, nil, errors.New("生成恢复码失败")
+	}
+	if err := s.twoFactorDAO.Enable(challenge.AdminID, hashes); err != nil {
+		return nil, nil, errors.New("启用两步验证失败")
+	}
+	util.Security("启用两步验证: admin_id=%d", challenge.AdminID)
+	return challenge, &RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// Setup 生成新的 TOTP 密钥，需调用 Enable 验证首个验证码后才会生效
+func (s *TwoFactorService) Setup(adminID uint) (*TwoFactorSetupResponse, error) {
+	admin, err := s.adminDAO.GetByID(adminID)
+	if err != nil {
+		return nil, errors.New("管理员不存在")
+	}
+
+	secret, err := util.GenerateTOTPSecret()
+	if err != nil {
+		return nil, errors.New("生成密钥失败")
+	}
+	if err := s.twoFactorDAO.SavePending(adminID, secret); err != nil {
+		if errors.Is(err, dao.ErrTwoFactorEnabled) {
+			return nil, err
+		}
+		return nil, errors.New("保存密钥失败")
+	}
+
+	return &TwoFactorSetupResponse{
+		Secret:     secret,
+		OTPAuthURI: util.TOTPURI(twoFactorIssuer(), admin.Username, secret),
+	}, nil
+}
+
+// Enable 验证首个验证码后启用两步验证，返回恢复码
+func (s *TwoFactorService) Enable(adminID uint, code string) (*RecoveryCodesResponse, error) {
+	tf, err := s.twoFactorDAO.Get(adminID)
+	if err != nil {
+		return nil, errors.New("请先生成两步验证密钥")
+	}
+	if tf.Enabled {
+		return nil, errors.New("两步验证已启用")
+	}
+	if !s.verifyTOTP(tf, code) {
+		return nil, errors.New("验证码错误")
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, errors.New("生成恢复码失败")
+	}
+	if err := s.twoFactorDAO.Enable(adminID, hashes); err != nil {
+		return nil, errors.New("启用两步验证失败")
+	}
+
+	util.Security("启用两步验证: admin_id=%d", adminID)
+	return &RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// Disable 关闭两步验证，需要验证密码和动态验证码（或恢复码）
+func (s *TwoFactorService) Disable(adminID uint, req *TwoFactorDisableRequest) error {
+	admin, err := s.adminDAO.GetByID(adminID)
+	if err != nil {
+		return errors.New("管理员不存在")
+	}
+	if s.Required(admin) {
+		return errors.New("超级管理员必须启用两步验证")
+	}
+
+	// 缓存中不含密码，需从数据库读取
+	full, err := s.adminDAO.GetByUsername(admin.Username)
+	if err != nil || !util.CheckPassword(req.Password, full.Password) {
+		return errors.New("密码错误")
+	}
+
+	ok, err := s.VerifyCode(adminID, req.Code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("验证码错误")
+	}
+
+	if err := s.twoFactorDAO.Disable(adminID); err != nil {
+		return errors.New("关闭两步验证失败")
+	}
+
+	util.Security("关闭两步验证: admin_id=%d", adminID)
+	return nil
+}
+
+// RegenerateRecoveryCodes 验证动态验证码后重新生成恢复码，旧恢复码作废
+func (s *TwoFactorService) RegenerateRecoveryCodes(adminID uint, code string) (*RecoveryCodesResponse, error) {
+	tf, err := s.twoFactorDAO.Get(adminID)
+	if err != nil || !tf.Enabled {
+		return nil, errors.New("未启用两步验证")
+	}
+	if !s.verifyTOTP(tf, code) {
+		return nil, errors.New("验证码错误")
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, errors.New("生成恢复码失败")
+	}
+	if err := s.twoFactorDAO.ReplaceRecoveryCodes(adminID, hashes); err != nil {
+		return nil, errors.New("生成恢复码失败")
+	}
+
+	util.Security("重新生成恢复码: admin_id=%d", adminID)
+	return &RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// Reset 重置其他管理员的两步验证（如丢失设备），并吊销该管理员的全部会话，下次登录时重新绑定；调用前由 AdminService 校验目标管理员
+func (s *TwoFactorService) Reset(operatorID, adminID uint) error {
+	if operatorID == adminID {
+		return errors.New("不能重置自己的两步验证")
+	}
+	if err := s.twoFactorDAO.Disable(adminID); err != nil {
+		return errors.New("重置两步验证失败")
+	}
+	// 设备可能已落入他人之手，已登录的会话同样需要失效；失败时仅记录日志，与重置密码一致
+	if err := s.tokenService.RevokeAdminSessions(adminID); err != nil {
+		util.LogError("吊销管理员会话失败: admin_id=%d err=%v", adminID, err)
+	}
+
+	util.Security("重置两步验证: admin_id=%d operator_id=%d", adminID, operatorID)
+	return nil
+}
+
+// VerifyCode 校验已启用的两步验证：6位数字按 TOTP 校验，其他格式按恢复码核销
+func (s *TwoFactorService) VerifyCode(adminID uint, code string) (bool, error) {
+	tf, err := s.twoFactorDAO.Get(adminID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && !tf.Enabled) {
+		return false, errors.New("未启用两步验证")
+	}
+	if err != nil {
+		return false, errors.New("校验两步验证失败")
+	}
+
+	code = strings.TrimSpace(code)
+	if isTOTPCode(code) {
+		return s.verifyTOTP(tf, code), nil
+	}
+
+	ok, err := s.twoFactorDAO.UseRecoveryCode(adminID, util.HashToken(util.NormalizeRecoveryCode(code)))
+	if err != nil {
+		return false, errors.New("校验两步验证失败")
+	}
+	if ok {
+		util.Security("使用恢复码登录: admin_id=%d", adminID)
+	}
+	return ok, nil
+}
+
+// verifyTOTP 校验动态验证码，同一验证码在有效期内只能使用一次
+func (s *TwoFactorService) verifyTOTP(tf *model.AdminTwoFactor, code string) bool {
+	skew := config.Cfg.TwoFactor.Skew
+	if skew < 0 {
+		skew = 0
+	}
+	step, ok := util.ValidateTOTP(tf.Secret, code, time.Now(), skew)
+	if !ok {
+		return false
+	}
+
+	ttl := time.Duration((2*skew+2)*totpPeriodSeconds) * time.Second
+	fresh, err := s.twoFactorDAO.MarkCodeUsed(tf.AdminID, step, ttl)
+	if err != nil {
+		// Redis 不可用时无法防重放，但不影响正常登录
+		util.LogError("记录TOTP使用状态失败: %v", err)
+		return true
+	}
+	return fresh
+}
+
+// newRecoveryCodes 生成恢复码明文（返回给管理员）及其摘要（保存到数据库）
+func newRecoveryCodes() ([]string, []string, error) {
+	n := config.Cfg.TwoFactor.RecoveryCodes
+	if n <= 0 {
+		n = defaultRecoveryCodeCount
+	}
+	codes, err := util.GenerateRecoveryCodes(n)
+	if err != nil {
+		return nil, nil, err
+	}
+	hashes := make([]string, 0, len(codes))
+	for _, code := range codes {
+		hashes = append(hashes, util.HashToken(util.NormalizeRecoveryCode(code)))
+	}
+	return codes, hashes, nil
+}
+
+func isTOTPCode(code string) bool {
+	if len(code) != 6 {
+		return false
+	}
+	for _, c := range code {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func twoFactorIssuer() string {
+	if config.Cfg.TwoFactor.Issuer == "" {
+		return defaultTwoFactorIssuer
+	}
+	return config.Cfg.TwoFactor.Issuer
+}
+
+func twoFactorChallengeTTL() time.Duration {
+	ttl := config.Cfg.TwoFactor.ChallengeTTL
+	if ttl <= 0 {
+		ttl = defaultTwoFactorChallengeTTL
+	}
+	return time.Duration(ttl) * time.Second
+}
+
+func twoFactorMaxAttempts() int64 {
+	if config.Cfg.TwoFactor.MaxAttempts <= 0 {
+		return defaultTwoFactorMaxAttempts
+	}
+	return int64(config.Cfg.TwoFactor.MaxAttempts)
+}
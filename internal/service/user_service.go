@@ -3,80 +3,222 @@ package service
 import (
 	"errors"
 	"time"
+	"unicode/utf8"
 
+	"bgame/internal/config"
 	"bgame/internal/dao"
 	"bgame/internal/model"
 	"bgame/internal/util"
+	"gorm.io/gorm"
 )
 
 type UserService struct {
 	userDAO        *dao.UserDAO
 	userProfileDAO *dao.UserProfileDAO
+	tokenService   *TokenService
+	loginGuard     *LoginGuardService
 }
 
 func NewUserService() *UserService {
 	return &UserService{
 		userDAO:        dao.NewUserDAO(),
 		userProfileDAO: dao.NewUserProfileDAO(),
+		tokenService:   NewTokenService(),
+		loginGuard:     NewLoginGuardService(),
 	}
 }
 
+type RegisterRequest struct {
+	Username string `json:"username" binding:"required,min=3,max=50"`
+	Password string `json:"password" binding:"required,min=6,max=50"`
+	Email    string `json:"email" binding:"omitempty,email,max=100"`
+	Nickname string `json:"nickname" binding:"omitempty,max=50"`
+	DeviceID string `json:"device_id" binding:"omitempty,max=64"`
+}
+
+type LoginRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+	DeviceID string `json:"device_id" binding:"omitempty,max=64"`
+	ClientIP string `json:"-"`
+}
+
 type RegAndLoginRequest struct {
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
+	DeviceID string `json:"device_id" binding:"omitempty,max=64"`
+	ClientIP string `json:"-"`
 }
 
-type RegAndLoginResponse struct {
-	Token    string      `json:"token"`
-	UserInfo *model.User `json:"user_info"`
+type AdminUserQuery struct {
+	util.PageQuery
+	ID        uint      `form:"id"`
+	Username  string    `form:"username"` // 模糊匹配
+	Email     string    `form:"email"`    // 模糊匹配
+	Nickname  string    `form:"nickname"` // 模糊匹配
+	Status    int       `form:"status"`
+	StartTime time.Time `form:"start_time" time_format:"2006-01-02 15:04:05"` // 注册时间
+	EndTime   time.Time `form:"end_time" time_format:"2006-01-02 15:04:05"`
+}
+
+type UserDetailResponse struct {
+	User        *model.User        `json:"user"`
 	UserProfile *model.UserProfile `json:"user_profile"`
 }
 
-// RegAndLoginRequest 注册和登录
-func (s *UserService) RegAndLogin(req *RegAndLoginRequest) (*RegAndLoginResponse, error) {
+type BanUserRequest struct {
+	Reason    string     `json:"reason" binding:"required,max=255"`
+	ExpiresAt *time.Time `json:"expires_at"` // 封禁到期时间（RFC3339），不传表示永久封禁
+}
+
+type UpdateUserRequest struct {
+	Nickname *string `json:"nickname" binding:"omitempty,min=1,max=50"`
+	Email    *string `json:"email" binding:"omitempty,email,max=100"`
+}
+
+type UserLoginResponse struct {
+	Token        string             `json:"token"`
+	RefreshToken string             `json:"refresh_token"`
+	ExpiresIn    int                `json:"expires_in"` // access token 有效期（秒）
+	IsNew       bool               `json:"is_new"` // 是否为本次新注册的用户
+	UserInfo    *model.User        `json:"user_info"`
+	UserProfile *model.UserProfile `json:"user_profile"`
+}
+
+// Register 用户注册
+func (s *UserService) Register(req *RegisterRequest) (*UserLoginResponse, error) {
 	// 检查用户名是否已存在
 	_, err := s.userDAO.GetByUsername(req.Username)
 	if err == nil {
 		return nil, errors.New("用户名已存在")
 	}
+	return s.register(req)
+}
+
+// Login 用户登录
+func (s *UserService) Login(req *LoginRequest) (*UserLoginResponse, error) {
+	if err := s.loginGuard.Check(loginScopeUser, req.Username, req.ClientIP); err != nil {
+		return nil, err
+	}
+	user, err := s.userDAO.GetByUsername(req.Username)
+	if err != nil {
+		s.loginGuard.RecordFailure(loginScopeUser, req.Username, req.ClientIP)
+		return nil, errors.New("用户名或密码错误")
+	}
+	return s.login(user, req.Password, req.DeviceID, req.ClientIP)
+}
+
+// RegAndLogin 注册和登录合并：已存在的用户直接登录，新用户名在开启自动注册时注册
+func (s *UserService) RegAndLogin(req *RegAndLoginRequest) (*UserLoginResponse, error) {
+	if err := s.loginGuard.Check(loginScopeUser, req.Username, req.ClientIP); err != nil {
+		return nil, err
+	}
+	user, err := s.userDAO.GetByUsername(req.Username)
+	if err == nil {
+		return s.login(user, req.Password, req.DeviceID, req.ClientIP)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("查询用户失败")
+	}
+	if !config.Cfg.User.AutoRegister {
+		s.loginGuard.RecordFailure(loginScopeUser, req.Username, req.ClientIP)
+		return nil, errors.New("用户名或密码错误")
+	}
+	regReq := &RegisterRequest{
+		Username: req.Username,
+		Password: req.Password,
+		DeviceID: req.DeviceID,
+	}
+	if err := validateRegister(regReq); err != nil {
+		return nil, err
+	}
+	return s.register(regReq)
+}
+
+// validateRegister 自动注册时按 RegisterRequest 的 binding 规则校验用户名和密码长度
+func validateRegister(req *RegisterRequest) error {
+	if n := utf8.RuneCountInString(req.Username); n < 3 || n > 50 {
+		return errors.New("用户名长度必须为3-50个字符")
+	}
+	if n := utf8.RuneCountInString(req.Password); n < 6 || n > 50 {
+		return errors.New("密码长度必须为6-50个字符")
+	}
+	return nil
+}
+
+// register 创建用户及用户资料（同一事务）并签发token
+func (s *UserService) register(req *RegisterRequest) (*UserLoginResponse, error) {
 	// 加密密码
 	hashedPassword, err := util.HashPassword(req.Password)
 	if err != nil {
 		return nil, errors.New("密码加密失败")
 	}
-	// 创建用户
+	email := req.Email
+	if email == "" {
+		email = req.Username + "@bgame.com"
+	}
+	nickname := req.Nickname
+	if nickname == "" {
+		nickname = req.Username
+	}
 	user := &model.User{
 		Username: req.Username,
 		Password: hashedPassword,
-		Nickname: req.Username,
-		Email:    req.Username + "@bgame.com",
+		Nickname: nickname,
+		Email:    email,
 		Status:   1,
 	}
-	if err := s.userDAO.Create(user); err != nil {
-		return nil, errors.New("创建用户失败")
-	}
-	// 创建用户资料
 	userProfile := &model.UserProfile{
-		UserID:          user.ID,
 		Balance:         0,
 		ActivityBalance: 0,
 		Level:           1,
 		Experience:      0,
 		RegisterTime:    time.Now(),
 	}
-	if err := s.userProfileDAO.CreateUserProfile(userProfile); err != nil {
-		return nil, errors.New("创建用户资料失败")
+	if err := s.userDAO.CreateWithProfile(user, userProfile); err != nil {
+		return nil, errors.New("创建用户失败")
 	}
-	token, err := util.GenerateUserToken(user.ID, user.Username)
+	resp, err := s.buildLoginResponse(user, userProfile, req.DeviceID)
 	if err != nil {
-		return nil, errors.New("生成token失败")
+		return nil, err
+	}
+	resp.IsNew = true
+	return resp, nil
+}
+
+// login 校验密码和状态后签发token
+func (s *UserService) login(user *model.User, password, deviceID, clientIP string) (*UserLoginResponse, error) {
+	// 先验证密码，避免未持有密码的人通过封禁提示探测账号状态
+	if !util.CheckPassword(password, user.Password) {
+		s.loginGuard.RecordFailure(loginScopeUser, user.Username, clientIP)
+		return nil, errors.New("用户名或密码错误")
 	}
-	userProfile, err = s.userProfileDAO.GetUserProfileByUserID(user.ID)
+	s.loginGuard.RecordSuccess(loginScopeUser, user.Username)
+
+	// 检查用户状态，封禁到期的用户自动解封
+	if user.Status != model.UserStatusNormal {
+		if err := s.checkBan(user); err != nil {
+			return nil, err
+		}
+	}
+
+	userProfile, err := s.userProfileDAO.GetUserProfileByUserID(user.ID)
 	if err != nil {
 		return nil, errors.New("获取用户资料失败")
 	}
-	return &RegAndLoginResponse{
-		Token: token,
+	return s.buildLoginResponse(user, userProfile, deviceID)
+}
+
+// buildLoginResponse 生成token并组装登录响应（清除敏感信息）
+func (s *UserService) buildLoginResponse(user *model.User, userProfile *model.UserProfile, deviceID string) (*UserLoginResponse, error) {
+	tokens, err := s.tokenService.IssueUserTokens(user.ID, user.Username, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	return &UserLoginResponse{
+		Token:        tokens.Token,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
 		UserInfo: &model.User{
 			ID:        user.ID,
 			Username:  user.Username,
@@ -126,3 +268,132 @@ func (s *UserService) CreateUserProfile(userID uint, userProfile *model.UserProf
 func (s *UserService) GetUserProfileByUserID(userID uint) (*model.UserProfile, error) {
 	return s.userProfileDAO.GetUserProfileByUserID(userID)
 }
+
+// SearchUsers 管理员分页查询用户
+func (s *UserService) SearchUsers(req *AdminUserQuery) (*util.PageResult, error) {
+	req.Normalize()
+	users, total, err := s.userDAO.List(&dao.UserFilter{
+		ID:        req.ID,
+		Username:  req.Username,
+		Email:     req.Email,
+		Nickname:  req.Nickname,
+		Status:    req.Status,
+		StartTime: req.StartTime,
+		EndTime:   req.EndTime,
+	}, req.Offset(), req.PageSize)
+	if err != nil {
+		return nil, errors.New("查询用户失败")
+	}
+	return util.NewPageResult(users, total, &req.PageQuery), nil
+}
+
+// GetUserDetail 管理员查看用户及其资料（包括已封禁的用户）
+func (s *UserService) GetUserDetail(userID uint) (*UserDetailResponse, error) {
+	user, err := s.userDAO.FindByID(userID)
+	if err != nil {
+		return nil, errors.New("用户不存在")
+	}
+	userProfile, err := s.userProfileDAO.GetUserProfileByUserID(userID)
+	if err != nil {
+		return nil, errors.New("获取用户资料失败")
+	}
+	return &UserDetailResponse{User: user, UserProfile: userProfile}, nil
+}
+
+// BanUser 封禁用户并吊销其全部会话
+func (s *UserService) BanUser(operatorID, userID uint, req *BanUserRequest) error {
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return errors.New("封禁到期时间必须晚于当前时间")
+	}
+	if _, err := s.userDAO.FindByID(userID); err != nil {
+		return errors.New("用户不存在")
+	}
+
+	if err := s.userDAO.UpdateFields(userID, map[string]interface{}{
+		"status":       model.UserStatusBanned,
+		"ban_reason":   req.Reason,
+		"banned_until": req.ExpiresAt,
+	}); err != nil {
+		return errors.New("封禁用户失败")
+	}
+	if err := s.tokenService.RevokeUserSessions(userID); err != nil {
+		util.LogError("吊销用户会话失败: user_id=%d err=%v", userID, err)
+	}
+
+	util.Info("封禁用户: user_id=%d reason=%s expires_at=%v operator_id=%d", userID, req.Reason, req.ExpiresAt, operatorID)
+	return nil
+}
+
+// UnbanUser 解除用户封禁
+func (s *UserService) UnbanUser(operatorID, userID uint) error {
+	user, err := s.userDAO.FindByID(userID)
+	if err != nil {
+		return errors.New("用户不存在")
+	}
+	if user.Status == model.UserStatusNormal {
+		return nil
+	}
+
+	if err := s.unban(userID); err != nil {
+		return errors.New("解除封禁失败")
+	}
+
+	util.Info("解除封禁: user_id=%d operator_id=%d", userID, operatorID)
+	return nil
+}
+
+// UpdateUser 管理员修改用户昵称或邮箱
+func (s *UserService) UpdateUser(operatorID, userID uint, req *UpdateUserRequest) (*model.User, error) {
+	if _, err := s.userDAO.FindByID(userID); err != nil {
+		return nil, errors.New("用户不存在")
+	}
+
+	fields := make(map[string]interface{})
+	if req.Nickname != nil {
+		fields["nickname"] = *req.Nickname
+	}
+	if req.Email != nil {
+		if existing, err := s.userDAO.GetByEmail(*req.Email); err == nil && existing.ID != userID {
+			return nil, errors.New("邮箱已被使用")
+		}
+		fields["email"] = *req.Email
+	}
+	if len(fields) == 0 {
+		return nil, errors.New("没有需要修改的字段")
+	}
+
+	if err := s.userDAO.UpdateFields(userID, fields); err != nil {
+		return nil, errors.New("修改用户失败")
+	}
+
+	util.Info("修改用户资料: user_id=%d fields=%v operator_id=%d", userID, fields, operatorID)
+	return s.userDAO.FindByID(userID)
+}
+
+// checkBan 校验封禁状态：封禁已到期时自动解封，否则返回封禁原因
+func (s *UserService) checkBan(user *model.User) error {
+	if user.Status == model.UserStatusBanned && user.BannedUntil != nil && !time.Now().Before(*user.BannedUntil) {
+		if err := s.unban(user.ID); err != nil {
+			return errors.New("登录失败，请稍后再试")
+		}
+		user.Status = model.UserStatusNormal
+		return nil
+	}
+
+	msg := "用户已被禁用"
+	if user.BanReason != "" {
+		msg += "，原因：" + user.BanReason
+	}
+	if user.BannedUntil != nil {
+		msg += "，解封时间：" + user.BannedUntil.Format("2006-01-02 15:04:05")
+	}
+	return errors.New(msg)
+}
+
+func (s *UserService) unban(userID uint) error {
+	return s.userDAO.UpdateFields(userID, map[string]interface{}{
+		"status":       model.UserStatusNormal,
+		"ban_reason":   "",
+		"banned_until": nil,
+	})
+}
@@ -0,0 +1,30 @@
+package service
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateRegister(t *testing.T) {
+	tests := []struct {
+		username string
+		password string
+		ok       bool
+	}{
+		{"abc", "123456", true},
+		{"玩家一号", "123456", true},
+		{strings.Repeat("a", 50), strings.Repeat("p", 50), true},
+		{"a", "123456", false},
+		{"ab", "123456", false},
+		{strings.Repeat("a", 51), "123456", false},
+		{"abc", "1", false},
+		{"abc", "12345", false},
+		{"abc", strings.Repeat("p", 51), false},
+	}
+	for _, tt := range tests {
+		err := validateRegister(&RegisterRequest{Username: tt.username, Password: tt.password})
+		if (err == nil) != tt.ok {
+			t.Errorf("validateRegister(%q, %q) = %v, want ok=%v", tt.username, tt.password, err, tt.ok)
+		}
+	}
+}
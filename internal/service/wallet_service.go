@@ -0,0 +1,155 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"bgame/internal/dao"
+	"bgame/internal/model"
+	"bgame/internal/util"
+	"gorm.io/gorm"
+)
+
+type WalletService struct {
+	walletDAO      *dao.WalletDAO
+	userProfileDAO *dao.UserProfileDAO
+}
+
+func NewWalletService() *WalletService {
+	return &WalletService{
+		walletDAO:      dao.NewWalletDAO(),
+		userProfileDAO: dao.NewUserProfileDAO(),
+	}
+}
+
+type WalletResponse struct {
+	Balance         model.Money `json:"balance" swaggertype:"number"`
+	ActivityBalance model.Money `json:"activity_balance" swaggertype:"number"`
+}
+
+type WalletTransactionQuery struct {
+	util.PageQuery
+	Currency   model.WalletCurrency  `form:"currency"`
+	Direction  model.WalletDirection `form:"direction"`
+	ReasonCode string                `form:"reason_code"`
+}
+
+type AdminWalletTransactionQuery struct {
+	WalletTransactionQuery
+	UserID      uint      `form:"user_id"`
+	ReferenceID string    `form:"reference_id"`
+	StartTime   time.Time `form:"start_time" time_format:"2006-01-02 15:04:05"`
+	EndTime     time.Time `form:"end_time" time_format:"2006-01-02 15:04:05"`
+}
+
+type AdjustWalletRequest struct {
+	UserID    uint                  `json:"user_id" binding:"required"`
+	Currency  model.WalletCurrency  `json:"currency" binding:"required"`
+	Direction model.WalletDirection `json:"direction" binding:"required"`
+	Amount    model.Money           `json:"amount" binding:"required,gt=0" swaggertype:"number"`
+	Remark    string                `json:"remark" binding:"required,max=255"`
+}
+
+// GetWallet 获取用户钱包余额
+func (s *WalletService) GetWallet(userID uint) (*WalletResponse, error) {
+	profile, err := s.userProfileDAO.GetUserProfileByUserID(userID)
+	if err != nil {
+		return nil, errors.New("用户不存在")
+	}
+	return &WalletResponse{
+		Balance:         profile.Balance,
+		ActivityBalance: profile.ActivityBalance,
+	}, nil
+}
+
+// Change 执行一笔钱包变动（独立事务）
+func (s *WalletService) Change(change *dao.WalletChange) (*model.WalletLedgerEntry, error) {
+	var entry *model.WalletLedgerEntry
+	err := dao.Transaction(func(tx *gorm.DB) error {
+		var err error
+		entry, err = s.ChangeTx(tx, change)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// ChangeTx 在调用方事务中执行一笔钱包变动，供需要与其他写操作保持原子性的业务使用
+func (s *WalletService) ChangeTx(tx *gorm.DB, change *dao.WalletChange) (*model.WalletLedgerEntry, error) {
+	if !change.Currency.Valid() {
+		return nil, errors.New("无效的币种")
+	}
+	if !change.Direction.Valid() {
+		return nil, errors.New("无效的变动方向")
+	}
+	if !change.Amount.IsPositive() {
+		return nil, errors.New("金额必须大于0")
+	}
+
+	entry, err := s.walletDAO.Apply(tx, change)
+	if err != nil {
+		if errors.Is(err, dao.ErrInsufficientBalance) || errors.Is(err, dao.ErrBalanceOverflow) {
+			return nil, err
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("用户不存在")
+		}
+		util.LogError("钱包变动失败: user_id=%d currency=%s err=%v", change.UserID, change.Currency, err)
+		return nil, errors.New("钱包变动失败")
+	}
+	return entry, nil
+}
+
+// Adjust 管理员调整用户余额
+func (s *WalletService) Adjust(operatorID uint, req *AdjustWalletRequest) (*model.WalletLedgerEntry, error) {
+	entry, err := s.Change(&dao.WalletChange{
+		UserID:     req.UserID,
+		Currency:   req.Currency,
+		Direction:  req.Direction,
+		Amount:     req.Amount,
+		ReasonCode: model.WalletReasonAdminAdjust,
+		OperatorID: operatorID,
+		Remark:     req.Remark,
+	})
+	if err != nil {
+		return nil, err
+	}
+	util.Info("管理员调整余额: admin_id=%d user_id=%d currency=%s direction=%s amount=%s tx_no=%s",
+		operatorID, req.UserID, req.Currency, req.Direction, req.Amount, entry.TxNo)
+	return entry, nil
+}
+
+// ListUserTransactions 查询用户自己的流水
+func (s *WalletService) ListUserTransactions(userID uint, req *WalletTransactionQuery) (*util.PageResult, error) {
+	req.Normalize()
+	entries, total, err := s.walletDAO.ListUserEntries(&dao.WalletLedgerFilter{
+		UserID:     userID,
+		Currency:   req.Currency,
+		Direction:  req.Direction,
+		ReasonCode: req.ReasonCode,
+	}, req.Offset(), req.PageSize)
+	if err != nil {
+		return nil, errors.New("查询流水失败")
+	}
+	return util.NewPageResult(entries, total, &req.PageQuery), nil
+}
+
+// ListTransactions 管理员查询流水
+func (s *WalletService) ListTransactions(req *AdminWalletTransactionQuery) (*util.PageResult, error) {
+	req.Normalize()
+	entries, total, err := s.walletDAO.ListUserEntries(&dao.WalletLedgerFilter{
+		UserID:      req.UserID,
+		Currency:    req.Currency,
+		Direction:   req.Direction,
+		ReasonCode:  req.ReasonCode,
+		ReferenceID: req.ReferenceID,
+		StartTime:   req.StartTime,
+		EndTime:     req.EndTime,
+	}, req.Offset(), req.PageSize)
+	if err != nil {
+		return nil, errors.New("查询流水失败")
+	}
+	return util.NewPageResult(entries, total, &req.PageQuery), nil
+}
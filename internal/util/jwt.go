@@ -13,33 +13,41 @@ type Claims struct {
 	Username string `json:"username"`
 	Type     string `json:"type"` // "user" or "admin"
 	Role     int    `json:"role,omitempty"` // admin role
-	jwt.RegisteredClaims
+	jwt.RegisteredClaims // ID 即 jti，用于服务端吊销
 }
 
 // GenerateUserToken 生成用户token
 func GenerateUserToken(userID uint, username string) (string, error) {
 	cfg := config.Cfg
 	expireTime := time.Now().Add(time.Duration(cfg.JWT.UserExpire) * time.Second)
+	jti, err := RandomToken(16)
+	if err != nil {
+		return "", err
+	}
 
 	claims := &Claims{
 		UserID:   userID,
 		Username: username,
 		Type:     "user",
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
 			ExpiresAt: jwt.NewNumericDate(expireTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(cfg.JWT.Secret))
+	return signClaims(claims)
 }
 
 // GenerateAdminToken 生成管理员token
 func GenerateAdminToken(adminID uint, username string, role int) (string, error) {
 	cfg := config.Cfg
 	expireTime := time.Now().Add(time.Duration(cfg.JWT.AdminExpire) * time.Second)
+	jti, err := RandomToken(16)
+	if err != nil {
+		return "", err
+	}
 
 	claims := &Claims{
 		UserID:   adminID,
@@ -47,25 +55,19 @@ func GenerateAdminToken(adminID uint, username string, role int) (string, error)
 		Type:     "admin",
 		Role:     role,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
 			ExpiresAt: jwt.NewNumericDate(expireTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(cfg.JWT.Secret))
+	return signClaims(claims)
 }
 
-// ParseToken 解析token
+// ParseToken 解析token（按 kid 选择验签密钥，无 kid 时按 HS256 校验）
 func ParseToken(tokenString string) (*Claims, error) {
-	cfg := config.Cfg
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("无效的签名方法")
-		}
-		return []byte(cfg.JWT.Secret), nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, verificationKey)
 
 	if err != nil {
 		return nil, err
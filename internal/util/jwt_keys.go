@@ -0,0 +1,238 @@
+package util
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"bgame/internal/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// jwtKey 一个可用于签名或验签的密钥
+type jwtKey struct {
+	kid         string
+	method      jwt.SigningMethod
+	privateKey  crypto.PrivateKey // 仅当前签名密钥需要
+	publicKey   crypto.PublicKey
+	verifyUntil time.Time // 零值表示长期有效
+}
+
+var (
+	jwtKeys      map[string]*jwtKey
+	activeJWTKey *jwtKey // 为 nil 时使用 jwt.secret 进行 HS256 签名
+	jwtKeysMutex sync.RWMutex
+)
+
+// JWK 单个公钥（RFC 7517）
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`   // RSA 模数
+	E   string `json:"e,omitempty"`   // RSA 指数
+	Crv string `json:"crv,omitempty"` // OKP 曲线
+	X   string `json:"x,omitempty"`   // OKP 公钥
+}
+
+// JWKSet JWKS 响应
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// InitJWTKeys 加载 jwt.keys 中配置的非对称密钥
+func InitJWTKeys() error {
+	cfg := config.Cfg.JWT
+	keys := make(map[string]*jwtKey, len(cfg.Keys))
+
+	for _, kc := range cfg.Keys {
+		key, err := loadJWTKey(kc)
+		if err != nil {
+			return fmt.Errorf("加载JWT密钥 %s 失败: %w", kc.KID, err)
+		}
+		if _, ok := keys[key.kid]; ok {
+			return fmt.Errorf("JWT密钥ID重复: %s", key.kid)
+		}
+		keys[key.kid] = key
+	}
+
+	var active *jwtKey
+	if cfg.ActiveKID != "" {
+		active = keys[cfg.ActiveKID]
+		if active == nil {
+			return fmt.Errorf("未找到当前签名密钥: %s", cfg.ActiveKID)
+		}
+		if active.privateKey == nil {
+			return fmt.Errorf("当前签名密钥 %s 未配置私钥", cfg.ActiveKID)
+		}
+	} else if cfg.Secret == "" {
+		return errors.New("未配置 jwt.secret 或 jwt.active_kid")
+	}
+
+	jwtKeysMutex.Lock()
+	jwtKeys = keys
+	activeJWTKey = active
+	jwtKeysMutex.Unlock()
+	return nil
+}
+
+// GetJWKS 返回仍在验签有效期内的全部公钥
+func GetJWKS() *JWKSet {
+	jwtKeysMutex.RLock()
+	defer jwtKeysMutex.RUnlock()
+
+	set := &JWKSet{Keys: make([]JWK, 0, len(jwtKeys))}
+	now := time.Now()
+	for _, key := range jwtKeys {
+		if !key.verifyUntil.IsZero() && now.After(key.verifyUntil) {
+			continue
+		}
+		jwk := JWK{Kid: key.kid, Use: "sig", Alg: key.method.Alg()}
+		switch pub := key.publicKey.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		default:
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].Kid < set.Keys[j].Kid })
+	return set
+}
+
+// signClaims 使用当前签名密钥签发token
+func signClaims(claims *Claims) (string, error) {
+	jwtKeysMutex.RLock()
+	active := activeJWTKey
+	jwtKeysMutex.RUnlock()
+
+	if active == nil {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		return token.SignedString([]byte(config.Cfg.JWT.Secret))
+	}
+
+	token := jwt.NewWithClaims(active.method, claims)
+	token.Header["kid"] = active.kid
+	return token.SignedString(active.privateKey)
+}
+
+// verificationKey 根据token头部选择验签密钥
+func verificationKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		// 未携带 kid 的旧 token 仅在配置了 jwt.secret 时按 HS256 校验
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("无效的签名方法")
+		}
+		if config.Cfg.JWT.Secret == "" {
+			return nil, errors.New("未启用HMAC签名")
+		}
+		return []byte(config.Cfg.JWT.Secret), nil
+	}
+
+	jwtKeysMutex.RLock()
+	key := jwtKeys[kid]
+	jwtKeysMutex.RUnlock()
+
+	if key == nil {
+		return nil, errors.New("未知的密钥ID")
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, errors.New("无效的签名方法")
+	}
+	if !key.verifyUntil.IsZero() && time.Now().After(key.verifyUntil) {
+		return nil, errors.New("密钥已过期")
+	}
+	return key.publicKey, nil
+}
+
+// loadJWTKey 从 PEM 文件加载密钥，未配置公钥文件时从私钥推导
+func loadJWTKey(kc config.JWTKeyConfig) (*jwtKey, error) {
+	if kc.KID == "" {
+		return nil, errors.New("kid 不能为空")
+	}
+
+	key := &jwtKey{kid: kc.KID}
+	if kc.VerifyUntil != "" {
+		t, err := time.Parse(time.RFC3339, kc.VerifyUntil)
+		if err != nil {
+			return nil, fmt.Errorf("verify_until 格式错误: %w", err)
+		}
+		key.verifyUntil = t
+	}
+
+	switch kc.Algorithm {
+	case "RS256":
+		key.method = jwt.SigningMethodRS256
+		if kc.PrivateKeyFile != "" {
+			data, err := os.ReadFile(kc.PrivateKeyFile)
+			if err != nil {
+				return nil, err
+			}
+			priv, err := jwt.ParseRSAPrivateKeyFromPEM(data)
+			if err != nil {
+				return nil, err
+			}
+			key.privateKey = priv
+			key.publicKey = &priv.PublicKey
+		}
+		if kc.PublicKeyFile != "" {
+			data, err := os.ReadFile(kc.PublicKeyFile)
+			if err != nil {
+				return nil, err
+			}
+			pub, err := jwt.ParseRSAPublicKeyFromPEM(data)
+			if err != nil {
+				return nil, err
+			}
+			key.publicKey = pub
+		}
+	case "EdDSA":
+		key.method = jwt.SigningMethodEdDSA
+		if kc.PrivateKeyFile != "" {
+			data, err := os.ReadFile(kc.PrivateKeyFile)
+			if err != nil {
+				return nil, err
+			}
+			priv, err := jwt.ParseEdPrivateKeyFromPEM(data)
+			if err != nil {
+				return nil, err
+			}
+			key.privateKey = priv
+			key.publicKey = priv.(ed25519.PrivateKey).Public()
+		}
+		if kc.PublicKeyFile != "" {
+			data, err := os.ReadFile(kc.PublicKeyFile)
+			if err != nil {
+				return nil, err
+			}
+			pub, err := jwt.ParseEdPublicKeyFromPEM(data)
+			if err != nil {
+				return nil, err
+			}
+			key.publicKey = pub
+		}
+	default:
+		return nil, fmt.Errorf("不支持的签名算法: %s", kc.Algorithm)
+	}
+
+	if key.publicKey == nil {
+		return nil, errors.New("未配置 private_key_file 或 public_key_file")
+	}
+	return key, nil
+}
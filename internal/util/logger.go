@@ -13,11 +13,12 @@ import (
 )
 
 var (
-	infoLogger  *log.Logger
-	errorLogger *log.Logger
-	logMutex    sync.Mutex
-	currentDate string
-	logDir      string
+	infoLogger     *log.Logger
+	errorLogger    *log.Logger
+	securityLogger *log.Logger
+	logMutex       sync.Mutex
+	currentDate    string
+	logDir         string
 )
 
 // InitLogger 初始化日志系统
@@ -45,7 +46,7 @@ func updateLoggers() error {
 	today := time.Now().Format("2006-01-02")
 
 	// 如果日期没变，不需要更新
-	if currentDate == today && infoLogger != nil && errorLogger != nil {
+	if currentDate == today && infoLogger != nil && errorLogger != nil && securityLogger != nil {
 		return nil
 	}
 
@@ -63,9 +64,16 @@ func updateLoggers() error {
 		return fmt.Errorf("打开 error 日志文件失败: %w", err)
 	}
 
+	// Security 日志文件
+	securityFile, err := openLogFile(filepath.Join(logDir, fmt.Sprintf("%s.security.log", today)))
+	if err != nil {
+		return fmt.Errorf("打开 security 日志文件失败: %w", err)
+	}
+
 	// 创建日志记录器
 	infoLogger = log.New(infoFile, "[INFO] ", log.LstdFlags|log.Lshortfile)
 	errorLogger = log.New(errorFile, "[ERROR] ", log.LstdFlags|log.Lshortfile)
+	securityLogger = log.New(securityFile, "[SECURITY] ", log.LstdFlags)
 
 	return nil
 }
@@ -90,8 +98,11 @@ func getLogger(level string) *log.Logger {
 		updateLoggers()
 	}
 
-	if level == "error" {
+	switch level {
+	case "error":
 		return errorLogger
+	case "security":
+		return securityLogger
 	}
 	return infoLogger
 }
@@ -126,6 +137,16 @@ func Warn(format string, v ...interface{}) {
 	log.Printf("[WARN] "+format, v...)
 }
 
+// Security 记录安全事件日志（账号锁定等，写入 security 文件）
+func Security(format string, v ...interface{}) {
+	logger := getLogger("security")
+	if logger != nil {
+		logger.Printf(format, v...)
+	}
+	// 同时输出到控制台
+	log.Printf("[SECURITY] "+format, v...)
+}
+
 // Debug 记录 Debug 级别日志（根据配置决定是否记录）
 func Debug(format string, v ...interface{}) {
 	if config.Cfg != nil && config.Cfg.Log.Level == "debug" {
@@ -0,0 +1,48 @@
+package util
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// PageQuery 分页查询参数
+type PageQuery struct {
+	Page     int `form:"page" json:"page"`
+	PageSize int `form:"page_size" json:"page_size"`
+}
+
+// PageResult 分页查询结果
+type PageResult struct {
+	List     interface{} `json:"list"`
+	Total    int64       `json:"total"`
+	Page     int         `json:"page"`
+	PageSize int         `json:"page_size"`
+}
+
+// Normalize 修正非法的分页参数
+func (q *PageQuery) Normalize() {
+	if q.Page < 1 {
+		q.Page = 1
+	}
+	if q.PageSize < 1 {
+		q.PageSize = defaultPageSize
+	}
+	if q.PageSize > maxPageSize {
+		q.PageSize = maxPageSize
+	}
+}
+
+// Offset 计算数据库查询偏移量
+func (q *PageQuery) Offset() int {
+	return (q.Page - 1) * q.PageSize
+}
+
+// NewPageResult 组装分页结果
+func NewPageResult(list interface{}, total int64, q *PageQuery) *PageResult {
+	return &PageResult{
+		List:     list,
+		Total:    total,
+		Page:     q.Page,
+		PageSize: q.PageSize,
+	}
+}
@@ -61,3 +61,17 @@ func Forbidden(c *gin.Context, message string) {
 	})
 }
 
+func Conflict(c *gin.Context, message string) {
+	c.JSON(http.StatusConflict, Response{
+		Code:    http.StatusConflict,
+		Message: message,
+	})
+}
+
+func UnprocessableEntity(c *gin.Context, message string) {
+	c.JSON(http.StatusUnprocessableEntity, Response{
+		Code:    http.StatusUnprocessableEntity,
+		Message: message,
+	})
+}
+
@@ -0,0 +1,50 @@
+package util
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrSignatureMissing = errors.New("缺少签名")
+	ErrSignatureInvalid = errors.New("签名无效")
+	ErrSignatureExpired = errors.New("签名已过期")
+)
+
+// SignHMAC 计算回调签名：hex(HMAC-SHA256(secret, timestamp + "." + body))
+func SignHMAC(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyHMAC 校验回调签名和时间戳（Unix 秒），时间戳与当前时间的偏差超过 tolerance 时视为重放
+func VerifyHMAC(secret, timestamp string, body []byte, signature string, tolerance time.Duration) error {
+	if secret == "" || timestamp == "" || signature == "" {
+		return ErrSignatureMissing
+	}
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrSignatureInvalid
+	}
+	if tolerance > 0 {
+		diff := time.Since(time.Unix(ts, 0))
+		if diff < 0 {
+			diff = -diff
+		}
+		if diff > tolerance {
+			return ErrSignatureExpired
+		}
+	}
+	expected := SignHMAC(secret, timestamp, body)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return ErrSignatureInvalid
+	}
+	return nil
+}
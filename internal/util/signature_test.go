@@ -0,0 +1,100 @@
+package util
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSignHMAC(t *testing.T) {
+	// hex(HMAC-SHA256("secret", "1700000000.{}"))
+	const want = "b8569b78799ff9e3cbff0fc2d63a33a2b57f3282abd07c37ae5e8e7d79a5f163"
+	got := SignHMAC("secret", "1700000000", []byte("{}"))
+	if got != want {
+		t.Fatalf("SignHMAC = %s, want %s", got, want)
+	}
+	// 时间戳和请求体之间有分隔符，拼接位置不同的输入签名不同
+	if got == SignHMAC("secret", "170000000", []byte("0{}")) {
+		t.Error("timestamp/body boundary should affect signature")
+	}
+}
+
+func TestVerifyHMAC(t *testing.T) {
+	const secret = "callback-secret"
+	body := []byte(`{"order_no":"P1","status":"paid","amount":"10.00"}`)
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	sign := SignHMAC(secret, now, body)
+
+	if err := VerifyHMAC(secret, now, body, sign, 5*time.Minute); err != nil {
+		t.Fatalf("valid signature: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp string
+		body      []byte
+		signature string
+		want      error
+	}{
+		{"篡改请求体", secret, now, []byte(`{"order_no":"P1","status":"paid","amount":"99.00"}`), sign, ErrSignatureInvalid},
+		{"篡改时间戳", secret, strconv.FormatInt(time.Now().Unix()-1, 10), body, sign, ErrSignatureInvalid},
+		{"错误密钥", "other-secret", now, body, sign, ErrSignatureInvalid},
+		{"签名截断", secret, now, body, sign[:63], ErrSignatureInvalid},
+		{"签名大小写不同", secret, now, body, upperHex(sign), ErrSignatureInvalid},
+		{"时间戳非数字", secret, "abc", body, sign, ErrSignatureInvalid},
+		{"缺少签名", secret, now, body, "", ErrSignatureMissing},
+		{"缺少时间戳", secret, "", body, sign, ErrSignatureMissing},
+		{"未配置密钥", "", now, body, sign, ErrSignatureMissing},
+	}
+	for _, tt := range tests {
+		if err := VerifyHMAC(tt.secret, tt.timestamp, tt.body, tt.signature, 5*time.Minute); !errors.Is(err, tt.want) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestVerifyHMACTolerance(t *testing.T) {
+	const secret = "callback-secret"
+	body := []byte("{}")
+	tolerance := 5 * time.Minute
+
+	tests := []struct {
+		name   string
+		offset time.Duration
+		want   error
+	}{
+		{"当前时间", 0, nil},
+		{"过去容忍范围内", -4 * time.Minute, nil},
+		{"未来容忍范围内", 4 * time.Minute, nil},
+		{"过去超出容忍范围", -6 * time.Minute, ErrSignatureExpired},
+		{"未来超出容忍范围", 6 * time.Minute, ErrSignatureExpired},
+	}
+	for _, tt := range tests {
+		ts := strconv.FormatInt(time.Now().Add(tt.offset).Unix(), 10)
+		if err := VerifyHMAC(secret, ts, body, SignHMAC(secret, ts, body), tolerance); !errors.Is(err, tt.want) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	// tolerance 为0时不校验时间戳
+	old := strconv.FormatInt(time.Now().Add(-24*time.Hour).Unix(), 10)
+	if err := VerifyHMAC(secret, old, body, SignHMAC(secret, old, body), 0); err != nil {
+		t.Errorf("zero tolerance: %v", err)
+	}
+	// 过期检查先于签名比较，过期的伪造请求也返回过期
+	if err := VerifyHMAC(secret, old, body, "forged", tolerance); !errors.Is(err, ErrSignatureExpired) {
+		t.Errorf("expired forged: err = %v, want %v", err, ErrSignatureExpired)
+	}
+}
+
+func upperHex(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		if c >= 'a' && c <= 'f' {
+			b[i] = c - 'a' + 'A'
+		}
+	}
+	return string(b)
+}
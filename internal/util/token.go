@@ -0,0 +1,54 @@
+package util
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"math/big"
+	"time"
+)
+
+// codeAlphabet 人工输入用的字符集，去掉易混淆的 0/O、1/I/L
+const codeAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+
+// RandomToken 生成指定字节数的随机串（base64url 编码，无填充）
+func RandomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// RandomCode 生成指定长度的随机码（大写字母和数字，不含易混淆字符），用于兑换码等需要人工输入的场景
+func RandomCode(length int) (string, error) {
+	max := big.NewInt(int64(len(codeAlphabet)))
+	b := make([]byte, length)
+	for i := range b {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		b[i] = codeAlphabet[n.Int64()]
+	}
+	return string(b), nil
+}
+
+// GenerateRefreshToken 生成不透明的 refresh token
+func GenerateRefreshToken() (string, error) {
+	return RandomToken(32)
+}
+
+// HashToken 计算 token 的 SHA-256 摘要，服务端只保存摘要
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewSerialNo 生成带前缀的业务流水号：前缀 + 时间戳 + 8位随机十六进制
+func NewSerialNo(prefix string) string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return prefix + time.Now().Format("20060102150405") + hex.EncodeToString(b)
+}
@@ -0,0 +1,110 @@
+package util
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret RFC 6238 附录 B 的 SHA1 测试密钥 "12345678901234567890" 的 base32 编码
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// RFC 6238 附录 B 的 SHA1 测试向量，取8位结果的后6位
+var rfc6238Vectors = []struct {
+	unix int64
+	code string
+}{
+	{59, "287082"},
+	{1111111109, "081804"},
+	{1111111111, "050471"},
+	{1234567890, "005924"},
+	{2000000000, "279037"},
+	{20000000000, "353130"},
+}
+
+func TestValidateTOTPRFC6238(t *testing.T) {
+	for _, v := range rfc6238Vectors {
+		now := time.Unix(v.unix, 0)
+		step, ok := ValidateTOTP(rfc6238Secret, v.code, now, 0)
+		if !ok {
+			t.Errorf("t=%d: code %s rejected", v.unix, v.code)
+			continue
+		}
+		if want := v.unix / totpPeriod; step != want {
+			t.Errorf("t=%d: step = %d, want %d", v.unix, step, want)
+		}
+	}
+}
+
+func TestValidateTOTPSkew(t *testing.T) {
+	// 59 秒的验证码属于第1个时间步，在相邻时间步内按 skew 放行
+	next := time.Unix(59+totpPeriod, 0)
+	if _, ok := ValidateTOTP(rfc6238Secret, "287082", next, 0); ok {
+		t.Error("code from previous step accepted without skew")
+	}
+	step, ok := ValidateTOTP(rfc6238Secret, "287082", next, 1)
+	if !ok || step != 1 {
+		t.Errorf("skew=1: step = %d, ok = %v, want 1, true", step, ok)
+	}
+	if _, ok := ValidateTOTP(rfc6238Secret, "287082", time.Unix(59+2*totpPeriod, 0), 1); ok {
+		t.Error("code two steps old accepted with skew=1")
+	}
+}
+
+func TestValidateTOTPInvalid(t *testing.T) {
+	now := time.Unix(59, 0)
+	for _, code := range []string{"", "28708", "2870820", "287083", "abcdef"} {
+		if _, ok := ValidateTOTP(rfc6238Secret, code, now, 1); ok {
+			t.Errorf("code %q accepted", code)
+		}
+	}
+	if _, ok := ValidateTOTP("not base32!", "287082", now, 1); ok {
+		t.Error("invalid secret accepted")
+	}
+	// 密钥大小写不敏感，验证码两端空白忽略
+	if _, ok := ValidateTOTP(strings.ToLower(rfc6238Secret), " 287082 ", now, 0); !ok {
+		t.Error("lowercase secret or padded code rejected")
+	}
+}
+
+func TestGenerateTOTPSecret(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := totpEncoding.DecodeString(secret)
+	if err != nil || len(key) != 20 {
+		t.Errorf("secret %q decodes to %d bytes, err %v", secret, len(key), err)
+	}
+}
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != 50 {
+		t.Fatalf("got %d codes, want 50", len(codes))
+	}
+	seen := make(map[string]bool)
+	for _, code := range codes {
+		if len(code) != 11 || code[5] != '-' {
+			t.Errorf("code %q is not in xxxxx-xxxxx format", code)
+		}
+		for i, r := range code {
+			if i != 5 && !strings.ContainsRune(recoveryCodeAlphabet, r) {
+				t.Errorf("code %q contains %q outside the alphabet", code, r)
+			}
+		}
+		if seen[code] {
+			t.Errorf("duplicate code %q", code)
+		}
+		seen[code] = true
+	}
+}
+
+func TestNormalizeRecoveryCode(t *testing.T) {
+	if got := NormalizeRecoveryCode(" ABCDE-fghjk "); got != "abcdefghjk" {
+		t.Errorf("NormalizeRecoveryCode = %q", got)
+	}
+}
@@ -1,22 +1,67 @@
 package main
 
 import (
+	"bufio"
+	"flag"
 	"fmt"
 	"log"
+	"os"
+	"strings"
 
+	"bgame/internal/config"
+	"bgame/internal/model"
+	"bgame/internal/service"
 	"bgame/internal/util"
+	"bgame/pkg/mysql"
 )
 
-// 用于生成管理员密码的工具脚本
-// 使用方法: go run scripts/create_admin.go
+// 创建管理员的命令行工具，用于初始化第一个超级管理员或在无法登录时找回访问权限
+// 需要先启动一次服务完成数据库迁移
+// 使用方法: go run scripts/create_admin.go -username admin [-role 1] [-config config.yaml]
+// 密码从标准输入读取，也可以通过环境变量 ADMIN_PASSWORD 传入
 func main() {
-	password := "admin123"
-	hashed, err := util.HashPassword(password)
+	configPath := flag.String("config", "config.yaml", "配置文件路径")
+	username := flag.String("username", "", "管理员用户名")
+	role := flag.Int("role", int(model.RoleSuperAdmin), "角色ID（1:超级管理员 2:管理员 3:操作员）")
+	flag.Parse()
+
+	if *username == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	password := os.Getenv("ADMIN_PASSWORD")
+	if password == "" {
+		fmt.Print("请输入密码: ")
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			log.Fatalf("读取密码失败: %v", err)
+		}
+		password = strings.TrimSpace(line)
+	}
+	if len(password) < 6 || len(password) > 50 {
+		log.Fatal("密码长度需为6-50位")
+	}
+
+	if err := config.LoadConfig(*configPath); err != nil {
+		log.Fatalf("加载配置失败: %v", err)
+	}
+	if err := util.InitLogger(); err != nil {
+		log.Fatalf("初始化日志系统失败: %v", err)
+	}
+	if err := mysql.Init(); err != nil {
+		log.Fatalf("初始化MySQL失败: %v", err)
+	}
+	defer mysql.Close()
+
+	admin, err := service.NewBootstrapService().CreateAdmin(&service.CreateAdminRequest{
+		Username: *username,
+		Password: password,
+		Role:     model.AdminRole(*role),
+	})
 	if err != nil {
-		log.Fatal(err)
+		log.Fatalf("创建管理员失败: %v", err)
 	}
-	fmt.Printf("原始密码: %s\n", password)
-	fmt.Printf("加密后的密码: %s\n", hashed)
-	fmt.Println("\n可以将此密码用于数据库初始化脚本")
-}
 
+	fmt.Printf("创建管理员成功: id=%d username=%s role=%s\n", admin.ID, admin.Username, admin.Role)
+}
@@ -41,11 +41,6 @@ CREATE TABLE IF NOT EXISTS `admins` (
   KEY `idx_admins_deleted_at` (`deleted_at`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
 
--- 插入一个默认超级管理员（密码：admin123）
--- 注意：实际密码需要经过 bcrypt 加密
--- 可以使用以下 Go 代码生成加密后的密码：
--- password, _ := util.HashPassword("admin123")
-INSERT INTO `admins` (`username`, `password`, `role`, `status`, `created_at`, `updated_at`) 
-VALUES ('admin', '$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy', 1, 1, NOW(), NOW())
-ON DUPLICATE KEY UPDATE `username`=`username`;
-
+-- 不再内置默认管理员账号，第一个超级管理员通过以下任一方式创建：
+-- 1. 服务启动时若没有任何管理员，会在控制台输出一次性初始化 token，调用 POST /api/admin/setup 创建
+-- 2. 运行 go run scripts/create_admin.go -username admin 按提示输入密码
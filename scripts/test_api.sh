@@ -2,6 +2,7 @@
 
 # API 测试脚本
 # 使用方法: bash scripts/test_api.sh
+# 管理员账号通过环境变量 ADMIN_USERNAME、ADMIN_PASSWORD 指定
 
 BASE_URL="http://localhost:8080"
 
@@ -40,8 +41,8 @@ echo "=== 测试管理员登录 ==="
 ADMIN_TOKEN=$(curl -s -X POST "${BASE_URL}/api/admin/login" \
   -H "Content-Type: application/json" \
   -d '{
-    "username": "admin",
-    "password": "admin123"
+    "username": "'"${ADMIN_USERNAME:-admin}"'",
+    "password": "'"${ADMIN_PASSWORD:-admin123}"'"
   }' | grep -o '"token":"[^"]*' | cut -d'"' -f4)
 
 echo "Admin Token: $ADMIN_TOKEN"